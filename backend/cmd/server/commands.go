@@ -0,0 +1,416 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/applehealth"
+	"github.com/satishthakur/health-assistant/backend/internal/auth"
+	"github.com/satishthakur/health-assistant/backend/internal/config"
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin/garmintest"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/ingesttoken"
+	"github.com/satishthakur/health-assistant/backend/internal/migrate"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/oura"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+	"github.com/satishthakur/health-assistant/backend/internal/whoop"
+)
+
+// runCommand dispatches CLI subcommands. The server starts when none is given.
+func runCommand(args []string) error {
+	switch args[0] {
+	case "import-garmin-export":
+		return runImport(importer.KindGarminExport, args)
+	case "import-apple-health":
+		return runImport(importer.KindAppleHealthExport, args)
+	case "sync-oura", "sync-whoop":
+		return runDeviceSync(args)
+	case "mock-garmin":
+		return runMockGarmin(args)
+	case "create-ingest-token":
+		return runCreateIngestToken(args)
+	case "set-role":
+		return runSetRole(args)
+	case "list-roles":
+		return runListRoles(args)
+	case "migrate":
+		return runMigrate(args)
+	case "help", "-h", "--help":
+		printUsage()
+		return nil
+	default:
+		printUsage()
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  server                                        start the HTTP server
+  server import-garmin-export -user ID FILE.zip import a Garmin Connect data export
+  server import-apple-health -user ID FILE      import an Apple Health export.zip or export.xml
+  server <import-command> -job ID               resume an interrupted import job
+  server sync-oura -user ID [-start -end]       pull Oura data (token: -token or OURA_ACCESS_TOKEN)
+  server sync-whoop -user ID [-start -end]      pull Whoop data (token: -token or WHOOP_ACCESS_TOKEN)
+  server mock-garmin [-addr :8090]              serve a fake Garmin Health API for local testing
+  server create-ingest-token -user ID -name NAME issue an ingest token (e.g. for the sync scheduler)
+  server set-role -user ID|EMAIL -role ROLE     make a user an admin, coach or plain user
+  server list-roles [-role ROLE]                list admins and coaches
+  server migrate up|down|status                 apply, revert the latest or list schema migrations
+  server migrate baseline -version N            record migrations up to N as applied by hand`)
+}
+
+// runImport imports an export archive in the foreground, printing progress.
+// Interrupted runs can be resumed with -job.
+func runImport(kind string, args []string) error {
+	command := args[0]
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID to import the export for")
+	jobID := fs.String("job", "", "resume an existing import job")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *jobID == "" && (*userID == "" || fs.NArg() != 1) {
+		return fmt.Errorf("usage: %s -user ID FILE", command)
+	}
+
+	cfg := config.Load()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	repo := importer.NewRepository(database)
+
+	var job *importer.Job
+	if *jobID != "" {
+		job, err = repo.GetJob(ctx, *jobID)
+		if err != nil {
+			return err
+		}
+		if job.Kind != kind {
+			return fmt.Errorf("job %s is a %s import", job.ID, job.Kind)
+		}
+	} else {
+		path, err := filepath.Abs(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+		job = &importer.Job{UserID: *userID, Kind: kind, FilePath: path}
+		if err := repo.CreateJob(ctx, job); err != nil {
+			return err
+		}
+		log.Printf("Created import job %s", job.ID)
+	}
+
+	eventRepo := db.NewEventRepository(database)
+	processors := map[string]importer.Processor{
+		importer.KindGarminExport:      &progressPrinter{next: garmin.NewExportProcessor(eventRepo, quarantine.NewRepository(database))},
+		importer.KindAppleHealthExport: &progressPrinter{next: applehealth.NewProcessor(eventRepo)},
+	}
+	// No upload dir: files given on the command line are never deleted
+	runner := importer.NewRunner(repo, processors, "")
+
+	if err := runner.Run(ctx, job); err != nil {
+		if ctx.Err() != nil {
+			log.Printf("Interrupted; resume with: %s -job %s", command, job.ID)
+		}
+		return err
+	}
+
+	log.Printf("Import complete: %d events imported, %d records skipped", job.EventsImported, job.SkippedRecords)
+	return nil
+}
+
+// progressPrinter logs each progress checkpoint of the wrapped processor.
+type progressPrinter struct {
+	next importer.Processor
+}
+
+func (p *progressPrinter) Process(ctx context.Context, job *importer.Job, report func(importer.Progress) error) error {
+	return p.next.Process(ctx, job, func(progress importer.Progress) error {
+		if progress.ProcessedItems > 0 {
+			log.Printf("Processed %d/%d (%d events imported, %d records skipped)",
+				progress.ProcessedItems, progress.TotalItems, progress.EventsImported, progress.SkippedRecords)
+		}
+		return report(progress)
+	})
+}
+
+// runDeviceSync pulls a date range from the Oura or Whoop API for one user.
+// -base-url points the client at a stub server for local testing.
+func runDeviceSync(args []string) error {
+	command := args[0]
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID to store the data for")
+	token := fs.String("token", "", "API access token")
+	baseURL := fs.String("base-url", "", "API base URL (defaults to the vendor API)")
+	start := fs.String("start", time.Now().AddDate(0, 0, -7).Format("2006-01-02"), "first day to sync (YYYY-MM-DD)")
+	end := fs.String("end", time.Now().Format("2006-01-02"), "last day to sync (YYYY-MM-DD)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	tokenEnv := "OURA_ACCESS_TOKEN"
+	if command == "sync-whoop" {
+		tokenEnv = "WHOOP_ACCESS_TOKEN"
+	}
+	if *token == "" {
+		*token = os.Getenv(tokenEnv)
+	}
+	if *userID == "" || *token == "" {
+		return fmt.Errorf("usage: %s -user ID [-token TOKEN | %s] [-start YYYY-MM-DD] [-end YYYY-MM-DD]", command, tokenEnv)
+	}
+
+	startDate, err := time.Parse("2006-01-02", *start)
+	if err != nil {
+		return fmt.Errorf("invalid -start: %w", err)
+	}
+	endDate, err := time.Parse("2006-01-02", *end)
+	if err != nil {
+		return fmt.Errorf("invalid -end: %w", err)
+	}
+
+	cfg := config.Load()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	eventRepo := db.NewEventRepository(database)
+
+	var inserted int
+	if command == "sync-oura" {
+		inserted, err = oura.Sync(ctx, oura.NewClient(*baseURL, *token), eventRepo, *userID, startDate, endDate)
+	} else {
+		// Whoop filters on timestamps; cover the whole of the end day
+		inserted, err = whoop.Sync(ctx, whoop.NewClient(*baseURL, *token), eventRepo, *userID, startDate, endDate.AddDate(0, 0, 1))
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Sync complete: %d new events", inserted)
+	return nil
+}
+
+// runMockGarmin serves a fake Garmin Health API so the connect flow and
+// webhook can be exercised locally. Point GARMIN_*_BASE_URL at it.
+func runMockGarmin(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	addr := fs.String("addr", ":8090", "address to listen on")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	consumerKey := config.Load().Garmin.ConsumerKey
+	if consumerKey == "" {
+		return fmt.Errorf("GARMIN_CONSUMER_KEY must be set to the key the server will use")
+	}
+
+	log.Printf("Mock Garmin Health API listening on %s (user %s)", *addr, garmintest.UserID)
+	return http.ListenAndServe(*addr, garmintest.Handler(consumerKey))
+}
+
+// runCreateIngestToken issues an ingest token for a user and prints its
+// secret, for services such as the sync scheduler that have no login.
+func runCreateIngestToken(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	userID := fs.String("user", "", "user ID the token writes data for")
+	name := fs.String("name", "", "name to tell the token apart, e.g. garmin-scheduler")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *userID == "" || *name == "" {
+		return fmt.Errorf("usage: %s -user ID -name NAME", args[0])
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	token, secret, err := ingesttoken.NewRepository(database).Create(ctx, *userID, *name)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Created ingest token %s (%s) for user %s", token.ID, token.Name, token.UserID)
+	fmt.Println(secret)
+	return nil
+}
+
+// runSetRole changes a user's role.
+func runSetRole(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	user := fs.String("user", "", "user ID or email")
+	role := fs.String("role", "", "user, admin or coach")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *user == "" || !models.ValidRole(*role) {
+		return fmt.Errorf("usage: %s -user ID|EMAIL -role user|admin|coach", args[0])
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	updated, err := auth.NewUserRepository(database).SetRole(ctx, *user, *role)
+	if err != nil {
+		return err
+	}
+	if updated == nil {
+		return fmt.Errorf("no user %q", *user)
+	}
+
+	log.Printf("User %s (%s) is now %s", updated.ID, updated.Email, updated.Role)
+	return nil
+}
+
+// runListRoles prints the users with a role other than user.
+func runListRoles(args []string) error {
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	role := fs.String("role", "", "only list this role")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *role != "" && !models.ValidRole(*role) {
+		return fmt.Errorf("invalid role %q", *role)
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	users, err := auth.NewUserRepository(database).ListUsersByRole(ctx, *role)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		fmt.Printf("%-6s %s %s\n", u.Role, u.ID, u.Email)
+	}
+	return nil
+}
+
+// runMigrate applies, reverts or lists schema migrations.
+func runMigrate(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: %s up|down|status|baseline -version N", args[0])
+	}
+	fs := flag.NewFlagSet(args[0]+" "+args[1], flag.ContinueOnError)
+	version := fs.Int("version", 0, "last migration applied by hand (baseline)")
+	if err := fs.Parse(args[2:]); err != nil {
+		return err
+	}
+
+	cfg := config.Load()
+	ctx := context.Background()
+
+	database, err := db.NewDatabase(ctx, cfg.Database)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer database.Close()
+
+	migrator, err := migrate.New(database)
+	if err != nil {
+		return err
+	}
+
+	switch args[1] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Printf("Applied %d migrations", applied)
+
+	case "down":
+		reverted, err := migrator.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			log.Println("No migrations applied")
+			return nil
+		}
+		log.Printf("Reverted migration %03d_%s", reverted.Version, reverted.Name)
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format(time.RFC3339)
+			}
+			switch {
+			case s.Modified:
+				state += " (modified since)"
+			case s.Unknown:
+				state += " (not in this binary)"
+			}
+			fmt.Printf("%03d %-30s %s\n", s.Version, s.Name, state)
+		}
+
+	case "baseline":
+		if *version <= 0 {
+			return fmt.Errorf("usage: %s baseline -version N", args[0])
+		}
+		recorded, err := migrator.Baseline(ctx, *version)
+		if err != nil {
+			return err
+		}
+		log.Printf("Recorded %d migrations up to %03d as applied", recorded, *version)
+
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[1])
+	}
+	return nil
+}
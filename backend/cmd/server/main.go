@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"net/http"
 	"os"
@@ -10,6 +9,11 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/satishthakur/health-assistant/backend/internal/accesstoken"
+	"github.com/satishthakur/health-assistant/backend/internal/activity"
+	"github.com/satishthakur/health-assistant/backend/internal/admin"
+	"github.com/satishthakur/health-assistant/backend/internal/alert"
+	"github.com/satishthakur/health-assistant/backend/internal/applehealth"
 	"github.com/satishthakur/health-assistant/backend/internal/audit"
 	"github.com/satishthakur/health-assistant/backend/internal/auth"
 	"github.com/satishthakur/health-assistant/backend/internal/checkin"
@@ -17,23 +21,76 @@ import (
 	"github.com/satishthakur/health-assistant/backend/internal/dashboard"
 	"github.com/satishthakur/health-assistant/backend/internal/db"
 	"github.com/satishthakur/health-assistant/backend/internal/garmin"
-	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/ingesttoken"
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+	"github.com/satishthakur/health-assistant/backend/internal/migrate"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/oura"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+	"github.com/satishthakur/health-assistant/backend/internal/signing"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
+	"github.com/satishthakur/health-assistant/backend/internal/sources"
+	"github.com/satishthakur/health-assistant/backend/internal/whoop"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.Fatalf("Command failed: %v", err)
+		}
+		return
+	}
+
 	log.Println("Starting Health Assistant Server...")
 
 	// Load configuration
 	cfg := config.Load()
 
 	// Initialize JWT token service
-	tokenService, err := auth.NewTokenService(cfg.Auth.JWTSecret, cfg.Auth.TokenDuration)
+	tokenService, err := auth.NewTokenService(cfg.Auth.JWTSecret, cfg.Auth.AccessTokenTTL)
 	if err != nil {
 		log.Fatalf("Invalid JWT configuration: %v", err)
 	}
 
-	// Initialize Google verifier (empty clientID = dev mode, audience check skipped)
-	googleVerifier := auth.NewGoogleVerifier(os.Getenv("GOOGLE_CLIENT_ID"))
+	// Initialize Google verifier (ID tokens checked locally against Google's JWKS)
+	googleVerifier, err := auth.NewGoogleVerifier(cfg.Auth.GoogleClientID, auth.NewJWKSCache(auth.GoogleJWKSURL), cfg.Auth.GoogleAllowAnyAudience)
+	if err != nil {
+		log.Fatalf("Invalid Google Sign-In configuration: %v", err)
+	}
+	if cfg.Auth.GoogleAllowAnyAudience {
+		log.Println("WARNING: GOOGLE_ALLOW_ANY_AUDIENCE is set; Google ID tokens for any client are accepted")
+	}
+	verifiers := map[string]auth.IDTokenVerifier{auth.ProviderGoogle: googleVerifier}
+
+	// Sign in with Apple and a generic OIDC provider are optional
+	if len(cfg.Auth.AppleClientIDs) > 0 {
+		appleVerifier, err := auth.NewAppleVerifier(cfg.Auth.AppleClientIDs, auth.NewJWKSCache(auth.AppleJWKSURL))
+		if err != nil {
+			log.Fatalf("Invalid Sign in with Apple configuration: %v", err)
+		}
+		verifiers[auth.ProviderApple] = appleVerifier
+	}
+	if cfg.Auth.OIDCIssuer != "" {
+		name := cfg.Auth.OIDCProviderName
+		if _, taken := verifiers[name]; taken || auth.IsReservedPath(name) {
+			log.Fatalf("OIDC_PROVIDER_NAME %q is reserved", name)
+		}
+		if cfg.Auth.OIDCClientID == "" {
+			log.Fatal("OIDC_CLIENT_ID must be set when OIDC_ISSUER is")
+		}
+		verifiers[name] = auth.NewOIDCVerifier(name, []string{cfg.Auth.OIDCIssuer}, []string{cfg.Auth.OIDCClientID}, auth.NewDiscoveredKeys(cfg.Auth.OIDCIssuer))
+		log.Printf("OIDC sign-in enabled as %q (issuer %s)", name, cfg.Auth.OIDCIssuer)
+	}
+
+	if os.Getenv("ADMIN_USER_IDS") != "" {
+		log.Println("WARNING: ADMIN_USER_IDS is no longer used; grant the admin role with `server set-role`")
+	}
+	for keyID := range cfg.Ingest.SigningKeys {
+		if len(cfg.Ingest.SigningKeyUsers[keyID]) == 0 {
+			log.Printf("WARNING: ingest signing key %q is bound to no users in INGEST_SIGNING_KEY_USERS; requests signed with it are refused", keyID)
+		}
+	}
 
 	// Initialize database connection
 	ctx := context.Background()
@@ -45,72 +102,126 @@ func main() {
 
 	log.Println("Database connection established")
 
+	if cfg.Database.AutoMigrate {
+		migrator, err := migrate.New(database)
+		if err != nil {
+			log.Fatalf("Failed to load migrations: %v", err)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		log.Printf("Database schema up to date (%d migrations applied)", applied)
+	}
+
 	// Create repositories
 	eventRepo := db.NewEventRepository(database)
 	userRepo := auth.NewUserRepository(database)
 	checkinRepo := checkin.NewRepository(database)
 	auditRepo := audit.NewRepository(database)
+	importRepo := importer.NewRepository(database)
+	sourcesRepo := sources.NewRepository(database)
+	garminConns := garmin.NewConnectionRepository(database)
+	quarantineRepo := quarantine.NewRepository(database)
 
-	// Create handlers
-	authHandler := auth.NewHandler(googleVerifier, userRepo, tokenService)
-	garminHandler := garmin.NewHandler(eventRepo)
-	auditHandler := audit.NewHandler(auditRepo)
-	checkinHandler := checkin.NewHandler(eventRepo, checkinRepo)
-	dashboardHandler := dashboard.NewHandler(checkinRepo)
-
-	// Build middleware
-	requireAuth := middleware.WithAuth(tokenService)
-	requireIngest := middleware.WithIngestSecret(os.Getenv("GARMIN_INGEST_SECRET"))
-
-	// Setup routes
-	mux := http.NewServeMux()
-
-	// Health check endpoint (public)
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
-		defer cancel()
+	// Start the background import runner; unfinished jobs resume from their checkpoint
+	importRunner := importer.NewRunner(importRepo, map[string]importer.Processor{
+		importer.KindGarminExport:      garmin.NewExportProcessor(eventRepo, quarantineRepo),
+		importer.KindAppleHealthExport: applehealth.NewProcessor(eventRepo),
+	}, cfg.Import.Dir)
+	runnerCtx, stopRunner := context.WithCancel(ctx)
+	defer stopRunner()
+	if err := importRunner.Start(runnerCtx); err != nil {
+		log.Printf("Failed to resume import jobs: %v", err)
+	}
 
-		dbStatus := "connected"
-		if err := database.Health(ctx); err != nil {
-			dbStatus = "disconnected"
-			log.Printf("Database health check failed: %v", err)
-		}
+	// Create handlers
+	sessionRepo := auth.NewSessionRepository(database)
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{
-			"status":          "healthy",
-			"service":         "health-assistant",
-			"database_status": dbStatus,
+	// Initialize mailer (verification and password reset emails)
+	var mail mailer.Mailer = mailer.LogMailer{}
+	if cfg.Mail.SMTPHost != "" {
+		mail, err = mailer.NewSMTPMailer(mailer.SMTPConfig{
+			Host:     cfg.Mail.SMTPHost,
+			Port:     cfg.Mail.SMTPPort,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+			From:     cfg.Mail.From,
 		})
+		if err != nil {
+			log.Fatalf("Invalid SMTP configuration: %v", err)
+		}
+	} else {
+		log.Println("SMTP_HOST not set; emails will be written to the log")
+	}
+	ingestTokenRepo := ingesttoken.NewRepository(database)
+	accessTokenRepo := accesstoken.NewRepository(database)
+	authHandler := auth.NewHandler(verifiers, userRepo, sessionRepo, tokenService, cfg.Auth.RefreshTokenTTL, mail, cfg.Server.PublicURL, accessTokenRepo, ingestTokenRepo)
+	garminHandler := garmin.NewHandler(eventRepo, quarantineRepo)
+	ingestTokenHandler := ingesttoken.NewHandler(ingestTokenRepo)
+	accessTokenHandler := accesstoken.NewHandler(accessTokenRepo)
+	adminHandler := admin.NewHandler(admin.NewRepository(database))
+	quarantineHandler := quarantine.NewHandler(quarantineRepo, eventRepo, map[string]quarantine.Converter{
+		models.SourceGarmin: garmin.EventsFromPayload,
 	})
+	var notifiers []alert.Notifier
+	if cfg.Alert.WebhookURL != "" {
+		notifiers = append(notifiers, alert.NewWebhookNotifier(cfg.Alert.WebhookURL))
+	}
+	if cfg.Alert.SlackWebhookURL != "" {
+		notifiers = append(notifiers, alert.NewSlackNotifier(cfg.Alert.SlackWebhookURL))
+	}
+	if len(cfg.Alert.EmailTo) > 0 {
+		notifiers = append(notifiers, alert.NewEmailNotifier(mail, cfg.Alert.EmailTo))
+	}
+	if len(notifiers) == 0 {
+		log.Println("No ALERT_* destinations set; sync failure alerts are off")
+	}
+	syncMonitor := alert.NewMonitor(alert.NewRepository(database), notifiers, cfg.Alert.FailureThreshold, cfg.Alert.Cooldown)
+	auditHandler := audit.NewHandler(auditRepo, userRepo, syncMonitor)
+	// A single data type syncs in seconds; one running for an hour is dead
+	go audit.NewStaleSweeper(auditRepo, syncMonitor, time.Hour, 10*time.Minute).Run(runnerCtx)
+	checkinHandler := checkin.NewHandler(eventRepo, checkinRepo)
+	dashboardHandler := dashboard.NewHandler(checkinRepo)
+	sleepHandler := sleep.NewHandler(eventRepo)
+	activityHandler := activity.NewHandler(eventRepo)
+	importHandler := importer.NewHandler(importRepo, importRunner, cfg.Import.Dir)
+	ouraHandler := oura.NewHandler(eventRepo)
+	whoopHandler := whoop.NewHandler(eventRepo)
+	sourcesHandler := sources.NewHandler(sourcesRepo)
+	healthAPIClient := garmin.NewHealthAPIClient(cfg.Garmin)
+	healthAPIHandler := garmin.NewHealthAPIHandler(
+		healthAPIClient,
+		garminConns,
+		garmin.NewPushProcessor(healthAPIClient, garminConns, eventRepo, quarantineRepo),
+		cfg.Garmin.CallbackURL,
+	)
 
-	// Auth endpoint (public — no auth middleware)
-	mux.HandleFunc("/api/v1/auth/google", authHandler.HandleGoogleAuth)
-
-	// Garmin ingestion endpoints (ingest-secret protected, server-to-server)
-	mux.Handle("/api/v1/garmin/ingest/sleep", requireIngest(http.HandlerFunc(garminHandler.HandleSleepIngestion)))
-	mux.Handle("/api/v1/garmin/ingest/activity", requireIngest(http.HandlerFunc(garminHandler.HandleActivityIngestion)))
-	mux.Handle("/api/v1/garmin/ingest/hrv", requireIngest(http.HandlerFunc(garminHandler.HandleHRVIngestion)))
-	mux.Handle("/api/v1/garmin/ingest/stress", requireIngest(http.HandlerFunc(garminHandler.HandleStressIngestion)))
-	mux.Handle("/api/v1/garmin/ingest/daily-stats", requireIngest(http.HandlerFunc(garminHandler.HandleDailyStatsIngestion)))
-	mux.Handle("/api/v1/garmin/ingest/body-battery", requireIngest(http.HandlerFunc(garminHandler.HandleBodyBatteryIngestion)))
-
-	// Audit endpoints (JWT protected)
-	mux.Handle("/api/v1/audit/sync", requireAuth(http.HandlerFunc(auditHandler.HandlePostSyncAudit)))
-	mux.Handle("/api/v1/audit/sync/recent", requireAuth(http.HandlerFunc(auditHandler.HandleGetRecentSyncAudits)))
-	mux.Handle("/api/v1/audit/sync/by-type", requireAuth(http.HandlerFunc(auditHandler.HandleGetSyncAuditsByType)))
-	mux.Handle("/api/v1/audit/sync/stats", requireAuth(http.HandlerFunc(auditHandler.HandleGetSyncAuditStats)))
-
-	// Check-in endpoints (JWT protected)
-	mux.Handle("/api/v1/checkin", requireAuth(http.HandlerFunc(checkinHandler.HandleSubmission)))
-	mux.Handle("/api/v1/checkin/latest", requireAuth(http.HandlerFunc(checkinHandler.HandleGetLatest)))
-	mux.Handle("/api/v1/checkin/history", requireAuth(http.HandlerFunc(checkinHandler.HandleGetHistory)))
-
-	// Dashboard and trends endpoints (JWT protected)
-	mux.Handle("/api/v1/dashboard/today", requireAuth(http.HandlerFunc(dashboardHandler.HandleGetToday)))
-	mux.Handle("/api/v1/trends/week", requireAuth(http.HandlerFunc(dashboardHandler.HandleGetWeekTrends)))
-	mux.Handle("/api/v1/insights/correlations", requireAuth(http.HandlerFunc(dashboardHandler.HandleGetCorrelations)))
+	mux := newRouter(handlers{
+		auth:         authHandler,
+		healthAPI:    healthAPIHandler,
+		garmin:       garminHandler,
+		audit:        auditHandler,
+		checkin:      checkinHandler,
+		dashboard:    dashboardHandler,
+		sources:      sourcesHandler,
+		ingestTokens: ingestTokenHandler,
+		accessTokens: accessTokenHandler,
+		sleep:        sleepHandler,
+		activity:     activityHandler,
+		imports:      importHandler,
+		oura:         ouraHandler,
+		whoop:        whoopHandler,
+		quarantine:   quarantineHandler,
+		admin:        adminHandler,
+	}, authenticators{
+		tokens:       tokenService,
+		accessTokens: accessTokenRepo,
+		ingestTokens: ingestTokenRepo,
+		signatures:   signing.NewVerifier(cfg.Ingest.SigningKeys, cfg.Ingest.MaxSkew),
+		signingUsers: cfg.Ingest.SigningKeyUsers,
+		roles:        userRepo,
+	}, database)
 
 	// Create HTTP server
 	port := ":8083"
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/accesstoken"
+	"github.com/satishthakur/health-assistant/backend/internal/activity"
+	"github.com/satishthakur/health-assistant/backend/internal/admin"
+	"github.com/satishthakur/health-assistant/backend/internal/audit"
+	"github.com/satishthakur/health-assistant/backend/internal/auth"
+	"github.com/satishthakur/health-assistant/backend/internal/checkin"
+	"github.com/satishthakur/health-assistant/backend/internal/dashboard"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/ingesttoken"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/oura"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+	"github.com/satishthakur/health-assistant/backend/internal/signing"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
+	"github.com/satishthakur/health-assistant/backend/internal/sources"
+	"github.com/satishthakur/health-assistant/backend/internal/whoop"
+)
+
+// handlers holds one handler per API area.
+type handlers struct {
+	auth         *auth.Handler
+	healthAPI    *garmin.HealthAPIHandler
+	garmin       *garmin.Handler
+	audit        *audit.Handler
+	checkin      *checkin.Handler
+	dashboard    *dashboard.Handler
+	sources      *sources.Handler
+	ingestTokens *ingesttoken.Handler
+	accessTokens *accesstoken.Handler
+	sleep        *sleep.Handler
+	activity     *activity.Handler
+	imports      *importer.Handler
+	oura         *oura.Handler
+	whoop        *whoop.Handler
+	quarantine   *quarantine.Handler
+	admin        *admin.Handler
+}
+
+// authenticators holds what the middleware checks credentials against.
+type authenticators struct {
+	tokens       middleware.TokenValidator
+	accessTokens middleware.AccessTokenValidator
+	ingestTokens middleware.IngestTokenValidator
+	signatures   *signing.Verifier
+	signingUsers map[string][]string // users each signing key may write for
+	roles        middleware.RoleLookup
+}
+
+// healthChecker reports whether the database is reachable.
+type healthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// newRouter registers every route with its middleware.
+func newRouter(h handlers, a authenticators, database healthChecker) *http.ServeMux {
+	// Build middleware
+	requireAuth := middleware.WithAuth(a.tokens, a.accessTokens)
+	// requireScope also accepts personal access tokens granted scope
+	requireScope := func(scope string) func(http.Handler) http.Handler {
+		return middleware.WithAuth(a.tokens, a.accessTokens, scope)
+	}
+	requireIngest := middleware.WithIngestAuth(a.ingestTokens, a.signatures, a.signingUsers)
+	requireRole := func(roles ...string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return requireAuth(middleware.RequireRole(a.roles, roles...)(next))
+		}
+	}
+	requireAdmin := requireRole(models.RoleAdmin)
+
+	mux := http.NewServeMux()
+
+	// Health check endpoint (public)
+	mux.HandleFunc("/health", healthHandler(database))
+
+	// Auth endpoints (public — no auth middleware; refresh and logout take the refresh token,
+	// any other path is a sign-in provider)
+	mux.HandleFunc("/api/v1/auth/{provider}", h.auth.HandleSignIn)
+	mux.HandleFunc("/api/v1/auth/refresh", h.auth.HandleRefresh)
+	mux.HandleFunc("/api/v1/auth/logout", h.auth.HandleLogout)
+	mux.HandleFunc("/api/v1/auth/register", h.auth.HandleRegister)
+	mux.HandleFunc("/api/v1/auth/login", h.auth.HandleLogin)
+	mux.HandleFunc("/api/v1/auth/verify-email", h.auth.HandleVerifyEmail)
+	mux.Handle("/api/v1/auth/verify-email/resend", requireAuth(http.HandlerFunc(h.auth.HandleResendVerification)))
+	mux.HandleFunc("/api/v1/auth/password/forgot", h.auth.HandleForgotPassword)
+	mux.HandleFunc("/api/v1/auth/password/reset", h.auth.HandleResetPassword)
+
+	// Garmin Health API connect flow (JWT protected, except the browser callback)
+	mux.Handle("/api/v1/garmin/connect", requireAuth(http.HandlerFunc(h.healthAPI.HandleConnect)))
+	mux.Handle("/api/v1/garmin/connection", requireAuth(http.HandlerFunc(h.healthAPI.HandleConnection)))
+	mux.HandleFunc("/api/v1/garmin/callback", h.healthAPI.HandleCallback)
+
+	// Garmin Health API ping/push webhook (public — matched on Garmin user ID and access token)
+	mux.HandleFunc("/api/v1/garmin/push", h.healthAPI.HandlePush)
+
+	// Garmin ingestion endpoints (ingest token and/or signed request, server-to-server)
+	mux.Handle("/api/v1/garmin/ingest/sleep", requireIngest(http.HandlerFunc(h.garmin.HandleSleepIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/activity", requireIngest(http.HandlerFunc(h.garmin.HandleActivityIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/hrv", requireIngest(http.HandlerFunc(h.garmin.HandleHRVIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/stress", requireIngest(http.HandlerFunc(h.garmin.HandleStressIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/daily-stats", requireIngest(http.HandlerFunc(h.garmin.HandleDailyStatsIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/body-battery", requireIngest(http.HandlerFunc(h.garmin.HandleBodyBatteryIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/spo2", requireIngest(http.HandlerFunc(h.garmin.HandleSpO2Ingestion)))
+	mux.Handle("/api/v1/garmin/ingest/respiration", requireIngest(http.HandlerFunc(h.garmin.HandleRespirationIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/training-readiness", requireIngest(http.HandlerFunc(h.garmin.HandleTrainingReadinessIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/training-status", requireIngest(http.HandlerFunc(h.garmin.HandleTrainingStatusIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/vo2max", requireIngest(http.HandlerFunc(h.garmin.HandleVO2MaxIngestion)))
+	mux.Handle("/api/v1/garmin/ingest/intensity-minutes", requireIngest(http.HandlerFunc(h.garmin.HandleIntensityMinutesIngestion)))
+
+	// Audit endpoints (written by the scheduler with ingest credentials; read
+	// with a JWT, own audits only unless admin)
+	mux.Handle("/api/v1/audit/sync", requireIngest(http.HandlerFunc(h.audit.HandlePostSyncAudit)))
+	mux.Handle("/api/v1/audit/sync/start", requireIngest(http.HandlerFunc(h.audit.HandleStartSync)))
+	mux.Handle("/api/v1/audit/sync/{id}/complete", requireIngest(http.HandlerFunc(h.audit.HandleCompleteSync)))
+	mux.Handle("/api/v1/audit/sync/{id}/fail", requireIngest(http.HandlerFunc(h.audit.HandleFailSync)))
+	mux.Handle("/api/v1/audit/sync/recent", requireAuth(http.HandlerFunc(h.audit.HandleGetRecentSyncAudits)))
+	mux.Handle("/api/v1/audit/sync/by-type", requireAuth(http.HandlerFunc(h.audit.HandleGetSyncAuditsByType)))
+	mux.Handle("/api/v1/audit/sync/stats", requireAuth(http.HandlerFunc(h.audit.HandleGetSyncAuditStats)))
+
+	// Sync gaps (JWT for the report; ingest credentials for the scheduler's
+	// list of dates to re-fetch)
+	mux.Handle("/api/v1/sync/gaps", requireAuth(http.HandlerFunc(h.audit.HandleGetSyncGaps)))
+	mux.Handle("/api/v1/sync/refetch", requireIngest(http.HandlerFunc(h.audit.HandleGetRefetchDates)))
+
+	// Check-in endpoints (JWT, or access token with the route's scope)
+	mux.Handle("/api/v1/checkin", requireScope(accesstoken.ScopeWriteCheckin)(http.HandlerFunc(h.checkin.HandleSubmission)))
+	mux.Handle("/api/v1/checkin/latest", requireScope(accesstoken.ScopeReadEvents)(http.HandlerFunc(h.checkin.HandleGetLatest)))
+	mux.Handle("/api/v1/checkin/history", requireScope(accesstoken.ScopeReadEvents)(http.HandlerFunc(h.checkin.HandleGetHistory)))
+
+	// Dashboard and trends endpoints (JWT, or access token with read:insights)
+	mux.Handle("/api/v1/dashboard/today", requireScope(accesstoken.ScopeReadInsights)(http.HandlerFunc(h.dashboard.HandleGetToday)))
+	mux.Handle("/api/v1/trends/week", requireScope(accesstoken.ScopeReadInsights)(http.HandlerFunc(h.dashboard.HandleGetWeekTrends)))
+	mux.Handle("/api/v1/insights/correlations", requireScope(accesstoken.ScopeReadInsights)(http.HandlerFunc(h.dashboard.HandleGetCorrelations)))
+
+	// Source priority settings (JWT protected)
+	mux.Handle("/api/v1/settings/source-priorities", requireAuth(http.HandlerFunc(h.sources.HandleGetPriorities)))
+	mux.Handle("/api/v1/settings/source-priorities/{metric}", requireAuth(http.HandlerFunc(h.sources.HandleMetricPriority)))
+
+	// Signed-in devices (JWT protected)
+	mux.Handle("/api/v1/settings/devices", requireAuth(http.HandlerFunc(h.auth.HandleListDevices)))
+	mux.Handle("/api/v1/settings/devices/{id}", requireAuth(http.HandlerFunc(h.auth.HandleRevokeDevice)))
+
+	// Linked sign-in identities (JWT protected)
+	mux.Handle("/api/v1/settings/identities", requireAuth(http.HandlerFunc(h.auth.HandleIdentities)))
+	mux.Handle("/api/v1/settings/identities/{id}", requireAuth(http.HandlerFunc(h.auth.HandleUnlinkIdentity)))
+
+	// Ingest token settings (JWT protected)
+	mux.Handle("/api/v1/settings/ingest-tokens", requireAuth(http.HandlerFunc(h.ingestTokens.HandleTokens)))
+	mux.Handle("/api/v1/settings/ingest-tokens/{id}", requireAuth(http.HandlerFunc(h.ingestTokens.HandleRevoke)))
+
+	// Personal access tokens for scripts (JWT protected; a token can't manage tokens)
+	mux.Handle("/api/v1/settings/access-tokens", requireAuth(http.HandlerFunc(h.accessTokens.HandleTokens)))
+	mux.Handle("/api/v1/settings/access-tokens/{id}", requireAuth(http.HandlerFunc(h.accessTokens.HandleRevoke)))
+
+	// Sleep detail endpoints (JWT, or access token with read:events)
+	mux.Handle("/api/v1/sleep/{date}/hypnogram", requireScope(accesstoken.ScopeReadEvents)(http.HandlerFunc(h.sleep.HandleGetHypnogram)))
+
+	// Activity import endpoints (JWT protected)
+	mux.Handle("/api/v1/activities/import/fit", requireAuth(http.HandlerFunc(h.activity.HandleFITImport)))
+
+	// Bulk import endpoints (JWT protected)
+	mux.Handle("/api/v1/import/garmin-export", requireAuth(http.HandlerFunc(h.imports.HandleGarminExportUpload)))
+	mux.Handle("/api/v1/import/apple-health", requireAuth(http.HandlerFunc(h.imports.HandleAppleHealthUpload)))
+	mux.Handle("/api/v1/import/oura", requireAuth(http.HandlerFunc(h.oura.HandleImport)))
+	mux.Handle("/api/v1/import/whoop", requireAuth(http.HandlerFunc(h.whoop.HandleImport)))
+	mux.Handle("/api/v1/import/jobs", requireAuth(http.HandlerFunc(h.imports.HandleListJobs)))
+	mux.Handle("/api/v1/import/jobs/{id}", requireAuth(http.HandlerFunc(h.imports.HandleGetJob)))
+
+	// Admin endpoints (JWT protected, admin role only)
+	mux.Handle("/api/v1/admin/quarantine", requireAdmin(http.HandlerFunc(h.quarantine.HandleList)))
+	mux.Handle("/api/v1/admin/quarantine/stats", requireAdmin(http.HandlerFunc(h.quarantine.HandleStats)))
+	mux.Handle("/api/v1/admin/quarantine/{id}", requireAdmin(http.HandlerFunc(h.quarantine.HandleEntry)))
+	mux.Handle("/api/v1/admin/quarantine/{id}/replay", requireAdmin(http.HandlerFunc(h.quarantine.HandleReplay)))
+	mux.Handle("/debug/vars", requireAdmin(expvar.Handler()))
+
+	// Cross-user views and reprocessing. Coaches see neither: nothing says
+	// which users are theirs yet.
+	mux.Handle("/api/v1/admin/users", requireAdmin(http.HandlerFunc(h.admin.HandleListUsers)))
+	mux.Handle("/api/v1/admin/sync-health", requireAdmin(http.HandlerFunc(h.admin.HandleSyncHealth)))
+	mux.Handle("/api/v1/admin/users/{id}/reprocess", requireAdmin(http.HandlerFunc(h.quarantine.HandleReprocessUser)))
+
+	return mux
+}
+
+// healthHandler reports the service healthy, and whether database answers.
+func healthHandler(database healthChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		defer cancel()
+
+		dbStatus := "connected"
+		if err := database.Health(ctx); err != nil {
+			dbStatus = "disconnected"
+			log.Printf("Database health check failed: %v", err)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":          "healthy",
+			"service":         "health-assistant",
+			"database_status": dbStatus,
+		})
+	}
+}
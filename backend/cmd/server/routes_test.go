@@ -0,0 +1,1180 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/accesstoken"
+	"github.com/satishthakur/health-assistant/backend/internal/activity"
+	"github.com/satishthakur/health-assistant/backend/internal/admin"
+	"github.com/satishthakur/health-assistant/backend/internal/audit"
+	"github.com/satishthakur/health-assistant/backend/internal/auth"
+	"github.com/satishthakur/health-assistant/backend/internal/checkin"
+	"github.com/satishthakur/health-assistant/backend/internal/config"
+	"github.com/satishthakur/health-assistant/backend/internal/dashboard"
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin/garmintest"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/ingesttoken"
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/oura"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+	"github.com/satishthakur/health-assistant/backend/internal/signing"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
+	"github.com/satishthakur/health-assistant/backend/internal/sources"
+	"github.com/satishthakur/health-assistant/backend/internal/whoop"
+)
+
+const (
+	testJWTSecret         = "routes-test-secret-at-least-32-characters"
+	testSigningKey        = "routes-test-signing-key"
+	testGarminConsumerKey = "routes-test-consumer-key"
+	testGarminCallbackURL = "https://health.example.com/api/v1/garmin/callback"
+)
+
+// fakeIDTokens verifies an ID token by looking it up.
+type fakeIDTokens map[string]*auth.Identity
+
+func (f fakeIDTokens) VerifyIDToken(ctx context.Context, idToken string) (*auth.Identity, error) {
+	id, ok := f[idToken]
+	if !ok {
+		return nil, errors.New("unknown ID token")
+	}
+	return id, nil
+}
+
+type fakeDatabase struct{ err error }
+
+func (f *fakeDatabase) Health(ctx context.Context) error { return f.err }
+
+// testServer is the full router over in-memory stores, with a fake Garmin
+// Health API behind the connect flow.
+type testServer struct {
+	mux          *http.ServeMux
+	events       *db.MemoryEventStore
+	users        *auth.MemoryUserStore
+	sessions     *auth.MemorySessionStore
+	audits       *audit.MemoryStore
+	quarantine   *quarantine.MemoryStore
+	mail         *mailer.MemoryMailer
+	tokens       *auth.TokenService
+	idTokens     fakeIDTokens
+	accessTokens *accesstoken.MemoryStore
+	ingestTokens *ingesttoken.MemoryStore
+	signingUsers map[string][]string // users each signing key may write for
+	garmin       *garmintest.Server
+	database     *fakeDatabase
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	tokens, err := auth.NewTokenService(testJWTSecret, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &testServer{
+		events:       db.NewMemoryEventStore(),
+		users:        auth.NewMemoryUserStore(),
+		sessions:     auth.NewMemorySessionStore(),
+		mail:         mailer.NewMemoryMailer(),
+		tokens:       tokens,
+		quarantine:   quarantine.NewMemoryStore(),
+		idTokens:     fakeIDTokens{},
+		accessTokens: accesstoken.NewMemoryStore(),
+		ingestTokens: ingesttoken.NewMemoryStore(),
+		signingUsers: map[string][]string{},
+		garmin:       garmintest.NewServer(testGarminConsumerKey),
+		database:     &fakeDatabase{},
+	}
+	t.Cleanup(s.garmin.Close)
+	s.audits = audit.NewMemoryStore(s.events)
+
+	checkins := checkin.NewMemoryStore(s.events)
+	verifiers := map[string]auth.IDTokenVerifier{
+		auth.ProviderGoogle: s.idTokens,
+		auth.ProviderApple:  s.idTokens,
+	}
+	healthAPIClient := garmin.NewHealthAPIClient(config.GarminConfig{
+		ConsumerKey:    testGarminConsumerKey,
+		ConsumerSecret: "routes-test-consumer-secret",
+		OAuthBaseURL:   s.garmin.URL,
+		ConnectBaseURL: s.garmin.URL,
+		APIBaseURL:     s.garmin.URL,
+	})
+	garminConns := garmin.NewMemoryConnectionStore()
+	// Jobs are only queued: no worker runs them
+	imports := importer.NewMemoryStore()
+	uploadDir := t.TempDir()
+
+	s.mux = newRouter(handlers{
+		auth:         auth.NewHandler(verifiers, s.users, s.sessions, tokens, 30*24*time.Hour, s.mail, "https://health.example.com", s.accessTokens, s.ingestTokens),
+		healthAPI:    garmin.NewHealthAPIHandler(healthAPIClient, garminConns, garmin.NewPushProcessor(healthAPIClient, garminConns, s.events, s.quarantine), testGarminCallbackURL),
+		garmin:       garmin.NewHandler(s.events, s.quarantine),
+		audit:        audit.NewHandler(s.audits, s.users, nil),
+		checkin:      checkin.NewHandler(s.events, checkins),
+		dashboard:    dashboard.NewHandler(checkins),
+		sources:      sources.NewHandler(sources.NewMemoryStore()),
+		ingestTokens: ingesttoken.NewHandler(s.ingestTokens),
+		accessTokens: accesstoken.NewHandler(s.accessTokens),
+		sleep:        sleep.NewHandler(s.events),
+		activity:     activity.NewHandler(s.events),
+		imports:      importer.NewHandler(imports, importer.NewRunner(imports, nil, uploadDir), uploadDir),
+		oura:         oura.NewHandler(s.events),
+		whoop:        whoop.NewHandler(s.events),
+		quarantine: quarantine.NewHandler(s.quarantine, s.events, map[string]quarantine.Converter{
+			models.SourceGarmin: garmin.EventsFromPayload,
+		}),
+		admin: admin.NewHandler(admin.NewMemoryStore(s.users, s.sessions, s.audits)),
+	}, authenticators{
+		tokens:       tokens,
+		accessTokens: s.accessTokens,
+		ingestTokens: s.ingestTokens,
+		signatures:   signing.NewVerifier(map[string]string{"scheduler": testSigningKey, "unbound": testSigningKey}, signing.DefaultMaxSkew),
+		signingUsers: s.signingUsers,
+		roles:        s.users,
+	}, s.database)
+	return s
+}
+
+// request sends a request through the router. headers are name, value
+// pairs.
+func (s *testServer) request(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, r)
+	return w
+}
+
+var nonces atomic.Int64
+
+// signed returns the headers of a request signed with the scheduler's key.
+func signed(method, path, body string) []string {
+	return signedWith("scheduler", method, path, body)
+}
+
+// signedWith returns the headers of a request signed with the key keyID.
+func signedWith(keyID, method, path, body string) []string {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := fmt.Sprintf("nonce-%d", nonces.Add(1))
+	return []string{
+		signing.HeaderKeyID, keyID,
+		signing.HeaderTimestamp, timestamp,
+		signing.HeaderNonce, nonce,
+		signing.HeaderSignature, signing.Sign([]byte(testSigningKey), method, path, timestamp, nonce, []byte(body)),
+	}
+}
+
+func bearer(token string) []string {
+	return []string{"Authorization", "Bearer " + token}
+}
+
+// newUser creates a user with a password and returns its ID and an access
+// token for it.
+func (s *testServer) newUser(t *testing.T, email, role string) (string, string) {
+	t.Helper()
+	ctx := context.Background()
+
+	hash, err := auth.HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := s.users.CreateUserWithPassword(ctx, email, hash, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if role != models.RoleUser {
+		if _, err := s.users.SetRole(ctx, user.ID, role); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token, err := s.tokens.GenerateToken(user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.ID, token
+}
+
+// ingestToken issues an ingest token for userID and returns its secret.
+func (s *testServer) ingestToken(t *testing.T, userID string) string {
+	t.Helper()
+	_, secret, err := s.ingestTokens.Create(context.Background(), userID, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+// accessToken issues a personal access token for userID with scopes and
+// returns its secret.
+func (s *testServer) accessToken(t *testing.T, userID string, scopes ...string) string {
+	t.Helper()
+	_, secret, err := s.accessTokens.Create(context.Background(), userID, "test", scopes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return secret
+}
+
+func wantStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d; body: %s", w.Code, want, w.Body.String())
+	}
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode response %q: %v", w.Body.String(), err)
+	}
+}
+
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	UserID       string `json:"user_id"`
+}
+
+func TestHealth(t *testing.T) {
+	s := newTestServer(t)
+
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{want: "connected"},
+		{err: errors.New("connection refused"), want: "disconnected"},
+	} {
+		s.database.err = tt.err
+		w := s.request(http.MethodGet, "/health", "")
+		wantStatus(t, w, http.StatusOK)
+
+		var body map[string]string
+		decode(t, w, &body)
+		if body["database_status"] != tt.want {
+			t.Errorf("database_status = %q, want %q", body["database_status"], tt.want)
+		}
+	}
+}
+
+var verifyLink = regexp.MustCompile(`token=(\S+)`)
+
+func TestPasswordAccountLifecycle(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.request(http.MethodPost, "/api/v1/auth/register", `{"email":"Ana@Example.com","password":"correct horse battery","device_name":"Phone"}`)
+	wantStatus(t, w, http.StatusOK)
+	var registered tokenResponse
+	decode(t, w, &registered)
+
+	w = s.request(http.MethodPost, "/api/v1/auth/register", `{"email":"ana@example.com","password":"another good password"}`)
+	wantStatus(t, w, http.StatusConflict)
+
+	// Verify the email with the link that was sent
+	sent := s.mail.Sent()
+	if len(sent) != 1 {
+		t.Fatalf("sent %d emails, want 1", len(sent))
+	}
+	match := verifyLink.FindStringSubmatch(sent[0].Body)
+	if match == nil {
+		t.Fatalf("no verification link in %q", sent[0].Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/auth/verify-email?token="+url.QueryEscape(token), ""), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/auth/verify-email?token="+url.QueryEscape(token), ""), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/verify-email/resend", "", bearer(registered.Token)...), http.StatusConflict)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"wrong password"}`), http.StatusUnauthorized)
+	w = s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"correct horse battery"}`)
+	wantStatus(t, w, http.StatusOK)
+
+	// A refresh token works once; replaying it revokes the session
+	w = s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, registered.RefreshToken))
+	wantStatus(t, w, http.StatusOK)
+	var refreshed tokenResponse
+	decode(t, w, &refreshed)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, registered.RefreshToken)), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, refreshed.RefreshToken)), http.StatusUnauthorized)
+
+	// Reset the password with the emailed code; every session and token ends
+	readToken := s.accessToken(t, registered.UserID, accesstoken.ScopeReadEvents)
+	anaIngest := s.ingestToken(t, registered.UserID)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/password/forgot", `{"email":"nobody@example.com"}`), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/password/forgot", `{"email":"ana@example.com"}`), http.StatusOK)
+	sent = s.mail.Sent()
+	if len(sent) != 2 {
+		t.Fatalf("sent %d emails, want 2", len(sent))
+	}
+	paragraphs := strings.Split(sent[1].Body, "\n\n")
+	if len(paragraphs) < 2 {
+		t.Fatalf("no reset code in %q", sent[1].Body)
+	}
+	code := strings.TrimSpace(paragraphs[1])
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/password/reset", fmt.Sprintf(`{"token":%q,"password":"brand new password"}`, code)), http.StatusOK)
+
+	w = s.request(http.MethodGet, "/api/v1/settings/devices", "", bearer(registered.Token)...)
+	wantStatus(t, w, http.StatusOK)
+	var devices struct{ Count int }
+	decode(t, w, &devices)
+	if devices.Count != 0 {
+		t.Errorf("%d devices still signed in after a password reset", devices.Count)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/checkin/history", "", bearer(readToken)...), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", `{"date":"2026-01-28","hrv_data":{"last_night_avg":48}}`, "X-Ingest-Token", anaIngest), http.StatusUnauthorized)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"correct horse battery"}`), http.StatusUnauthorized)
+	w = s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"brand new password"}`)
+	wantStatus(t, w, http.StatusOK)
+
+	var signedIn tokenResponse
+	decode(t, w, &signedIn)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/logout", fmt.Sprintf(`{"refresh_token":%q}`, signedIn.RefreshToken)), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/logout", fmt.Sprintf(`{"refresh_token":%q}`, signedIn.RefreshToken)), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, signedIn.RefreshToken)), http.StatusUnauthorized)
+}
+
+func TestSignInAndIdentities(t *testing.T) {
+	s := newTestServer(t)
+	s.idTokens["google-ana"] = &auth.Identity{Provider: auth.ProviderGoogle, Subject: "g-1", Email: "ana@example.com", EmailVerified: true, Name: "Ana"}
+	s.idTokens["apple-ana"] = &auth.Identity{Provider: auth.ProviderApple, Subject: "a-1", Email: "relay@privaterelay.appleid.com", EmailVerified: true, PrivateEmail: true}
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/github", `{"id_token":"google-ana"}`), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/google", `{"id_token":"forged"}`), http.StatusUnauthorized)
+
+	// An unverified password account with the same email is taken over,
+	// and whoever registered it is signed out
+	w := s.request(http.MethodPost, "/api/v1/auth/register", `{"email":"ana@example.com","password":"correct horse battery"}`)
+	wantStatus(t, w, http.StatusOK)
+	var registrant tokenResponse
+	decode(t, w, &registrant)
+	readToken := s.accessToken(t, registrant.UserID, accesstoken.ScopeReadEvents)
+	squatterIngest := s.ingestToken(t, registrant.UserID)
+	w = s.request(http.MethodPost, "/api/v1/auth/google", `{"id_token":"google-ana"}`)
+	wantStatus(t, w, http.StatusOK)
+	var ana tokenResponse
+	decode(t, w, &ana)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"correct horse battery"}`), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, registrant.RefreshToken)), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/checkin/history", "", bearer(readToken)...), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", `{"date":"2026-01-28","hrv_data":{"last_night_avg":48}}`, "X-Ingest-Token", squatterIngest), http.StatusUnauthorized)
+
+	w = s.request(http.MethodPost, "/api/v1/auth/google", `{"id_token":"google-ana"}`)
+	wantStatus(t, w, http.StatusOK)
+	var again tokenResponse
+	decode(t, w, &again)
+	if again.UserID != ana.UserID {
+		t.Errorf("second sign-in is user %s, want %s", again.UserID, ana.UserID)
+	}
+	// Signing in again claims nothing, so the first session survives
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, ana.RefreshToken)), http.StatusOK)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/settings/identities", `{"provider":"apple","id_token":"apple-ana"}`, bearer(ana.Token)...), http.StatusCreated)
+	_, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/settings/identities", `{"provider":"apple","id_token":"apple-ana"}`, bearer(otherToken)...), http.StatusConflict)
+
+	w = s.request(http.MethodGet, "/api/v1/settings/identities", "", bearer(ana.Token)...)
+	wantStatus(t, w, http.StatusOK)
+	var listed struct {
+		Identities []auth.UserIdentity `json:"identities"`
+	}
+	decode(t, w, &listed)
+	if len(listed.Identities) != 2 {
+		t.Fatalf("%d identities, want 2", len(listed.Identities))
+	}
+
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/identities/"+listed.Identities[0].ID, "", bearer(otherToken)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/identities/"+listed.Identities[0].ID, "", bearer(ana.Token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/identities/"+listed.Identities[1].ID, "", bearer(ana.Token)...), http.StatusConflict)
+}
+
+func TestDevices(t *testing.T) {
+	s := newTestServer(t)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/register", `{"email":"ana@example.com","password":"correct horse battery","device_name":"Phone"}`), http.StatusOK)
+	w := s.request(http.MethodPost, "/api/v1/auth/login", `{"email":"ana@example.com","password":"correct horse battery","device_name":"Laptop"}`)
+	wantStatus(t, w, http.StatusOK)
+	var laptop tokenResponse
+	decode(t, w, &laptop)
+
+	w = s.request(http.MethodGet, "/api/v1/settings/devices", "", bearer(laptop.Token)...)
+	wantStatus(t, w, http.StatusOK)
+	var listed struct {
+		Devices []auth.Session `json:"devices"`
+	}
+	decode(t, w, &listed)
+	if len(listed.Devices) != 2 || listed.Devices[0].DeviceName != "Laptop" {
+		t.Fatalf("devices = %+v, want Laptop then Phone", listed.Devices)
+	}
+
+	_, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/devices/"+listed.Devices[0].ID, "", bearer(otherToken)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/devices/"+listed.Devices[0].ID, "", bearer(laptop.Token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/auth/refresh", fmt.Sprintf(`{"refresh_token":%q}`, laptop.RefreshToken)), http.StatusUnauthorized)
+}
+
+func TestCheckinAndDashboard(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	readToken := s.accessToken(t, userID, accesstoken.ScopeReadEvents)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/checkin", `{"energy":11,"mood":5,"focus":5,"physical":5}`, bearer(token)...), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/checkin", `{"energy":7,"mood":6,"focus":5,"physical":8}`, bearer(readToken)...), http.StatusForbidden)
+
+	for _, want := range []string{"inserted", "updated"} {
+		w := s.request(http.MethodPost, "/api/v1/checkin", `{"energy":7,"mood":6,"focus":5,"physical":8}`, bearer(token)...)
+		wantStatus(t, w, http.StatusOK)
+		var body struct{ Action string }
+		decode(t, w, &body)
+		if body.Action != want {
+			t.Errorf("action = %q, want %q", body.Action, want)
+		}
+	}
+
+	w := s.request(http.MethodGet, "/api/v1/checkin/latest", "", bearer(readToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var latest struct {
+		Checkin *models.SubjectiveFeeling `json:"checkin"`
+	}
+	decode(t, w, &latest)
+	if latest.Checkin == nil || latest.Checkin.Energy != 7 {
+		t.Errorf("latest check-in = %+v, want energy 7", latest.Checkin)
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/checkin/history?days=7", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var history struct{ Count int }
+	decode(t, w, &history)
+	if history.Count != 1 {
+		t.Errorf("history has %d check-ins, want 1", history.Count)
+	}
+
+	// read:events does not cover the insight routes
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/dashboard/today", "", bearer(readToken)...), http.StatusForbidden)
+
+	w = s.request(http.MethodGet, "/api/v1/dashboard/today", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var today struct {
+		Data checkin.DashboardData `json:"data"`
+	}
+	decode(t, w, &today)
+	if today.Data.Checkin == nil || today.Data.Checkin.Mood != 6 {
+		t.Errorf("dashboard check-in = %+v, want mood 6", today.Data.Checkin)
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/trends/week", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var trends struct{ Trends []checkin.TrendData }
+	decode(t, w, &trends)
+	if len(trends.Trends) != 1 || trends.Trends[0].Checkin == nil {
+		t.Errorf("trends = %+v, want today's check-in", trends.Trends)
+	}
+
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/insights/correlations?days=14", "", bearer(token)...), http.StatusOK)
+
+	// A corrupt reading from the top-ranked source gives way to the next one
+	now := time.Now()
+	for _, e := range []*models.Event{
+		{Time: now, UserID: userID, EventType: models.EventTypeGarminSleep, Source: models.SourceGarmin, Data: []byte(`{"duration_minutes":"long"}`)},
+		{Time: now, UserID: userID, EventType: models.EventTypeGarminSleep, Source: models.SourceOura, Data: []byte(`{"duration_minutes":420}`)},
+	} {
+		if _, err := s.events.InsertEvent(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w = s.request(http.MethodGet, "/api/v1/dashboard/today", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	decode(t, w, &today)
+	if today.Data.Garmin == nil || today.Data.Garmin.Sleep == nil || today.Data.Garmin.Sleep.DurationMinutes != 420 {
+		t.Errorf("dashboard garmin = %+v, want oura's 420 min of sleep", today.Data.Garmin)
+	}
+}
+
+func TestGarminIngest(t *testing.T) {
+	s := newTestServer(t)
+	userID, _ := s.newUser(t, "ana@example.com", models.RoleUser)
+	anaIngest := s.ingestToken(t, userID)
+
+	// The token's user wins over the payload's
+	body := `{"user_id":"someone-else","date":"2026-01-28","hrv_data":{"last_night_avg":48,"status":"BALANCED"}}`
+	for _, want := range []string{"inserted", "updated"} {
+		w := s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", body, "X-Ingest-Token", anaIngest)
+		wantStatus(t, w, http.StatusOK)
+		var result struct{ Action string }
+		decode(t, w, &result)
+		if result.Action != want {
+			t.Errorf("action = %q, want %q", result.Action, want)
+		}
+	}
+
+	day := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+	events, err := s.events.GetEventsByUserAndType(context.Background(), userID, models.EventTypeGarminHRV, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("stored %d HRV events, want 1", len(events))
+	}
+
+	// A signed request without a token writes for the payload's user, who
+	// must be one its key is bound to
+	otherID, _ := s.newUser(t, "bo@example.com", models.RoleUser)
+	s.signingUsers["scheduler"] = []string{userID}
+	stress := "/api/v1/garmin/ingest/stress"
+	body = fmt.Sprintf(`{"user_id":%q,"date":"2026-01-28","stress_data":{"average_stress_level":31}}`, userID)
+	wantStatus(t, s.request(http.MethodPost, stress, body, signed(http.MethodPost, stress, body)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, stress, body, signed(http.MethodPost, stress, "{}")...), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodPost, stress, body, signedWith("unbound", http.MethodPost, stress, body)...), http.StatusUnauthorized)
+	otherBody := fmt.Sprintf(`{"user_id":%q,"date":"2026-01-28","stress_data":{"average_stress_level":31}}`, otherID)
+	wantStatus(t, s.request(http.MethodPost, stress, otherBody, signed(http.MethodPost, stress, otherBody)...), http.StatusForbidden)
+	// nor can a token for someone else get around the binding
+	boIngest := s.ingestToken(t, otherID)
+	wantStatus(t, s.request(http.MethodPost, stress, body, append(signed(http.MethodPost, stress, body), "X-Ingest-Token", boIngest)...), http.StatusForbidden)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/stress", body, "X-Ingest-Token", "revoked"), http.StatusUnauthorized)
+
+	events, err = s.events.GetEventsByUser(context.Background(), userID, day, day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 {
+		t.Errorf("stored %d events, want HRV and stress", len(events))
+	}
+	if events, _ := s.events.GetEventsByUser(context.Background(), otherID, day, day); len(events) != 0 {
+		t.Errorf("stored %d events for a user the key is not bound to", len(events))
+	}
+}
+
+func TestSyncAudits(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	otherID, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+	anaIngest := s.ingestToken(t, userID)
+	boIngest := s.ingestToken(t, otherID)
+	ingest := func(token string) []string { return []string{"X-Ingest-Token", token} }
+
+	// A running sync is finished once
+	w := s.request(http.MethodPost, "/api/v1/audit/sync/start", `{"data_type":"hrv","target_date":"2026-01-28"}`, ingest(anaIngest)...)
+	wantStatus(t, w, http.StatusCreated)
+	var started struct{ ID string }
+	decode(t, w, &started)
+
+	completePath := "/api/v1/audit/sync/" + started.ID + "/complete"
+	wantStatus(t, s.request(http.MethodPost, completePath, `{}`, ingest(boIngest)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodPost, completePath, `{"records_fetched":0}`, ingest(anaIngest)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, completePath, `{}`, ingest(anaIngest)...), http.StatusConflict)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/audit/sync/not-a-uuid/fail", `{"error_message":"timeout"}`, ingest(anaIngest)...), http.StatusNotFound)
+
+	body := `{"data_type":"hrv","target_date":"2026-01-26","status":"failed","sync_started_at":"2026-01-26T06:00:00Z"}`
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/audit/sync", body, ingest(anaIngest)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", `{"date":"2026-01-27","hrv_data":{"last_night_avg":50}}`, ingest(anaIngest)...), http.StatusOK)
+
+	// Reads are the caller's own unless they are an admin
+	w = s.request(http.MethodGet, "/api/v1/audit/sync/recent", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var recent []audit.SyncAudit
+	decode(t, w, &recent)
+	if len(recent) != 2 || recent[0].TargetDate != "2026-01-28" {
+		t.Errorf("recent audits = %+v, want the 2026-01-28 sync first of 2", recent)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/audit/sync/recent?user_id="+userID, "", bearer(otherToken)...), http.StatusForbidden)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/audit/sync/recent?user_id="+userID, "", bearer(adminToken)...), http.StatusOK)
+
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/audit/sync/by-type", "", bearer(token)...), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/audit/sync/by-type?data_type=hrv&all_users=true", "", bearer(token)...), http.StatusForbidden)
+	w = s.request(http.MethodGet, "/api/v1/audit/sync/by-type?data_type=hrv&all_users=true", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var byType []audit.SyncAudit
+	decode(t, w, &byType)
+	if len(byType) != 2 {
+		t.Errorf("%d hrv audits, want 2", len(byType))
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/audit/sync/stats?start=2026-01-01&end=2099-01-01", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var stats map[string]interface{}
+	decode(t, w, &stats)
+	if stats["total_syncs"] != float64(2) || stats["failed_syncs"] != float64(1) {
+		t.Errorf("stats = %v, want 2 syncs, 1 failed", stats)
+	}
+	w = s.request(http.MethodGet, "/api/v1/audit/sync/stats", "", bearer(otherToken)...)
+	wantStatus(t, w, http.StatusOK)
+
+	// The 26th failed, the 27th has data without an audit, the 28th synced empty
+	w = s.request(http.MethodGet, "/api/v1/sync/gaps?start=2026-01-25&end=2026-01-28", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var report struct{ Gaps []audit.Gap }
+	decode(t, w, &report)
+	want := []audit.Gap{
+		{Date: "2026-01-25", DataType: "hrv", Reason: audit.GapMissing},
+		{Date: "2026-01-26", DataType: "hrv", Reason: audit.GapFailed},
+		{Date: "2026-01-28", DataType: "hrv", Reason: audit.GapEmpty},
+	}
+	if fmt.Sprint(report.Gaps) != fmt.Sprint(want) {
+		t.Errorf("gaps = %+v, want %+v", report.Gaps, want)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sync/gaps?start=2026-01-28&end=2026-01-25", "", bearer(token)...), http.StatusBadRequest)
+
+	s.signingUsers["scheduler"] = []string{userID}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sync/refetch?user_id="+otherID, "", signed(http.MethodGet, "/api/v1/sync/refetch?user_id="+otherID, "")...), http.StatusForbidden)
+	path := "/api/v1/sync/refetch?user_id=" + userID + "&start=2026-01-25&end=2026-01-28"
+	w = s.request(http.MethodGet, path, "", signed(http.MethodGet, path, "")...)
+	wantStatus(t, w, http.StatusOK)
+	var refetch struct{ Dates map[string][]string }
+	decode(t, w, &refetch)
+	if got := strings.Join(refetch.Dates["hrv"], ","); got != "2026-01-25,2026-01-26,2026-01-28" {
+		t.Errorf("hrv refetch dates = %s", got)
+	}
+
+	// A key bound to several users must be told which one a sync is for,
+	// and only finishes that user's syncs
+	s.signingUsers["scheduler"] = []string{userID, otherID}
+	body = fmt.Sprintf(`{"user_id":%q,"data_type":"stress","target_date":"2026-01-28"}`, otherID)
+	w = s.request(http.MethodPost, "/api/v1/audit/sync/start", body, signed(http.MethodPost, "/api/v1/audit/sync/start", body)...)
+	wantStatus(t, w, http.StatusCreated)
+	decode(t, w, &started)
+	completePath = "/api/v1/audit/sync/" + started.ID + "/complete"
+	for _, tt := range []struct {
+		body string
+		want int
+	}{
+		{`{"records_fetched":1}`, http.StatusBadRequest},
+		{fmt.Sprintf(`{"user_id":%q,"records_fetched":1}`, userID), http.StatusNotFound},
+		{fmt.Sprintf(`{"user_id":%q,"records_fetched":1}`, otherID), http.StatusOK},
+	} {
+		wantStatus(t, s.request(http.MethodPost, completePath, tt.body, signed(http.MethodPost, completePath, tt.body)...), tt.want)
+	}
+}
+
+func TestSleepHypnogram(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.newUser(t, "ana@example.com", models.RoleUser)
+
+	// Stored at the wake-up time, which is the previous UTC day here
+	data, err := json.Marshal(models.SleepHypnogram{
+		Date:       "2026-01-28",
+		SleepStart: time.Date(2026, 1, 27, 14, 0, 0, 0, time.UTC),
+		SleepEnd:   time.Date(2026, 1, 27, 21, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.events.InsertEvent(context.Background(), &models.Event{
+		Time:      time.Date(2026, 1, 27, 21, 0, 0, 0, time.UTC),
+		UserID:    userID,
+		EventType: models.EventTypeSleepHypnogram,
+		Source:    models.SourceGarmin,
+		Data:      data,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sleep/2026-01-28/hypnogram", "", bearer(token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sleep/2026-01-27/hypnogram", "", bearer(token)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sleep/yesterday/hypnogram", "", bearer(token)...), http.StatusBadRequest)
+
+	// A night held for review keeps its stage timeline, and releasing it
+	// restores the summary
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+	anaIngest := s.ingestToken(t, userID)
+	body := `{"date":"2026-01-30","sleep_data":{"sleep_time_seconds":21600,"awake_seconds":25200,
+		"sleep_start_timestamp_gmt":"2026-01-29T10:00:00Z","sleep_end_timestamp_gmt":"2026-01-30T01:00:00Z",
+		"sleep_levels":[{"stage":"light","start_gmt":"2026-01-29T10:00:00Z","end_gmt":"2026-01-29T16:00:00Z"},
+		{"stage":"awake","start_gmt":"2026-01-29T16:00:00Z","end_gmt":"2026-01-29T23:00:00Z"}]}}`
+	w := s.request(http.MethodPost, "/api/v1/garmin/ingest/sleep", body, "X-Ingest-Token", anaIngest)
+	wantStatus(t, w, http.StatusAccepted)
+	var held struct {
+		QuarantineID string `json:"quarantine_id"`
+	}
+	decode(t, w, &held)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/sleep/2026-01-30/hypnogram", "", bearer(token)...), http.StatusOK)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/admin/quarantine/"+held.QuarantineID+"/replay", `{"force":true}`, bearer(adminToken)...), http.StatusOK)
+	day := time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC)
+	events, err := s.events.GetEventsByUserAndType(context.Background(), userID, models.EventTypeGarminSleep, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Errorf("stored %d sleep summaries after releasing the night, want 1", len(events))
+	}
+}
+
+func TestGarminHealthAPIRoutes(t *testing.T) {
+	s := newTestServer(t)
+	userID, token := s.newUser(t, "ana@example.com", models.RoleUser)
+
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/garmin/callback", ""), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/push", "not json"), http.StatusBadRequest)
+
+	w := s.request(http.MethodPost, "/api/v1/garmin/connect", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var connect struct {
+		AuthorizeURL string `json:"authorize_url"`
+	}
+	decode(t, w, &connect)
+
+	// The consent page sends the browser back to the callback once
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(connect.AuthorizeURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(callback.String(), testGarminCallbackURL) {
+		t.Fatalf("authorize redirected to %q, want %s", resp.Header.Get("Location"), testGarminCallbackURL)
+	}
+	wantStatus(t, s.request(http.MethodGet, callback.RequestURI(), ""), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, callback.RequestURI(), ""), http.StatusBadRequest)
+
+	w = s.request(http.MethodGet, "/api/v1/garmin/connection", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var conn struct {
+		Connected    bool   `json:"connected"`
+		GarminUserID string `json:"garmin_user_id"`
+	}
+	decode(t, w, &conn)
+	if !conn.Connected || conn.GarminUserID != garmintest.UserID {
+		t.Errorf("connection = %+v, want connected as %s", conn, garmintest.UserID)
+	}
+
+	// A ping pulls the user's summaries into events
+	w = s.request(http.MethodPost, "/api/v1/garmin/push", string(s.garmin.Ping("hrv")))
+	wantStatus(t, w, http.StatusOK)
+	var push struct{ Result garmin.PushResult }
+	decode(t, w, &push)
+	if push.Result.EventsInserted == 0 {
+		t.Errorf("push result = %+v, want events inserted", push.Result)
+	}
+	events, err := s.events.GetEventsByUser(context.Background(), userID, time.Time{}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != push.Result.EventsInserted {
+		t.Errorf("stored %d events, want %d", len(events), push.Result.EventsInserted)
+	}
+
+	w = s.request(http.MethodDelete, "/api/v1/garmin/connection", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	decode(t, w, &conn)
+	if conn.Connected {
+		t.Error("still connected after disconnecting")
+	}
+	w = s.request(http.MethodPost, "/api/v1/garmin/push", string(s.garmin.Ping("hrv")))
+	wantStatus(t, w, http.StatusOK)
+	decode(t, w, &push)
+	if push.Result.EventsInserted != 0 || push.Result.Skipped != 1 {
+		t.Errorf("push after disconnecting = %+v, want it skipped", push.Result)
+	}
+}
+
+func TestSourcePriorities(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	path := "/api/v1/settings/source-priorities/" + sources.MetricSleep
+
+	sleepOrder := func() (order []string, custom bool) {
+		t.Helper()
+		w := s.request(http.MethodGet, "/api/v1/settings/source-priorities", "", bearer(token)...)
+		wantStatus(t, w, http.StatusOK)
+		var body struct {
+			Priorities map[string]struct {
+				Sources []string
+				Custom  bool
+			}
+		}
+		decode(t, w, &body)
+		sleep := body.Priorities[sources.MetricSleep]
+		return sleep.Sources, sleep.Custom
+	}
+
+	if order, custom := sleepOrder(); custom || fmt.Sprint(order) != fmt.Sprint(sources.DefaultOrder()) {
+		t.Errorf("sleep priority = %v (custom %v), want the default", order, custom)
+	}
+
+	wantStatus(t, s.request(http.MethodPut, path, `{"sources":["oura","garmin"]}`, bearer(token)...), http.StatusOK)
+	if order, custom := sleepOrder(); !custom || len(order) == 0 || order[0] != models.SourceOura {
+		t.Errorf("sleep priority = %v (custom %v), want oura first", order, custom)
+	}
+
+	wantStatus(t, s.request(http.MethodPut, path, `{"sources":["fitbit"]}`, bearer(token)...), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodPut, "/api/v1/settings/source-priorities/checkin", `{"sources":["manual"]}`, bearer(token)...), http.StatusBadRequest)
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/settings/source-priorities/steps", "", bearer(token)...), http.StatusBadRequest)
+
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(token)...), http.StatusOK)
+	if _, custom := sleepOrder(); custom {
+		t.Error("sleep priority still custom after resetting it")
+	}
+}
+
+func TestIngestTokens(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/settings/ingest-tokens", `{}`, bearer(token)...), http.StatusBadRequest)
+	w := s.request(http.MethodPost, "/api/v1/settings/ingest-tokens", `{"name":"scheduler"}`, bearer(token)...)
+	wantStatus(t, w, http.StatusCreated)
+	var created struct {
+		Token  ingesttoken.Token
+		Secret string
+	}
+	decode(t, w, &created)
+
+	hrv := `{"date":"2026-01-28","hrv_data":{"last_night_avg":48}}`
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", hrv, "X-Ingest-Token", created.Secret), http.StatusOK)
+
+	w = s.request(http.MethodGet, "/api/v1/settings/ingest-tokens", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var list struct{ Tokens []ingesttoken.Token }
+	decode(t, w, &list)
+	if len(list.Tokens) != 1 || list.Tokens[0].ID != created.Token.ID || list.Tokens[0].LastUsedAt == nil {
+		t.Errorf("tokens = %+v, want the used scheduler token", list.Tokens)
+	}
+
+	// Only the owner can revoke a token
+	path := "/api/v1/settings/ingest-tokens/" + created.Token.ID
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(otherToken)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(token)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", hrv, "X-Ingest-Token", created.Secret), http.StatusUnauthorized)
+}
+
+func TestAccessTokens(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/settings/access-tokens", `{"name":"notebook","scopes":["write:everything"]}`, bearer(token)...), http.StatusBadRequest)
+	w := s.request(http.MethodPost, "/api/v1/settings/access-tokens", `{"name":"notebook","scopes":["read:events"],"expires_in_days":30}`, bearer(token)...)
+	wantStatus(t, w, http.StatusCreated)
+	var created struct {
+		Token  accesstoken.Token
+		Secret string
+	}
+	decode(t, w, &created)
+	if created.Token.ExpiresAt == nil {
+		t.Error("token has no expiry")
+	}
+
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/checkin/history", "", bearer(created.Secret)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/dashboard/today", "", bearer(created.Secret)...), http.StatusForbidden)
+	// Access tokens can't manage tokens
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/settings/access-tokens", "", bearer(created.Secret)...), http.StatusForbidden)
+
+	w = s.request(http.MethodGet, "/api/v1/settings/access-tokens", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var list struct{ Tokens []accesstoken.Token }
+	decode(t, w, &list)
+	if len(list.Tokens) != 1 || list.Tokens[0].ID != created.Token.ID {
+		t.Errorf("tokens = %+v, want the notebook token", list.Tokens)
+	}
+
+	path := "/api/v1/settings/access-tokens/" + created.Token.ID
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(otherToken)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(token)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/checkin/history", "", bearer(created.Secret)...), http.StatusUnauthorized)
+}
+
+func TestImportJobs(t *testing.T) {
+	s := newTestServer(t)
+	_, token := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, otherToken := s.newUser(t, "bo@example.com", models.RoleUser)
+
+	wantStatus(t, s.request(http.MethodPost, "/api/v1/import/garmin-export", "", bearer(token)...), http.StatusBadRequest)
+	w := s.request(http.MethodPost, "/api/v1/import/garmin-export", "PK not really a zip", bearer(token)...)
+	wantStatus(t, w, http.StatusAccepted)
+	var created struct {
+		Job struct{ ID, Kind, Status string }
+	}
+	decode(t, w, &created)
+	if created.Job.Kind != importer.KindGarminExport || created.Job.Status != importer.StatusQueued {
+		t.Errorf("job = %+v, want a queued garmin export", created.Job)
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/import/jobs", "", bearer(token)...)
+	wantStatus(t, w, http.StatusOK)
+	var list struct{ Count int }
+	decode(t, w, &list)
+	if list.Count != 1 {
+		t.Errorf("%d jobs, want 1", list.Count)
+	}
+
+	path := "/api/v1/import/jobs/" + created.Job.ID
+	wantStatus(t, s.request(http.MethodGet, path, "", bearer(token)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, path, "", bearer(otherToken)...), http.StatusNotFound)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/import/jobs/not-a-uuid", "", bearer(token)...), http.StatusNotFound)
+}
+
+func TestQuarantineReview(t *testing.T) {
+	s := newTestServer(t)
+	userID, _ := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+	anaIngest := s.ingestToken(t, userID)
+
+	// Payloads that fail validation are kept for review
+	for _, body := range []string{`{"date":"2026-01-28"}`, `{"date":"2026-01-29"}`} {
+		wantStatus(t, s.request(http.MethodPost, "/api/v1/garmin/ingest/hrv", body, "X-Ingest-Token", anaIngest), http.StatusBadRequest)
+	}
+
+	w := s.request(http.MethodGet, "/api/v1/admin/quarantine", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var list struct{ Entries []quarantine.Entry }
+	decode(t, w, &list)
+	if len(list.Entries) != 2 || list.Entries[0].Stage != quarantine.StageValidation {
+		t.Fatalf("quarantine = %+v, want 2 rejected payloads", list.Entries)
+	}
+	entry := list.Entries[0]
+	if entry.UserID == nil || *entry.UserID != userID {
+		t.Errorf("entry user = %v, want %s", entry.UserID, userID)
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/admin/quarantine/stats", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var stats struct{ Pending map[string]int }
+	decode(t, w, &stats)
+	if stats.Pending[garmin.DataTypeHRV] != 2 {
+		t.Errorf("pending = %v, want 2 hrv", stats.Pending)
+	}
+
+	path := "/api/v1/admin/quarantine/" + entry.ID
+	wantStatus(t, s.request(http.MethodGet, path, "", bearer(adminToken)...), http.StatusOK)
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/admin/quarantine/not-a-uuid", "", bearer(adminToken)...), http.StatusNotFound)
+
+	// Replaying it unedited fails validation again; a fixed payload is stored
+	wantStatus(t, s.request(http.MethodPost, path+"/replay", "", bearer(adminToken)...), http.StatusUnprocessableEntity)
+	fixed := fmt.Sprintf(`{"payload":{"user_id":%q,"date":"2026-01-28","hrv_data":{"last_night_avg":48}}}`, userID)
+	w = s.request(http.MethodPost, path+"/replay", fixed, bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var replay struct {
+		EventsInserted int `json:"events_inserted"`
+	}
+	decode(t, w, &replay)
+	if replay.EventsInserted != 1 {
+		t.Errorf("replay inserted %d events, want 1", replay.EventsInserted)
+	}
+	wantStatus(t, s.request(http.MethodPost, path+"/replay", fixed, bearer(adminToken)...), http.StatusConflict)
+	wantStatus(t, s.request(http.MethodDelete, path, "", bearer(adminToken)...), http.StatusConflict)
+
+	// Reprocessing leaves what still fails pending, until it is discarded
+	w = s.request(http.MethodPost, "/api/v1/admin/users/"+userID+"/reprocess", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var reprocess struct {
+		Checked      int `json:"checked"`
+		StillPending int `json:"still_pending"`
+	}
+	decode(t, w, &reprocess)
+	if reprocess.Checked != 1 || reprocess.StillPending != 1 {
+		t.Errorf("reprocess = %+v, want 1 entry still pending", reprocess)
+	}
+	wantStatus(t, s.request(http.MethodDelete, "/api/v1/admin/quarantine/"+list.Entries[1].ID, "", bearer(adminToken)...), http.StatusOK)
+
+	w = s.request(http.MethodGet, "/api/v1/admin/quarantine?status=pending", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	decode(t, w, &list)
+	if len(list.Entries) != 0 {
+		t.Errorf("%d entries still pending, want 0", len(list.Entries))
+	}
+}
+
+func TestAdminViews(t *testing.T) {
+	s := newTestServer(t)
+	userID, _ := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+	anaIngest := s.ingestToken(t, userID)
+
+	w := s.request(http.MethodGet, "/api/v1/admin/users", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var users struct{ Users []admin.UserSummary }
+	decode(t, w, &users)
+	if len(users.Users) != 2 {
+		t.Errorf("%d users, want 2", len(users.Users))
+	}
+	w = s.request(http.MethodGet, "/api/v1/admin/users?role=admin", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	decode(t, w, &users)
+	if len(users.Users) != 1 || users.Users[0].Email != "admin@example.com" {
+		t.Errorf("admins = %+v, want admin@example.com", users.Users)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/admin/users?role=owner", "", bearer(adminToken)...), http.StatusBadRequest)
+
+	// A sync that fails after succeeding is failing
+	ingest := []string{"X-Ingest-Token", anaIngest}
+	for _, path := range []string{"complete", "fail"} {
+		w = s.request(http.MethodPost, "/api/v1/audit/sync/start", `{"data_type":"hrv","target_date":"2026-01-28"}`, ingest...)
+		wantStatus(t, w, http.StatusCreated)
+		var started struct{ ID string }
+		decode(t, w, &started)
+		wantStatus(t, s.request(http.MethodPost, "/api/v1/audit/sync/"+started.ID+"/"+path, `{"error_message":"timeout"}`, ingest...), http.StatusOK)
+	}
+
+	w = s.request(http.MethodGet, "/api/v1/admin/sync-health", "", bearer(adminToken)...)
+	wantStatus(t, w, http.StatusOK)
+	var health struct {
+		Summary map[string]int
+		Syncs   []admin.SyncHealth
+	}
+	decode(t, w, &health)
+	if len(health.Syncs) != 1 || health.Syncs[0].Status != admin.HealthFailing || health.Syncs[0].Runs != 2 {
+		t.Errorf("sync health = %+v, want one failing hrv sync of 2 runs", health.Syncs)
+	}
+	if health.Summary[admin.HealthFailing] != 1 {
+		t.Errorf("summary = %v, want 1 failing", health.Summary)
+	}
+	wantStatus(t, s.request(http.MethodGet, "/api/v1/admin/sync-health?hours=0", "", bearer(adminToken)...), http.StatusBadRequest)
+}
+
+// Access levels of the routes in newRouter
+const (
+	public = "public"
+	jwt    = "jwt"    // JWT, or an access token on scoped routes
+	ingest = "ingest" // ingest token or signed request
+	admins = "admin"
+)
+
+var routes = []struct {
+	path   string
+	access string
+}{
+	{"/api/v1/auth/google", public},
+	{"/api/v1/auth/refresh", public},
+	{"/api/v1/auth/logout", public},
+	{"/api/v1/auth/register", public},
+	{"/api/v1/auth/login", public},
+	{"/api/v1/auth/verify-email", public},
+	{"/api/v1/auth/verify-email/resend", jwt},
+	{"/api/v1/auth/password/forgot", public},
+	{"/api/v1/auth/password/reset", public},
+	{"/api/v1/garmin/connect", jwt},
+	{"/api/v1/garmin/connection", jwt},
+	{"/api/v1/garmin/callback", public},
+	{"/api/v1/garmin/push", public},
+	{"/api/v1/garmin/ingest/sleep", ingest},
+	{"/api/v1/garmin/ingest/activity", ingest},
+	{"/api/v1/garmin/ingest/hrv", ingest},
+	{"/api/v1/garmin/ingest/stress", ingest},
+	{"/api/v1/garmin/ingest/daily-stats", ingest},
+	{"/api/v1/garmin/ingest/body-battery", ingest},
+	{"/api/v1/garmin/ingest/spo2", ingest},
+	{"/api/v1/garmin/ingest/respiration", ingest},
+	{"/api/v1/garmin/ingest/training-readiness", ingest},
+	{"/api/v1/garmin/ingest/training-status", ingest},
+	{"/api/v1/garmin/ingest/vo2max", ingest},
+	{"/api/v1/garmin/ingest/intensity-minutes", ingest},
+	{"/api/v1/audit/sync", ingest},
+	{"/api/v1/audit/sync/start", ingest},
+	{"/api/v1/audit/sync/some-id/complete", ingest},
+	{"/api/v1/audit/sync/some-id/fail", ingest},
+	{"/api/v1/audit/sync/recent", jwt},
+	{"/api/v1/audit/sync/by-type", jwt},
+	{"/api/v1/audit/sync/stats", jwt},
+	{"/api/v1/sync/gaps", jwt},
+	{"/api/v1/sync/refetch", ingest},
+	{"/api/v1/checkin", jwt},
+	{"/api/v1/checkin/latest", jwt},
+	{"/api/v1/checkin/history", jwt},
+	{"/api/v1/dashboard/today", jwt},
+	{"/api/v1/trends/week", jwt},
+	{"/api/v1/insights/correlations", jwt},
+	{"/api/v1/settings/source-priorities", jwt},
+	{"/api/v1/settings/source-priorities/sleep", jwt},
+	{"/api/v1/settings/devices", jwt},
+	{"/api/v1/settings/devices/some-id", jwt},
+	{"/api/v1/settings/identities", jwt},
+	{"/api/v1/settings/identities/some-id", jwt},
+	{"/api/v1/settings/ingest-tokens", jwt},
+	{"/api/v1/settings/ingest-tokens/some-id", jwt},
+	{"/api/v1/settings/access-tokens", jwt},
+	{"/api/v1/settings/access-tokens/some-id", jwt},
+	{"/api/v1/sleep/2026-01-28/hypnogram", jwt},
+	{"/api/v1/activities/import/fit", jwt},
+	{"/api/v1/import/garmin-export", jwt},
+	{"/api/v1/import/apple-health", jwt},
+	{"/api/v1/import/oura", jwt},
+	{"/api/v1/import/whoop", jwt},
+	{"/api/v1/import/jobs", jwt},
+	{"/api/v1/import/jobs/some-id", jwt},
+	{"/api/v1/admin/quarantine", admins},
+	{"/api/v1/admin/quarantine/stats", admins},
+	{"/api/v1/admin/quarantine/some-id", admins},
+	{"/api/v1/admin/quarantine/some-id/replay", admins},
+	{"/api/v1/admin/users", admins},
+	{"/api/v1/admin/sync-health", admins},
+	{"/api/v1/admin/users/some-id/reprocess", admins},
+}
+
+func TestRouteAccess(t *testing.T) {
+	s := newTestServer(t)
+	userID, userToken := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, coachToken := s.newUser(t, "coach@example.com", models.RoleCoach)
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+	anaIngest := s.ingestToken(t, userID)
+
+	for _, route := range routes {
+		t.Run(route.path, func(t *testing.T) {
+			if route.access != public {
+				wantStatus(t, s.request(http.MethodPatch, route.path, ""), http.StatusUnauthorized)
+				wantStatus(t, s.request(http.MethodPatch, route.path, "", bearer("not-a-token")...), http.StatusUnauthorized)
+			}
+
+			// Past the credential checks, every route rejects a method it
+			// doesn't serve before doing anything else
+			var credentials []string
+			switch route.access {
+			case jwt:
+				credentials = bearer(userToken)
+			case ingest:
+				credentials = []string{"X-Ingest-Token", anaIngest}
+			case admins:
+				wantStatus(t, s.request(http.MethodPatch, route.path, "", bearer(userToken)...), http.StatusForbidden)
+				wantStatus(t, s.request(http.MethodPatch, route.path, "", bearer(coachToken)...), http.StatusForbidden)
+				credentials = bearer(adminToken)
+			}
+			wantStatus(t, s.request(http.MethodPatch, route.path, "", credentials...), http.StatusMethodNotAllowed)
+		})
+	}
+}
+
+func TestCrossUserViewsAdminOnly(t *testing.T) {
+	s := newTestServer(t)
+	_, userToken := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, coachToken := s.newUser(t, "coach@example.com", models.RoleCoach)
+
+	// Coaches have no assigned users, so they see no one's
+	for _, path := range []string{"/api/v1/admin/users", "/api/v1/admin/sync-health"} {
+		wantStatus(t, s.request(http.MethodGet, path, "", bearer(userToken)...), http.StatusForbidden)
+		wantStatus(t, s.request(http.MethodGet, path, "", bearer(coachToken)...), http.StatusForbidden)
+	}
+}
+
+func TestDebugVarsAdminOnly(t *testing.T) {
+	s := newTestServer(t)
+	_, userToken := s.newUser(t, "ana@example.com", models.RoleUser)
+	_, adminToken := s.newUser(t, "admin@example.com", models.RoleAdmin)
+
+	wantStatus(t, s.request(http.MethodGet, "/debug/vars", ""), http.StatusUnauthorized)
+	wantStatus(t, s.request(http.MethodGet, "/debug/vars", "", bearer(userToken)...), http.StatusForbidden)
+	wantStatus(t, s.request(http.MethodGet, "/debug/vars", "", bearer(adminToken)...), http.StatusOK)
+}
@@ -0,0 +1,142 @@
+package accesstoken
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxNameLength matches the personal_access_tokens.name column.
+const maxNameLength = 100
+
+// maxExpiresInDays caps how long a token can be valid for.
+const maxExpiresInDays = 365
+
+// Handler lets users manage their own personal access tokens.
+type Handler struct {
+	repo Store
+}
+
+// NewHandler creates a new accesstoken Handler.
+func NewHandler(repo Store) *Handler {
+	return &Handler{repo: repo}
+}
+
+type createRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 never expires
+}
+
+// HandleTokens handles GET and POST /api/v1/settings/access-tokens
+// POST {"name": "notebook", "scopes": ["read:events"], "expires_in_days": 90}
+// returns the token secret, once.
+func (h *Handler) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		tokens, err := h.repo.ListByUser(r.Context(), userID)
+		if err != nil {
+			log.Printf("Failed to list access tokens: %v", err)
+			http.Error(w, "Failed to retrieve access tokens", http.StatusInternalServerError)
+			return
+		}
+		if tokens == nil {
+			tokens = []Token{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"count":  len(tokens),
+			"tokens": tokens,
+		})
+		return
+	}
+
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxNameLength {
+		http.Error(w, "name is required (max 100 characters)", http.StatusBadRequest)
+		return
+	}
+	scopes, err := NormalizeScopes(req.Scopes)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxExpiresInDays {
+		http.Error(w, "expires_in_days must be between 0 (never) and 365", http.StatusBadRequest)
+		return
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	token, secret, err := h.repo.Create(r.Context(), userID, name, scopes, expiresAt)
+	if err != nil {
+		log.Printf("Failed to create access token: %v", err)
+		http.Error(w, "Failed to create access token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"token":  token,
+		"secret": secret,
+	})
+}
+
+// HandleRevoke handles DELETE /api/v1/settings/access-tokens/{id}
+func (h *Handler) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	id := r.PathValue("id")
+	revoked, err := h.repo.Revoke(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("Failed to revoke access token: %v", err)
+		http.Error(w, "Failed to revoke access token", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Access token not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"id":     id,
+	})
+}
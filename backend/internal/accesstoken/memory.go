@@ -0,0 +1,113 @@
+package accesstoken
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// MemoryStore keeps personal access tokens in memory with the same
+// semantics as Repository. It is for tests.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tokens []*memoryToken // in creation order
+}
+
+type memoryToken struct {
+	Token
+	hash string
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Create implements Store.
+func (s *MemoryStore) Create(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*Token, string, error) {
+	secret, err := Generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token := &memoryToken{
+		Token: Token{
+			ID:        db.NewUUID(),
+			UserID:    userID,
+			Name:      name,
+			Prefix:    Prefix(secret),
+			Scopes:    append([]string(nil), scopes...),
+			CreatedAt: time.Now(),
+			ExpiresAt: expiresAt,
+		},
+		hash: Hash(secret),
+	}
+	s.tokens = append(s.tokens, token)
+
+	created := token.Token
+	return &created, secret, nil
+}
+
+// ListByUser implements Store.
+func (s *MemoryStore) ListByUser(ctx context.Context, userID string) ([]Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tokens []Token
+	for i := len(s.tokens) - 1; i >= 0; i-- {
+		if s.tokens[i].UserID == userID {
+			tokens = append(tokens, s.tokens[i].Token)
+		}
+	}
+	return tokens, nil
+}
+
+// Revoke implements Store.
+func (s *MemoryStore) Revoke(ctx context.Context, userID, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tokens {
+		if t.ID == id && t.UserID == userID && t.RevokedAt == nil {
+			now := time.Now()
+			t.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// RevokeAll implements Store.
+func (s *MemoryStore) RevokeAll(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range s.tokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// AuthenticateAccessToken implements Store.
+func (s *MemoryStore) AuthenticateAccessToken(ctx context.Context, secret string) (string, []string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := Hash(secret)
+	now := time.Now()
+	for _, t := range s.tokens {
+		if t.hash == hash && t.RevokedAt == nil && (t.ExpiresAt == nil || t.ExpiresAt.After(now)) {
+			t.LastUsedAt = &now
+			return t.UserID, append([]string(nil), t.Scopes...), nil
+		}
+	}
+	return "", nil, ErrInvalidToken
+}
@@ -0,0 +1,152 @@
+package accesstoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Store issues, lists, revokes and authenticates personal access tokens.
+// Repository implements it on Postgres and MemoryStore in memory, for
+// tests. Both also implement middleware.AccessTokenValidator.
+type Store interface {
+	Create(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*Token, string, error)
+	ListByUser(ctx context.Context, userID string) ([]Token, error)
+	Revoke(ctx context.Context, userID, id string) (bool, error)
+	RevokeAll(ctx context.Context, userID string) error
+	AuthenticateAccessToken(ctx context.Context, secret string) (string, []string, error)
+}
+
+// Repository handles database operations for personal access tokens.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new accesstoken Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+const tokenColumns = `id, user_id, name, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at`
+
+func scanToken(row interface{ Scan(...interface{}) error }) (*Token, error) {
+	var t Token
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.Scopes, &t.CreatedAt, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create issues a new token for userID with the given scopes and returns it
+// with its secret. A nil expiresAt never expires. The secret cannot be
+// retrieved again.
+func (r *Repository) Create(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time) (*Token, string, error) {
+	secret, err := Generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + tokenColumns
+
+	token, err := scanToken(r.db.Pool.QueryRow(ctx, query, userID, name, Prefix(secret), Hash(secret), scopes, expiresAt))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create access token: %w", err)
+	}
+	return token, secret, nil
+}
+
+// ListByUser retrieves a user's tokens, including expired and revoked ones,
+// newest first.
+func (r *Repository) ListByUser(ctx context.Context, userID string) ([]Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes one of userID's tokens. It returns false if the user has
+// no active token with that ID.
+func (r *Repository) Revoke(ctx context.Context, userID, id string) (bool, error) {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = NOW()
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeAll revokes all of userID's tokens, for when someone else may hold
+// them.
+func (r *Repository) RevokeAll(ctx context.Context, userID string) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAccessToken resolves a token secret to its user and scopes
+// and records the use. It returns ErrInvalidToken for unknown, expired or
+// revoked tokens.
+func (r *Repository) AuthenticateAccessToken(ctx context.Context, secret string) (string, []string, error) {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING user_id, scopes
+	`
+
+	var userID string
+	var scopes []string
+	err := r.db.Pool.QueryRow(ctx, query, Hash(secret)).Scan(&userID, &scopes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrInvalidToken
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to authenticate access token: %w", err)
+	}
+	return userID, scopes, nil
+}
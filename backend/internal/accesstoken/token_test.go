@@ -0,0 +1,58 @@
+package accesstoken
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	a, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	b, _ := Generate()
+
+	if !strings.HasPrefix(a, "hap_") {
+		t.Errorf("Generate() = %q, want hap_ prefix", a)
+	}
+	if a == b {
+		t.Error("Generate() returned the same token twice")
+	}
+	if Hash(a) == Hash(b) || len(Hash(a)) != 64 {
+		t.Errorf("Hash() = %q", Hash(a))
+	}
+	if got := Prefix(a); len(got) != 12 || !strings.HasPrefix(a, got) {
+		t.Errorf("Prefix() = %q", got)
+	}
+}
+
+func TestNormalizeScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		scopes  []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "one", scopes: []string{"read:events"}, want: []string{"read:events"}},
+		{
+			name:   "sorted and deduplicated",
+			scopes: []string{"read:insights", "read:events", "read:insights"},
+			want:   []string{"read:events", "read:insights"},
+		},
+		{name: "unknown", scopes: []string{"read:events", "admin"}, wantErr: true},
+		{name: "none", scopes: nil, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeScopes(tt.scopes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeScopes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeScopes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
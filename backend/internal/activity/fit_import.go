@@ -0,0 +1,124 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/fit"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// ErrNoSessions is returned when a FIT file contains no activity sessions.
+var ErrNoSessions = errors.New("FIT file contains no activity sessions")
+
+// ErrNoStartTime is returned when a FIT session has neither a start time nor
+// an end timestamp to work it out from.
+var ErrNoStartTime = errors.New("FIT session has no start time")
+
+// FITEvents holds the events produced from a decoded FIT file.
+type FITEvents struct {
+	Activities []*models.Event
+	Samples    []*models.Event
+}
+
+// EventsFromFIT converts a decoded FIT file into one activity event per session
+// plus an intraday sample event per record.
+func EventsFromFIT(userID string, file *fit.File) (*FITEvents, error) {
+	if len(file.Sessions) == 0 {
+		return nil, ErrNoSessions
+	}
+
+	result := &FITEvents{}
+
+	for _, session := range file.Sessions {
+		start := session.StartTime
+		if start.IsZero() {
+			if session.Timestamp.IsZero() {
+				return nil, ErrNoStartTime
+			}
+			start = session.Timestamp.Add(-time.Duration(session.TotalElapsedTime * float64(time.Second)))
+		}
+		end := start.Add(time.Duration(session.TotalElapsedTime * float64(time.Second)))
+
+		activity := models.GarminActivity{
+			ActivityType:    session.Sport,
+			DurationMinutes: int(session.TotalElapsedTime / 60),
+			Calories:        session.TotalCalories,
+			AvgHR:           session.AvgHeartRate,
+			MaxHR:           session.MaxHeartRate,
+			Distance:        session.TotalDistance,
+			AvgCadence:      session.AvgCadence,
+			AvgPower:        session.AvgPower,
+			MaxPower:        session.MaxPower,
+		}
+
+		for _, lap := range file.Laps {
+			if lap.StartTime.Before(start) || lap.StartTime.After(end) {
+				continue
+			}
+			activity.Laps = append(activity.Laps, models.ActivityLap{
+				StartTime:       lap.StartTime,
+				DurationSeconds: int(lap.TotalElapsedTime),
+				Distance:        lap.TotalDistance,
+				Calories:        lap.TotalCalories,
+				AvgHR:           lap.AvgHeartRate,
+				MaxHR:           lap.MaxHeartRate,
+				AvgCadence:      lap.AvgCadence,
+				AvgPower:        lap.AvgPower,
+			})
+		}
+
+		dataJSON, err := json.Marshal(activity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal activity data: %w", err)
+		}
+
+		result.Activities = append(result.Activities, &models.Event{
+			Time:      start,
+			UserID:    userID,
+			EventType: models.EventTypeGarminActivity,
+			Source:    models.SourceFITFile,
+			Data:      dataJSON,
+		})
+	}
+
+	for _, record := range file.Records {
+		if record.Timestamp.IsZero() {
+			continue
+		}
+
+		sample := models.ActivitySample{
+			HeartRate: record.HeartRate,
+			Power:     record.Power,
+			Cadence:   record.Cadence,
+			Speed:     record.Speed,
+			Distance:  record.Distance,
+		}
+		if record.HasPosition {
+			lat, long := record.Latitude, record.Longitude
+			sample.Latitude = &lat
+			sample.Longitude = &long
+		}
+		if record.HasAltitude {
+			alt := record.Altitude
+			sample.Altitude = &alt
+		}
+
+		dataJSON, err := json.Marshal(sample)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal activity sample: %w", err)
+		}
+
+		result.Samples = append(result.Samples, &models.Event{
+			Time:      record.Timestamp,
+			UserID:    userID,
+			EventType: models.EventTypeActivitySample,
+			Source:    models.SourceFITFile,
+			Data:      dataJSON,
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/fit"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestEventsFromFIT(t *testing.T) {
+	start := time.Date(2026, 1, 28, 6, 30, 0, 0, time.UTC)
+	file := &fit.File{
+		Sessions: []fit.Session{{
+			StartTime:        start,
+			Sport:            "cycling",
+			TotalElapsedTime: 3600,
+			TotalDistance:    30000,
+			TotalCalories:    800,
+			AvgHeartRate:     140,
+			AvgPower:         210,
+		}},
+		Laps: []fit.Lap{
+			{StartTime: start, TotalElapsedTime: 1800, TotalDistance: 15000},
+			{StartTime: start.Add(2 * time.Hour), TotalElapsedTime: 600}, // outside the session
+		},
+		Records: []fit.Record{
+			{Timestamp: start, HeartRate: 120, Power: 200, HasPosition: true, Latitude: 51.5, Longitude: -0.1},
+			{Timestamp: start.Add(time.Second), HeartRate: 121},
+			{HeartRate: 99}, // no timestamp, dropped
+		},
+	}
+
+	events, err := EventsFromFIT("user-1", file)
+	if err != nil {
+		t.Fatalf("EventsFromFIT() error = %v", err)
+	}
+
+	if len(events.Activities) != 1 {
+		t.Fatalf("got %d activities, want 1", len(events.Activities))
+	}
+	event := events.Activities[0]
+	if !event.Time.Equal(start) || event.EventType != models.EventTypeGarminActivity || event.Source != models.SourceFITFile {
+		t.Errorf("activity event = %+v", event)
+	}
+	var activity models.GarminActivity
+	if err := json.Unmarshal(event.Data, &activity); err != nil {
+		t.Fatalf("unmarshal activity: %v", err)
+	}
+	if activity.ActivityType != "cycling" || activity.DurationMinutes != 60 || activity.AvgPower != 210 || len(activity.Laps) != 1 {
+		t.Errorf("activity = %+v", activity)
+	}
+
+	if len(events.Samples) != 2 {
+		t.Fatalf("got %d samples, want 2", len(events.Samples))
+	}
+	var sample models.ActivitySample
+	if err := json.Unmarshal(events.Samples[0].Data, &sample); err != nil {
+		t.Fatalf("unmarshal sample: %v", err)
+	}
+	if sample.HeartRate != 120 || sample.Power != 200 || sample.Latitude == nil || *sample.Latitude != 51.5 {
+		t.Errorf("sample = %+v", sample)
+	}
+}
+
+func TestEventsFromFIT_NoSessions(t *testing.T) {
+	_, err := EventsFromFIT("user-1", &fit.File{Records: []fit.Record{{Timestamp: time.Now()}}})
+	if !errors.Is(err, ErrNoSessions) {
+		t.Errorf("EventsFromFIT() error = %v, want ErrNoSessions", err)
+	}
+}
+
+func TestEventsFromFIT_StartTime(t *testing.T) {
+	end := time.Date(2026, 1, 28, 7, 30, 0, 0, time.UTC)
+
+	// Without a start time, it is worked out from the end timestamp
+	events, err := EventsFromFIT("user-1", &fit.File{Sessions: []fit.Session{{Timestamp: end, TotalElapsedTime: 3600}}})
+	if err != nil {
+		t.Fatalf("EventsFromFIT() error = %v", err)
+	}
+	if want := end.Add(-time.Hour); !events.Activities[0].Time.Equal(want) {
+		t.Errorf("activity time = %v, want %v", events.Activities[0].Time, want)
+	}
+
+	// With neither, there is nothing to work from, however long it lasted
+	_, err = EventsFromFIT("user-1", &fit.File{Sessions: []fit.Session{{TotalElapsedTime: 3600}}})
+	if !errors.Is(err, ErrNoStartTime) {
+		t.Errorf("EventsFromFIT() error = %v, want ErrNoStartTime", err)
+	}
+}
@@ -0,0 +1,97 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/fit"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxFITUploadBytes bounds uploads; multi-hour activities with 1s recording are a few MB.
+const maxFITUploadBytes = 32 << 20
+
+// Handler handles activity import endpoints.
+type Handler struct {
+	eventRepo db.EventStore
+}
+
+// NewHandler creates a new activity Handler.
+func NewHandler(eventRepo db.EventStore) *Handler {
+	return &Handler{eventRepo: eventRepo}
+}
+
+// HandleFITImport handles POST /api/v1/activities/import/fit
+//
+// Accepts either a multipart form with the file in the "file" field or the raw
+// .fit bytes as the request body.
+func (h *Handler) HandleFITImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxFITUploadBytes)
+
+	body, err := importer.OpenUpload(r)
+	if err != nil {
+		log.Printf("Failed to read FIT upload: %v", err)
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	file, err := fit.Decode(body)
+	if err != nil {
+		log.Printf("Failed to decode FIT file: %v", err)
+		http.Error(w, fmt.Sprintf("Invalid FIT file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	events, err := EventsFromFIT(userID, file)
+	if err != nil {
+		log.Printf("Failed to transform FIT file: %v", err)
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrNoSessions) || errors.Is(err, ErrNoStartTime) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, fmt.Sprintf("Transformation error: %v", err), status)
+		return
+	}
+
+	activitiesInserted, err := h.eventRepo.InsertEvents(r.Context(), events.Activities)
+	if err != nil {
+		log.Printf("Failed to insert FIT activities: %v", err)
+		http.Error(w, "Failed to store activities", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := h.eventRepo.InsertEvents(r.Context(), events.Samples); err != nil {
+		log.Printf("Failed to insert FIT samples: %v", err)
+		http.Error(w, "Failed to store activity samples", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported FIT file for user %s: %d activities (%d new), %d samples",
+		userID, len(events.Activities), activitiesInserted, len(events.Samples))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":              "success",
+		"activities":          len(events.Activities),
+		"activities_inserted": activitiesInserted,
+		"samples":             len(events.Samples),
+	})
+}
@@ -0,0 +1,115 @@
+package admin
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Handler serves the admin user and sync health endpoints.
+type Handler struct {
+	repo Store
+}
+
+// NewHandler creates a new admin Handler.
+func NewHandler(repo Store) *Handler {
+	return &Handler{repo: repo}
+}
+
+// HandleListUsers handles GET /api/v1/admin/users?role=&limit=&offset=
+func (h *Handler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	role := query.Get("role")
+	if role != "" && !models.ValidRole(role) {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 || l > 500 {
+			http.Error(w, "Invalid limit (must be 1-500)", http.StatusBadRequest)
+			return
+		}
+		limit = l
+	}
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		if err != nil || o < 0 {
+			http.Error(w, "Invalid offset", http.StatusBadRequest)
+			return
+		}
+		offset = o
+	}
+
+	users, err := h.repo.ListUsers(r.Context(), role, limit, offset)
+	if err != nil {
+		log.Printf("Failed to list users: %v", err)
+		http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
+		return
+	}
+	if users == nil {
+		users = []UserSummary{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"count":  len(users),
+		"users":  users,
+	})
+}
+
+// HandleSyncHealth handles GET /api/v1/admin/sync-health?hours=
+// Each user's data types are reported ok, failing or stale; runs and
+// failures are counted over the last hours (default 24).
+func (h *Handler) HandleSyncHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hours := 24
+	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+		hr, err := strconv.Atoi(hoursStr)
+		if err != nil || hr < 1 || hr > 720 {
+			http.Error(w, "Invalid hours (must be 1-720)", http.StatusBadRequest)
+			return
+		}
+		hours = hr
+	}
+
+	health, err := h.repo.SyncHealth(r.Context(), time.Now().Add(-time.Duration(hours)*time.Hour))
+	if err != nil {
+		log.Printf("Failed to get sync health: %v", err)
+		http.Error(w, "Failed to retrieve sync health", http.StatusInternalServerError)
+		return
+	}
+	if health == nil {
+		health = []SyncHealth{}
+	}
+
+	summary := map[string]int{HealthOK: 0, HealthFailing: 0, HealthStale: 0}
+	for _, h := range health {
+		summary[h.Status]++
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"window_hours": hours,
+		"summary":      summary,
+		"syncs":        health,
+	})
+}
@@ -0,0 +1,42 @@
+// Package admin serves the cross-user views for admins: the user list and
+// sync health.
+package admin
+
+import (
+	"time"
+)
+
+// Sync health statuses
+const (
+	HealthOK      = "ok"
+	HealthFailing = "failing" // the latest run failed
+	HealthStale   = "stale"   // no successful run recently
+)
+
+// staleAfter is how long a data type can go without a successful sync
+// before it is reported stale. Syncs normally run daily.
+const staleAfter = 48 * time.Hour
+
+// SyncHealth summarises one user's syncs of one data type.
+type SyncHealth struct {
+	UserID        string     `json:"user_id"`
+	Email         string     `json:"email"`
+	DataType      string     `json:"data_type"`
+	Status        string     `json:"status"`
+	LastSuccessAt *time.Time `json:"last_success_at"`
+	LastFailureAt *time.Time `json:"last_failure_at"`
+	LastError     *string    `json:"last_error,omitempty"`
+	Runs          int        `json:"runs"`     // in the window
+	Failures      int        `json:"failures"` // in the window
+}
+
+// healthStatus classifies h as of now.
+func healthStatus(h SyncHealth, now time.Time) string {
+	if h.LastFailureAt != nil && (h.LastSuccessAt == nil || h.LastFailureAt.After(*h.LastSuccessAt)) {
+		return HealthFailing
+	}
+	if h.LastSuccessAt == nil || now.Sub(*h.LastSuccessAt) > staleAfter {
+		return HealthStale
+	}
+	return HealthOK
+}
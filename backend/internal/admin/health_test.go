@@ -0,0 +1,37 @@
+package admin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHealthStatus(t *testing.T) {
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	at := func(hoursAgo int) *time.Time {
+		t := now.Add(-time.Duration(hoursAgo) * time.Hour)
+		return &t
+	}
+
+	tests := []struct {
+		name        string
+		lastSuccess *time.Time
+		lastFailure *time.Time
+		want        string
+	}{
+		{name: "recent success", lastSuccess: at(3), want: HealthOK},
+		{name: "failure then success", lastSuccess: at(3), lastFailure: at(5), want: HealthOK},
+		{name: "success then failure", lastSuccess: at(5), lastFailure: at(3), want: HealthFailing},
+		{name: "only failures", lastFailure: at(3), want: HealthFailing},
+		{name: "old success", lastSuccess: at(72), want: HealthStale},
+		{name: "never synced", want: HealthStale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := SyncHealth{LastSuccessAt: tt.lastSuccess, LastFailureAt: tt.lastFailure}
+			if got := healthStatus(h, now); got != tt.want {
+				t.Errorf("healthStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package admin
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/audit"
+	"github.com/satishthakur/health-assistant/backend/internal/auth"
+)
+
+// MemoryStore answers the admin queries from the in-memory user, session
+// and audit stores, the same way Repository does over their tables. Only
+// active sessions count towards a user's LastSeenAt. It is for tests.
+type MemoryStore struct {
+	users    *auth.MemoryUserStore
+	sessions *auth.MemorySessionStore
+	audits   *audit.MemoryStore
+}
+
+// NewMemoryStore creates a MemoryStore reading the given stores.
+func NewMemoryStore(users *auth.MemoryUserStore, sessions *auth.MemorySessionStore, audits *audit.MemoryStore) *MemoryStore {
+	return &MemoryStore{users: users, sessions: sessions, audits: audits}
+}
+
+// ListUsers implements Store.
+func (s *MemoryStore) ListUsers(ctx context.Context, role string, limit, offset int) ([]UserSummary, error) {
+	all := s.users.Users()
+	audits := s.audits.Audits()
+
+	var users []UserSummary
+	for i := len(all) - 1; i >= 0; i-- {
+		u := all[i]
+		if role != "" && u.Role != role {
+			continue
+		}
+		summary := UserSummary{
+			ID:            u.ID,
+			Email:         u.Email,
+			DisplayName:   u.DisplayName,
+			Role:          u.Role,
+			EmailVerified: u.EmailVerifiedAt != nil,
+			CreatedAt:     u.CreatedAt,
+		}
+
+		sessions, err := s.sessions.ListActiveSessions(ctx, u.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			if summary.LastSeenAt == nil || session.LastUsedAt.After(*summary.LastSeenAt) {
+				lastUsed := session.LastUsedAt
+				summary.LastSeenAt = &lastUsed
+			}
+		}
+		// audits are newest first
+		for _, a := range audits {
+			if a.UserID == u.ID && a.Status == audit.StatusSuccess {
+				started := a.SyncStartedAt
+				summary.LastSyncAt = &started
+				break
+			}
+		}
+		users = append(users, summary)
+	}
+
+	if offset >= len(users) {
+		return nil, nil
+	}
+	users = users[offset:]
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+// SyncHealth implements Store.
+func (s *MemoryStore) SyncHealth(ctx context.Context, since time.Time) ([]SyncHealth, error) {
+	emails := make(map[string]string)
+	for _, u := range s.users.Users() {
+		emails[u.ID] = u.Email
+	}
+
+	type key struct{ userID, dataType string }
+	byKey := make(map[key]*SyncHealth)
+	// audits are newest first, so the first success and failure seen are
+	// the latest
+	for _, a := range s.audits.Audits() {
+		email, ok := emails[a.UserID]
+		if !ok {
+			continue
+		}
+		k := key{a.UserID, a.DataType}
+		h := byKey[k]
+		if h == nil {
+			h = &SyncHealth{UserID: a.UserID, Email: email, DataType: a.DataType}
+			byKey[k] = h
+		}
+		started := a.SyncStartedAt
+		switch {
+		case a.Status == audit.StatusSuccess && h.LastSuccessAt == nil:
+			h.LastSuccessAt = &started
+		case a.Status == audit.StatusFailed && h.LastFailureAt == nil:
+			h.LastFailureAt = &started
+			h.LastError = a.ErrorMessage
+		}
+		if !started.Before(since) {
+			h.Runs++
+			if a.Status == audit.StatusFailed {
+				h.Failures++
+			}
+		}
+	}
+
+	now := time.Now()
+	health := make([]SyncHealth, 0, len(byKey))
+	for _, h := range byKey {
+		h.Status = healthStatus(*h, now)
+		health = append(health, *h)
+	}
+	sort.Slice(health, func(i, j int) bool {
+		if health[i].Email != health[j].Email {
+			return health[i].Email < health[j].Email
+		}
+		return health[i].DataType < health[j].DataType
+	})
+	return health, nil
+}
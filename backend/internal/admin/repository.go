@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Store answers the cross-user admin queries. Repository implements it on
+// Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	ListUsers(ctx context.Context, role string, limit, offset int) ([]UserSummary, error)
+	SyncHealth(ctx context.Context, since time.Time) ([]SyncHealth, error)
+}
+
+// Repository handles the cross-user admin queries.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new admin Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+// UserSummary is a user as listed to admins.
+type UserSummary struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	DisplayName   string     `json:"display_name"`
+	Role          string     `json:"role"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastSeenAt    *time.Time `json:"last_seen_at"` // latest session refresh
+	LastSyncAt    *time.Time `json:"last_sync_at"` // latest successful sync
+}
+
+// ListUsers retrieves users, newest first, optionally only those with role.
+func (r *Repository) ListUsers(ctx context.Context, role string, limit, offset int) ([]UserSummary, error) {
+	query := `
+		SELECT
+			u.id, u.email, COALESCE(u.display_name, ''), u.role,
+			u.email_verified_at IS NOT NULL, u.created_at,
+			(SELECT MAX(s.last_used_at) FROM sessions s WHERE s.user_id = u.id),
+			(SELECT MAX(a.sync_started_at) FROM sync_audit a WHERE a.user_id = u.id AND a.status = 'success')
+		FROM users u
+		WHERE ($1 = '' OR u.role = $1)
+		ORDER BY u.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, role, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []UserSummary
+	for rows.Next() {
+		var u UserSummary
+		err := rows.Scan(&u.ID, &u.Email, &u.DisplayName, &u.Role, &u.EmailVerified, &u.CreatedAt, &u.LastSeenAt, &u.LastSyncAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SyncHealth summarises every user's syncs per data type. Runs and
+// failures are counted since since; the latest success and failure are
+// from all time.
+func (r *Repository) SyncHealth(ctx context.Context, since time.Time) ([]SyncHealth, error) {
+	query := `
+		SELECT
+			a.user_id, u.email, a.data_type,
+			MAX(a.sync_started_at) FILTER (WHERE a.status = 'success'),
+			MAX(a.sync_started_at) FILTER (WHERE a.status = 'failed'),
+			(ARRAY_AGG(a.error_message ORDER BY a.sync_started_at DESC) FILTER (WHERE a.status = 'failed'))[1],
+			COUNT(*) FILTER (WHERE a.sync_started_at >= $1),
+			COUNT(*) FILTER (WHERE a.sync_started_at >= $1 AND a.status = 'failed')
+		FROM sync_audit a
+		JOIN users u ON u.id = a.user_id
+		GROUP BY a.user_id, u.email, a.data_type
+		ORDER BY u.email, a.data_type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sync health: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var health []SyncHealth
+	for rows.Next() {
+		var h SyncHealth
+		err := rows.Scan(&h.UserID, &h.Email, &h.DataType, &h.LastSuccessAt, &h.LastFailureAt, &h.LastError, &h.Runs, &h.Failures)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sync health: %w", err)
+		}
+		h.Status = healthStatus(h, now)
+		health = append(health, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sync health: %w", err)
+	}
+
+	return health, nil
+}
@@ -0,0 +1,65 @@
+// Package alert notifies operators when a user's syncs keep failing, such
+// as after their Garmin credentials expire, and again when they recover.
+package alert
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Alert kinds
+const (
+	KindFailing   = "sync_failing"
+	KindRecovered = "sync_recovered"
+)
+
+// Alert describes a user whose syncs started failing or recovered.
+type Alert struct {
+	Kind     string `json:"event"`
+	UserID   string `json:"user_id"`
+	Email    string `json:"email,omitempty"`
+	DataType string `json:"data_type"` // the data type that triggered it
+	// FailingDataTypes are all of the user's data types still failing.
+	FailingDataTypes    []string  `json:"failing_data_types"`
+	ConsecutiveFailures int       `json:"consecutive_failures,omitempty"`
+	LastError           string    `json:"last_error,omitempty"`
+	At                  time.Time `json:"at"`
+}
+
+// Notifier delivers alerts, such as to a webhook or by email.
+type Notifier interface {
+	Notify(ctx context.Context, a Alert) error
+}
+
+// Summary describes a in one line.
+func (a Alert) Summary() string {
+	who := a.Email
+	if who == "" {
+		who = a.UserID
+	}
+
+	if a.Kind == KindRecovered {
+		return fmt.Sprintf("Sync recovered for %s: every data type is syncing again", who)
+	}
+
+	summary := fmt.Sprintf("Sync failing for %s: %s failed %d times in a row", who, a.DataType, a.ConsecutiveFailures)
+	if others := without(a.FailingDataTypes, a.DataType); len(others) > 0 {
+		summary += fmt.Sprintf(" (also failing: %s)", strings.Join(others, ", "))
+	}
+	if a.LastError != "" {
+		summary += ". Last error: " + a.LastError
+	}
+	return summary
+}
+
+func without(items []string, item string) []string {
+	var rest []string
+	for _, i := range items {
+		if i != item {
+			rest = append(rest, i)
+		}
+	}
+	return rest
+}
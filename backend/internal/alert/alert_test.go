@@ -0,0 +1,112 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+)
+
+var testAlert = Alert{
+	Kind:                KindFailing,
+	UserID:              "user-1",
+	Email:               "alice@example.com",
+	DataType:            "sleep",
+	FailingDataTypes:    []string{"sleep", "hrv"},
+	ConsecutiveFailures: 3,
+	LastError:           "401 Unauthorized",
+	At:                  time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC),
+}
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		alert Alert
+		want  string
+	}{
+		{
+			name:  "failing",
+			alert: testAlert,
+			want:  "Sync failing for alice@example.com: sleep failed 3 times in a row (also failing: hrv). Last error: 401 Unauthorized",
+		},
+		{
+			name:  "failing without email or error",
+			alert: Alert{Kind: KindFailing, UserID: "user-1", DataType: "hrv", FailingDataTypes: []string{"hrv"}, ConsecutiveFailures: 5},
+			want:  "Sync failing for user-1: hrv failed 5 times in a row",
+		},
+		{
+			name:  "recovered",
+			alert: Alert{Kind: KindRecovered, UserID: "user-1", Email: "alice@example.com", DataType: "sleep"},
+			want:  "Sync recovered for alice@example.com: every data type is syncing again",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alert.Summary(); got != tt.want {
+				t.Errorf("Summary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifiers(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		got = nil
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("webhook Notify() error = %v", err)
+	}
+	if got["event"] != KindFailing || got["user_id"] != "user-1" || got["data_type"] != "sleep" || got["consecutive_failures"] != 3.0 {
+		t.Errorf("webhook payload = %v", got)
+	}
+
+	if err := NewSlackNotifier(server.URL).Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Slack Notify() error = %v", err)
+	}
+	if len(got) != 1 || got["text"] != testAlert.Summary() {
+		t.Errorf("Slack payload = %v", got)
+	}
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookNotifier(server.URL).Notify(context.Background(), testAlert); err == nil {
+		t.Error("Notify() succeeded against a failing webhook")
+	}
+}
+
+func TestEmailNotifier(t *testing.T) {
+	mail := mailer.NewMemoryMailer()
+	n := NewEmailNotifier(mail, []string{"ops@example.com", "oncall@example.com"})
+	if err := n.Notify(context.Background(), testAlert); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	sent := mail.Sent()
+	if len(sent) != 2 || sent[0].To != "ops@example.com" || sent[1].To != "oncall@example.com" {
+		t.Fatalf("sent = %+v", sent)
+	}
+	if sent[0].Subject != "Sync failing: alice@example.com" {
+		t.Errorf("Subject = %q", sent[0].Subject)
+	}
+	if !strings.Contains(sent[0].Body, testAlert.Summary()) {
+		t.Errorf("Body = %q, want the summary", sent[0].Body)
+	}
+}
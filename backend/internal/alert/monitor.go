@@ -0,0 +1,93 @@
+package alert
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/audit"
+)
+
+// checkTimeout bounds checking one sync and sending its alerts.
+const checkTimeout = 30 * time.Second
+
+// Monitor watches recorded syncs and alerts when a user's syncs of a data
+// type fail threshold times in a row. Alerts are per user: once alerted,
+// further failures of any data type are held back until cooldown has
+// passed, and a recovery notice follows when every data type syncs again.
+type Monitor struct {
+	repo      *Repository
+	notifiers []Notifier
+	threshold int
+	cooldown  time.Duration
+}
+
+// NewMonitor creates a Monitor sending alerts to notifiers. With no
+// notifiers it does nothing.
+func NewMonitor(repo *Repository, notifiers []Notifier, threshold int, cooldown time.Duration) *Monitor {
+	return &Monitor{repo: repo, notifiers: notifiers, threshold: threshold, cooldown: cooldown}
+}
+
+// ObserveSync implements audit.SyncObserver. The check runs in the
+// background so recording the sync is not held up by slow notifiers.
+func (m *Monitor) ObserveSync(ctx context.Context, a *audit.SyncAudit) {
+	if len(m.notifiers) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), checkTimeout)
+	go func() {
+		defer cancel()
+		if err := m.check(ctx, a.UserID, a.DataType, a.Status); err != nil {
+			log.Printf("Failed to check sync alerts for user %s, %s: %v", a.UserID, a.DataType, err)
+		}
+	}()
+}
+
+func (m *Monitor) check(ctx context.Context, userID, dataType, status string) error {
+	switch status {
+	case "success":
+		recovered, err := m.repo.MarkRecovered(ctx, userID, dataType)
+		if err != nil || !recovered {
+			return err
+		}
+		return m.notify(ctx, Alert{Kind: KindRecovered, UserID: userID, DataType: dataType, At: time.Now()})
+
+	case "failed", "partial":
+		failures, lastError, err := m.repo.FailureStreak(ctx, userID, dataType)
+		if err != nil || failures < m.threshold {
+			return err
+		}
+		failing, send, err := m.repo.MarkFailing(ctx, userID, dataType, m.cooldown)
+		if err != nil || !send {
+			return err
+		}
+		return m.notify(ctx, Alert{
+			Kind:                KindFailing,
+			UserID:              userID,
+			DataType:            dataType,
+			FailingDataTypes:    failing,
+			ConsecutiveFailures: failures,
+			LastError:           lastError,
+			At:                  time.Now(),
+		})
+	}
+	return nil
+}
+
+// notify sends a to every notifier, logging the ones that fail.
+func (m *Monitor) notify(ctx context.Context, a Alert) error {
+	email, err := m.repo.UserEmail(ctx, a.UserID)
+	if err != nil {
+		return err
+	}
+	a.Email = email
+
+	log.Printf("Sync alert: %s", a.Summary())
+	for _, n := range m.notifiers {
+		if err := n.Notify(ctx, a); err != nil {
+			log.Printf("Failed to send sync alert: %v", err)
+		}
+	}
+	return nil
+}
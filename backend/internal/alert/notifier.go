@@ -0,0 +1,103 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+)
+
+// WebhookNotifier POSTs each alert as JSON to a URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier for url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier.
+func (n *WebhookNotifier) Notify(ctx context.Context, a Alert) error {
+	return postJSON(ctx, n.client, n.url, a)
+}
+
+// SlackNotifier posts each alert's summary to a Slack incoming webhook, or
+// any service that accepts the same {"text": ...} payload.
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier creates a SlackNotifier for an incoming webhook URL.
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify implements Notifier.
+func (n *SlackNotifier) Notify(ctx context.Context, a Alert) error {
+	return postJSON(ctx, n.client, n.url, map[string]string{"text": a.Summary()})
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailNotifier emails each alert to fixed recipients.
+type EmailNotifier struct {
+	mail mailer.Mailer
+	to   []string
+}
+
+// NewEmailNotifier creates an EmailNotifier sending to each of to.
+func NewEmailNotifier(mail mailer.Mailer, to []string) *EmailNotifier {
+	return &EmailNotifier{mail: mail, to: to}
+}
+
+// Notify implements Notifier.
+func (n *EmailNotifier) Notify(ctx context.Context, a Alert) error {
+	subject := "Sync failing"
+	if a.Kind == KindRecovered {
+		subject = "Sync recovered"
+	}
+	who := a.Email
+	if who == "" {
+		who = a.UserID
+	}
+
+	body := fmt.Sprintf("%s\n\nUser: %s (%s)\nData type: %s\nAt: %s\n",
+		a.Summary(), who, a.UserID, a.DataType, a.At.UTC().Format(time.RFC3339))
+
+	for _, to := range n.to {
+		msg := mailer.Message{To: to, Subject: fmt.Sprintf("%s: %s", subject, who), Body: body}
+		if err := n.mail.Send(ctx, msg); err != nil {
+			return fmt.Errorf("failed to email alert to %s: %w", to, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Repository handles database operations for sync alerts.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new alert Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+// FailureStreak counts the failed and partial syncs of dataType for userID
+// since its last successful one, and returns the latest error among them.
+func (r *Repository) FailureStreak(ctx context.Context, userID, dataType string) (int, string, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE((ARRAY_AGG(error_message ORDER BY sync_started_at DESC) FILTER (WHERE error_message IS NOT NULL))[1], '')
+		FROM sync_audit
+		WHERE user_id = $1
+			AND data_type = $2
+			AND status IN ('failed', 'partial')
+			AND sync_started_at > COALESCE((
+				SELECT MAX(sync_started_at) FROM sync_audit
+				WHERE user_id = $1 AND data_type = $2 AND status = 'success'
+			), '-infinity'::timestamptz)
+	`
+
+	var count int
+	var lastError string
+	if err := r.db.Pool.QueryRow(ctx, query, userID, dataType).Scan(&count, &lastError); err != nil {
+		return 0, "", fmt.Errorf("failed to count failed syncs: %w", err)
+	}
+	return count, lastError, nil
+}
+
+// MarkFailing records that dataType is failing for userID and returns all
+// the user's failing data types. send reports whether to alert: the user
+// has not been alerted about within cooldown, in which case the alert time
+// is updated.
+func (r *Repository) MarkFailing(ctx context.Context, userID, dataType string, cooldown time.Duration) ([]string, bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `INSERT INTO sync_alerts (user_id) VALUES ($1) ON CONFLICT DO NOTHING`, userID); err != nil {
+		return nil, false, fmt.Errorf("failed to create sync alert: %w", err)
+	}
+
+	query := `
+		UPDATE sync_alerts
+		SET data_types = CASE WHEN $2 = ANY(data_types) THEN data_types ELSE ARRAY_APPEND(data_types, $2) END,
+			alerted_at = CASE WHEN alerted_at IS NULL OR alerted_at < NOW() - make_interval(secs => $3) THEN NOW() ELSE alerted_at END
+		WHERE user_id = $1
+		RETURNING data_types, alerted_at = NOW()
+	`
+
+	var dataTypes []string
+	var send bool
+	if err := tx.QueryRow(ctx, query, userID, dataType, cooldown.Seconds()).Scan(&dataTypes, &send); err != nil {
+		return nil, false, fmt.Errorf("failed to update sync alert: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("failed to commit sync alert: %w", err)
+	}
+	return dataTypes, send, nil
+}
+
+// MarkRecovered removes dataType from userID's failing data types.
+// recovered reports whether it was the last one, so the outage is over.
+func (r *Repository) MarkRecovered(ctx context.Context, userID, dataType string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE sync_alerts
+		SET data_types = ARRAY_REMOVE(data_types, $2)
+		WHERE user_id = $1 AND $2 = ANY(data_types)
+		RETURNING CARDINALITY(data_types)
+	`
+
+	var remaining int
+	err = tx.QueryRow(ctx, query, userID, dataType).Scan(&remaining)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to update sync alert: %w", err)
+	}
+	if remaining > 0 {
+		return false, tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM sync_alerts WHERE user_id = $1`, userID); err != nil {
+		return false, fmt.Errorf("failed to delete sync alert: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit sync alert: %w", err)
+	}
+	return true, nil
+}
+
+// UserEmail returns userID's email address, or "" if there is no such user.
+func (r *Repository) UserEmail(ctx context.Context, userID string) (string, error) {
+	var email string
+	err := r.db.Pool.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get user email: %w", err)
+	}
+	return email, nil
+}
@@ -0,0 +1,325 @@
+package applehealth
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quality"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
+)
+
+// Sleep analysis category values. Watches on watchOS 9+ record stages; older
+// data and phones only record asleep/in bed.
+const (
+	sleepInBed       = "HKCategoryValueSleepAnalysisInBed"
+	sleepAsleep      = "HKCategoryValueSleepAnalysisAsleep"
+	sleepUnspecified = "HKCategoryValueSleepAnalysisAsleepUnspecified"
+	sleepCore        = "HKCategoryValueSleepAnalysisAsleepCore"
+	sleepDeep        = "HKCategoryValueSleepAnalysisAsleepDeep"
+	sleepREM         = "HKCategoryValueSleepAnalysisAsleepREM"
+	sleepAwake       = "HKCategoryValueSleepAnalysisAwake"
+)
+
+// sleepStages maps staged sleep values onto hypnogram stages; Core is
+// Apple's name for light sleep.
+var sleepStages = map[string]string{
+	sleepCore:  models.SleepStageLight,
+	sleepDeep:  models.SleepStageDeep,
+	sleepREM:   models.SleepStageREM,
+	sleepAwake: models.SleepStageAwake,
+}
+
+type dayAggregate struct {
+	stepsBySource map[string]float64
+	minHR         float64
+	maxHR         float64
+	restingHR     float64
+	restingHRAt   time.Time
+	hrvSum        float64
+	hrvCount      int
+	hrvMin        float64
+	hrvMax        float64
+}
+
+type sleepSample struct {
+	value string
+	start time.Time
+	end   time.Time
+}
+
+type bodyMassSample struct {
+	time     time.Time
+	weightKg float64
+}
+
+// Aggregator reduces a stream of records to per-day summaries.
+type Aggregator struct {
+	days     map[string]*dayAggregate
+	nights   map[string][]sleepSample
+	bodyMass []bodyMassSample
+
+	// Records is the number of supported records seen; Skipped counts those
+	// dropped for bad dates, values or units.
+	Records int
+	Skipped int
+}
+
+// NewAggregator creates an empty Aggregator.
+func NewAggregator() *Aggregator {
+	return &Aggregator{
+		days:   make(map[string]*dayAggregate),
+		nights: make(map[string][]sleepSample),
+	}
+}
+
+// Add folds one record into the aggregates. Unsupported types are ignored.
+func (a *Aggregator) Add(rec Record) {
+	switch rec.Type {
+	case TypeStepCount, TypeHeartRate, TypeRestingHeartRate, TypeHRVSDNN, TypeBodyMass, TypeSleepAnalysis:
+	default:
+		return
+	}
+
+	a.Records++
+	if rec.StartDate.IsZero() || rec.EndDate.IsZero() {
+		a.Skipped++
+		return
+	}
+
+	if rec.Type == TypeSleepAnalysis {
+		// A night runs noon to noon and is attributed to the wake-up day
+		night := rec.StartDate.Add(12 * time.Hour).Format("2006-01-02")
+		a.nights[night] = append(a.nights[night], sleepSample{value: rec.Value, start: rec.StartDate, end: rec.EndDate})
+		return
+	}
+
+	value, err := strconv.ParseFloat(rec.Value, 64)
+	if err != nil || value < 0 || math.IsNaN(value) {
+		a.Skipped++
+		return
+	}
+
+	if rec.Type == TypeBodyMass {
+		weightKg, err := quality.Normalize(quality.MeasureWeight, value, rec.Unit)
+		if err != nil {
+			a.Skipped++
+			return
+		}
+		a.bodyMass = append(a.bodyMass, bodyMassSample{time: rec.StartDate, weightKg: weightKg})
+		return
+	}
+
+	day := a.day(rec.StartDate.Format("2006-01-02"))
+
+	switch rec.Type {
+	case TypeStepCount:
+		day.stepsBySource[rec.SourceName] += value
+	case TypeHeartRate:
+		if day.minHR == 0 || value < day.minHR {
+			day.minHR = value
+		}
+		if value > day.maxHR {
+			day.maxHR = value
+		}
+	case TypeRestingHeartRate:
+		if !rec.StartDate.Before(day.restingHRAt) {
+			day.restingHR = value
+			day.restingHRAt = rec.StartDate
+		}
+	case TypeHRVSDNN:
+		if day.hrvCount == 0 || value < day.hrvMin {
+			day.hrvMin = value
+		}
+		if value > day.hrvMax {
+			day.hrvMax = value
+		}
+		day.hrvSum += value
+		day.hrvCount++
+	}
+}
+
+func (a *Aggregator) day(date string) *dayAggregate {
+	day, ok := a.days[date]
+	if !ok {
+		day = &dayAggregate{stepsBySource: make(map[string]float64)}
+		a.days[date] = day
+	}
+	return day
+}
+
+// Events converts the aggregates into events in the shapes the dashboard reads.
+func (a *Aggregator) Events(userID string) ([]*models.Event, error) {
+	var events []*models.Event
+
+	add := func(t time.Time, eventType string, data interface{}) error {
+		dataJSON, err := json.Marshal(data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+		}
+		events = append(events, &models.Event{
+			Time:      t,
+			UserID:    userID,
+			EventType: eventType,
+			Source:    models.SourceAppleHealth,
+			Data:      dataJSON,
+		})
+		return nil
+	}
+
+	for _, date := range sortedKeys(a.days) {
+		day := a.days[date]
+		eventTime, _ := time.Parse("2006-01-02", date)
+
+		if len(day.stepsBySource) > 0 || day.maxHR > 0 || day.restingHR > 0 {
+			// iPhone and Watch both count steps for the same walk; take the
+			// busiest source rather than double counting
+			var steps float64
+			for _, s := range day.stepsBySource {
+				steps = math.Max(steps, s)
+			}
+			stats := models.GarminDailyStats{
+				Steps:            int(math.Round(steps)),
+				MinHeartRate:     int(math.Round(day.minHR)),
+				MaxHeartRate:     int(math.Round(day.maxHR)),
+				RestingHeartRate: int(math.Round(day.restingHR)),
+			}
+			if err := add(eventTime, models.EventTypeGarminDailyStats, stats); err != nil {
+				return nil, err
+			}
+		}
+
+		if day.hrvCount > 0 {
+			// Apple reports SDNN where Garmin reports RMSSD; values are not interchangeable
+			hrv := models.HRVSDNN{
+				Avg: math.Round(day.hrvSum/float64(day.hrvCount)*10) / 10,
+				Min: day.hrvMin,
+				Max: day.hrvMax,
+			}
+			if err := add(eventTime, models.EventTypeHRVSDNN, hrv); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, night := range sortedKeys(a.nights) {
+		summary, hypnogram := summarizeNight(night, a.nights[night])
+		if summary == nil {
+			continue
+		}
+		if err := add(*summary.SleepEnd, models.EventTypeGarminSleep, summary); err != nil {
+			return nil, err
+		}
+		if hypnogram != nil {
+			if err := add(*summary.SleepEnd, models.EventTypeSleepHypnogram, hypnogram); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, sample := range a.bodyMass {
+		if err := add(sample.time, models.EventTypeBodyMass, models.BodyMass{WeightKg: sample.weightKg}); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// summarizeNight builds a sleep summary for one night. Staged samples are used
+// when present, since unstaged "asleep" samples from other sources overlap them.
+// Returns nil when the night has no asleep time (e.g. in-bed only).
+func summarizeNight(date string, samples []sleepSample) (*models.GarminSleep, *models.SleepHypnogram) {
+	staged := false
+	for _, s := range samples {
+		if _, ok := sleepStages[s.value]; ok && s.value != sleepAwake {
+			staged = true
+			break
+		}
+	}
+
+	var summary models.GarminSleep
+	var start, end time.Time
+	var hypnogram *models.SleepHypnogram
+	if staged {
+		hypnogram = &models.SleepHypnogram{Date: date}
+	}
+
+	asleep := 0
+	for _, s := range samples {
+		minutes := int(s.end.Sub(s.start).Minutes())
+		if minutes <= 0 {
+			continue
+		}
+
+		stage, isStage := sleepStages[s.value]
+		switch {
+		case staged && isStage:
+			hypnogram.Stages = append(hypnogram.Stages, models.SleepStageInterval{
+				Stage: stage,
+				Start: s.start.UTC(),
+				End:   s.end.UTC(),
+			})
+			switch stage {
+			case models.SleepStageDeep:
+				summary.DeepSleepMinutes += minutes
+			case models.SleepStageLight:
+				summary.LightSleepMinutes += minutes
+			case models.SleepStageREM:
+				summary.REMSleepMinutes += minutes
+			case models.SleepStageAwake:
+				summary.AwakeMinutes += minutes
+			}
+			if stage != models.SleepStageAwake {
+				asleep += minutes
+			}
+		case !staged && (s.value == sleepAsleep || s.value == sleepUnspecified):
+			asleep += minutes
+		case !staged && s.value == sleepAwake:
+			summary.AwakeMinutes += minutes
+		default:
+			// In-bed samples and unstaged samples on a staged night
+			continue
+		}
+
+		if start.IsZero() || s.start.Before(start) {
+			start = s.start
+		}
+		if s.end.After(end) {
+			end = s.end
+		}
+	}
+
+	if asleep == 0 {
+		return nil, nil
+	}
+
+	start, end = start.UTC(), end.UTC()
+	summary.DurationMinutes = asleep
+	summary.SleepStart = &start
+	summary.SleepEnd = &end
+
+	if hypnogram != nil {
+		sort.Slice(hypnogram.Stages, func(i, j int) bool {
+			return hypnogram.Stages[i].Start.Before(hypnogram.Stages[j].Start)
+		})
+		hypnogram.SleepStart = start
+		hypnogram.SleepEnd = end
+		sleep.ApplyMetrics(&summary, hypnogram)
+	}
+
+	return &summary, hypnogram
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
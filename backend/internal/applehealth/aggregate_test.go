@@ -0,0 +1,166 @@
+package applehealth
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+const testExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE HealthData [
+<!ELEMENT HealthData (ExportDate,Me,(Record|Workout)*)>
+]>
+<HealthData locale="en_GB">
+ <ExportDate value="2024-03-03 09:00:00 +0000"/>
+ <Me HKCharacteristicTypeIdentifierDateOfBirth=""/>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="iPhone" unit="count" startDate="2024-03-02 08:00:00 +0000" endDate="2024-03-02 08:10:00 +0000" value="900"/>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="Apple Watch" unit="count" startDate="2024-03-02 08:00:00 +0000" endDate="2024-03-02 08:10:00 +0000" value="1000"/>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="Apple Watch" unit="count" startDate="2024-03-02 18:00:00 +0000" endDate="2024-03-02 18:30:00 +0000" value="3500"/>
+ <Record type="HKQuantityTypeIdentifierHeartRate" sourceName="Apple Watch" unit="count/min" startDate="2024-03-02 08:05:00 +0000" endDate="2024-03-02 08:05:00 +0000" value="121">
+  <MetadataEntry key="HKMetadataKeyHeartRateMotionContext" value="2"/>
+ </Record>
+ <Record type="HKQuantityTypeIdentifierHeartRate" sourceName="Apple Watch" unit="count/min" startDate="2024-03-02 03:00:00 +0000" endDate="2024-03-02 03:00:00 +0000" value="48"/>
+ <Record type="HKQuantityTypeIdentifierRestingHeartRate" sourceName="Apple Watch" unit="count/min" startDate="2024-03-02 00:00:00 +0000" endDate="2024-03-02 23:59:00 +0000" value="52"/>
+ <Record type="HKQuantityTypeIdentifierHeartRateVariabilitySDNN" sourceName="Apple Watch" unit="ms" startDate="2024-03-02 02:00:00 +0000" endDate="2024-03-02 02:01:00 +0000" value="40">
+  <HeartRateVariabilityMetadataList>
+   <InstantaneousBeatsPerMinute bpm="55" time="2:00:01.00 AM"/>
+  </HeartRateVariabilityMetadataList>
+ </Record>
+ <Record type="HKQuantityTypeIdentifierHeartRateVariabilitySDNN" sourceName="Apple Watch" unit="ms" startDate="2024-03-02 05:00:00 +0000" endDate="2024-03-02 05:01:00 +0000" value="60"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="lb" startDate="2024-03-02 07:00:00 +0000" endDate="2024-03-02 07:00:00 +0000" value="165"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="stone" startDate="2024-03-02 07:00:00 +0000" endDate="2024-03-02 07:00:00 +0000" value="11"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="iPhone" startDate="2024-03-01 22:30:00 +0000" endDate="2024-03-02 06:30:00 +0000" value="HKCategoryValueSleepAnalysisInBed"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Apple Watch" startDate="2024-03-01 23:00:00 +0000" endDate="2024-03-02 01:00:00 +0000" value="HKCategoryValueSleepAnalysisAsleepCore"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Apple Watch" startDate="2024-03-02 01:00:00 +0000" endDate="2024-03-02 02:00:00 +0000" value="HKCategoryValueSleepAnalysisAsleepDeep"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Apple Watch" startDate="2024-03-02 02:00:00 +0000" endDate="2024-03-02 02:20:00 +0000" value="HKCategoryValueSleepAnalysisAwake"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Apple Watch" startDate="2024-03-02 02:20:00 +0000" endDate="2024-03-02 04:00:00 +0000" value="HKCategoryValueSleepAnalysisAsleepREM"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Apple Watch" startDate="2024-03-02 04:00:00 +0000" endDate="2024-03-02 06:00:00 +0000" value="HKCategoryValueSleepAnalysisAsleepCore"/>
+ <Record type="HKCategoryTypeIdentifierSleepAnalysis" sourceName="Other" startDate="2024-03-01 23:00:00 +0000" endDate="2024-03-02 06:00:00 +0000" value="HKCategoryValueSleepAnalysisAsleepUnspecified"/>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="iPhone" unit="count" startDate="not a date" endDate="2024-03-02 08:10:00 +0000" value="10"/>
+ <Record type="HKQuantityTypeIdentifierDietaryWater" sourceName="App" unit="mL" startDate="2024-03-02 08:00:00 +0000" endDate="2024-03-02 08:00:00 +0000" value="250"/>
+ <Workout workoutActivityType="HKWorkoutActivityTypeRunning" duration="30"/>
+</HealthData>`
+
+func TestParseAndAggregate(t *testing.T) {
+	aggregator := NewAggregator()
+	err := Parse(strings.NewReader(testExport), func(rec Record) error {
+		aggregator.Add(rec)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if aggregator.Records != 18 || aggregator.Skipped != 2 {
+		t.Errorf("records = %d, skipped = %d; want 18, 2", aggregator.Records, aggregator.Skipped)
+	}
+
+	events, err := aggregator.Events("user-1")
+	if err != nil {
+		t.Fatalf("Events() error = %v", err)
+	}
+
+	byType := make(map[string]*models.Event)
+	for _, e := range events {
+		if e.Source != models.SourceAppleHealth || e.UserID != "user-1" {
+			t.Errorf("event %s has source %q user %q", e.EventType, e.Source, e.UserID)
+		}
+		byType[e.EventType] = e
+	}
+	if len(events) != 5 {
+		t.Fatalf("got %d events, want 5", len(events))
+	}
+
+	var stats models.GarminDailyStats
+	json.Unmarshal(byType[models.EventTypeGarminDailyStats].Data, &stats)
+	// Watch (4500) beats iPhone (900) rather than summing both
+	if stats.Steps != 4500 || stats.MinHeartRate != 48 || stats.MaxHeartRate != 121 || stats.RestingHeartRate != 52 {
+		t.Errorf("daily stats = %+v", stats)
+	}
+
+	if _, ok := byType[models.EventTypeGarminHRV]; ok {
+		t.Error("SDNN stored as RMSSD garmin_hrv")
+	}
+	var hrv models.HRVSDNN
+	json.Unmarshal(byType[models.EventTypeHRVSDNN].Data, &hrv)
+	if hrv.Avg != 50.0 || hrv.Min == 0 || hrv.Max < hrv.Min {
+		t.Errorf("hrv = %+v", hrv)
+	}
+
+	var sleep models.GarminSleep
+	sleepEvent := byType[models.EventTypeGarminSleep]
+	json.Unmarshal(sleepEvent.Data, &sleep)
+	if sleep.DurationMinutes != 400 || sleep.DeepSleepMinutes != 60 || sleep.LightSleepMinutes != 240 ||
+		sleep.REMSleepMinutes != 100 || sleep.AwakeMinutes != 20 {
+		t.Errorf("sleep = %+v", sleep)
+	}
+	if sleep.WASOMinutes == nil || *sleep.WASOMinutes != 20 || sleep.Awakenings == nil || *sleep.Awakenings != 1 {
+		t.Errorf("sleep continuity = %v, %v", sleep.WASOMinutes, sleep.Awakenings)
+	}
+	if want := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC); !sleepEvent.Time.Equal(want) {
+		t.Errorf("sleep event time = %v, want %v", sleepEvent.Time, want)
+	}
+
+	var hypnogram models.SleepHypnogram
+	json.Unmarshal(byType[models.EventTypeSleepHypnogram].Data, &hypnogram)
+	if hypnogram.Date != "2024-03-02" || len(hypnogram.Stages) != 5 {
+		t.Errorf("hypnogram = %+v", hypnogram)
+	}
+
+	var mass models.BodyMass
+	json.Unmarshal(byType[models.EventTypeBodyMass].Data, &mass)
+	if math.Abs(mass.WeightKg-74.843) > 0.001 {
+		t.Errorf("body mass = %v kg", mass.WeightKg)
+	}
+}
+
+func TestSummarizeNight_Unstaged(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2024, 3, 2, h, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		samples   []sleepSample
+		wantNil   bool
+		wantSleep int
+		wantAwake int
+	}{
+		{
+			name: "asleep and awake",
+			samples: []sleepSample{
+				{value: sleepInBed, start: at(0), end: at(8)},
+				{value: sleepAsleep, start: at(0), end: at(3)},
+				{value: sleepAwake, start: at(3), end: at(4)},
+				{value: sleepAsleep, start: at(4), end: at(7)},
+			},
+			wantSleep: 360,
+			wantAwake: 60,
+		},
+		{
+			name:    "in bed only",
+			samples: []sleepSample{{value: sleepInBed, start: at(0), end: at(8)}},
+			wantNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary, hypnogram := summarizeNight("2024-03-02", tt.samples)
+			if tt.wantNil {
+				if summary != nil {
+					t.Errorf("summarizeNight() = %+v, want nil", summary)
+				}
+				return
+			}
+			if hypnogram != nil {
+				t.Error("unstaged night should not produce a hypnogram")
+			}
+			if summary.DurationMinutes != tt.wantSleep || summary.AwakeMinutes != tt.wantAwake {
+				t.Errorf("summary = %+v", summary)
+			}
+		})
+	}
+}
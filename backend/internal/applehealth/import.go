@@ -0,0 +1,157 @@
+package applehealth
+
+import (
+	"archive/zip"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+)
+
+// bytesPerItem is the progress unit: one item per MiB of export.xml read.
+const bytesPerItem = 1 << 20
+
+// insertBatchSize bounds the events sent to the database per round trip.
+const insertBatchSize = 1000
+
+// Processor imports Apple Health exports as importer jobs. It accepts either
+// the export.zip produced by the Health app or a bare export.xml.
+type Processor struct {
+	eventRepo db.EventStore
+}
+
+// NewProcessor creates a new Apple Health Processor.
+func NewProcessor(eventRepo db.EventStore) *Processor {
+	return &Processor{eventRepo: eventRepo}
+}
+
+// Process streams the export and upserts the aggregated events. Aggregates
+// span the whole file, so an interrupted job re-reads from the start; the
+// upserts make that idempotent.
+func (p *Processor) Process(ctx context.Context, job *importer.Job, report func(importer.Progress) error) error {
+	xmlFile, size, err := openExportXML(job.FilePath)
+	if err != nil {
+		return err
+	}
+	defer xmlFile.Close()
+
+	progress := importer.Progress{TotalItems: int((size + bytesPerItem - 1) / bytesPerItem)}
+	if err := report(progress); err != nil {
+		return err
+	}
+
+	counter := &countingReader{r: xmlFile}
+	aggregator := NewAggregator()
+
+	err = Parse(bufio.NewReaderSize(counter, 1<<20), func(rec Record) error {
+		aggregator.Add(rec)
+
+		if read := int(counter.n / bytesPerItem); read > progress.ProcessedItems {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			progress.ProcessedItems = read
+			return report(progress)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	events, err := aggregator.Events(job.UserID)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < len(events); i += insertBatchSize {
+		end := min(i+insertBatchSize, len(events))
+		inserted, err := p.eventRepo.InsertEvents(ctx, events[i:end])
+		if err != nil {
+			return fmt.Errorf("failed to store Apple Health events: %w", err)
+		}
+		progress.EventsImported += inserted
+	}
+
+	progress.ProcessedItems = progress.TotalItems
+	progress.SkippedRecords = aggregator.Skipped
+	return report(progress)
+}
+
+// openExportXML opens export.xml directly or from inside an export.zip and
+// returns its uncompressed size.
+func openExportXML(filePath string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open Apple Health export: %w", err)
+	}
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("failed to read Apple Health export: %w", err)
+	}
+
+	if string(magic) != "PK\x03\x04" {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			f.Close()
+			return nil, 0, err
+		}
+		return f, info.Size(), nil
+	}
+	f.Close()
+
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open Apple Health archive: %w", err)
+	}
+	for _, entry := range archive.File {
+		if path.Base(entry.Name) != "export.xml" {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			archive.Close()
+			return nil, 0, fmt.Errorf("failed to open export.xml: %w", err)
+		}
+		return &zipEntryReader{ReadCloser: rc, archive: archive}, int64(entry.UncompressedSize64), nil
+	}
+
+	archive.Close()
+	return nil, 0, fmt.Errorf("archive does not contain export.xml")
+}
+
+// zipEntryReader closes the archive along with the entry.
+type zipEntryReader struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (z *zipEntryReader) Close() error {
+	err := z.ReadCloser.Close()
+	if archiveErr := z.archive.Close(); err == nil {
+		err = archiveErr
+	}
+	return err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,82 @@
+// Package applehealth imports Apple Health "Export All Health Data" archives.
+//
+// export.xml routinely runs to several GB, so it is streamed token by token
+// and reduced to per-day aggregates rather than loaded into memory.
+package applehealth
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// HealthKit record types we import
+const (
+	TypeStepCount        = "HKQuantityTypeIdentifierStepCount"
+	TypeHeartRate        = "HKQuantityTypeIdentifierHeartRate"
+	TypeRestingHeartRate = "HKQuantityTypeIdentifierRestingHeartRate"
+	TypeHRVSDNN          = "HKQuantityTypeIdentifierHeartRateVariabilitySDNN"
+	TypeBodyMass         = "HKQuantityTypeIdentifierBodyMass"
+	TypeSleepAnalysis    = "HKCategoryTypeIdentifierSleepAnalysis"
+)
+
+// exportTimeLayout is the timestamp format used throughout export.xml.
+const exportTimeLayout = "2006-01-02 15:04:05 -0700"
+
+// Record is one <Record> element from export.xml. Dates keep the UTC offset
+// they were recorded with, so their calendar day is the user's local day.
+type Record struct {
+	Type       string
+	SourceName string
+	Unit       string
+	Value      string
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+// Parse streams export.xml and calls fn for every <Record> element.
+// Records with unparseable dates are passed through with zero times.
+func Parse(r io.Reader, fn func(Record) error) error {
+	decoder := xml.NewDecoder(r)
+	// Some exports contain characters outside the XML 1.0 range in free-text metadata
+	decoder.Strict = false
+
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read export.xml: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Record" {
+			continue
+		}
+
+		var rec Record
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "type":
+				rec.Type = attr.Value
+			case "sourceName":
+				rec.SourceName = attr.Value
+			case "unit":
+				rec.Unit = attr.Value
+			case "value":
+				rec.Value = attr.Value
+			case "startDate":
+				rec.StartDate, _ = time.Parse(exportTimeLayout, attr.Value)
+			case "endDate":
+				rec.EndDate, _ = time.Parse(exportTimeLayout, attr.Value)
+			}
+		}
+
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"sort"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+)
+
+// Gap reasons
+const (
+	GapMissing = "missing" // never synced
+	GapFailed  = "failed"  // every sync failed
+	GapEmpty   = "empty"   // synced, but nothing came back
+)
+
+// maxGapDays caps the range of a gap report.
+const maxGapDays = 366
+
+// sparseDataTypes are data types with no data on many days as a matter of
+// course, such as a day without a workout, so an empty sync is not a gap.
+var sparseDataTypes = map[string]bool{
+	garmin.DataTypeActivity: true,
+	garmin.DataTypeVO2Max:   true,
+}
+
+// Gap is a day with no data of a data type.
+type Gap struct {
+	Date     string `json:"date"`
+	DataType string `json:"data_type"`
+	Reason   string `json:"reason"`
+}
+
+// dayKey identifies one data type on one day (YYYY-MM-DD).
+type dayKey struct {
+	dataType string
+	date     string
+}
+
+// dayStatus is what is known about one data type on one day.
+type dayStatus struct {
+	synced  bool // a sync succeeded, at least partially
+	failed  bool // a sync failed
+	hasData bool // events exist, or a sync fetched records
+}
+
+// findGaps returns, for each of dataTypes and each day from start to end
+// inclusive, the days without data, ordered by data type and date.
+func findGaps(dataTypes []string, start, end time.Time, days map[dayKey]dayStatus) []Gap {
+	sorted := append([]string(nil), dataTypes...)
+	sort.Strings(sorted)
+
+	gaps := []Gap{}
+	for _, dataType := range sorted {
+		for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+			date := d.Format("2006-01-02")
+			reason := gapReason(dataType, days[dayKey{dataType, date}])
+			if reason != "" {
+				gaps = append(gaps, Gap{Date: date, DataType: dataType, Reason: reason})
+			}
+		}
+	}
+	return gaps
+}
+
+// gapReason classifies one day of dataType, or returns "" if it is not a
+// gap.
+func gapReason(dataType string, s dayStatus) string {
+	switch {
+	case s.hasData:
+		return ""
+	case s.synced:
+		if sparseDataTypes[dataType] {
+			return ""
+		}
+		return GapEmpty
+	case s.failed:
+		return GapFailed
+	default:
+		return GapMissing
+	}
+}
+
+// refetchDates groups gaps by data type into the dates to sync again.
+func refetchDates(gaps []Gap) map[string][]string {
+	dates := make(map[string][]string)
+	for _, g := range gaps {
+		dates[g.DataType] = append(dates[g.DataType], g.Date)
+	}
+	return dates
+}
+
+// gapRange parses a gap report's start and end (YYYY-MM-DD). end defaults
+// to yesterday, as today may not have synced yet, and start to defaultDays
+// before end.
+func gapRange(startStr, endStr string, defaultDays int, now time.Time) (start, end time.Time, ok bool) {
+	y, m, d := now.UTC().Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	end = today.AddDate(0, 0, -1)
+	if endStr != "" {
+		t, err := time.Parse("2006-01-02", endStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		end = t
+	}
+
+	start = end.AddDate(0, 0, 1-defaultDays)
+	if startStr != "" {
+		t, err := time.Parse("2006-01-02", startStr)
+		if err != nil {
+			return time.Time{}, time.Time{}, false
+		}
+		start = t
+	}
+
+	if start.After(end) || end.Sub(start) >= maxGapDays*24*time.Hour {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, end, true
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGapReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType string
+		status   dayStatus
+		want     string
+	}{
+		{name: "data", dataType: "sleep", status: dayStatus{synced: true, hasData: true}, want: ""},
+		{name: "data despite failed sync", dataType: "sleep", status: dayStatus{failed: true, hasData: true}, want: ""},
+		{name: "nothing", dataType: "sleep", want: GapMissing},
+		{name: "only failed syncs", dataType: "sleep", status: dayStatus{failed: true}, want: GapFailed},
+		{name: "empty sync", dataType: "sleep", status: dayStatus{synced: true}, want: GapEmpty},
+		{name: "empty sync of sparse type", dataType: "activity", status: dayStatus{synced: true}, want: ""},
+		{name: "sparse type never synced", dataType: "activity", want: GapMissing},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gapReason(tt.dataType, tt.status); got != tt.want {
+				t.Errorf("gapReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)
+	days := map[dayKey]dayStatus{
+		{"sleep", "2026-01-01"}: {synced: true, hasData: true},
+		{"sleep", "2026-01-02"}: {failed: true},
+		{"hrv", "2026-01-01"}:   {hasData: true},
+		{"hrv", "2026-01-02"}:   {hasData: true},
+		{"hrv", "2026-01-03"}:   {synced: true},
+	}
+
+	got := findGaps([]string{"sleep", "hrv"}, start, end, days)
+	want := []Gap{
+		{Date: "2026-01-03", DataType: "hrv", Reason: GapEmpty},
+		{Date: "2026-01-02", DataType: "sleep", Reason: GapFailed},
+		{Date: "2026-01-03", DataType: "sleep", Reason: GapMissing},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("findGaps() = %+v, want %+v", got, want)
+	}
+
+	wantDates := map[string][]string{
+		"hrv":   {"2026-01-03"},
+		"sleep": {"2026-01-02", "2026-01-03"},
+	}
+	if dates := refetchDates(got); !reflect.DeepEqual(dates, wantDates) {
+		t.Errorf("refetchDates() = %v, want %v", dates, wantDates)
+	}
+}
+
+func TestGapRange(t *testing.T) {
+	now := time.Date(2026, 1, 28, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		start     string
+		end       string
+		wantStart string
+		wantEnd   string
+		wantOK    bool
+	}{
+		{name: "defaults", wantStart: "2026-01-21", wantEnd: "2026-01-27", wantOK: true},
+		{name: "explicit", start: "2026-01-01", end: "2026-01-10", wantStart: "2026-01-01", wantEnd: "2026-01-10", wantOK: true},
+		{name: "single day", start: "2026-01-05", end: "2026-01-05", wantStart: "2026-01-05", wantEnd: "2026-01-05", wantOK: true},
+		{name: "start only", start: "2026-01-20", wantStart: "2026-01-20", wantEnd: "2026-01-27", wantOK: true},
+		{name: "start after end", start: "2026-01-10", end: "2026-01-01"},
+		{name: "too long", start: "2025-01-01", end: "2026-01-10"},
+		{name: "bad date", start: "yesterday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, ok := gapRange(tt.start, tt.end, 7, now)
+			if ok != tt.wantOK {
+				t.Fatalf("gapRange() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if got := start.Format("2006-01-02"); got != tt.wantStart {
+				t.Errorf("start = %s, want %s", got, tt.wantStart)
+			}
+			if got := end.Format("2006-01-02"); got != tt.wantEnd {
+				t.Errorf("end = %s, want %s", got, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -1,24 +1,41 @@
 package audit
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
 	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
 )
 
+// SyncObserver is told about each sync recorded, such as to alert when a
+// user's syncs keep failing.
+type SyncObserver interface {
+	ObserveSync(ctx context.Context, a *SyncAudit)
+}
+
 // Handler handles sync audit endpoints.
 type Handler struct {
-	repo *Repository
+	repo     Store
+	roles    middleware.RoleLookup
+	observer SyncObserver
 }
 
-// NewHandler creates a new audit Handler.
-func NewHandler(repo *Repository) *Handler {
-	return &Handler{repo: repo}
+// NewHandler creates a new audit Handler. Reads are limited to the caller's
+// own audits unless roles says the caller is an admin. observer, if not
+// nil, sees every sync recorded.
+func NewHandler(repo Store, roles middleware.RoleLookup, observer SyncObserver) *Handler {
+	return &Handler{repo: repo, roles: roles, observer: observer}
 }
 
 // HandlePostSyncAudit handles POST /api/v1/audit/sync
+// It sits behind the ingest credentials, like the data it audits. An ingest
+// token's user takes precedence over the payload's user_id.
 func (h *Handler) HandlePostSyncAudit(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -32,6 +49,14 @@ func (h *Handler) HandlePostSyncAudit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if userID := middleware.UserIDFromContext(r.Context()); userID != "" {
+		payload.UserID = userID
+	}
+	if payload.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+
 	if err := h.repo.InsertSyncAudit(r.Context(), &payload); err != nil {
 		log.Printf("Failed to insert sync audit: %v", err)
 		http.Error(w, "Failed to store audit", http.StatusInternalServerError)
@@ -41,6 +66,10 @@ func (h *Handler) HandlePostSyncAudit(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Audit recorded: user=%s, type=%s, date=%s, status=%s",
 		payload.UserID, payload.DataType, payload.TargetDate, payload.Status)
 
+	if h.observer != nil {
+		h.observer.ObserveSync(r.Context(), &payload)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -49,16 +78,46 @@ func (h *Handler) HandlePostSyncAudit(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// HandleGetRecentSyncAudits handles GET /api/v1/audit/sync/recent?user_id=X&limit=Y
+// targetUser returns whose audits a read is for: the caller's own, or
+// another user's named by ?user_id= if the caller is an admin. Otherwise it
+// writes an error response and returns false.
+func (h *Handler) targetUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return "", false
+	}
+
+	requested := r.URL.Query().Get("user_id")
+	if requested == "" || requested == userID {
+		return userID, true
+	}
+	if !h.isAdmin(r, userID) {
+		http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
+		return "", false
+	}
+	return requested, true
+}
+
+func (h *Handler) isAdmin(r *http.Request, userID string) bool {
+	role, err := h.roles.UserRole(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to look up role of user %s: %v", userID, err)
+		return false
+	}
+	return role == models.RoleAdmin
+}
+
+// HandleGetRecentSyncAudits handles GET /api/v1/audit/sync/recent?limit=Y
+// Admins may add user_id=X to read another user's audits.
 func (h *Handler) HandleGetRecentSyncAudits(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "user_id parameter is required", http.StatusBadRequest)
+	userID, ok := h.targetUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -82,12 +141,32 @@ func (h *Handler) HandleGetRecentSyncAudits(w http.ResponseWriter, r *http.Reque
 }
 
 // HandleGetSyncAuditsByType handles GET /api/v1/audit/sync/by-type?data_type=X&limit=Y
+// Admins may add user_id=X for another user, or all_users=true for
+// everyone's.
 func (h *Handler) HandleGetSyncAuditsByType(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
+	var userID string
+	if r.URL.Query().Get("all_users") == "true" {
+		caller := middleware.UserIDFromContext(r.Context())
+		if caller == "" {
+			http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		if !h.isAdmin(r, caller) {
+			http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
+			return
+		}
+	} else {
+		var ok bool
+		if userID, ok = h.targetUser(w, r); !ok {
+			return
+		}
+	}
+
 	dataType := r.URL.Query().Get("data_type")
 	if dataType == "" {
 		http.Error(w, "data_type parameter is required", http.StatusBadRequest)
@@ -101,7 +180,7 @@ func (h *Handler) HandleGetSyncAuditsByType(w http.ResponseWriter, r *http.Reque
 		}
 	}
 
-	audits, err := h.repo.GetSyncAuditsByDataType(r.Context(), dataType, limit)
+	audits, err := h.repo.GetSyncAuditsByDataType(r.Context(), userID, dataType, limit)
 	if err != nil {
 		log.Printf("Failed to get sync audits by type: %v", err)
 		http.Error(w, "Failed to retrieve audits", http.StatusInternalServerError)
@@ -113,16 +192,16 @@ func (h *Handler) HandleGetSyncAuditsByType(w http.ResponseWriter, r *http.Reque
 	json.NewEncoder(w).Encode(audits)
 }
 
-// HandleGetSyncAuditStats handles GET /api/v1/audit/sync/stats?user_id=X&start=Y&end=Z
+// HandleGetSyncAuditStats handles GET /api/v1/audit/sync/stats?start=Y&end=Z
+// Admins may add user_id=X to read another user's stats.
 func (h *Handler) HandleGetSyncAuditStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "user_id parameter is required", http.StatusBadRequest)
+	userID, ok := h.targetUser(w, r)
+	if !ok {
 		return
 	}
 
@@ -152,3 +231,195 @@ func (h *Handler) HandleGetSyncAuditStats(w http.ResponseWriter, r *http.Request
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(stats)
 }
+
+// HandleGetSyncGaps handles GET /api/v1/sync/gaps?start=Y&end=Z
+// It reports the days without data per data type the caller syncs, over
+// the last 30 days by default. Admins may add user_id=X.
+func (h *Handler) HandleGetSyncGaps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := h.targetUser(w, r)
+	if !ok {
+		return
+	}
+
+	start, end, ok := gapRange(r.URL.Query().Get("start"), r.URL.Query().Get("end"), 30, time.Now())
+	if !ok {
+		http.Error(w, "start and end must be dates (YYYY-MM-DD), start not after end, at most 366 days apart", http.StatusBadRequest)
+		return
+	}
+
+	gaps, err := h.repo.SyncGaps(r.Context(), userID, start, end)
+	if err != nil {
+		log.Printf("Failed to find sync gaps: %v", err)
+		http.Error(w, "Failed to retrieve gaps", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"start":  start.Format("2006-01-02"),
+		"end":    end.Format("2006-01-02"),
+		"gaps":   gaps,
+	})
+}
+
+// HandleGetRefetchDates handles GET /api/v1/sync/refetch?user_id=X&start=Y&end=Z
+// It is for the sync scheduler, behind the ingest credentials, and lists
+// the dates to sync again per data type, over the last 7 days by default.
+// An ingest token's user takes precedence over user_id.
+func (h *Handler) HandleGetRefetchDates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		userID = r.URL.Query().Get("user_id")
+	}
+	if userID == "" {
+		http.Error(w, "user_id parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	start, end, ok := gapRange(r.URL.Query().Get("start"), r.URL.Query().Get("end"), 7, time.Now())
+	if !ok {
+		http.Error(w, "start and end must be dates (YYYY-MM-DD), start not after end, at most 366 days apart", http.StatusBadRequest)
+		return
+	}
+
+	gaps, err := h.repo.SyncGaps(r.Context(), userID, start, end)
+	if err != nil {
+		log.Printf("Failed to find sync gaps for refetch: %v", err)
+		http.Error(w, "Failed to retrieve gaps", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"user_id": userID,
+		"start":   start.Format("2006-01-02"),
+		"end":     end.Format("2006-01-02"),
+		"dates":   refetchDates(gaps),
+	})
+}
+
+// HandleStartSync handles POST /api/v1/audit/sync/start
+// It records a sync as running before it starts, for the scheduler to
+// finish with /complete or /fail. Behind the ingest credentials, like
+// HandlePostSyncAudit.
+func (h *Handler) HandleStartSync(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload SyncAudit
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	if userID := middleware.UserIDFromContext(r.Context()); userID != "" {
+		payload.UserID = userID
+	}
+	if payload.UserID == "" || payload.DataType == "" {
+		http.Error(w, "user_id and data_type are required", http.StatusBadRequest)
+		return
+	}
+	if _, err := time.Parse("2006-01-02", payload.TargetDate); err != nil {
+		http.Error(w, "target_date must be a date (YYYY-MM-DD)", http.StatusBadRequest)
+		return
+	}
+
+	started, err := h.repo.StartSync(r.Context(), &payload)
+	if err != nil {
+		log.Printf("Failed to start sync audit: %v", err)
+		http.Error(w, "Failed to store audit", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"id":     started.ID,
+		"audit":  started,
+	})
+}
+
+// HandleCompleteSync handles POST /api/v1/audit/sync/{id}/complete
+// The body carries the record counts and timestamps, and a status of
+// success (the default) or partial.
+func (h *Handler) HandleCompleteSync(w http.ResponseWriter, r *http.Request) {
+	h.finishSync(w, r, false)
+}
+
+// HandleFailSync handles POST /api/v1/audit/sync/{id}/fail
+// The body carries the error_message and any records counted before the
+// failure.
+func (h *Handler) HandleFailSync(w http.ResponseWriter, r *http.Request) {
+	h.finishSync(w, r, true)
+}
+
+func (h *Handler) finishSync(w http.ResponseWriter, r *http.Request, failed bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var result SyncAudit
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case failed:
+		result.Status = StatusFailed
+	case result.Status == "":
+		result.Status = StatusSuccess
+	case result.Status != StatusSuccess && result.Status != StatusPartial:
+		http.Error(w, "status must be success or partial", http.StatusBadRequest)
+		return
+	}
+
+	// Only the user WithIngestAuth resolved can finish its syncs: the token's
+	// user, or the user a signed request names, which its key is bound to.
+	userID := middleware.UserIDFromContext(r.Context())
+	finished, err := h.repo.FinishSync(r.Context(), r.PathValue("id"), userID, &result)
+	switch {
+	case errors.Is(err, ErrSyncNotFound):
+		http.Error(w, "Sync not found", http.StatusNotFound)
+		return
+	case errors.Is(err, ErrSyncNotRunning):
+		http.Error(w, "Sync already finished", http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Failed to finish sync audit: %v", err)
+		http.Error(w, "Failed to store audit", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Audit finished: user=%s, type=%s, date=%s, status=%s",
+		finished.UserID, finished.DataType, finished.TargetDate, finished.Status)
+
+	if h.observer != nil {
+		h.observer.ObserveSync(r.Context(), finished)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"audit":  finished,
+	})
+}
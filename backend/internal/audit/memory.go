@@ -0,0 +1,279 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+)
+
+// MemoryStore keeps sync audits in memory with the same semantics as
+// Repository, reading events for SyncGaps from a db.EventStore. It is for
+// tests.
+type MemoryStore struct {
+	events db.EventStore
+
+	mu     sync.Mutex
+	audits []*SyncAudit // in insertion order
+}
+
+// NewMemoryStore creates an empty MemoryStore over events.
+func NewMemoryStore(events db.EventStore) *MemoryStore {
+	return &MemoryStore{events: events}
+}
+
+// add checks a as the sync_audit table's constraints would and stores a
+// copy of it with a new ID.
+func (s *MemoryStore) add(a *SyncAudit) (*SyncAudit, error) {
+	if a.UserID == "" || a.DataType == "" {
+		return nil, fmt.Errorf("user_id and data_type are required")
+	}
+	targetDate, err := time.Parse("2006-01-02", a.TargetDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target_date %q", a.TargetDate)
+	}
+	switch a.Status {
+	case StatusRunning, StatusSuccess, StatusPartial, StatusFailed:
+	default:
+		return nil, fmt.Errorf("invalid status %q", a.Status)
+	}
+
+	stored := *a
+	stored.ID = db.NewUUID()
+	stored.TargetDate = targetDate.Format("2006-01-02")
+	s.audits = append(s.audits, &stored)
+	return &stored, nil
+}
+
+// InsertSyncAudit implements Store.
+func (s *MemoryStore) InsertSyncAudit(ctx context.Context, audit *SyncAudit) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if audit.SyncCompletedAt != nil {
+		duration := int(math.Round(audit.SyncCompletedAt.Sub(audit.SyncStartedAt).Seconds()))
+		audit.SyncDurationSeconds = &duration
+	}
+
+	stored, err := s.add(audit)
+	if err != nil {
+		return fmt.Errorf("failed to insert sync audit: %w", err)
+	}
+	audit.ID = stored.ID
+	return nil
+}
+
+// StartSync implements Store.
+func (s *MemoryStore) StartSync(ctx context.Context, audit *SyncAudit) (*SyncAudit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	started := SyncAudit{
+		SyncStartedAt: audit.SyncStartedAt,
+		UserID:        audit.UserID,
+		DataType:      audit.DataType,
+		TargetDate:    audit.TargetDate,
+		Status:        StatusRunning,
+		Metadata:      audit.Metadata,
+	}
+	if started.SyncStartedAt.IsZero() {
+		started.SyncStartedAt = time.Now()
+	}
+
+	stored, err := s.add(&started)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start sync audit: %w", err)
+	}
+	result := *stored
+	return &result, nil
+}
+
+// FinishSync implements Store.
+func (s *MemoryStore) FinishSync(ctx context.Context, id, userID string, result *SyncAudit) (*SyncAudit, error) {
+	switch result.Status {
+	case StatusSuccess, StatusPartial, StatusFailed:
+	default:
+		return nil, fmt.Errorf("failed to finish sync audit: invalid status %q", result.Status)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var a *SyncAudit
+	for _, candidate := range s.audits {
+		if candidate.ID == id && (userID == "" || candidate.UserID == userID) {
+			a = candidate
+		}
+	}
+	if a == nil {
+		return nil, ErrSyncNotFound
+	}
+	if a.Status != StatusRunning {
+		return nil, ErrSyncNotRunning
+	}
+
+	now := time.Now()
+	duration := int(math.Round(math.Max(0, now.Sub(a.SyncStartedAt).Seconds())))
+	a.Status = result.Status
+	a.SyncCompletedAt = &now
+	a.SyncDurationSeconds = &duration
+	a.RecordsFetched = result.RecordsFetched
+	a.RecordsInserted = result.RecordsInserted
+	a.RecordsUpdated = result.RecordsUpdated
+	a.EarliestTimestamp = result.EarliestTimestamp
+	a.LatestTimestamp = result.LatestTimestamp
+	a.ErrorMessage = result.ErrorMessage
+	if result.Metadata != nil {
+		a.Metadata = result.Metadata
+	}
+
+	finished := *a
+	return &finished, nil
+}
+
+// FailStaleSyncs implements Store.
+func (s *MemoryStore) FailStaleSyncs(ctx context.Context, cutoff time.Time) ([]SyncAudit, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	message := "sync never completed; marked failed as stale"
+	var failed []SyncAudit
+	for _, a := range s.audits {
+		if a.Status == StatusRunning && a.SyncStartedAt.Before(cutoff) {
+			a.Status = StatusFailed
+			a.ErrorMessage = &message
+			failed = append(failed, *a)
+		}
+	}
+	return failed, nil
+}
+
+// newest returns copies of the audits that match, most recently started
+// first, at most limit of them.
+func (s *MemoryStore) newest(limit int, match func(*SyncAudit) bool) []SyncAudit {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var audits []SyncAudit
+	for i := len(s.audits) - 1; i >= 0; i-- {
+		if match(s.audits[i]) {
+			audits = append(audits, *s.audits[i])
+		}
+	}
+	sort.SliceStable(audits, func(i, j int) bool {
+		return audits[i].SyncStartedAt.After(audits[j].SyncStartedAt)
+	})
+	if limit >= 0 && len(audits) > limit {
+		audits = audits[:limit]
+	}
+	return audits
+}
+
+// Audits returns every user's audits, most recently started first.
+func (s *MemoryStore) Audits() []SyncAudit {
+	return s.newest(-1, func(*SyncAudit) bool { return true })
+}
+
+// GetRecentSyncAudits implements Store.
+func (s *MemoryStore) GetRecentSyncAudits(ctx context.Context, userID string, limit int) ([]SyncAudit, error) {
+	return s.newest(limit, func(a *SyncAudit) bool { return a.UserID == userID }), nil
+}
+
+// GetSyncAuditsByDataType implements Store.
+func (s *MemoryStore) GetSyncAuditsByDataType(ctx context.Context, userID, dataType string, limit int) ([]SyncAudit, error) {
+	return s.newest(limit, func(a *SyncAudit) bool {
+		return a.DataType == dataType && (userID == "" || a.UserID == userID)
+	}), nil
+}
+
+// GetSyncAuditStats implements Store.
+func (s *MemoryStore) GetSyncAuditStats(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]interface{}, error) {
+	audits := s.newest(-1, func(a *SyncAudit) bool {
+		return a.UserID == userID && !a.SyncStartedAt.Before(startDate) && !a.SyncStartedAt.After(endDate)
+	})
+
+	var fetched, inserted, updated, successful, failed, timed, totalDuration int
+	for _, a := range audits {
+		fetched += a.RecordsFetched
+		inserted += a.RecordsInserted
+		updated += a.RecordsUpdated
+		switch a.Status {
+		case StatusSuccess:
+			successful++
+		case StatusFailed:
+			failed++
+		}
+		if a.SyncDurationSeconds != nil {
+			timed++
+			totalDuration += *a.SyncDurationSeconds
+		}
+	}
+
+	result := map[string]interface{}{
+		"total_syncs":      len(audits),
+		"total_fetched":    fetched,
+		"total_inserted":   inserted,
+		"total_updated":    updated,
+		"successful_syncs": successful,
+		"failed_syncs":     failed,
+	}
+	if timed > 0 {
+		result["avg_duration_seconds"] = float64(totalDuration) / float64(timed)
+	}
+	return result, nil
+}
+
+// SyncGaps implements Store.
+func (s *MemoryStore) SyncGaps(ctx context.Context, userID string, start, end time.Time) ([]Gap, error) {
+	first, last := start.Format("2006-01-02"), end.Format("2006-01-02")
+	days := make(map[dayKey]dayStatus)
+	seen := make(map[string]bool)
+	var dataTypes []string
+
+	for _, a := range s.newest(-1, func(a *SyncAudit) bool { return a.UserID == userID }) {
+		if !seen[a.DataType] {
+			seen[a.DataType] = true
+			dataTypes = append(dataTypes, a.DataType)
+		}
+		if a.TargetDate < first || a.TargetDate > last {
+			continue
+		}
+
+		k := dayKey{a.DataType, a.TargetDate}
+		st := days[k]
+		succeeded := a.Status == StatusSuccess || a.Status == StatusPartial
+		st.synced = st.synced || succeeded
+		st.failed = st.failed || a.Status == StatusFailed
+		st.hasData = st.hasData || (succeeded && a.RecordsFetched > 0)
+		days[k] = st
+	}
+
+	// Events count from start up to, not including, the day after end
+	until := end.AddDate(0, 0, 1)
+	for _, dataType := range dataTypes {
+		eventType := garmin.EventType(dataType)
+		if eventType == "" {
+			continue
+		}
+		events, err := s.events.GetEventsByUserAndType(ctx, userID, eventType, start, until)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query events by day: %w", err)
+		}
+		for _, e := range events {
+			if !e.Time.Before(until) {
+				continue
+			}
+			k := dayKey{dataType, e.Time.UTC().Format("2006-01-02")}
+			st := days[k]
+			st.hasData = true
+			days[k] = st
+		}
+	}
+
+	return findGaps(dataTypes, start, end, days), nil
+}
@@ -2,10 +2,29 @@ package audit
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin"
+)
+
+// Sync statuses. A sync recorded in one go is finished; one reported as it
+// happens starts out running.
+const (
+	StatusRunning = "running"
+	StatusSuccess = "success"
+	StatusPartial = "partial"
+	StatusFailed  = "failed"
+)
+
+// Errors finishing a sync
+var (
+	ErrSyncNotFound   = errors.New("sync audit not found")
+	ErrSyncNotRunning = errors.New("sync audit is not running")
 )
 
 // SyncAudit represents a sync audit record.
@@ -27,6 +46,19 @@ type SyncAudit struct {
 	Metadata            []byte     `json:"metadata,omitempty"`
 }
 
+// Store records syncs and answers the audit and gap queries. Repository
+// implements it on Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	InsertSyncAudit(ctx context.Context, audit *SyncAudit) error
+	StartSync(ctx context.Context, audit *SyncAudit) (*SyncAudit, error)
+	FinishSync(ctx context.Context, id, userID string, result *SyncAudit) (*SyncAudit, error)
+	FailStaleSyncs(ctx context.Context, cutoff time.Time) ([]SyncAudit, error)
+	GetRecentSyncAudits(ctx context.Context, userID string, limit int) ([]SyncAudit, error)
+	GetSyncAuditsByDataType(ctx context.Context, userID, dataType string, limit int) ([]SyncAudit, error)
+	GetSyncAuditStats(ctx context.Context, userID string, startDate, endDate time.Time) (map[string]interface{}, error)
+	SyncGaps(ctx context.Context, userID string, start, end time.Time) ([]Gap, error)
+}
+
 // Repository handles database operations for sync audit.
 type Repository struct {
 	db *db.Database
@@ -37,7 +69,30 @@ func NewRepository(database *db.Database) *Repository {
 	return &Repository{db: database}
 }
 
-// InsertSyncAudit inserts a new sync audit record.
+const auditColumns = `
+	id, sync_started_at, sync_completed_at, sync_duration_seconds,
+	user_id, data_type, target_date::text,
+	records_fetched, records_inserted, records_updated,
+	earliest_timestamp, latest_timestamp,
+	status, error_message, metadata`
+
+func scanAudit(row interface{ Scan(...interface{}) error }) (*SyncAudit, error) {
+	var a SyncAudit
+	err := row.Scan(
+		&a.ID, &a.SyncStartedAt, &a.SyncCompletedAt, &a.SyncDurationSeconds,
+		&a.UserID, &a.DataType, &a.TargetDate,
+		&a.RecordsFetched, &a.RecordsInserted, &a.RecordsUpdated,
+		&a.EarliestTimestamp, &a.LatestTimestamp,
+		&a.Status, &a.ErrorMessage, &a.Metadata,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// InsertSyncAudit inserts a new sync audit record. The duration is
+// computed from the start and completion times when both are set.
 func (r *Repository) InsertSyncAudit(ctx context.Context, audit *SyncAudit) error {
 	query := `
 		INSERT INTO sync_audit (
@@ -47,11 +102,13 @@ func (r *Repository) InsertSyncAudit(ctx context.Context, audit *SyncAudit) erro
 			earliest_timestamp, latest_timestamp,
 			status, error_message, metadata
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
-		RETURNING id
+		VALUES ($1, $2, COALESCE(EXTRACT(EPOCH FROM ($2::timestamptz - $1::timestamptz))::int, $3),
+			$4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id, sync_duration_seconds
 	`
 
 	var id string
+	var duration *int
 	err := r.db.Pool.QueryRow(
 		ctx, query,
 		audit.SyncStartedAt, audit.SyncCompletedAt, audit.SyncDurationSeconds,
@@ -59,24 +116,127 @@ func (r *Repository) InsertSyncAudit(ctx context.Context, audit *SyncAudit) erro
 		audit.RecordsFetched, audit.RecordsInserted, audit.RecordsUpdated,
 		audit.EarliestTimestamp, audit.LatestTimestamp,
 		audit.Status, audit.ErrorMessage, audit.Metadata,
-	).Scan(&id)
+	).Scan(&id, &duration)
 	if err != nil {
 		return fmt.Errorf("failed to insert sync audit: %w", err)
 	}
 
 	audit.ID = id
+	audit.SyncDurationSeconds = duration
 	return nil
 }
 
+// StartSync records a sync that is under way. It starts now unless
+// audit.SyncStartedAt is set, and is finished later with FinishSync.
+func (r *Repository) StartSync(ctx context.Context, audit *SyncAudit) (*SyncAudit, error) {
+	var startedAt *time.Time
+	if !audit.SyncStartedAt.IsZero() {
+		startedAt = &audit.SyncStartedAt
+	}
+
+	query := `
+		INSERT INTO sync_audit (sync_started_at, user_id, data_type, target_date, status, metadata)
+		VALUES (COALESCE($1, NOW()), $2, $3, $4, $5, $6)
+		RETURNING ` + auditColumns
+
+	started, err := scanAudit(r.db.Pool.QueryRow(ctx, query,
+		startedAt, audit.UserID, audit.DataType, audit.TargetDate, StatusRunning, audit.Metadata))
+	if err != nil {
+		return nil, fmt.Errorf("failed to start sync audit: %w", err)
+	}
+	return started, nil
+}
+
+// FinishSync completes the running sync id with result, whose status must
+// be success, partial or failed. It completes now, and its duration is
+// computed from when it started. A non-empty userID must own the sync.
+// Returns ErrSyncNotFound or ErrSyncNotRunning if it cannot be finished.
+func (r *Repository) FinishSync(ctx context.Context, id, userID string, result *SyncAudit) (*SyncAudit, error) {
+	query := `
+		UPDATE sync_audit
+		SET status = $3,
+			sync_completed_at = NOW(),
+			sync_duration_seconds = GREATEST(0, EXTRACT(EPOCH FROM (NOW() - sync_started_at)))::int,
+			records_fetched = $4,
+			records_inserted = $5,
+			records_updated = $6,
+			earliest_timestamp = $7,
+			latest_timestamp = $8,
+			error_message = $9,
+			metadata = COALESCE($10, metadata)
+		WHERE id::text = $1
+			AND ($2 = '' OR user_id::text = $2)
+			AND status = 'running'
+		RETURNING ` + auditColumns
+
+	finished, err := scanAudit(r.db.Pool.QueryRow(ctx, query,
+		id, userID, result.Status,
+		result.RecordsFetched, result.RecordsInserted, result.RecordsUpdated,
+		result.EarliestTimestamp, result.LatestTimestamp,
+		result.ErrorMessage, result.Metadata))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, r.whyNotRunning(ctx, id, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish sync audit: %w", err)
+	}
+	return finished, nil
+}
+
+// whyNotRunning tells apart a missing sync and one that already finished,
+// after FinishSync matched no row.
+func (r *Repository) whyNotRunning(ctx context.Context, id, userID string) error {
+	var status string
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT status FROM sync_audit
+		WHERE id::text = $1 AND ($2 = '' OR user_id::text = $2)
+	`, id, userID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSyncNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get sync audit: %w", err)
+	}
+	return ErrSyncNotRunning
+}
+
+// FailStaleSyncs marks syncs still running that started before cutoff as
+// failed, since whatever ran them has presumably died, and returns them.
+func (r *Repository) FailStaleSyncs(ctx context.Context, cutoff time.Time) ([]SyncAudit, error) {
+	query := `
+		UPDATE sync_audit
+		SET status = 'failed',
+			error_message = 'sync never completed; marked failed as stale'
+		WHERE status = 'running'
+			AND sync_started_at < $1
+		RETURNING ` + auditColumns
+
+	rows, err := r.db.Pool.Query(ctx, query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fail stale syncs: %w", err)
+	}
+	defer rows.Close()
+
+	var audits []SyncAudit
+	for rows.Next() {
+		a, err := scanAudit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sync audit: %w", err)
+		}
+		audits = append(audits, *a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating sync audits: %w", err)
+	}
+
+	return audits, nil
+}
+
 // GetRecentSyncAudits retrieves recent sync audit records.
 func (r *Repository) GetRecentSyncAudits(ctx context.Context, userID string, limit int) ([]SyncAudit, error) {
 	query := `
-		SELECT
-			id, sync_started_at, sync_completed_at, sync_duration_seconds,
-			user_id, data_type, target_date,
-			records_fetched, records_inserted, records_updated,
-			earliest_timestamp, latest_timestamp,
-			status, error_message, metadata
+		SELECT ` + auditColumns + `
 		FROM sync_audit
 		WHERE user_id = $1
 		ORDER BY sync_started_at DESC
@@ -91,17 +251,11 @@ func (r *Repository) GetRecentSyncAudits(ctx context.Context, userID string, lim
 
 	var audits []SyncAudit
 	for rows.Next() {
-		var a SyncAudit
-		if err := rows.Scan(
-			&a.ID, &a.SyncStartedAt, &a.SyncCompletedAt, &a.SyncDurationSeconds,
-			&a.UserID, &a.DataType, &a.TargetDate,
-			&a.RecordsFetched, &a.RecordsInserted, &a.RecordsUpdated,
-			&a.EarliestTimestamp, &a.LatestTimestamp,
-			&a.Status, &a.ErrorMessage, &a.Metadata,
-		); err != nil {
+		a, err := scanAudit(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan sync audit: %w", err)
 		}
-		audits = append(audits, a)
+		audits = append(audits, *a)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -111,22 +265,19 @@ func (r *Repository) GetRecentSyncAudits(ctx context.Context, userID string, lim
 	return audits, nil
 }
 
-// GetSyncAuditsByDataType retrieves sync audit records filtered by data type.
-func (r *Repository) GetSyncAuditsByDataType(ctx context.Context, dataType string, limit int) ([]SyncAudit, error) {
+// GetSyncAuditsByDataType retrieves sync audit records filtered by data
+// type, for one user or, with an empty userID, for every user.
+func (r *Repository) GetSyncAuditsByDataType(ctx context.Context, userID, dataType string, limit int) ([]SyncAudit, error) {
 	query := `
-		SELECT
-			id, sync_started_at, sync_completed_at, sync_duration_seconds,
-			user_id, data_type, target_date,
-			records_fetched, records_inserted, records_updated,
-			earliest_timestamp, latest_timestamp,
-			status, error_message, metadata
+		SELECT ` + auditColumns + `
 		FROM sync_audit
 		WHERE data_type = $1
+		  AND ($3 = '' OR user_id::text = $3)
 		ORDER BY sync_started_at DESC
 		LIMIT $2
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, dataType, limit)
+	rows, err := r.db.Pool.Query(ctx, query, dataType, limit, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query sync audits: %w", err)
 	}
@@ -134,17 +285,11 @@ func (r *Repository) GetSyncAuditsByDataType(ctx context.Context, dataType strin
 
 	var audits []SyncAudit
 	for rows.Next() {
-		var a SyncAudit
-		if err := rows.Scan(
-			&a.ID, &a.SyncStartedAt, &a.SyncCompletedAt, &a.SyncDurationSeconds,
-			&a.UserID, &a.DataType, &a.TargetDate,
-			&a.RecordsFetched, &a.RecordsInserted, &a.RecordsUpdated,
-			&a.EarliestTimestamp, &a.LatestTimestamp,
-			&a.Status, &a.ErrorMessage, &a.Metadata,
-		); err != nil {
+		a, err := scanAudit(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan sync audit: %w", err)
 		}
-		audits = append(audits, a)
+		audits = append(audits, *a)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -159,9 +304,9 @@ func (r *Repository) GetSyncAuditStats(ctx context.Context, userID string, start
 	query := `
 		SELECT
 			COUNT(*) as total_syncs,
-			SUM(records_fetched) as total_fetched,
-			SUM(records_inserted) as total_inserted,
-			SUM(records_updated) as total_updated,
+			COALESCE(SUM(records_fetched), 0) as total_fetched,
+			COALESCE(SUM(records_inserted), 0) as total_inserted,
+			COALESCE(SUM(records_updated), 0) as total_updated,
 			COUNT(CASE WHEN status = 'success' THEN 1 END) as successful_syncs,
 			COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed_syncs,
 			AVG(sync_duration_seconds) as avg_duration_seconds
@@ -205,3 +350,97 @@ func (r *Repository) GetSyncAuditStats(ctx context.Context, userID string, start
 
 	return result, nil
 }
+
+// SyncGaps finds the days from start to end inclusive on which userID has
+// no data of a data type they sync. A day belongs to a data type if a sync
+// targeted it or one of its events falls on it (in UTC).
+func (r *Repository) SyncGaps(ctx context.Context, userID string, start, end time.Time) ([]Gap, error) {
+	typeRows, err := r.db.Pool.Query(ctx, `SELECT DISTINCT data_type FROM sync_audit WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query synced data types: %w", err)
+	}
+	var dataTypes []string
+	for typeRows.Next() {
+		var dataType string
+		if err := typeRows.Scan(&dataType); err != nil {
+			typeRows.Close()
+			return nil, fmt.Errorf("failed to scan data type: %w", err)
+		}
+		dataTypes = append(dataTypes, dataType)
+	}
+	typeRows.Close()
+	if err := typeRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating data types: %w", err)
+	}
+
+	days := make(map[dayKey]dayStatus)
+
+	syncRows, err := r.db.Pool.Query(ctx, `
+		SELECT
+			data_type, TO_CHAR(target_date, 'YYYY-MM-DD'),
+			BOOL_OR(status IN ('success', 'partial')),
+			BOOL_OR(status = 'failed'),
+			BOOL_OR(status IN ('success', 'partial') AND records_fetched > 0)
+		FROM sync_audit
+		WHERE user_id = $1
+			AND target_date BETWEEN $2 AND $3
+		GROUP BY 1, 2
+	`, userID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query syncs by day: %w", err)
+	}
+	for syncRows.Next() {
+		var k dayKey
+		var s dayStatus
+		if err := syncRows.Scan(&k.dataType, &k.date, &s.synced, &s.failed, &s.hasData); err != nil {
+			syncRows.Close()
+			return nil, fmt.Errorf("failed to scan syncs by day: %w", err)
+		}
+		days[k] = s
+	}
+	syncRows.Close()
+	if err := syncRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating syncs by day: %w", err)
+	}
+
+	dataTypeOf := make(map[string]string)
+	var eventTypes []string
+	for _, dataType := range dataTypes {
+		if eventType := garmin.EventType(dataType); eventType != "" {
+			dataTypeOf[eventType] = dataType
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(eventTypes) == 0 {
+		return findGaps(dataTypes, start, end, days), nil
+	}
+
+	eventRows, err := r.db.Pool.Query(ctx, `
+		SELECT DISTINCT event_type, TO_CHAR(time AT TIME ZONE 'UTC', 'YYYY-MM-DD')
+		FROM events
+		WHERE user_id = $1
+			AND event_type = ANY($2)
+			AND time >= $3
+			AND time < $4
+	`, userID, eventTypes, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query events by day: %w", err)
+	}
+	defer eventRows.Close()
+
+	for eventRows.Next() {
+		var eventType, date string
+		if err := eventRows.Scan(&eventType, &date); err != nil {
+			return nil, fmt.Errorf("failed to scan events by day: %w", err)
+		}
+		k := dayKey{dataTypeOf[eventType], date}
+		s := days[k]
+		s.hasData = true
+		days[k] = s
+	}
+	if err := eventRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating events by day: %w", err)
+	}
+
+	return findGaps(dataTypes, start, end, days), nil
+}
@@ -0,0 +1,54 @@
+package audit
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// StaleSweeper fails syncs left running for too long, which happens when
+// the scheduler dies between starting and finishing one.
+type StaleSweeper struct {
+	repo     Store
+	observer SyncObserver
+	after    time.Duration
+	interval time.Duration
+}
+
+// NewStaleSweeper creates a StaleSweeper that every interval fails syncs
+// running for longer than after. observer, if not nil, sees each one.
+func NewStaleSweeper(repo Store, observer SyncObserver, after, interval time.Duration) *StaleSweeper {
+	return &StaleSweeper{repo: repo, observer: observer, after: after, interval: interval}
+}
+
+// Run sweeps until ctx is done.
+func (s *StaleSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.sweep(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *StaleSweeper) sweep(ctx context.Context) {
+	stale, err := s.repo.FailStaleSyncs(ctx, time.Now().Add(-s.after))
+	if err != nil {
+		log.Printf("Failed to sweep stale syncs: %v", err)
+		return
+	}
+
+	for i := range stale {
+		a := &stale[i]
+		log.Printf("Sync %s (user=%s, type=%s, date=%s) never completed; marked failed",
+			a.ID, a.UserID, a.DataType, a.TargetDate)
+		if s.observer != nil {
+			s.observer.ObserveSync(ctx, a)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+)
+
+// Sign in with Apple
+const (
+	ProviderApple = "apple"
+	// AppleJWKSURL is where Apple publishes its ID token signing keys.
+	AppleJWKSURL = "https://appleid.apple.com/auth/keys"
+	appleIssuer  = "https://appleid.apple.com"
+	// applePrivateRelayDomain hosts "Hide My Email" addresses.
+	applePrivateRelayDomain = "@privaterelay.appleid.com"
+)
+
+// NewAppleVerifier creates a verifier for Apple ID tokens issued to one of
+// clientIDs (the app's bundle ID, and the Services ID used on the web) and
+// signed by a key from keys (normally NewJWKSCache(AppleJWKSURL)).
+func NewAppleVerifier(clientIDs []string, keys KeySource) (*OIDCVerifier, error) {
+	if len(clientIDs) == 0 {
+		return nil, errors.New("APPLE_CLIENT_IDS must be set to enable Sign in with Apple")
+	}
+	return NewOIDCVerifier(ProviderApple, []string{appleIssuer}, clientIDs, keys), nil
+}
+
+// isPrivateRelay reports whether email is an Apple private relay address.
+// Apple flags these with is_private_email, but not in every token.
+func isPrivateRelay(email string) bool {
+	return strings.HasSuffix(strings.ToLower(email), applePrivateRelayDomain)
+}
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+)
+
+// How long emailed tokens stay valid
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// verificationEmail is sent after registration. Its link opens
+// GET /api/v1/auth/verify-email in the browser.
+func verificationEmail(to, publicURL, token string) mailer.Message {
+	link := strings.TrimSuffix(publicURL, "/") + "/api/v1/auth/verify-email?token=" + url.QueryEscape(token)
+	return mailer.Message{
+		To:      to,
+		Subject: "Verify your Health Assistant email",
+		Body: fmt.Sprintf(`Confirm this is your email address by opening the link below:
+
+%s
+
+The link expires in %d hours. If you didn't create a Health Assistant account, ignore this email.
+`, link, int(verifyEmailTTL.Hours())),
+	}
+}
+
+// resetPasswordEmail carries a reset code to enter in the app.
+func resetPasswordEmail(to, token string) mailer.Message {
+	return mailer.Message{
+		To:      to,
+		Subject: "Reset your Health Assistant password",
+		Body: fmt.Sprintf(`Someone asked to reset the password for this email. To choose a new password, enter this code in the app:
+
+%s
+
+The code expires in %d minutes and works once. If you didn't ask for a reset, ignore this email; your password is unchanged.
+`, token, int(resetPasswordTTL.Minutes())),
+	}
+}
@@ -1,70 +1,32 @@
 package auth
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"net/http"
-	"net/url"
+	"errors"
 )
 
-// GoogleClaims holds claims returned from the Google tokeninfo endpoint.
-type GoogleClaims struct {
-	Sub           string `json:"sub"`
-	Email         string `json:"email"`
-	Name          string `json:"name"`
-	Picture       string `json:"picture"`
-	Audience      string `json:"aud"`
-	EmailVerified string `json:"email_verified"`
-}
-
-// GoogleVerifier verifies Google ID tokens via the tokeninfo endpoint.
-type GoogleVerifier struct {
-	clientID   string
-	httpClient *http.Client
-}
-
-// NewGoogleVerifier creates a GoogleVerifier.
-// If clientID is empty, audience validation is skipped (dev mode).
-func NewGoogleVerifier(clientID string) *GoogleVerifier {
-	return &GoogleVerifier{
-		clientID:   clientID,
-		httpClient: &http.Client{},
-	}
-}
-
-// VerifyIDToken calls the Google tokeninfo endpoint and validates the token.
-func (v *GoogleVerifier) VerifyIDToken(ctx context.Context, idToken string) (*GoogleClaims, error) {
-	tokenInfoURL := "https://oauth2.googleapis.com/tokeninfo?id_token=" + url.QueryEscape(idToken)
-
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenInfoURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating tokeninfo request: %w", err)
-	}
-
-	resp, err := v.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("calling tokeninfo endpoint: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("tokeninfo returned status %d", resp.StatusCode)
-	}
-
-	var claims GoogleClaims
-	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
-		return nil, fmt.Errorf("decoding tokeninfo response: %w", err)
-	}
+// Google Sign-In
+const (
+	ProviderGoogle = "google"
+	// GoogleJWKSURL is where Google publishes its ID token signing keys.
+	GoogleJWKSURL = "https://www.googleapis.com/oauth2/v3/certs"
+)
 
-	// Validate audience against our client ID (skip in dev mode when clientID is empty)
-	if v.clientID != "" && claims.Audience != v.clientID {
-		return nil, fmt.Errorf("token audience %q does not match expected client ID", claims.Audience)
+// googleIssuers are the two issuer forms Google ID tokens use.
+var googleIssuers = []string{"accounts.google.com", "https://accounts.google.com"}
+
+// NewGoogleVerifier creates a verifier for Google ID tokens issued for
+// clientID and signed by a key from keys (normally
+// NewJWKSCache(GoogleJWKSURL)). An empty clientID is an error unless
+// allowAnyAudience is set, which accepts tokens issued to any Google client
+// and is only for local development.
+func NewGoogleVerifier(clientID string, keys KeySource, allowAnyAudience bool) (*OIDCVerifier, error) {
+	if clientID == "" && !allowAnyAudience {
+		return nil, errors.New("GOOGLE_CLIENT_ID must be set (or GOOGLE_ALLOW_ANY_AUDIENCE=true for local development)")
 	}
 
-	if claims.EmailVerified != "true" {
-		return nil, fmt.Errorf("google account email is not verified")
+	var audiences []string
+	if clientID != "" {
+		audiences = []string{clientID}
 	}
-
-	return &claims, nil
+	return NewOIDCVerifier(ProviderGoogle, googleIssuers, audiences, keys), nil
 }
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "test-client.apps.googleusercontent.com"
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, method jwt.SigningMethod, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	var signingKey interface{} = key
+	if _, ok := method.(*jwt.SigningMethodHMAC); ok {
+		signingKey = []byte("not-an-rsa-key")
+	}
+	signed, err := token.SignedString(signingKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestGoogleVerifyIDToken(t *testing.T) {
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	key := newTestKey(t)
+	otherKey := newTestKey(t)
+	keys := StaticKeys{"k1": &key.PublicKey}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            "https://accounts.google.com",
+			"aud":            testClientID,
+			"sub":            "1234567890",
+			"email":          "runner@example.com",
+			"email_verified": true,
+			"name":           "Runner",
+			"iat":            now.Add(-time.Minute).Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name             string
+		modify           func(jwt.MapClaims)
+		signingKey       *rsa.PrivateKey
+		kid              string
+		method           jwt.SigningMethod
+		clientID         string
+		allowAnyAudience bool
+		wantErr          string
+	}{
+		{name: "valid"},
+		{name: "short issuer form", modify: func(c jwt.MapClaims) { c["iss"] = "accounts.google.com" }},
+		{name: "email_verified as string", modify: func(c jwt.MapClaims) { c["email_verified"] = "true" }},
+		{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" }, wantErr: "issuer"},
+		{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "other-client" }, wantErr: "aud"},
+		{name: "expired", modify: func(c jwt.MapClaims) { c["exp"] = now.Add(-2 * time.Minute).Unix() }, wantErr: "expired"},
+		{name: "no expiry", modify: func(c jwt.MapClaims) { delete(c, "exp") }, wantErr: "exp"},
+		{name: "unverified email", modify: func(c jwt.MapClaims) { c["email_verified"] = false }, wantErr: "not verified"},
+		{name: "signed by another key", signingKey: otherKey, wantErr: "verification error"},
+		{name: "unknown kid", kid: "k2", wantErr: "unknown signing key"},
+		{name: "HS256 rejected", method: jwt.SigningMethodHS256, wantErr: "signing method"},
+		{
+			name:             "any audience when opted in",
+			modify:           func(c jwt.MapClaims) { c["aud"] = "other-client" },
+			clientID:         "-",
+			allowAnyAudience: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			if tt.modify != nil {
+				tt.modify(claims)
+			}
+			signingKey, kid, method := key, "k1", jwt.SigningMethod(jwt.SigningMethodRS256)
+			if tt.signingKey != nil {
+				signingKey = tt.signingKey
+			}
+			if tt.kid != "" {
+				kid = tt.kid
+			}
+			if tt.method != nil {
+				method = tt.method
+			}
+			clientID := testClientID
+			if tt.clientID == "-" {
+				clientID = ""
+			}
+
+			v, err := NewGoogleVerifier(clientID, keys, tt.allowAnyAudience)
+			if err != nil {
+				t.Fatalf("NewGoogleVerifier() error = %v", err)
+			}
+			v.now = func() time.Time { return now }
+
+			got, err := v.VerifyIDToken(context.Background(), signTestToken(t, signingKey, kid, method, claims))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyIDToken() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if got.Subject != "1234567890" || got.Email != "runner@example.com" || got.Name != "Runner" {
+				t.Errorf("VerifyIDToken() = %+v", got)
+			}
+		})
+	}
+}
+
+func TestNewGoogleVerifierRequiresClientID(t *testing.T) {
+	if _, err := NewGoogleVerifier("", StaticKeys{}, false); err == nil {
+		t.Error("NewGoogleVerifier() with no client ID expected error, got nil")
+	}
+}
@@ -1,50 +1,128 @@
 package auth
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/mailer"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
 )
 
+// maxDeviceNameLength matches the sessions.device_name column.
+const maxDeviceNameLength = 100
+
 // Handler handles authentication endpoints.
 type Handler struct {
-	googleVerifier *GoogleVerifier
-	userRepo       *UserRepository
-	tokenService   *TokenService
+	verifiers    map[string]IDTokenVerifier
+	userRepo     UserStore
+	sessionRepo  SessionStore
+	tokenService *TokenService
+	refreshTTL   time.Duration
+	mailer       mailer.Mailer
+	publicURL    string
+	tokenRepos   []TokenRevoker
+}
+
+// TokenRevoker revokes all of a user's long-lived tokens. The access token
+// and ingest token stores implement it.
+type TokenRevoker interface {
+	RevokeAll(ctx context.Context, userID string) error
 }
 
-// NewHandler creates a new auth Handler.
+// NewHandler creates a new auth Handler. verifiers holds the configured
+// sign-in providers by name (ProviderGoogle, ProviderApple, ...). Sessions
+// stay signed in until refreshTTL passes without a refresh. Verification and
+// password reset emails go through mail, with links to publicURL. A password
+// reset or an account claim also revokes the user's tokens in tokenRepos.
 func NewHandler(
-	googleVerifier *GoogleVerifier,
-	userRepo *UserRepository,
+	verifiers map[string]IDTokenVerifier,
+	userRepo UserStore,
+	sessionRepo SessionStore,
 	tokenService *TokenService,
+	refreshTTL time.Duration,
+	mail mailer.Mailer,
+	publicURL string,
+	tokenRepos ...TokenRevoker,
 ) *Handler {
 	return &Handler{
-		googleVerifier: googleVerifier,
-		userRepo:       userRepo,
-		tokenService:   tokenService,
+		verifiers:    verifiers,
+		userRepo:     userRepo,
+		sessionRepo:  sessionRepo,
+		tokenService: tokenService,
+		refreshTTL:   refreshTTL,
+		mailer:       mail,
+		publicURL:    publicURL,
+		tokenRepos:   tokenRepos,
 	}
 }
 
-type googleAuthRequest struct {
-	IDToken string `json:"id_token"`
+type signInRequest struct {
+	IDToken    string `json:"id_token"`
+	DeviceName string `json:"device_name"`
+	// DisplayName is for providers that only tell the client the user's
+	// name (Apple, on first sign-in), not the ID token.
+	DisplayName string `json:"display_name"`
 }
 
-type googleAuthResponse struct {
-	Token       string `json:"token"`
-	UserID      string `json:"user_id"`
-	Email       string `json:"email"`
-	DisplayName string `json:"display_name"`
+type signInResponse struct {
+	Token         string `json:"token"`
+	RefreshToken  string `json:"refresh_token"`
+	ExpiresIn     int    `json:"expires_in"` // access token lifetime in seconds
+	UserID        string `json:"user_id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	DisplayName   string `json:"display_name"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type refreshResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// reservedPaths are the fixed endpoints under /api/v1/auth/ that take
+// precedence over /api/v1/auth/{provider}.
+var reservedPaths = map[string]bool{
+	"refresh":      true,
+	"logout":       true,
+	"register":     true,
+	"login":        true,
+	"verify-email": true,
+	"password":     true,
+}
+
+// IsReservedPath reports whether a sign-in provider named name would be
+// shadowed by another auth endpoint.
+func IsReservedPath(name string) bool {
+	return reservedPaths[name]
 }
 
-// HandleGoogleAuth handles POST /api/v1/auth/google
-func (h *Handler) HandleGoogleAuth(w http.ResponseWriter, r *http.Request) {
+// HandleSignIn handles POST /api/v1/auth/{provider}
+// e.g. /api/v1/auth/google, /api/v1/auth/apple
+func (h *Handler) HandleSignIn(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req googleAuthRequest
+	provider := r.PathValue("provider")
+	verifier, ok := h.verifiers[provider]
+	if !ok {
+		http.Error(w, `{"error":"unknown sign-in provider"}`, http.StatusNotFound)
+		return
+	}
+
+	var req signInRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
 		return
@@ -55,20 +133,67 @@ func (h *Handler) HandleGoogleAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	claims, err := h.googleVerifier.VerifyIDToken(r.Context(), req.IDToken)
+	identity, err := verifier.VerifyIDToken(r.Context(), req.IDToken)
 	if err != nil {
-		log.Printf("Google token verification failed: %v", err)
-		http.Error(w, `{"error":"invalid Google token"}`, http.StatusUnauthorized)
+		log.Printf("%s token verification failed: %v", provider, err)
+		http.Error(w, `{"error":"invalid ID token"}`, http.StatusUnauthorized)
 		return
 	}
 
-	user, err := h.userRepo.FindOrCreateUserByGoogleID(r.Context(), claims.Sub, claims.Email, claims.Name)
+	user, claimed, err := h.userRepo.FindOrCreateUserByIdentity(r.Context(), identity, strings.TrimSpace(req.DisplayName))
+	if errors.Is(err, ErrEmailInUse) {
+		http.Error(w, `{"error":"an account with this email already exists; sign in to it and link this provider"}`, http.StatusConflict)
+		return
+	}
 	if err != nil {
 		log.Printf("Failed to find or create user: %v", err)
 		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
 		return
 	}
 
+	// Whoever registered the claimed account may still hold its sessions
+	// and tokens
+	if claimed {
+		if err := h.signOutEverywhere(r.Context(), user.ID, RevokeClaim); err != nil {
+			log.Printf("Failed to sign out claimed user %s: %v", user.ID, err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		log.Printf("%s sign-in claimed unverified account %s; signed out its sessions and tokens", provider, user.ID)
+	}
+
+	h.signIn(w, r, user, req.DeviceName)
+
+	log.Printf("%s auth successful for user %s (%s)", provider, user.ID, user.Email)
+}
+
+// signOutEverywhere revokes all of userID's sessions and long-lived tokens.
+func (h *Handler) signOutEverywhere(ctx context.Context, userID, reason string) error {
+	if err := h.sessionRepo.RevokeAllSessions(ctx, userID, reason); err != nil {
+		return err
+	}
+	for _, repo := range h.tokenRepos {
+		if err := repo.RevokeAll(ctx, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// signIn starts a session for user and writes its tokens.
+func (h *Handler) signIn(w http.ResponseWriter, r *http.Request, user *models.User, deviceName string) {
+	deviceName = strings.TrimSpace(deviceName)
+	if len(deviceName) > maxDeviceNameLength {
+		deviceName = deviceName[:maxDeviceNameLength]
+	}
+
+	session, refreshToken, err := h.sessionRepo.CreateSession(r.Context(), user.ID, deviceName, r.UserAgent(), h.refreshTTL)
+	if err != nil {
+		log.Printf("Failed to create session: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
 	token, err := h.tokenService.GenerateToken(user.ID)
 	if err != nil {
 		log.Printf("Failed to generate token: %v", err)
@@ -78,12 +203,278 @@ func (h *Handler) HandleGoogleAuth(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(googleAuthResponse{
-		Token:       token,
-		UserID:      user.ID,
-		Email:       user.Email,
-		DisplayName: user.DisplayName,
+	json.NewEncoder(w).Encode(signInResponse{
+		Token:         token,
+		RefreshToken:  refreshToken,
+		ExpiresIn:     int(h.tokenService.TokenDuration().Seconds()),
+		UserID:        user.ID,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		DisplayName:   user.DisplayName,
+	})
+
+	log.Printf("Started session %s for user %s", session.ID, user.ID)
+}
+
+// HandleRefresh handles POST /api/v1/auth/refresh
+// The refresh token is single use: the response carries its replacement.
+func (h *Handler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, `{"error":"refresh_token is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	session, refreshToken, err := h.sessionRepo.RotateRefreshToken(r.Context(), req.RefreshToken, h.refreshTTL)
+	if errors.Is(err, ErrRefreshTokenReused) {
+		log.Printf("Refresh token reuse detected; session revoked")
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	if errors.Is(err, ErrInvalidRefreshToken) {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to refresh session: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	token, err := h.tokenService.GenerateToken(session.UserID)
+	if err != nil {
+		log.Printf("Failed to generate token: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(refreshResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(h.tokenService.TokenDuration().Seconds()),
+	})
+}
+
+// HandleLogout handles POST /api/v1/auth/logout
+// It ends the session of the given refresh token. Logging out twice is not
+// an error.
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, `{"error":"refresh_token is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	err := h.sessionRepo.RevokeByRefreshToken(r.Context(), req.RefreshToken)
+	if err != nil && !errors.Is(err, ErrInvalidRefreshToken) {
+		log.Printf("Failed to log out: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+	})
+}
+
+// HandleListDevices handles GET /api/v1/settings/devices
+func (h *Handler) HandleListDevices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.sessionRepo.ListActiveSessions(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to list sessions: %v", err)
+		http.Error(w, "Failed to retrieve devices", http.StatusInternalServerError)
+		return
+	}
+	if sessions == nil {
+		sessions = []Session{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"count":   len(sessions),
+		"devices": sessions,
+	})
+}
+
+// HandleRevokeDevice handles DELETE /api/v1/settings/devices/{id}
+// The device is signed out once its current access token expires.
+func (h *Handler) HandleRevokeDevice(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	id := r.PathValue("id")
+	revoked, err := h.sessionRepo.RevokeSession(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("Failed to revoke session: %v", err)
+		http.Error(w, "Failed to revoke device", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Device not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"id":     id,
 	})
+}
+
+type linkIdentityRequest struct {
+	Provider string `json:"provider"`
+	IDToken  string `json:"id_token"`
+}
+
+// HandleIdentities handles GET and POST /api/v1/settings/identities
+// GET lists the providers the user can sign in with; POST links another
+// one, proven by an ID token from it.
+func (h *Handler) HandleIdentities(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
 
-	log.Printf("Google auth successful for user %s (%s)", user.ID, user.Email)
+	switch r.Method {
+	case http.MethodGet:
+		h.listIdentities(w, r, userID)
+	case http.MethodPost:
+		h.linkIdentity(w, r, userID)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) listIdentities(w http.ResponseWriter, r *http.Request, userID string) {
+	identities, err := h.userRepo.ListIdentities(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to list identities: %v", err)
+		http.Error(w, "Failed to retrieve identities", http.StatusInternalServerError)
+		return
+	}
+	if identities == nil {
+		identities = []UserIdentity{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"count":      len(identities),
+		"identities": identities,
+	})
+}
+
+func (h *Handler) linkIdentity(w http.ResponseWriter, r *http.Request, userID string) {
+	var req linkIdentityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	verifier, ok := h.verifiers[req.Provider]
+	if !ok {
+		http.Error(w, "Unknown sign-in provider", http.StatusBadRequest)
+		return
+	}
+	if req.IDToken == "" {
+		http.Error(w, "id_token is required", http.StatusBadRequest)
+		return
+	}
+
+	identity, err := verifier.VerifyIDToken(r.Context(), req.IDToken)
+	if err != nil {
+		log.Printf("%s token verification failed: %v", req.Provider, err)
+		http.Error(w, "Invalid ID token", http.StatusUnauthorized)
+		return
+	}
+
+	err = h.userRepo.LinkIdentity(r.Context(), userID, identity)
+	if errors.Is(err, ErrIdentityInUse) {
+		http.Error(w, "This account is already linked to another user", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to link identity: %v", err)
+		http.Error(w, "Failed to link identity", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":   "success",
+		"provider": identity.Provider,
+		"email":    identity.Email,
+	})
+}
+
+// HandleUnlinkIdentity handles DELETE /api/v1/settings/identities/{id}
+func (h *Handler) HandleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	id := r.PathValue("id")
+	unlinked, err := h.userRepo.UnlinkIdentity(r.Context(), userID, id)
+	if errors.Is(err, ErrLastIdentity) {
+		http.Error(w, "Cannot unlink your only sign-in method", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to unlink identity: %v", err)
+		http.Error(w, "Failed to unlink identity", http.StatusInternalServerError)
+		return
+	}
+	if !unlinked {
+		http.Error(w, "Identity not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"id":     id,
+	})
 }
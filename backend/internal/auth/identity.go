@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+	"time"
+)
+
+// Identity link errors
+var (
+	ErrIdentityInUse = errors.New("identity is linked to another user")
+	ErrLastIdentity  = errors.New("cannot unlink the only way to sign in")
+	ErrEmailInUse    = errors.New("email belongs to another user")
+)
+
+// UserIdentity is a provider account linked to a user. Any linked identity
+// signs in as that user.
+type UserIdentity struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"-"`
+	Provider     string    `json:"provider"`
+	Subject      string    `json:"-"`
+	Email        string    `json:"email"`
+	PrivateEmail bool      `json:"private_email"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastLoginAt  time.Time `json:"last_login_at"`
+}
+
+// linksByEmail reports whether a first sign-in with id should attach to an
+// existing user with the same email rather than create a new one. Private
+// relay addresses are unique to one app and never match a real inbox.
+func linksByEmail(id *Identity) bool {
+	return id.Email != "" && id.EmailVerified && !id.PrivateEmail
+}
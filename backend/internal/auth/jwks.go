@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KeySource resolves the public key an ID token was signed with by its
+// "kid" header.
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// ErrUnknownKey is returned for a kid the key source does not have.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// StaticKeys is a fixed KeySource, for tests and pinned keys.
+type StaticKeys map[string]*rsa.PublicKey
+
+// Key implements KeySource.
+func (s StaticKeys) Key(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+const (
+	// defaultJWKSMaxAge applies when the JWKS response has no max-age.
+	defaultJWKSMaxAge = time.Hour
+	// minJWKSRefetch limits refetches triggered by unknown kids, so tokens
+	// with made-up kids cannot hammer the provider.
+	minJWKSRefetch = time.Minute
+)
+
+// JWKSCache is a KeySource backed by a provider's JSON Web Key Set. Keys are
+// cached for the response's Cache-Control max-age. An unknown kid triggers a
+// refetch, which picks up rotated keys before the cache expires.
+type JWKSCache struct {
+	url        string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	expires   time.Time
+	fetchedAt time.Time
+}
+
+// NewJWKSCache creates a JWKSCache for the key set at url.
+func NewJWKSCache(url string) *JWKSCache {
+	return &JWKSCache{
+		url:        url,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Key implements KeySource.
+func (c *JWKSCache) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if key, ok := c.keys[kid]; ok && now.Before(c.expires) {
+		return key, nil
+	}
+
+	stale := c.keys != nil && now.Before(c.expires)
+	if stale && now.Sub(c.fetchedAt) < minJWKSRefetch {
+		return nil, ErrUnknownKey
+	}
+
+	if err := c.fetch(ctx, now); err != nil {
+		return nil, err
+	}
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// jwk is the subset of a JSON Web Key used for RSA signature keys.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+func (c *JWKSCache) fetch(ctx context.Context, now time.Time) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("creating JWKS request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("JWKS endpoint returned status %d", resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := parseRSAKey(k)
+		if err != nil {
+			return fmt.Errorf("parsing JWKS key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	c.keys = keys
+	c.fetchedAt = now
+	c.expires = now.Add(maxAge(resp.Header.Get("Cache-Control")))
+	return nil
+}
+
+func parseRSAKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("exponent out of range")
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// maxAge reads max-age from a Cache-Control header.
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return defaultJWKSMaxAge
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// jwksServer serves whichever keys it currently holds and counts fetches.
+type jwksServer struct {
+	mu      sync.Mutex
+	keys    map[string]*rsa.PublicKey
+	fetches int
+}
+
+func (s *jwksServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, key := range s.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		})
+	}
+	w.Header().Set("Cache-Control", "public, max-age=3600, must-revalidate")
+	json.NewEncoder(w).Encode(set)
+}
+
+func TestJWKSCache(t *testing.T) {
+	k1 := newTestKey(t)
+	k2 := newTestKey(t)
+	server := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": &k1.PublicKey}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	cache := NewJWKSCache(ts.URL)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	key, err := cache.Key(ctx, "k1")
+	if err != nil {
+		t.Fatalf("Key(k1) error = %v", err)
+	}
+	if key.N.Cmp(k1.PublicKey.N) != 0 {
+		t.Error("Key(k1) returned the wrong key")
+	}
+
+	// Cached: no refetch
+	if _, err := cache.Key(ctx, "k1"); err != nil || server.fetches != 1 {
+		t.Fatalf("cached Key(k1) error = %v, fetches = %d, want 1", err, server.fetches)
+	}
+
+	// Google rotates to k2. Within a minute of the last fetch an unknown kid
+	// does not refetch.
+	server.mu.Lock()
+	server.keys["k2"] = &k2.PublicKey
+	server.mu.Unlock()
+	if _, err := cache.Key(ctx, "k2"); !errors.Is(err, ErrUnknownKey) || server.fetches != 1 {
+		t.Fatalf("Key(k2) right after fetch error = %v, fetches = %d", err, server.fetches)
+	}
+
+	// After that, the unknown kid triggers a refetch that finds it
+	now = now.Add(2 * time.Minute)
+	if _, err := cache.Key(ctx, "k2"); err != nil || server.fetches != 2 {
+		t.Fatalf("Key(k2) after rotation error = %v, fetches = %d, want 2", err, server.fetches)
+	}
+
+	// The max-age expiry forces a refetch even for known kids
+	now = now.Add(2 * time.Hour)
+	if _, err := cache.Key(ctx, "k1"); err != nil || server.fetches != 3 {
+		t.Fatalf("Key(k1) after expiry error = %v, fetches = %d, want 3", err, server.fetches)
+	}
+}
+
+func TestMaxAge(t *testing.T) {
+	tests := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"public, max-age=19845, must-revalidate, no-transform", 19845 * time.Second},
+		{"max-age=60", time.Minute},
+		{"no-cache", defaultJWKSMaxAge},
+		{"", defaultJWKSMaxAge},
+		{"max-age=abc", defaultJWKSMaxAge},
+	}
+
+	for _, tt := range tests {
+		if got := maxAge(tt.header); got != tt.want {
+			t.Errorf("maxAge(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -14,9 +14,9 @@ type TokenService struct {
 	tokenDuration time.Duration
 }
 
-// NewTokenService creates a new TokenService.
-// Returns an error if secret is empty or the default placeholder.
-func NewTokenService(secret string, hours int) (*TokenService, error) {
+// NewTokenService creates a new TokenService issuing access tokens valid for
+// tokenDuration. Returns an error if secret is empty or the default placeholder.
+func NewTokenService(secret string, tokenDuration time.Duration) (*TokenService, error) {
 	if secret == "" || secret == "change-me-in-production" {
 		return nil, errors.New("JWT_SECRET must be set to a non-default value")
 	}
@@ -25,10 +25,15 @@ func NewTokenService(secret string, hours int) (*TokenService, error) {
 	}
 	return &TokenService{
 		secret:        []byte(secret),
-		tokenDuration: time.Duration(hours) * time.Hour,
+		tokenDuration: tokenDuration,
 	}, nil
 }
 
+// TokenDuration returns how long issued access tokens are valid.
+func (s *TokenService) TokenDuration() time.Duration {
+	return s.tokenDuration
+}
+
 type jwtClaims struct {
 	jwt.RegisteredClaims
 }
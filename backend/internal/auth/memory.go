@@ -0,0 +1,526 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// MemoryUserStore keeps users, identities and email tokens in memory with
+// the same semantics as UserRepository. It is for tests.
+type MemoryUserStore struct {
+	mu         sync.Mutex
+	users      []*models.User // with password hashes
+	identities []*UserIdentity
+	tokens     []*emailToken
+}
+
+type emailToken struct {
+	userID    string
+	purpose   string
+	hash      string
+	expiresAt time.Time
+	used      bool
+}
+
+// NewMemoryUserStore creates an empty MemoryUserStore.
+func NewMemoryUserStore() *MemoryUserStore {
+	return &MemoryUserStore{}
+}
+
+// public returns a copy of u without its password hash, as UserRepository
+// returns users.
+func public(u *models.User) *models.User {
+	user := *u
+	user.PasswordHash = ""
+	return &user
+}
+
+func (s *MemoryUserStore) userByID(id string) *models.User {
+	for _, u := range s.users {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *MemoryUserStore) userByEmail(email string) *models.User {
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+func (s *MemoryUserStore) identity(provider, subject string) *UserIdentity {
+	for _, i := range s.identities {
+		if i.Provider == provider && i.Subject == subject {
+			return i
+		}
+	}
+	return nil
+}
+
+// addUser creates a user, enforcing the unique email the users table does.
+func (s *MemoryUserStore) addUser(email, passwordHash, displayName string, verifiedAt *time.Time) (*models.User, error) {
+	for _, u := range s.users {
+		if u.Email == email {
+			return nil, ErrEmailInUse
+		}
+	}
+	user := &models.User{
+		ID:              db.NewUUID(),
+		Email:           email,
+		PasswordHash:    passwordHash,
+		DisplayName:     displayName,
+		EmailVerifiedAt: verifiedAt,
+		Role:            models.RoleUser,
+		CreatedAt:       time.Now(),
+	}
+	s.users = append(s.users, user)
+	return user, nil
+}
+
+func (s *MemoryUserStore) addIdentity(userID string, id *Identity) error {
+	if s.identity(id.Provider, id.Subject) != nil {
+		return ErrIdentityInUse
+	}
+	now := time.Now()
+	s.identities = append(s.identities, &UserIdentity{
+		ID:           db.NewUUID(),
+		UserID:       userID,
+		Provider:     id.Provider,
+		Subject:      id.Subject,
+		Email:        id.Email,
+		PrivateEmail: id.PrivateEmail,
+		CreatedAt:    now,
+		LastLoginAt:  now,
+	})
+	return nil
+}
+
+// FindOrCreateUserByIdentity implements UserStore.
+func (s *MemoryUserStore) FindOrCreateUserByIdentity(ctx context.Context, id *Identity, displayName string) (*models.User, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing := s.identity(id.Provider, id.Subject); existing != nil {
+		existing.Email = id.Email
+		existing.LastLoginAt = time.Now()
+		return public(s.userByID(existing.UserID)), false, nil
+	}
+
+	var user *models.User
+	if linksByEmail(id) {
+		user = s.userByEmail(id.Email)
+	}
+
+	claimed := user != nil && user.EmailVerifiedAt == nil
+	if claimed {
+		now := time.Now()
+		user.PasswordHash = ""
+		user.EmailVerifiedAt = &now
+	}
+
+	if user == nil {
+		if id.Name != "" {
+			displayName = id.Name
+		}
+		now := time.Now()
+		var err error
+		if user, err = s.addUser(id.Email, "", displayName, &now); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := s.addIdentity(user.ID, id); err != nil {
+		return nil, false, err
+	}
+	return public(user), claimed, nil
+}
+
+// LinkIdentity implements UserStore.
+func (s *MemoryUserStore) LinkIdentity(ctx context.Context, userID string, id *Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing := s.identity(id.Provider, id.Subject); existing != nil {
+		if existing.UserID != userID {
+			return ErrIdentityInUse
+		}
+		return nil
+	}
+	return s.addIdentity(userID, id)
+}
+
+// ListIdentities implements UserStore.
+func (s *MemoryUserStore) ListIdentities(ctx context.Context, userID string) ([]UserIdentity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var identities []UserIdentity
+	for _, i := range s.identities {
+		if i.UserID == userID {
+			identities = append(identities, *i)
+		}
+	}
+	sort.SliceStable(identities, func(a, b int) bool {
+		return identities[a].CreatedAt.Before(identities[b].CreatedAt)
+	})
+	return identities, nil
+}
+
+// UnlinkIdentity implements UserStore.
+func (s *MemoryUserStore) UnlinkIdentity(ctx context.Context, userID, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByID(userID)
+	if user == nil {
+		return false, fmt.Errorf("count identities: %w", pgx.ErrNoRows)
+	}
+
+	index, others := -1, 0
+	for n, i := range s.identities {
+		switch {
+		case i.UserID != userID:
+		case i.ID == id:
+			index = n
+		default:
+			others++
+		}
+	}
+	if index < 0 {
+		return false, nil
+	}
+	if others == 0 && user.PasswordHash == "" {
+		return false, ErrLastIdentity
+	}
+
+	s.identities = append(s.identities[:index], s.identities[index+1:]...)
+	return true, nil
+}
+
+// CreateUserWithPassword implements UserStore.
+func (s *MemoryUserStore) CreateUserWithPassword(ctx context.Context, email, passwordHash, displayName string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userByEmail(email) != nil {
+		return nil, ErrEmailInUse
+	}
+	user, err := s.addUser(email, passwordHash, displayName, nil)
+	if err != nil {
+		return nil, err
+	}
+	return public(user), nil
+}
+
+// FindUserByEmail implements UserStore.
+func (s *MemoryUserStore) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByEmail(email)
+	if user == nil {
+		return nil, nil
+	}
+	found := *user
+	return &found, nil
+}
+
+// CreateEmailToken implements UserStore.
+func (s *MemoryUserStore) CreateEmailToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.userByID(userID) == nil {
+		return "", fmt.Errorf("create email token: no user %s", userID)
+	}
+	for _, t := range s.tokens {
+		if t.userID == userID && t.purpose == purpose {
+			t.used = true
+		}
+	}
+	s.tokens = append(s.tokens, &emailToken{
+		userID:    userID,
+		purpose:   purpose,
+		hash:      hashSecret(secret),
+		expiresAt: time.Now().Add(ttl),
+	})
+	return secret, nil
+}
+
+// useEmailToken marks an unused, unexpired token spent and returns its user.
+func (s *MemoryUserStore) useEmailToken(purpose, secret string) (*models.User, error) {
+	hash := hashSecret(secret)
+	for _, t := range s.tokens {
+		if t.hash == hash && t.purpose == purpose && !t.used && time.Now().Before(t.expiresAt) {
+			t.used = true
+			return s.userByID(t.userID), nil
+		}
+	}
+	return nil, ErrInvalidEmailToken
+}
+
+// VerifyEmail implements UserStore.
+func (s *MemoryUserStore) VerifyEmail(ctx context.Context, secret string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.useEmailToken(PurposeVerifyEmail, secret)
+	if err != nil {
+		return "", err
+	}
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return user.ID, nil
+}
+
+// ResetPassword implements UserStore.
+func (s *MemoryUserStore) ResetPassword(ctx context.Context, secret, passwordHash string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, err := s.useEmailToken(PurposeResetPassword, secret)
+	if err != nil {
+		return "", err
+	}
+	user.PasswordHash = passwordHash
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return user.ID, nil
+}
+
+// FindUserByID implements UserStore.
+func (s *MemoryUserStore) FindUserByID(ctx context.Context, id string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByID(id)
+	if user == nil {
+		return nil, fmt.Errorf("find user by ID: %w", pgx.ErrNoRows)
+	}
+	return public(user), nil
+}
+
+// UserRole implements UserStore and middleware.RoleLookup.
+func (s *MemoryUserStore) UserRole(ctx context.Context, userID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByID(userID)
+	if user == nil {
+		return "", fmt.Errorf("find user role: %w", pgx.ErrNoRows)
+	}
+	return user.Role, nil
+}
+
+// Users returns every user, oldest first, without password hashes. It
+// stands in for the cross-user queries MemoryUserStore has no methods for.
+func (s *MemoryUserStore) Users() []models.User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]models.User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, *public(u))
+	}
+	return users
+}
+
+// SetRole changes the role of the user with the given ID or email, like
+// UserRepository.SetRole.
+func (s *MemoryUserStore) SetRole(ctx context.Context, userIDOrEmail, role string) (*models.User, error) {
+	if !models.ValidRole(role) {
+		return nil, fmt.Errorf("invalid role %q", role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user := s.userByID(userIDOrEmail)
+	if user == nil {
+		user = s.userByEmail(userIDOrEmail)
+	}
+	if user == nil {
+		return nil, nil
+	}
+	user.Role = role
+	return public(user), nil
+}
+
+// MemorySessionStore keeps login sessions in memory with the same
+// semantics as SessionRepository. It is for tests.
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions []*memorySession
+}
+
+type memorySession struct {
+	Session
+	refreshTokenHash string
+	revokeReason     string
+}
+
+// NewMemorySessionStore creates an empty MemorySessionStore.
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{}
+}
+
+func (s *MemorySessionStore) session(id string) *memorySession {
+	for _, session := range s.sessions {
+		if session.ID == id {
+			return session
+		}
+	}
+	return nil
+}
+
+func (m *memorySession) revoke(reason string) {
+	now := time.Now()
+	m.RevokedAt = &now
+	m.revokeReason = reason
+}
+
+// CreateSession implements SessionStore.
+func (s *MemorySessionStore) CreateSession(ctx context.Context, userID, deviceName, userAgent string, ttl time.Duration) (*Session, string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	session := &memorySession{
+		Session: Session{
+			ID:         db.NewUUID(),
+			UserID:     userID,
+			DeviceName: deviceName,
+			UserAgent:  userAgent,
+			CreatedAt:  now,
+			LastUsedAt: now,
+			ExpiresAt:  now.Add(ttl),
+		},
+		refreshTokenHash: hashSecret(secret),
+	}
+	s.sessions = append(s.sessions, session)
+
+	created := session.Session
+	return &created, formatRefreshToken(session.ID, secret), nil
+}
+
+// RotateRefreshToken implements SessionStore.
+func (s *MemorySessionStore) RotateRefreshToken(ctx context.Context, refreshToken string, ttl time.Duration) (*Session, string, error) {
+	sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(sessionID)
+	if session == nil || session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if hashSecret(secret) != session.refreshTokenHash {
+		session.revoke(RevokeReuse)
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	nextSecret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	session.refreshTokenHash = hashSecret(nextSecret)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(ttl)
+
+	rotated := session.Session
+	return &rotated, formatRefreshToken(session.ID, nextSecret), nil
+}
+
+// RevokeByRefreshToken implements SessionStore.
+func (s *MemorySessionStore) RevokeByRefreshToken(ctx context.Context, refreshToken string) error {
+	sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(sessionID)
+	if session == nil || session.RevokedAt != nil || session.refreshTokenHash != hashSecret(secret) {
+		return ErrInvalidRefreshToken
+	}
+	session.revoke(RevokeLogout)
+	return nil
+}
+
+// ListActiveSessions implements SessionStore.
+func (s *MemorySessionStore) ListActiveSessions(ctx context.Context, userID string) ([]Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var sessions []Session
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.ExpiresAt.After(now) {
+			sessions = append(sessions, session.Session)
+		}
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession implements SessionStore.
+func (s *MemorySessionStore) RevokeSession(ctx context.Context, userID, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(id)
+	if session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return false, nil
+	}
+	session.revoke(RevokeUser)
+	return true, nil
+}
+
+// RevokeAllSessions implements SessionStore.
+func (s *MemorySessionStore) RevokeAllSessions(ctx context.Context, userID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			session.revoke(reason)
+		}
+	}
+	return nil
+}
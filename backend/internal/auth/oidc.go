@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Identity is who a verified ID token says the user is, at one provider.
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	// PrivateEmail is set for Apple private relay addresses, which forward
+	// to the user's real inbox but match no other account.
+	PrivateEmail bool
+	Name         string
+}
+
+// IDTokenVerifier verifies an ID token from one sign-in provider.
+type IDTokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*Identity, error)
+}
+
+// idTokenClaims is the JWT payload of an OpenID Connect ID token, with the
+// Apple-specific is_private_email.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Email          string   `json:"email"`
+	EmailVerified  flexBool `json:"email_verified"`
+	IsPrivateEmail flexBool `json:"is_private_email"`
+	Name           string   `json:"name"`
+}
+
+// OIDCVerifier verifies RS256 ID tokens locally: signature against the
+// provider's keys, then issuer, audience and expiry.
+type OIDCVerifier struct {
+	provider  string
+	issuers   map[string]bool
+	audiences []string // empty accepts any audience
+	keys      KeySource
+	now       func() time.Time
+}
+
+// NewOIDCVerifier creates an OIDCVerifier for tokens from one of issuers,
+// issued to one of audiences and signed by a key from keys. With no
+// audiences, tokens for any client are accepted.
+func NewOIDCVerifier(provider string, issuers, audiences []string, keys KeySource) *OIDCVerifier {
+	valid := make(map[string]bool, len(issuers))
+	for _, issuer := range issuers {
+		valid[issuer] = true
+	}
+	return &OIDCVerifier{
+		provider:  provider,
+		issuers:   valid,
+		audiences: audiences,
+		keys:      keys,
+		now:       time.Now,
+	}
+}
+
+// VerifyIDToken implements IDTokenVerifier.
+func (v *OIDCVerifier) VerifyIDToken(ctx context.Context, idToken string) (*Identity, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+		jwt.WithTimeFunc(v.now),
+	}
+	if len(v.audiences) > 0 {
+		options = append(options, jwt.WithAudience(v.audiences...))
+	}
+
+	var c idTokenClaims
+	_, err := jwt.ParseWithClaims(idToken, &c, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	}, options...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s ID token: %w", v.provider, err)
+	}
+
+	if !v.issuers[c.Issuer] {
+		return nil, fmt.Errorf("token issuer %q is not %s", c.Issuer, v.provider)
+	}
+
+	if c.Subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	// Accounts are keyed by email, so one the provider hasn't checked
+	// could claim someone else's.
+	if c.Email == "" {
+		return nil, errors.New("token has no email")
+	}
+	if !c.EmailVerified {
+		return nil, fmt.Errorf("email %s is not verified", c.Email)
+	}
+
+	return &Identity{
+		Provider:      v.provider,
+		Subject:       c.Subject,
+		Email:         c.Email,
+		EmailVerified: true,
+		PrivateEmail:  bool(c.IsPrivateEmail) || isPrivateRelay(c.Email),
+		Name:          c.Name,
+	}, nil
+}
+
+// flexBool decodes a JSON boolean that some providers send as a string.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", `"true"`:
+		*b = true
+	case "false", `"false"`, "null":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+// DiscoveredKeys is a KeySource for an OIDC issuer. On first use it reads
+// the issuer's discovery document to find its jwks_uri, then caches keys
+// like JWKSCache.
+type DiscoveredKeys struct {
+	issuer     string
+	httpClient *http.Client
+
+	mu   sync.Mutex
+	jwks *JWKSCache
+}
+
+// NewDiscoveredKeys creates a DiscoveredKeys for the issuer URL.
+func NewDiscoveredKeys(issuer string) *DiscoveredKeys {
+	return &DiscoveredKeys{
+		issuer:     strings.TrimSuffix(issuer, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Key implements KeySource.
+func (d *DiscoveredKeys) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	d.mu.Lock()
+	jwks := d.jwks
+	if jwks == nil {
+		uri, err := d.discover(ctx)
+		if err != nil {
+			d.mu.Unlock()
+			return nil, err
+		}
+		jwks = NewJWKSCache(uri)
+		d.jwks = jwks
+	}
+	d.mu.Unlock()
+
+	return jwks.Key(ctx, kid)
+}
+
+func (d *DiscoveredKeys) discover(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return "", fmt.Errorf("creating discovery request: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("OIDC discovery returned status %d", resp.StatusCode)
+	}
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return "", fmt.Errorf("decoding OIDC discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(doc.Issuer, "/") != d.issuer {
+		return "", fmt.Errorf("discovery document issuer %q does not match %q", doc.Issuer, d.issuer)
+	}
+	if doc.JWKSURI == "" {
+		return "", errors.New("discovery document has no jwks_uri")
+	}
+
+	return doc.JWKSURI, nil
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestAppleVerifyIDToken(t *testing.T) {
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	key := newTestKey(t)
+
+	v, err := NewAppleVerifier([]string{"com.example.health", "com.example.health.web"}, StaticKeys{"k1": &key.PublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.now = func() time.Time { return now }
+
+	claims := func(aud, email string, private interface{}) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":            "https://appleid.apple.com",
+			"aud":            aud,
+			"sub":            "001234.abcdef.0123",
+			"email":          email,
+			"email_verified": "true",
+			"iat":            now.Add(-time.Minute).Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+		}
+		if private != nil {
+			c["is_private_email"] = private
+		}
+		return c
+	}
+
+	tests := []struct {
+		name        string
+		claims      jwt.MapClaims
+		wantPrivate bool
+		wantErr     string
+	}{
+		{name: "real email", claims: claims("com.example.health", "runner@example.com", nil)},
+		{name: "second client ID", claims: claims("com.example.health.web", "runner@example.com", false)},
+		{name: "private relay flag", claims: claims("com.example.health", "x7k2@privaterelay.appleid.com", "true"), wantPrivate: true},
+		{name: "private relay without flag", claims: claims("com.example.health", "x7k2@privaterelay.appleid.com", nil), wantPrivate: true},
+		{name: "other app", claims: claims("com.other.app", "runner@example.com", nil), wantErr: "aud"},
+		{name: "no email", claims: claims("com.example.health", "", nil), wantErr: "no email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.VerifyIDToken(context.Background(), signTestToken(t, key, "k1", jwt.SigningMethodRS256, tt.claims))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("VerifyIDToken() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyIDToken() error = %v", err)
+			}
+			if got.Provider != ProviderApple || got.Subject != "001234.abcdef.0123" {
+				t.Errorf("VerifyIDToken() = %+v", got)
+			}
+			if got.PrivateEmail != tt.wantPrivate {
+				t.Errorf("PrivateEmail = %v, want %v", got.PrivateEmail, tt.wantPrivate)
+			}
+		})
+	}
+}
+
+func TestNewAppleVerifierRequiresClientIDs(t *testing.T) {
+	if _, err := NewAppleVerifier(nil, StaticKeys{}); err == nil {
+		t.Error("NewAppleVerifier() with no client IDs expected error, got nil")
+	}
+}
+
+func TestDiscoveredKeys(t *testing.T) {
+	key := newTestKey(t)
+	keys := &jwksServer{keys: map[string]*rsa.PublicKey{"k1": &key.PublicKey}}
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	issuer := ts.URL
+	mux.Handle("/jwks", keys)
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer,
+			"jwks_uri": ts.URL + "/jwks",
+		})
+	})
+
+	got, err := NewDiscoveredKeys(ts.URL+"/").Key(context.Background(), "k1")
+	if err != nil {
+		t.Fatalf("Key() error = %v", err)
+	}
+	if got.N.Cmp(key.PublicKey.N) != 0 {
+		t.Error("Key() returned the wrong key")
+	}
+
+	// The discovery document must name the issuer that was configured
+	issuer = "https://impostor.example.com"
+	if _, err := NewDiscoveredKeys(ts.URL).Key(context.Background(), "k1"); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Errorf("Key() with mismatched issuer error = %v", err)
+	}
+}
+
+func TestLinksByEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		id   Identity
+		want bool
+	}{
+		{"verified", Identity{Email: "runner@example.com", EmailVerified: true}, true},
+		{"unverified", Identity{Email: "runner@example.com"}, false},
+		{"private relay", Identity{Email: "x7k2@privaterelay.appleid.com", EmailVerified: true, PrivateEmail: true}, false},
+		{"no email", Identity{EmailVerified: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := linksByEmail(&tt.id); got != tt.want {
+				t.Errorf("linksByEmail() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password rules. bcrypt only reads the first 72 bytes of a password.
+const (
+	minPasswordLength = 10
+	maxPasswordBytes  = 72
+	bcryptCost        = 12
+)
+
+// Email and password errors
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidEmailToken  = errors.New("invalid or expired token")
+)
+
+// Email token purposes
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// dummyHash is compared against when an email has no account, so a login
+// takes as long whether or not the email is registered.
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcryptCost)
+	return hash
+})
+
+// HashPassword returns the bcrypt hash stored in users.password_hash.
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CheckPassword reports whether password matches hash. An empty hash (an
+// account without a password) never matches.
+func CheckPassword(hash, password string) bool {
+	if hash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash(), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// validatePassword checks a new password against the password rules.
+func validatePassword(password string) error {
+	if utf8.RuneCountInString(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordBytes)
+	}
+	return nil
+}
+
+// normalizeEmail validates a bare email address and lowercases it.
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("invalid email address %q", email)
+	}
+	return strings.ToLower(email), nil
+}
@@ -0,0 +1,305 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+type registerRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"display_name"`
+	DeviceName  string `json:"device_name"`
+}
+
+type loginRequest struct {
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name"`
+}
+
+type emailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// HandleRegister handles POST /api/v1/auth/register
+// It creates an email/password account, emails a verification link and
+// signs the new user in.
+func (h *Handler) HandleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	email, err := normalizeEmail(req.Email)
+	if err != nil {
+		http.Error(w, `{"error":"invalid email address"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	user, err := h.userRepo.CreateUserWithPassword(r.Context(), email, hash, strings.TrimSpace(req.DisplayName))
+	if errors.Is(err, ErrEmailInUse) {
+		http.Error(w, `{"error":"an account with this email already exists"}`, http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to create user: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.sendVerificationEmail(r, user.ID, user.Email)
+
+	h.signIn(w, r, user, req.DeviceName)
+
+	log.Printf("Registered user %s (%s)", user.ID, user.Email)
+}
+
+// HandleLogin handles POST /api/v1/auth/login
+func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.FindUserByEmail(r.Context(), strings.TrimSpace(req.Email))
+	if err != nil {
+		log.Printf("Failed to find user: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	hash := ""
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	if !CheckPassword(hash, req.Password) {
+		http.Error(w, `{"error":"invalid email or password"}`, http.StatusUnauthorized)
+		return
+	}
+
+	h.signIn(w, r, user, req.DeviceName)
+}
+
+// HandleVerifyEmail handles GET and POST /api/v1/auth/verify-email
+// GET is the link in the verification email, opened in a browser; POST
+// takes the token as JSON, for apps that intercept the link.
+func (h *Handler) HandleVerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var token string
+	switch r.Method {
+	case http.MethodGet:
+		token = r.URL.Query().Get("token")
+	case http.MethodPost:
+		var req emailTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+			return
+		}
+		token = req.Token
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := h.userRepo.VerifyEmail(r.Context(), token)
+	if errors.Is(err, ErrInvalidEmailToken) {
+		if r.Method == http.MethodGet {
+			http.Error(w, "This link is invalid or has expired. Request a new one from the app.", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, `{"error":"invalid or expired token"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to verify email: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Verified email for user %s", userID)
+
+	if r.Method == http.MethodGet {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("Your email is verified. You can return to the app.\n"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+	})
+}
+
+// HandleResendVerification handles POST /api/v1/auth/verify-email/resend
+// It emails the signed-in user a fresh verification link.
+func (h *Handler) HandleResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.userRepo.FindUserByID(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to find user: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		http.Error(w, `{"error":"email is already verified"}`, http.StatusConflict)
+		return
+	}
+
+	h.sendVerificationEmail(r, user.ID, user.Email)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+	})
+}
+
+// HandleForgotPassword handles POST /api/v1/auth/password/forgot
+// It emails a reset code if the address has an account. The response is
+// the same either way, so it can't be used to find registered emails.
+func (h *Handler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req forgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		http.Error(w, `{"error":"email is required"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.userRepo.FindUserByEmail(r.Context(), strings.TrimSpace(req.Email))
+	if err != nil {
+		log.Printf("Failed to find user: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if user != nil {
+		token, err := h.userRepo.CreateEmailToken(r.Context(), user.ID, PurposeResetPassword, resetPasswordTTL)
+		if err != nil {
+			log.Printf("Failed to create reset token: %v", err)
+			http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+			return
+		}
+		if err := h.mailer.Send(r.Context(), resetPasswordEmail(user.Email, token)); err != nil {
+			log.Printf("Failed to send reset email to user %s: %v", user.ID, err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+	})
+}
+
+// HandleResetPassword handles POST /api/v1/auth/password/reset
+// It sets a new password with an emailed reset code and signs the user out
+// of every device.
+func (h *Handler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req resetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		http.Error(w, `{"error":"token is required"}`, http.StatusBadRequest)
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		http.Error(w, fmt.Sprintf(`{"error":%q}`, err.Error()), http.StatusBadRequest)
+		return
+	}
+
+	hash, err := HashPassword(req.Password)
+	if err != nil {
+		log.Printf("Failed to hash password: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := h.userRepo.ResetPassword(r.Context(), strings.TrimSpace(req.Token), hash)
+	if errors.Is(err, ErrInvalidEmailToken) {
+		http.Error(w, `{"error":"invalid or expired token"}`, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to reset password: %v", err)
+		http.Error(w, `{"error":"internal server error"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if err := h.signOutEverywhere(r.Context(), userID, RevokeReset); err != nil {
+		log.Printf("Failed to sign out user %s after password reset: %v", userID, err)
+	}
+
+	log.Printf("Password reset for user %s", userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+	})
+}
+
+// sendVerificationEmail emails userID a verification link. Failures are
+// logged; the user can ask for another link.
+func (h *Handler) sendVerificationEmail(r *http.Request, userID, email string) {
+	token, err := h.userRepo.CreateEmailToken(r.Context(), userID, PurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		log.Printf("Failed to create verification token for user %s: %v", userID, err)
+		return
+	}
+	if err := h.mailer.Send(r.Context(), verificationEmail(email, h.publicURL, token)); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", userID, err)
+	}
+}
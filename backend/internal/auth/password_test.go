@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashAndCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse battery")
+	if err != nil {
+		t.Fatalf("HashPassword() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$2a$12$") {
+		t.Errorf("HashPassword() = %q, want bcrypt cost 12", hash)
+	}
+
+	if !CheckPassword(hash, "correct horse battery") {
+		t.Error("CheckPassword() rejected the right password")
+	}
+	if CheckPassword(hash, "correct horse battery!") {
+		t.Error("CheckPassword() accepted the wrong password")
+	}
+	if CheckPassword("", "") {
+		t.Error("CheckPassword() accepted an account without a password")
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+	}{
+		{"ok", "long enough!", false},
+		{"too short", "short", true},
+		{"counts characters not bytes", "ééééééééé", true},
+		{"at byte limit", strings.Repeat("a", 72), false},
+		{"over byte limit", strings.Repeat("a", 73), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validatePassword(tt.password); (err != nil) != tt.wantErr {
+				t.Errorf("validatePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := []struct {
+		email   string
+		want    string
+		wantErr bool
+	}{
+		{email: "Runner@Example.com", want: "runner@example.com"},
+		{email: "  runner@example.com ", want: "runner@example.com"},
+		{email: "Runner <runner@example.com>", wantErr: true},
+		{email: "not-an-email", wantErr: true},
+		{email: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			got, err := normalizeEmail(tt.email)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeEmail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerificationEmail(t *testing.T) {
+	msg := verificationEmail("runner@example.com", "https://health.example.com/", "a+b/c")
+	if msg.To != "runner@example.com" {
+		t.Errorf("To = %q", msg.To)
+	}
+	if !strings.Contains(msg.Body, "https://health.example.com/api/v1/auth/verify-email?token=a%2Bb%2Fc\n") {
+		t.Errorf("Body has no verification link:\n%s", msg.Body)
+	}
+}
@@ -2,12 +2,35 @@ package auth
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	"github.com/satishthakur/health-assistant/backend/internal/db"
 	"github.com/satishthakur/health-assistant/backend/internal/models"
 )
 
+// UserStore holds users, their sign-in identities and emailed tokens.
+// UserRepository implements it on Postgres and MemoryUserStore in memory,
+// for tests. It also implements middleware.RoleLookup.
+type UserStore interface {
+	FindOrCreateUserByIdentity(ctx context.Context, id *Identity, displayName string) (*models.User, bool, error)
+	LinkIdentity(ctx context.Context, userID string, id *Identity) error
+	ListIdentities(ctx context.Context, userID string) ([]UserIdentity, error)
+	UnlinkIdentity(ctx context.Context, userID, id string) (bool, error)
+	CreateUserWithPassword(ctx context.Context, email, passwordHash, displayName string) (*models.User, error)
+	FindUserByEmail(ctx context.Context, email string) (*models.User, error)
+	CreateEmailToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error)
+	VerifyEmail(ctx context.Context, secret string) (string, error)
+	ResetPassword(ctx context.Context, secret, passwordHash string) (string, error)
+	FindUserByID(ctx context.Context, id string) (*models.User, error)
+	UserRole(ctx context.Context, userID string) (string, error)
+}
+
 // UserRepository handles database operations for users.
 type UserRepository struct {
 	db *db.Database
@@ -18,41 +41,652 @@ func NewUserRepository(database *db.Database) *UserRepository {
 	return &UserRepository{db: database}
 }
 
-// FindOrCreateUserByGoogleID upserts a user based on their Google ID.
-func (r *UserRepository) FindOrCreateUserByGoogleID(ctx context.Context, googleID, email, displayName string) (*models.User, error) {
+const userColumns = `id, email, COALESCE(display_name, ''), email_verified_at, role, created_at`
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Email, &user.DisplayName, &user.EmailVerifiedAt, &user.Role, &user.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindOrCreateUserByIdentity returns the user a provider identity signs in
+// as. The first sign-in with an identity links it to the user with the same
+// verified email, or creates a user if there is none. displayName is used
+// for new users when the token carries no name. It reports whether the
+// sign-in claimed an unverified account: whoever registered that account may
+// not own the email, so the caller must sign them out.
+func (r *UserRepository) FindOrCreateUserByIdentity(ctx context.Context, id *Identity, displayName string) (*models.User, bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	user, err := scanUser(tx.QueryRow(ctx, `
+		UPDATE user_identities i
+		SET email = $3, last_login_at = NOW()
+		FROM users u
+		WHERE u.id = i.user_id AND i.provider = $1 AND i.subject = $2
+		RETURNING u.id, u.email, COALESCE(u.display_name, ''), u.email_verified_at, u.role, u.created_at
+	`, id.Provider, id.Subject, id.Email))
+	if err == nil {
+		if err := tx.Commit(ctx); err != nil {
+			return nil, false, fmt.Errorf("find user by identity: %w", err)
+		}
+		return user, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("find user by identity: %w", err)
+	}
+
+	if linksByEmail(id) {
+		user, err = scanUser(tx.QueryRow(ctx,
+			`SELECT `+userColumns+` FROM users WHERE LOWER(email) = LOWER($1)`, id.Email))
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, fmt.Errorf("find user by email: %w", err)
+		}
+	}
+
+	claimed := user != nil && user.EmailVerifiedAt == nil
+	if claimed {
+		// The provider just proved who owns this email. A password set by
+		// whoever registered it unverified could be someone else's, so it
+		// no longer signs in.
+		user, err = scanUser(tx.QueryRow(ctx, `
+			UPDATE users
+			SET password_hash = NULL, email_verified_at = NOW()
+			WHERE id = $1
+			RETURNING `+userColumns, user.ID))
+		if err != nil {
+			return nil, false, fmt.Errorf("verify user email: %w", err)
+		}
+	}
+
+	if user == nil {
+		if id.Name != "" {
+			displayName = id.Name
+		}
+		user, err = scanUser(tx.QueryRow(ctx, `
+			INSERT INTO users (email, display_name, email_verified_at)
+			VALUES ($1, NULLIF($2, ''), NOW())
+			RETURNING `+userColumns,
+			id.Email, displayName))
+		if isUniqueViolation(err) {
+			return nil, false, ErrEmailInUse
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("create user: %w", err)
+		}
+	}
+
+	if err := insertIdentity(ctx, tx, user.ID, id); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, fmt.Errorf("link identity: %w", err)
+	}
+
+	return user, claimed, nil
+}
+
+// LinkIdentity adds a provider identity to userID's sign-in methods. It
+// returns ErrIdentityInUse if the identity already signs in another user.
+func (r *UserRepository) LinkIdentity(ctx context.Context, userID string, id *Identity) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var owner string
+	err = tx.QueryRow(ctx,
+		`SELECT user_id FROM user_identities WHERE provider = $1 AND subject = $2`,
+		id.Provider, id.Subject).Scan(&owner)
+	switch {
+	case err == nil && owner != userID:
+		return ErrIdentityInUse
+	case err == nil:
+		return nil // already linked
+	case !errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("find identity: %w", err)
+	}
+
+	if err := insertIdentity(ctx, tx, userID, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
+
+func insertIdentity(ctx context.Context, tx pgx.Tx, userID string, id *Identity) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO user_identities (user_id, provider, subject, email, private_email)
+		VALUES ($1, $2, $3, $4, $5)
+	`, userID, id.Provider, id.Subject, id.Email, id.PrivateEmail)
+	if isUniqueViolation(err) {
+		return ErrIdentityInUse
+	}
+	if err != nil {
+		return fmt.Errorf("link identity: %w", err)
+	}
+	return nil
+}
+
+// ListIdentities retrieves the provider identities linked to a user.
+func (r *UserRepository) ListIdentities(ctx context.Context, userID string) ([]UserIdentity, error) {
 	query := `
-		INSERT INTO users (email, google_id, display_name)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (google_id) DO UPDATE SET
-			email        = EXCLUDED.email,
-			display_name = EXCLUDED.display_name
-		RETURNING id, email, COALESCE(display_name, ''), COALESCE(google_id, ''), created_at
+		SELECT id, user_id, provider, subject, email, private_email, created_at, last_login_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at
 	`
 
-	var user models.User
-	err := r.db.Pool.QueryRow(ctx, query, email, googleID, displayName).
-		Scan(&user.ID, &user.Email, &user.DisplayName, &user.GoogleID, &user.CreatedAt)
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list identities: %w", err)
+	}
+	defer rows.Close()
+
+	var identities []UserIdentity
+	for rows.Next() {
+		var i UserIdentity
+		err := rows.Scan(&i.ID, &i.UserID, &i.Provider, &i.Subject, &i.Email, &i.PrivateEmail, &i.CreatedAt, &i.LastLoginAt)
+		if err != nil {
+			return nil, fmt.Errorf("scan identity: %w", err)
+		}
+		identities = append(identities, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate identities: %w", err)
+	}
+
+	return identities, nil
+}
+
+// UnlinkIdentity removes one of userID's identities. It returns false if the
+// user has no identity with that ID, and ErrLastIdentity if it is the
+// user's only way to sign in.
+func (r *UserRepository) UnlinkIdentity(ctx context.Context, userID, id string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the user row so two concurrent unlinks can't both pass the check
+	var others int
+	var hasPassword bool
+	err = tx.QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM user_identities WHERE user_id = u.id AND id::text <> $2),
+			COALESCE(u.password_hash, '') <> ''
+		FROM users u
+		WHERE u.id = $1
+		FOR UPDATE
+	`, userID, id).Scan(&others, &hasPassword)
+	if err != nil {
+		return false, fmt.Errorf("count identities: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM user_identities WHERE id::text = $1 AND user_id = $2`, id, userID)
 	if err != nil {
-		return nil, fmt.Errorf("find or create user by google ID: %w", err)
+		return false, fmt.Errorf("unlink identity: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+	if others == 0 && !hasPassword {
+		return false, ErrLastIdentity
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("unlink identity: %w", err)
 	}
+	return true, nil
+}
+
+// CreateUserWithPassword registers a user who signs in with email and
+// password. The email starts unverified. It returns ErrEmailInUse if any
+// user already has the email, in any letter case.
+func (r *UserRepository) CreateUserWithPassword(ctx context.Context, email, passwordHash, displayName string) (*models.User, error) {
+	query := `
+		INSERT INTO users (email, password_hash, display_name)
+		SELECT $1, $2, NULLIF($3, '')
+		WHERE NOT EXISTS (SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))
+		RETURNING ` + userColumns
 
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, query, email, passwordHash, displayName))
+	if errors.Is(err, pgx.ErrNoRows) || isUniqueViolation(err) {
+		return nil, ErrEmailInUse
+	}
+	if err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	return user, nil
+}
+
+// FindUserByEmail retrieves a user, with their password hash, by email in
+// any letter case. It returns nil if there is no such user.
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `, COALESCE(password_hash, '')
+		FROM users
+		WHERE LOWER(email) = LOWER($1)
+	`
+
+	var user models.User
+	err := r.db.Pool.QueryRow(ctx, query, email).
+		Scan(&user.ID, &user.Email, &user.DisplayName, &user.EmailVerifiedAt, &user.Role, &user.CreatedAt, &user.PasswordHash)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find user by email: %w", err)
+	}
 	return &user, nil
 }
 
+// CreateEmailToken issues a single-use token for purpose that expires after
+// ttl, replacing any unused one for the same purpose, and returns its
+// secret.
+func (r *UserRepository) CreateEmailToken(ctx context.Context, userID, purpose string, ttl time.Duration) (string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE email_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`, userID, purpose)
+	if err != nil {
+		return "", fmt.Errorf("expire email tokens: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO email_tokens (user_id, purpose, token_hash, expires_at)
+		VALUES ($1, $2, $3, $4)
+	`, userID, purpose, hashSecret(secret), time.Now().Add(ttl))
+	if err != nil {
+		return "", fmt.Errorf("create email token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("create email token: %w", err)
+	}
+	return secret, nil
+}
+
+// useEmailToken marks an unused, unexpired token spent and returns its
+// user. It returns ErrInvalidEmailToken otherwise.
+func useEmailToken(ctx context.Context, tx pgx.Tx, purpose, secret string) (string, error) {
+	var userID string
+	err := tx.QueryRow(ctx, `
+		UPDATE email_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id
+	`, hashSecret(secret), purpose).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInvalidEmailToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("use email token: %w", err)
+	}
+	return userID, nil
+}
+
+// VerifyEmail spends an email verification token and marks its user's email
+// verified. It returns the user's ID.
+func (r *UserRepository) VerifyEmail(ctx context.Context, secret string) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := useEmailToken(ctx, tx, PurposeVerifyEmail, secret)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`, userID)
+	if err != nil {
+		return "", fmt.Errorf("verify email: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("verify email: %w", err)
+	}
+	return userID, nil
+}
+
+// ResetPassword spends a password reset token and sets its user's password.
+// Receiving the token also proves the user owns the email. It returns the
+// user's ID.
+func (r *UserRepository) ResetPassword(ctx context.Context, secret, passwordHash string) (string, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	userID, err := useEmailToken(ctx, tx, PurposeResetPassword, secret)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET password_hash = $2, email_verified_at = COALESCE(email_verified_at, NOW())
+		WHERE id = $1
+	`, userID, passwordHash)
+	if err != nil {
+		return "", fmt.Errorf("reset password: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("reset password: %w", err)
+	}
+	return userID, nil
+}
+
 // FindUserByID retrieves a user by their primary key.
 func (r *UserRepository) FindUserByID(ctx context.Context, id string) (*models.User, error) {
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("find user by ID: %w", err)
+	}
+	return user, nil
+}
+
+// UserRole returns a user's role. It implements middleware.RoleLookup.
+func (r *UserRepository) UserRole(ctx context.Context, userID string) (string, error) {
+	var role string
+	err := r.db.Pool.QueryRow(ctx, `SELECT role FROM users WHERE id::text = $1`, userID).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("find user role: %w", err)
+	}
+	return role, nil
+}
+
+// SetRole changes the role of the user with the given ID or email. It
+// returns the updated user, or nil if there is no such user.
+func (r *UserRepository) SetRole(ctx context.Context, userIDOrEmail, role string) (*models.User, error) {
+	if !models.ValidRole(role) {
+		return nil, fmt.Errorf("invalid role %q", role)
+	}
+
+	query := `
+		UPDATE users
+		SET role = $2
+		WHERE id::text = $1 OR LOWER(email) = LOWER($1)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.Pool.QueryRow(ctx, query, userIDOrEmail, role))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("set user role: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsersByRole retrieves the users with any role other than RoleUser,
+// or only those with role if it is set.
+func (r *UserRepository) ListUsersByRole(ctx context.Context, role string) ([]models.User, error) {
 	query := `
-		SELECT id, email, COALESCE(display_name, ''), COALESCE(google_id, ''), created_at
+		SELECT ` + userColumns + `
 		FROM users
+		WHERE role <> 'user' AND ($1 = '' OR role = $1)
+		ORDER BY role, email
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, role)
+	if err != nil {
+		return nil, fmt.Errorf("list users by role: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate users: %w", err)
+	}
+
+	return users, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres unique constraint
+// violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// SessionStore holds login sessions. SessionRepository implements it on
+// Postgres and MemorySessionStore in memory, for tests.
+type SessionStore interface {
+	CreateSession(ctx context.Context, userID, deviceName, userAgent string, ttl time.Duration) (*Session, string, error)
+	RotateRefreshToken(ctx context.Context, refreshToken string, ttl time.Duration) (*Session, string, error)
+	RevokeByRefreshToken(ctx context.Context, refreshToken string) error
+	ListActiveSessions(ctx context.Context, userID string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, id string) (bool, error)
+	RevokeAllSessions(ctx context.Context, userID, reason string) error
+}
+
+// SessionRepository handles database operations for login sessions.
+type SessionRepository struct {
+	db *db.Database
+}
+
+// NewSessionRepository creates a new SessionRepository.
+func NewSessionRepository(database *db.Database) *SessionRepository {
+	return &SessionRepository{db: database}
+}
+
+const sessionColumns = `id, user_id, device_name, user_agent, created_at, last_used_at, expires_at, revoked_at`
+
+func scanSession(row interface{ Scan(...interface{}) error }) (*Session, error) {
+	var s Session
+	err := row.Scan(&s.ID, &s.UserID, &s.DeviceName, &s.UserAgent, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// CreateSession starts a session for userID that stays alive for ttl after
+// its last refresh, and returns it with its first refresh token.
+func (r *SessionRepository) CreateSession(ctx context.Context, userID, deviceName, userAgent string, ttl time.Duration) (*Session, string, error) {
+	secret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		INSERT INTO sessions (user_id, refresh_token_hash, device_name, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + sessionColumns
+
+	session, err := scanSession(r.db.Pool.QueryRow(ctx, query,
+		userID, hashSecret(secret), deviceName, userAgent, time.Now().Add(ttl),
+	))
+	if err != nil {
+		return nil, "", fmt.Errorf("create session: %w", err)
+	}
+
+	return session, formatRefreshToken(session.ID, secret), nil
+}
+
+// RotateRefreshToken exchanges a refresh token for a new one and extends the
+// session by ttl. Presenting a token that was already exchanged revokes the
+// session, since either the client or an attacker holds a stolen copy.
+func (r *SessionRepository) RotateRefreshToken(ctx context.Context, refreshToken string, ttl time.Duration) (*Session, string, error) {
+	sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var currentHash string
+	var expiresAt time.Time
+	var revoked bool
+	err = tx.QueryRow(ctx, `
+		SELECT refresh_token_hash, expires_at, revoked_at IS NOT NULL
+		FROM sessions
+		WHERE id::text = $1
+		FOR UPDATE
+	`, sessionID).Scan(&currentHash, &expiresAt, &revoked)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("find session: %w", err)
+	}
+	if revoked || time.Now().After(expiresAt) {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(currentHash)) != 1 {
+		revoke := `UPDATE sessions SET revoked_at = NOW(), revoke_reason = $2 WHERE id = $1`
+		if _, err := tx.Exec(ctx, revoke, sessionID, RevokeReuse); err != nil {
+			return nil, "", fmt.Errorf("revoke reused session: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return nil, "", fmt.Errorf("revoke reused session: %w", err)
+		}
+		return nil, "", ErrRefreshTokenReused
+	}
+
+	nextSecret, err := newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+
+	session, err := scanSession(tx.QueryRow(ctx, `
+		UPDATE sessions
+		SET refresh_token_hash = $2, last_used_at = NOW(), expires_at = $3
 		WHERE id = $1
+		RETURNING `+sessionColumns,
+		sessionID, hashSecret(nextSecret), time.Now().Add(ttl),
+	))
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", fmt.Errorf("rotate refresh token: %w", err)
+	}
+
+	return session, formatRefreshToken(session.ID, nextSecret), nil
+}
+
+// RevokeByRefreshToken ends the session a current refresh token belongs to.
+// It returns ErrInvalidRefreshToken if there is no such active session.
+func (r *SessionRepository) RevokeByRefreshToken(ctx context.Context, refreshToken string) error {
+	sessionID, secret, err := parseRefreshToken(refreshToken)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $3
+		WHERE id::text = $1 AND refresh_token_hash = $2 AND revoked_at IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, sessionID, hashSecret(secret), RevokeLogout)
+	if err != nil {
+		return fmt.Errorf("revoke session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrInvalidRefreshToken
+	}
+	return nil
+}
+
+// ListActiveSessions retrieves a user's signed-in devices, most recently
+// used first.
+func (r *SessionRepository) ListActiveSessions(ctx context.Context, userID string) ([]Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_used_at DESC
 	`
 
-	var user models.User
-	err := r.db.Pool.QueryRow(ctx, query, id).
-		Scan(&user.ID, &user.Email, &user.DisplayName, &user.GoogleID, &user.CreatedAt)
+	rows, err := r.db.Pool.Query(ctx, query, userID)
 	if err != nil {
-		return nil, fmt.Errorf("find user by ID: %w", err)
+		return nil, fmt.Errorf("list sessions: %w", err)
 	}
+	defer rows.Close()
 
-	return &user, nil
+	var sessions []Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sessions = append(sessions, *session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession ends one of userID's sessions. It returns false if the user
+// has no active session with that ID.
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID, id string) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $3
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID, RevokeUser)
+	if err != nil {
+		return false, fmt.Errorf("revoke session: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeAllSessions signs userID out everywhere.
+func (r *SessionRepository) RevokeAllSessions(ctx context.Context, userID, reason string) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), revoke_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, userID, reason); err != nil {
+		return fmt.Errorf("revoke sessions: %w", err)
+	}
+	return nil
 }
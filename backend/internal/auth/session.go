@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session revoke reasons
+const (
+	RevokeLogout = "logout"
+	RevokeUser   = "revoked" // removed from the device list
+	RevokeReuse  = "reuse"   // a replaced refresh token was presented again
+	RevokeReset  = "password_reset"
+	RevokeClaim  = "account_claimed" // a provider sign-in verified the email of an unverified account
+)
+
+// Refresh errors
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused; session revoked")
+)
+
+// Session is one signed-in device. Its refresh token changes on every
+// refresh and only its hash is stored.
+type Session struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	DeviceName string     `json:"device_name"`
+	UserAgent  string     `json:"user_agent"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Refresh tokens are "<session ID>.<secret>", so the session can be found
+// even when the secret is stale, which is how reuse is detected.
+
+// newSecret returns a random token secret, for refresh tokens and emailed
+// links. Only hashSecret of it is stored.
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token secret: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func formatRefreshToken(sessionID, secret string) string {
+	return sessionID + "." + secret
+}
+
+func parseRefreshToken(token string) (sessionID, secret string, err error) {
+	sessionID, secret, ok := strings.Cut(token, ".")
+	if !ok || sessionID == "" || secret == "" {
+		return "", "", ErrInvalidRefreshToken
+	}
+	return sessionID, secret, nil
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRefreshTokenRoundTrip(t *testing.T) {
+	secret, err := newSecret()
+	if err != nil {
+		t.Fatalf("newSecret() error = %v", err)
+	}
+
+	token := formatRefreshToken("7d1c0d3e-5a43-4c55-9d35-2f8a3c1b9e10", secret)
+	sessionID, gotSecret, err := parseRefreshToken(token)
+	if err != nil {
+		t.Fatalf("parseRefreshToken() error = %v", err)
+	}
+	if sessionID != "7d1c0d3e-5a43-4c55-9d35-2f8a3c1b9e10" || gotSecret != secret {
+		t.Errorf("parseRefreshToken() = %q, %q", sessionID, gotSecret)
+	}
+	if hashSecret(secret) == hashSecret(secret+"x") {
+		t.Error("hashSecret() collides on different secrets")
+	}
+}
+
+func TestParseRefreshTokenInvalid(t *testing.T) {
+	for _, token := range []string{"", "no-separator", ".secret", "session."} {
+		if _, _, err := parseRefreshToken(token); !errors.Is(err, ErrInvalidRefreshToken) {
+			t.Errorf("parseRefreshToken(%q) error = %v, want ErrInvalidRefreshToken", token, err)
+		}
+	}
+}
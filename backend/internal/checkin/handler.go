@@ -14,12 +14,12 @@ import (
 
 // Handler handles check-in related requests.
 type Handler struct {
-	eventRepo   *db.EventRepository
-	checkinRepo *Repository
+	eventRepo   db.EventStore
+	checkinRepo Store
 }
 
 // NewHandler creates a new check-in Handler.
-func NewHandler(eventRepo *db.EventRepository, checkinRepo *Repository) *Handler {
+func NewHandler(eventRepo db.EventStore, checkinRepo Store) *Handler {
 	return &Handler{
 		eventRepo:   eventRepo,
 		checkinRepo: checkinRepo,
@@ -0,0 +1,86 @@
+package checkin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/sources"
+)
+
+// MemoryStore answers the dashboard queries from a db.EventStore, resolving
+// sources the same way Repository does. It is for tests.
+type MemoryStore struct {
+	events db.EventStore
+
+	mu         sync.Mutex
+	priorities map[string]sources.Priorities
+}
+
+// NewMemoryStore creates a MemoryStore reading events. Every user has the
+// default source priorities until SetPriorities is called.
+func NewMemoryStore(events db.EventStore) *MemoryStore {
+	return &MemoryStore{events: events, priorities: make(map[string]sources.Priorities)}
+}
+
+// SetPriorities sets a user's source priorities.
+func (s *MemoryStore) SetPriorities(userID string, priorities sources.Priorities) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.priorities[userID] = priorities
+}
+
+// resolve offers userID's events in [start, end), except activity samples,
+// to a Resolver per key.
+func (s *MemoryStore) resolve(ctx context.Context, userID string, start, end time.Time, key func(time.Time) string) (map[string]*sources.Resolver, error) {
+	events, err := s.events.GetEventsByUser(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	priorities := s.priorities[userID]
+	s.mu.Unlock()
+
+	resolved := make(map[string]*sources.Resolver)
+	for _, e := range events {
+		if !e.Time.Before(end) || e.EventType == models.EventTypeActivitySample {
+			continue
+		}
+		offer(resolved, priorities, key, e.EventType, sources.Candidate{Time: e.Time, Source: e.Source, Data: e.Data})
+	}
+	return resolved, nil
+}
+
+// GetTodayDashboard implements Store.
+func (s *MemoryStore) GetTodayDashboard(ctx context.Context, userID string) (*DashboardData, error) {
+	start, end := todayRange(time.Now())
+	resolved, err := s.resolve(ctx, userID, start, end, wholeRange)
+	if err != nil {
+		return nil, err
+	}
+	return todayDashboard(resolved), nil
+}
+
+// GetWeekTrends implements Store.
+func (s *MemoryStore) GetWeekTrends(ctx context.Context, userID string) ([]TrendData, error) {
+	resolved, err := s.resolve(ctx, userID, weekStart(time.Now()), endOfTime, dateKey)
+	if err != nil {
+		return nil, err
+	}
+	return weekTrends(resolved), nil
+}
+
+// GetCorrelations implements Store.
+func (s *MemoryStore) GetCorrelations(ctx context.Context, userID string, days int) ([]CorrelationInsight, error) {
+	resolved, err := s.resolve(ctx, userID, startOfDay(time.Now().AddDate(0, 0, -days)), endOfTime, dateKey)
+	if err != nil {
+		return nil, err
+	}
+	return correlations(resolved), nil
+}
+
+// endOfTime bounds the queries Repository leaves open-ended.
+var endOfTime = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
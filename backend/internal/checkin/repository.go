@@ -6,53 +6,103 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+
 	"github.com/satishthakur/health-assistant/backend/internal/db"
 	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/sources"
 )
 
+// Store answers the dashboard, trend and correlation queries. Repository
+// implements it on Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	GetTodayDashboard(ctx context.Context, userID string) (*DashboardData, error)
+	GetWeekTrends(ctx context.Context, userID string) ([]TrendData, error)
+	GetCorrelations(ctx context.Context, userID string, days int) ([]CorrelationInsight, error)
+}
+
 // Repository handles database operations for check-in and dashboard queries.
 type Repository struct {
-	db *db.Database
+	db         *db.Database
+	priorities *sources.Repository
 }
 
 // NewRepository creates a new Repository.
 func NewRepository(database *db.Database) *Repository {
-	return &Repository{db: database}
+	return &Repository{db: database, priorities: sources.NewRepository(database)}
 }
 
 // DashboardData represents today's summary data.
 type DashboardData struct {
 	Checkin *models.SubjectiveFeeling `json:"checkin,omitempty"`
 	Garmin  *GarminSummary            `json:"garmin,omitempty"`
+	Sources map[string]string         `json:"sources,omitempty"` // metric -> source that won
 }
 
-// GarminSummary represents aggregated Garmin data for today.
+// GarminSummary represents aggregated wearable data for today. Despite the
+// name it is device-agnostic: when several sources report the same metric,
+// the user's source priorities decide which one fills the field.
 type GarminSummary struct {
-	Sleep       *models.GarminSleep       `json:"sleep,omitempty"`
-	Activity    *models.GarminActivity    `json:"activity,omitempty"`
-	HRV         *HRVData                  `json:"hrv,omitempty"`
-	Stress      *StressData               `json:"stress,omitempty"`
-	DailyStats  *models.GarminDailyStats  `json:"daily_stats,omitempty"`
-	BodyBattery *models.GarminBodyBattery `json:"body_battery,omitempty"`
+	Sleep            *models.GarminSleep       `json:"sleep,omitempty"`
+	Activity         *models.GarminActivity    `json:"activity,omitempty"`
+	HRV              *HRVData                  `json:"hrv,omitempty"`
+	Stress           *StressData               `json:"stress,omitempty"`
+	DailyStats       *models.GarminDailyStats  `json:"daily_stats,omitempty"`
+	BodyBattery      *models.GarminBodyBattery `json:"body_battery,omitempty"`
+	Readiness        *models.Readiness         `json:"readiness,omitempty"`
+	Strain           *models.Strain            `json:"strain,omitempty"`
+	SpO2             *models.SpO2              `json:"spo2,omitempty"`
+	Respiration      *models.Respiration       `json:"respiration,omitempty"`
+	TrainingStatus   *models.TrainingStatus    `json:"training_status,omitempty"`
+	VO2Max           *models.VO2Max            `json:"vo2max,omitempty"`
+	IntensityMinutes *models.IntensityMinutes  `json:"intensity_minutes,omitempty"`
 }
 
 // HRVData represents HRV information.
 type HRVData struct {
-	Average float64 `json:"average"`
+	Average           float64             `json:"average"` // last night's average, in ms
+	LastNight5MinHigh *float64            `json:"last_night_5min_high,omitempty"`
+	WeeklyAvg         *float64            `json:"weekly_avg,omitempty"`
+	Status            string              `json:"status,omitempty"`
+	Baseline          *models.HRVBaseline `json:"baseline,omitempty"`
 }
 
 // StressData represents stress information.
 type StressData struct {
 	Average int    `json:"average"`
+	Max     *int   `json:"max,omitempty"`
 	Level   string `json:"level"` // low, moderate, high
 }
 
+func newHRVData(hrv models.GarminHRV) *HRVData {
+	return &HRVData{
+		Average:           hrv.LastNightAvg,
+		LastNight5MinHigh: hrv.LastNight5MinHigh,
+		WeeklyAvg:         hrv.WeeklyAvg,
+		Status:            hrv.Status,
+		Baseline:          hrv.Baseline,
+	}
+}
+
+func newStressData(stress models.GarminStress) *StressData {
+	level := "low"
+	if stress.AverageStressLevel >= 26 && stress.AverageStressLevel <= 50 {
+		level = "moderate"
+	} else if stress.AverageStressLevel > 50 {
+		level = "high"
+	}
+	return &StressData{Average: stress.AverageStressLevel, Max: stress.MaxStressLevel, Level: level}
+}
+
 // TrendData represents 7-day trend data.
 type TrendData struct {
 	Date     string                    `json:"date"`
 	Checkin  *models.SubjectiveFeeling `json:"checkin,omitempty"`
 	Sleep    *models.GarminSleep       `json:"sleep,omitempty"`
 	Activity *models.GarminActivity    `json:"activity,omitempty"`
+	HRV      *HRVData                  `json:"hrv,omitempty"`
+	Stress   *StressData               `json:"stress,omitempty"`
+	Sources  map[string]string         `json:"sources,omitempty"` // metric -> source that won
 }
 
 // CorrelationInsight represents a correlation between metrics.
@@ -71,224 +121,356 @@ type dailyData struct {
 	Activity *models.GarminActivity
 }
 
-// GetTodayDashboard retrieves today's check-in and Garmin data.
+// GetTodayDashboard retrieves today's check-in and wearable data, resolving
+// metrics reported by several sources with the user's source priorities.
 func (r *Repository) GetTodayDashboard(ctx context.Context, userID string) (*DashboardData, error) {
-	now := time.Now()
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	start, end := todayRange(time.Now())
+
+	priorities, err := r.priorities.GetPriorities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	query := `
-		SELECT time, event_type, data
+		SELECT time, event_type, source, data
 		FROM events
 		WHERE user_id = $1
 			AND time >= $2
 			AND time < $3
-		ORDER BY time DESC
+			AND event_type <> $4
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, startOfDay, endOfDay)
+	rows, err := r.db.Pool.Query(ctx, query, userID, start, end, models.EventTypeActivitySample)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query today's events: %w", err)
 	}
-	defer rows.Close()
 
-	dashboard := &DashboardData{Garmin: &GarminSummary{}}
-
-	for rows.Next() {
-		var eventTime time.Time
-		var eventType string
-		var data []byte
+	resolved, err := resolveRows(rows, priorities, wholeRange)
+	if err != nil {
+		return nil, err
+	}
 
-		if err := rows.Scan(&eventTime, &eventType, &data); err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
-		}
+	return todayDashboard(resolved), nil
+}
 
-		switch eventType {
-		case models.EventTypeSubjectiveFeeling:
-			var feeling models.SubjectiveFeeling
-			if err := json.Unmarshal(data, &feeling); err == nil {
-				dashboard.Checkin = &feeling
-			}
-		case models.EventTypeGarminSleep:
-			var sleep models.GarminSleep
-			if err := json.Unmarshal(data, &sleep); err == nil {
-				dashboard.Garmin.Sleep = &sleep
-			}
-		case models.EventTypeGarminActivity:
-			var activity models.GarminActivity
-			if err := json.Unmarshal(data, &activity); err == nil {
-				dashboard.Garmin.Activity = &activity
-			}
-		case models.EventTypeGarminHRV:
-			var hrvData map[string]interface{}
-			if err := json.Unmarshal(data, &hrvData); err == nil {
-				if avg, ok := hrvData["average"].(float64); ok {
-					dashboard.Garmin.HRV = &HRVData{Average: avg}
-				}
-			}
-		case models.EventTypeGarminStress:
-			var stressData map[string]interface{}
-			if err := json.Unmarshal(data, &stressData); err == nil {
-				if avg, ok := stressData["average_stress_level"].(float64); ok {
-					avgInt := int(avg)
-					level := "low"
-					if avgInt >= 26 && avgInt <= 50 {
-						level = "moderate"
-					} else if avgInt > 50 {
-						level = "high"
-					}
-					dashboard.Garmin.Stress = &StressData{Average: avgInt, Level: level}
-				}
-			}
-		case models.EventTypeGarminDailyStats:
-			var dailyStats models.GarminDailyStats
-			if err := json.Unmarshal(data, &dailyStats); err == nil {
-				dashboard.Garmin.DailyStats = &dailyStats
-			}
-		case models.EventTypeGarminBodyBattery:
-			var bodyBattery models.GarminBodyBattery
-			if err := json.Unmarshal(data, &bodyBattery); err == nil {
-				dashboard.Garmin.BodyBattery = &bodyBattery
+// todayDashboard builds the dashboard from today's resolved events.
+func todayDashboard(resolved map[string]*sources.Resolver) *DashboardData {
+	dashboard := &DashboardData{Garmin: &GarminSummary{}, Sources: make(map[string]string)}
+	if resolver, ok := resolved[""]; ok {
+		for eventType, winner := range resolver.Winners() {
+			if dashboard.apply(eventType, winner.Data) {
+				recordSource(dashboard.Sources, eventType, winner.Source)
 			}
 		}
 	}
+	return dashboard
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating events: %w", err)
+// apply decodes data into the dashboard field for eventType, reporting
+// whether a field was filled.
+func (d *DashboardData) apply(eventType string, data []byte) bool {
+	switch eventType {
+	case models.EventTypeSubjectiveFeeling:
+		var feeling models.SubjectiveFeeling
+		if err := json.Unmarshal(data, &feeling); err == nil {
+			d.Checkin = &feeling
+			return true
+		}
+	case models.EventTypeGarminSleep:
+		var sleep models.GarminSleep
+		if err := json.Unmarshal(data, &sleep); err == nil {
+			d.Garmin.Sleep = &sleep
+			return true
+		}
+	case models.EventTypeGarminActivity:
+		var activity models.GarminActivity
+		if err := json.Unmarshal(data, &activity); err == nil {
+			d.Garmin.Activity = &activity
+			return true
+		}
+	case models.EventTypeGarminHRV:
+		var hrv models.GarminHRV
+		if err := json.Unmarshal(data, &hrv); err == nil {
+			d.Garmin.HRV = newHRVData(hrv)
+			return true
+		}
+	case models.EventTypeGarminStress:
+		var stress models.GarminStress
+		if err := json.Unmarshal(data, &stress); err == nil {
+			d.Garmin.Stress = newStressData(stress)
+			return true
+		}
+	case models.EventTypeGarminDailyStats:
+		var dailyStats models.GarminDailyStats
+		if err := json.Unmarshal(data, &dailyStats); err == nil {
+			d.Garmin.DailyStats = &dailyStats
+			return true
+		}
+	case models.EventTypeGarminBodyBattery:
+		var bodyBattery models.GarminBodyBattery
+		if err := json.Unmarshal(data, &bodyBattery); err == nil {
+			d.Garmin.BodyBattery = &bodyBattery
+			return true
+		}
+	case models.EventTypeReadiness:
+		var readiness models.Readiness
+		if err := json.Unmarshal(data, &readiness); err == nil {
+			d.Garmin.Readiness = &readiness
+			return true
+		}
+	case models.EventTypeStrain:
+		var strain models.Strain
+		if err := json.Unmarshal(data, &strain); err == nil {
+			d.Garmin.Strain = &strain
+			return true
+		}
+	case models.EventTypeSpO2:
+		var spo2 models.SpO2
+		if err := json.Unmarshal(data, &spo2); err == nil {
+			d.Garmin.SpO2 = &spo2
+			return true
+		}
+	case models.EventTypeRespiration:
+		var respiration models.Respiration
+		if err := json.Unmarshal(data, &respiration); err == nil {
+			d.Garmin.Respiration = &respiration
+			return true
+		}
+	case models.EventTypeTrainingStatus:
+		var status models.TrainingStatus
+		if err := json.Unmarshal(data, &status); err == nil {
+			d.Garmin.TrainingStatus = &status
+			return true
+		}
+	case models.EventTypeVO2Max:
+		var vo2max models.VO2Max
+		if err := json.Unmarshal(data, &vo2max); err == nil {
+			d.Garmin.VO2Max = &vo2max
+			return true
+		}
+	case models.EventTypeIntensityMinutes:
+		var minutes models.IntensityMinutes
+		if err := json.Unmarshal(data, &minutes); err == nil {
+			d.Garmin.IntensityMinutes = &minutes
+			return true
+		}
 	}
-
-	return dashboard, nil
+	return false
 }
 
-// GetWeekTrends retrieves 7-day trend data.
+// GetWeekTrends retrieves 7-day trend data, resolving each day's metrics with
+// the user's source priorities.
 func (r *Repository) GetWeekTrends(ctx context.Context, userID string) ([]TrendData, error) {
-	now := time.Now()
-	startDate := now.AddDate(0, 0, -6)
-	startOfWeek := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	startOfWeek := weekStart(time.Now())
+
+	priorities, err := r.priorities.GetPriorities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	query := `
-		SELECT time, event_type, data
+		SELECT time, event_type, source, data
 		FROM events
 		WHERE user_id = $1
 			AND time >= $2
-		ORDER BY time ASC
+			AND event_type <> $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, startOfWeek)
+	rows, err := r.db.Pool.Query(ctx, query, userID, startOfWeek, models.EventTypeActivitySample)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query week trends: %w", err)
 	}
-	defer rows.Close()
-
-	trendsByDate := make(map[string]*TrendData)
-
-	for rows.Next() {
-		var eventTime time.Time
-		var eventType string
-		var data []byte
 
-		if err := rows.Scan(&eventTime, &eventType, &data); err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
-		}
+	resolved, err := resolveRows(rows, priorities, dateKey)
+	if err != nil {
+		return nil, err
+	}
 
-		dateKey := eventTime.Format("2006-01-02")
-		if _, exists := trendsByDate[dateKey]; !exists {
-			trendsByDate[dateKey] = &TrendData{Date: dateKey}
-		}
-		trend := trendsByDate[dateKey]
+	return weekTrends(resolved), nil
+}
 
-		switch eventType {
-		case models.EventTypeSubjectiveFeeling:
-			var feeling models.SubjectiveFeeling
-			if err := json.Unmarshal(data, &feeling); err == nil {
+// weekTrends builds a trend entry for each day of resolved events.
+func weekTrends(resolved map[string]*sources.Resolver) []TrendData {
+	trends := make([]TrendData, 0, len(resolved))
+	for date, resolver := range resolved {
+		trend := TrendData{Date: date, Sources: make(map[string]string)}
+
+		for eventType, winner := range resolver.Winners() {
+			switch eventType {
+			case models.EventTypeSubjectiveFeeling:
+				var feeling models.SubjectiveFeeling
+				if err := json.Unmarshal(winner.Data, &feeling); err != nil {
+					continue
+				}
 				trend.Checkin = &feeling
-			}
-		case models.EventTypeGarminSleep:
-			var sleep models.GarminSleep
-			if err := json.Unmarshal(data, &sleep); err == nil {
+			case models.EventTypeGarminSleep:
+				var sleep models.GarminSleep
+				if err := json.Unmarshal(winner.Data, &sleep); err != nil {
+					continue
+				}
 				trend.Sleep = &sleep
-			}
-		case models.EventTypeGarminActivity:
-			var activity models.GarminActivity
-			if err := json.Unmarshal(data, &activity); err == nil {
+			case models.EventTypeGarminActivity:
+				var activity models.GarminActivity
+				if err := json.Unmarshal(winner.Data, &activity); err != nil {
+					continue
+				}
 				trend.Activity = &activity
+			case models.EventTypeGarminHRV:
+				var hrv models.GarminHRV
+				if err := json.Unmarshal(winner.Data, &hrv); err != nil {
+					continue
+				}
+				trend.HRV = newHRVData(hrv)
+			case models.EventTypeGarminStress:
+				var stress models.GarminStress
+				if err := json.Unmarshal(winner.Data, &stress); err != nil {
+					continue
+				}
+				trend.Stress = newStressData(stress)
+			default:
+				continue
 			}
+			recordSource(trend.Sources, eventType, winner.Source)
+		}
+
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// resolveRows reads (time, event_type, source, data) rows into one Resolver
+// per key, so that each key ends up with a single winner per event type.
+func resolveRows(rows pgx.Rows, priorities sources.Priorities, key func(time.Time) string) (map[string]*sources.Resolver, error) {
+	defer rows.Close()
+
+	resolved := make(map[string]*sources.Resolver)
+	for rows.Next() {
+		var c sources.Candidate
+		var eventType string
+
+		if err := rows.Scan(&c.Time, &eventType, &c.Source, &c.Data); err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
 		}
+
+		offer(resolved, priorities, key, eventType, c)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating events: %w", err)
 	}
 
-	trends := make([]TrendData, 0, len(trendsByDate))
-	for _, trend := range trendsByDate {
-		trends = append(trends, *trend)
+	return resolved, nil
+}
+
+// offer gives c to the Resolver for its key, creating it if needed. Rows the
+// dashboard can't decode are dropped first, so that a corrupt row doesn't
+// win and hide the next-ranked source's reading. The trends and
+// correlations read a subset of the dashboard's event types.
+func offer(resolved map[string]*sources.Resolver, priorities sources.Priorities, key func(time.Time) string, eventType string, c sources.Candidate) {
+	if !(&DashboardData{Garmin: &GarminSummary{}}).apply(eventType, c.Data) {
+		return
 	}
 
-	return trends, nil
+	k := key(c.Time)
+	resolver, ok := resolved[k]
+	if !ok {
+		resolver = sources.NewResolver(priorities)
+		resolved[k] = resolver
+	}
+	resolver.Offer(eventType, c)
+}
+
+func dateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// wholeRange keys every event the same, for a single resolution.
+func wholeRange(time.Time) string {
+	return ""
+}
+
+// startOfDay returns midnight at the start of t's day, in t's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// todayRange returns the start of now's day and of the next one.
+func todayRange(now time.Time) (time.Time, time.Time) {
+	start := startOfDay(now)
+	return start, start.Add(24 * time.Hour)
+}
+
+// weekStart returns the start of the 7-day trend window ending today.
+func weekStart(now time.Time) time.Time {
+	return startOfDay(now.AddDate(0, 0, -6))
+}
+
+// recordSource notes which source won the metric eventType reports.
+func recordSource(winners map[string]string, eventType, source string) {
+	if metric, ok := sources.MetricForEventType(eventType); ok {
+		winners[metric] = source
+	}
 }
 
 // GetCorrelations calculates simple correlations between Garmin data and feelings.
 func (r *Repository) GetCorrelations(ctx context.Context, userID string, days int) ([]CorrelationInsight, error) {
-	now := time.Now()
-	startDate := now.AddDate(0, 0, -days)
-	startTime := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, startDate.Location())
+	startTime := startOfDay(time.Now().AddDate(0, 0, -days))
+
+	priorities, err := r.priorities.GetPriorities(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
 
 	query := `
-		SELECT time, event_type, data
+		SELECT time, event_type, source, data
 		FROM events
 		WHERE user_id = $1
 			AND time >= $2
-		ORDER BY time ASC
+			AND event_type <> $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, startTime)
+	rows, err := r.db.Pool.Query(ctx, query, userID, startTime, models.EventTypeActivitySample)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query correlation data: %w", err)
 	}
-	defer rows.Close()
-
-	byDate := make(map[string]*dailyData)
 
-	for rows.Next() {
-		var eventTime time.Time
-		var eventType string
-		var data []byte
-
-		if err := rows.Scan(&eventTime, &eventType, &data); err != nil {
-			return nil, fmt.Errorf("failed to scan event: %w", err)
-		}
+	resolved, err := resolveRows(rows, priorities, dateKey)
+	if err != nil {
+		return nil, err
+	}
 
-		dateKey := eventTime.Format("2006-01-02")
-		if _, exists := byDate[dateKey]; !exists {
-			byDate[dateKey] = &dailyData{}
-		}
-		daily := byDate[dateKey]
+	return correlations(resolved), nil
+}
 
-		switch eventType {
-		case models.EventTypeSubjectiveFeeling:
-			var feeling models.SubjectiveFeeling
-			if err := json.Unmarshal(data, &feeling); err == nil {
-				daily.Feeling = &feeling
-			}
-		case models.EventTypeGarminSleep:
-			var sleep models.GarminSleep
-			if err := json.Unmarshal(data, &sleep); err == nil {
-				daily.Sleep = &sleep
-			}
-		case models.EventTypeGarminActivity:
-			var activity models.GarminActivity
-			if err := json.Unmarshal(data, &activity); err == nil {
-				daily.Activity = &activity
+// correlations finds insights in days of resolved events.
+func correlations(resolved map[string]*sources.Resolver) []CorrelationInsight {
+	byDate := make(map[string]*dailyData, len(resolved))
+	for date, resolver := range resolved {
+		daily := &dailyData{}
+		byDate[date] = daily
+
+		for eventType, winner := range resolver.Winners() {
+			switch eventType {
+			case models.EventTypeSubjectiveFeeling:
+				var feeling models.SubjectiveFeeling
+				if err := json.Unmarshal(winner.Data, &feeling); err == nil {
+					daily.Feeling = &feeling
+				}
+			case models.EventTypeGarminSleep:
+				var sleep models.GarminSleep
+				if err := json.Unmarshal(winner.Data, &sleep); err == nil {
+					daily.Sleep = &sleep
+				}
+			case models.EventTypeGarminActivity:
+				var activity models.GarminActivity
+				if err := json.Unmarshal(winner.Data, &activity); err == nil {
+					daily.Activity = &activity
+				}
 			}
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating events: %w", err)
-	}
-
-	return calculateCorrelations(byDate), nil
+	return calculateCorrelations(byDate)
 }
 
 func calculateCorrelations(byDate map[string]*dailyData) []CorrelationInsight {
@@ -303,6 +485,9 @@ func calculateCorrelations(byDate map[string]*dailyData) []CorrelationInsight {
 	if insight := sleepFocusCorrelation(byDate); insight != nil {
 		insights = append(insights, *insight)
 	}
+	if insight := sleepContinuityEnergyCorrelation(byDate); insight != nil {
+		insights = append(insights, *insight)
+	}
 
 	return insights
 }
@@ -403,6 +588,38 @@ func sleepFocusCorrelation(byDate map[string]*dailyData) *CorrelationInsight {
 	}
 }
 
+func sleepContinuityEnergyCorrelation(byDate map[string]*dailyData) *CorrelationInsight {
+	var withGood, withPoor []int
+	for _, d := range byDate {
+		if d.Feeling == nil || d.Sleep == nil || d.Sleep.WASOMinutes == nil {
+			continue
+		}
+		if *d.Sleep.WASOMinutes < 30 {
+			withGood = append(withGood, d.Feeling.Energy)
+		} else {
+			withPoor = append(withPoor, d.Feeling.Energy)
+		}
+	}
+	if len(withGood) < 5 || len(withPoor) < 5 {
+		return nil
+	}
+	avgGood, avgPoor := average(withGood), average(withPoor)
+	improvement := ((avgGood - avgPoor) / avgPoor) * 100
+	if improvement < 5 {
+		return nil
+	}
+	return &CorrelationInsight{
+		Type:        "sleep_continuity_energy",
+		Description: fmt.Sprintf("Your energy is %.0f%% higher after nights with under 30 minutes awake", improvement),
+		Confidence:  0.75,
+		SampleSize:  len(withGood) + len(withPoor),
+		Details: map[string]interface{}{
+			"condition": "waso < 30 minutes", "avg_energy_with": avgGood,
+			"avg_energy_without": avgPoor, "improvement_percent": improvement,
+		},
+	}
+}
+
 func average(values []int) float64 {
 	if len(values) == 0 {
 		return 0
@@ -2,6 +2,10 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all application configuration
@@ -11,6 +15,10 @@ type Config struct {
 	Auth     AuthConfig
 	AWS      AWSConfig
 	Garmin   GarminConfig
+	Import   ImportConfig
+	Ingest   IngestConfig
+	Mail     MailConfig
+	Alert    AlertConfig
 }
 
 type DatabaseConfig struct {
@@ -20,16 +28,32 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// Apply pending schema migrations when the server starts. Otherwise run
+	// `server migrate up` before deploying.
+	AutoMigrate bool
 }
 
 type ServerConfig struct {
-	Port string
-	Env  string // development, production
+	Port      string
+	Env       string // development, production
+	PublicURL string // base URL for links in emails
 }
 
 type AuthConfig struct {
-	JWTSecret     string
-	TokenDuration int // in hours
+	JWTSecret       string
+	AccessTokenTTL  time.Duration // lifetime of a JWT access token
+	RefreshTokenTTL time.Duration // how long an unused session stays signed in
+	GoogleClientID  string
+	// Accept Google ID tokens issued to any client. Local development only.
+	GoogleAllowAnyAudience bool
+	// Sign in with Apple is enabled when client IDs (bundle ID, Services ID)
+	// are set.
+	AppleClientIDs []string
+	// A generic OpenID Connect provider, enabled when an issuer is set.
+	// Users sign in at /api/v1/auth/{OIDCProviderName}.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCProviderName string
 }
 
 type AWSConfig struct {
@@ -43,26 +67,75 @@ type GarminConfig struct {
 	ConsumerKey    string
 	ConsumerSecret string
 	CallbackURL    string
+	// Health API hosts; override all three to point at a mock Garmin server
+	OAuthBaseURL   string
+	ConnectBaseURL string
+	APIBaseURL     string
+}
+
+type ImportConfig struct {
+	Dir string // where uploaded archives wait for their import job
+}
+
+type IngestConfig struct {
+	// HMAC keys trusted senders sign ingest requests with, by key ID.
+	// Several can be active at once to rotate secrets.
+	SigningKeys map[string]string
+	// Users each signing key may write for, by key ID. A signed request
+	// naming any other user is refused, as is any request signed with a
+	// key bound to no users.
+	SigningKeyUsers map[string][]string
+	MaxSkew         time.Duration // allowed clock difference for signed requests
+}
+
+type MailConfig struct {
+	// Outgoing mail for verification and password reset emails. Without a
+	// host, emails are written to the log.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+type AlertConfig struct {
+	// Where sync failure alerts go; alerting is off when none is set.
+	WebhookURL      string   // generic webhook, sent the alert as JSON
+	SlackWebhookURL string   // Slack-compatible incoming webhook
+	EmailTo         []string // sent through the Mail settings
+	// Consecutive failed or partial syncs of a data type before alerting.
+	FailureThreshold int
+	// Minimum time between alerts about the same user.
+	Cooldown time.Duration
 }
 
 // Load loads configuration from environment variables
 func Load() *Config {
 	return &Config{
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "healthuser"),
-			Password: getEnv("DB_PASSWORD", "healthpass"),
-			DBName:   getEnv("DB_NAME", "health_assistant"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:        getEnv("DB_HOST", "localhost"),
+			Port:        getEnv("DB_PORT", "5432"),
+			User:        getEnv("DB_USER", "healthuser"),
+			Password:    getEnv("DB_PASSWORD", "healthpass"),
+			DBName:      getEnv("DB_NAME", "health_assistant"),
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+			AutoMigrate: getEnv("DB_AUTO_MIGRATE", "true") == "true",
 		},
 		Server: ServerConfig{
-			Port: getEnv("SERVER_PORT", "8080"),
-			Env:  getEnv("ENV", "development"),
+			Port:      getEnv("SERVER_PORT", "8080"),
+			Env:       getEnv("ENV", "development"),
+			PublicURL: getEnv("PUBLIC_URL", "http://localhost:8080"),
 		},
 		Auth: AuthConfig{
-			JWTSecret:     getEnv("JWT_SECRET", "change-me-in-production"),
-			TokenDuration: 24, // 24 hours
+			JWTSecret:              getEnv("JWT_SECRET", "change-me-in-production"),
+			AccessTokenTTL:         time.Duration(getEnvInt("ACCESS_TOKEN_MINUTES", 15)) * time.Minute,
+			RefreshTokenTTL:        time.Duration(getEnvInt("REFRESH_TOKEN_DAYS", 60)) * 24 * time.Hour,
+			GoogleClientID:         getEnv("GOOGLE_CLIENT_ID", ""),
+			GoogleAllowAnyAudience: getEnv("GOOGLE_ALLOW_ANY_AUDIENCE", "") == "true",
+			AppleClientIDs:         splitList(getEnv("APPLE_CLIENT_IDS", "")),
+			OIDCIssuer:             getEnv("OIDC_ISSUER", ""),
+			OIDCClientID:           getEnv("OIDC_CLIENT_ID", ""),
+			OIDCProviderName:       getEnv("OIDC_PROVIDER_NAME", "oidc"),
 		},
 		AWS: AWSConfig{
 			Region:          getEnv("AWS_REGION", "us-east-1"),
@@ -73,9 +146,84 @@ func Load() *Config {
 		Garmin: GarminConfig{
 			ConsumerKey:    getEnv("GARMIN_CONSUMER_KEY", ""),
 			ConsumerSecret: getEnv("GARMIN_CONSUMER_SECRET", ""),
-			CallbackURL:    getEnv("GARMIN_CALLBACK_URL", "http://localhost:8080/auth/garmin/callback"),
+			CallbackURL:    getEnv("GARMIN_CALLBACK_URL", "http://localhost:8080/api/v1/garmin/callback"),
+			OAuthBaseURL:   getEnv("GARMIN_OAUTH_BASE_URL", "https://connectapi.garmin.com"),
+			ConnectBaseURL: getEnv("GARMIN_CONNECT_BASE_URL", "https://connect.garmin.com"),
+			APIBaseURL:     getEnv("GARMIN_API_BASE_URL", "https://apis.garmin.com"),
+		},
+		Import: ImportConfig{
+			Dir: getEnv("IMPORT_DIR", filepath.Join(os.TempDir(), "health-assistant-imports")),
+		},
+		Ingest: IngestConfig{
+			SigningKeys:     splitKeys(getEnv("INGEST_SIGNING_KEYS", "")),
+			SigningKeyUsers: splitKeyUsers(getEnv("INGEST_SIGNING_KEY_USERS", "")),
+			MaxSkew:         time.Duration(getEnvInt("INGEST_MAX_SKEW_SECONDS", 300)) * time.Second,
 		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "Health Assistant <noreply@localhost>"),
+		},
+		Alert: AlertConfig{
+			WebhookURL:       getEnv("ALERT_WEBHOOK_URL", ""),
+			SlackWebhookURL:  getEnv("ALERT_SLACK_WEBHOOK_URL", ""),
+			EmailTo:          splitList(getEnv("ALERT_EMAIL_TO", "")),
+			FailureThreshold: getEnvInt("ALERT_FAILURE_THRESHOLD", 3),
+			Cooldown:         time.Duration(getEnvInt("ALERT_COOLDOWN_HOURS", 6)) * time.Hour,
+		},
+	}
+}
+
+// splitList parses a comma-separated list, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
+
+// splitKeys parses a comma-separated list of id:secret pairs. Entries
+// without an ID or secret are dropped.
+func splitKeys(value string) map[string]string {
+	keys := make(map[string]string)
+	for _, item := range splitList(value) {
+		id, secret, ok := strings.Cut(item, ":")
+		if ok && id != "" && secret != "" {
+			keys[id] = secret
+		}
+	}
+	return keys
+}
+
+// splitKeyUsers parses a comma-separated list of id:user pairs, where user
+// may be several user IDs separated by "|". A key listed more than once
+// gets the users of every entry.
+func splitKeyUsers(value string) map[string][]string {
+	users := make(map[string][]string)
+	for _, item := range splitList(value) {
+		id, list, ok := strings.Cut(item, ":")
+		if !ok || id == "" {
+			continue
+		}
+		for _, userID := range strings.Split(list, "|") {
+			if userID = strings.TrimSpace(userID); userID != "" {
+				users[id] = append(users[id], userID)
+			}
+		}
+	}
+	return users
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
 }
 
 func getEnv(key, defaultValue string) string {
@@ -12,11 +12,11 @@ import (
 
 // Handler handles dashboard and trends requests.
 type Handler struct {
-	checkinRepo *checkin.Repository
+	checkinRepo checkin.Store
 }
 
 // NewHandler creates a new dashboard Handler.
-func NewHandler(checkinRepo *checkin.Repository) *Handler {
+func NewHandler(checkinRepo checkin.Store) *Handler {
 	return &Handler{checkinRepo: checkinRepo}
 }
 
@@ -9,6 +9,17 @@ import (
 	"github.com/satishthakur/health-assistant/backend/internal/models"
 )
 
+// EventStore reads and writes events. EventRepository implements it on
+// Postgres and MemoryEventStore in memory, for tests.
+type EventStore interface {
+	InsertEvent(ctx context.Context, event *models.Event) (*InsertEventResult, error)
+	InsertEvents(ctx context.Context, events []*models.Event) (int, error)
+	GetEventsByUserAndType(ctx context.Context, userID, eventType string, startTime, endTime time.Time) ([]models.Event, error)
+	GetEventsByUser(ctx context.Context, userID string, startTime, endTime time.Time) ([]models.Event, error)
+	DeleteEvent(ctx context.Context, userID, eventType string, eventTime time.Time) error
+	CountEventsByType(ctx context.Context, userID string, startTime, endTime time.Time) (map[string]int64, error)
+}
+
 // EventRepository handles database operations for events
 type EventRepository struct {
 	db *Database
@@ -24,15 +35,14 @@ type InsertEventResult struct {
 	WasInserted bool // true if inserted, false if updated
 }
 
-// InsertEvent inserts a new event or updates if conflict on (time, user_id, event_type)
+// InsertEvent inserts a new event or updates if conflict on (time, user_id, event_type, source)
 // Returns InsertEventResult indicating whether the row was inserted or updated
 func (r *EventRepository) InsertEvent(ctx context.Context, event *models.Event) (*InsertEventResult, error) {
 	query := `
 		INSERT INTO events (time, user_id, event_type, source, data, metadata, confidence)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		ON CONFLICT (time, user_id, event_type)
+		ON CONFLICT (time, user_id, event_type, source)
 		DO UPDATE SET
-			source = EXCLUDED.source,
 			data = EXCLUDED.data,
 			metadata = EXCLUDED.metadata,
 			confidence = EXCLUDED.confidence
@@ -59,6 +69,55 @@ func (r *EventRepository) InsertEvent(ctx context.Context, event *models.Event)
 	return &InsertEventResult{WasInserted: wasInserted}, nil
 }
 
+// InsertEvents upserts a batch of events in a single round trip, with the same
+// conflict semantics as InsertEvent. Returns the number of newly inserted rows.
+func (r *EventRepository) InsertEvents(ctx context.Context, events []*models.Event) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	query := `
+		INSERT INTO events (time, user_id, event_type, source, data, metadata, confidence)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (time, user_id, event_type, source)
+		DO UPDATE SET
+			data = EXCLUDED.data,
+			metadata = EXCLUDED.metadata,
+			confidence = EXCLUDED.confidence
+		RETURNING (xmax = 0) AS was_inserted
+	`
+
+	batch := &pgx.Batch{}
+	for _, event := range events {
+		batch.Queue(
+			query,
+			event.Time,
+			event.UserID,
+			event.EventType,
+			event.Source,
+			event.Data,
+			event.Metadata,
+			event.Confidence,
+		)
+	}
+
+	results := r.db.Pool.SendBatch(ctx, batch)
+	defer results.Close()
+
+	inserted := 0
+	for range events {
+		var wasInserted bool
+		if err := results.QueryRow().Scan(&wasInserted); err != nil {
+			return inserted, fmt.Errorf("failed to insert event batch: %w", err)
+		}
+		if wasInserted {
+			inserted++
+		}
+	}
+
+	return inserted, nil
+}
+
 // GetEventsByUserAndType retrieves events for a user filtered by event type
 func (r *EventRepository) GetEventsByUserAndType(
 	ctx context.Context,
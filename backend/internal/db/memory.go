@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// MemoryEventStore keeps events in memory with the same upsert and range
+// semantics as EventRepository. It is for tests.
+type MemoryEventStore struct {
+	mu     sync.Mutex
+	events map[eventKey]models.Event
+}
+
+// eventKey is the events table's primary key. Times are compared at
+// microsecond precision, as TIMESTAMPTZ stores them.
+type eventKey struct {
+	time      int64
+	userID    string
+	eventType string
+	source    string
+}
+
+// NewMemoryEventStore creates an empty MemoryEventStore.
+func NewMemoryEventStore() *MemoryEventStore {
+	return &MemoryEventStore{events: make(map[eventKey]models.Event)}
+}
+
+// InsertEvent implements EventStore.
+func (s *MemoryEventStore) InsertEvent(ctx context.Context, event *models.Event) (*InsertEventResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inserted, err := s.upsert(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert event: %w", err)
+	}
+	return &InsertEventResult{WasInserted: inserted}, nil
+}
+
+// InsertEvents implements EventStore.
+func (s *MemoryEventStore) InsertEvents(ctx context.Context, events []*models.Event) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inserted := 0
+	for _, event := range events {
+		wasInserted, err := s.upsert(event)
+		if err != nil {
+			return inserted, fmt.Errorf("failed to insert event batch: %w", err)
+		}
+		if wasInserted {
+			inserted++
+		}
+	}
+	return inserted, nil
+}
+
+// upsert stores event, replacing the data, metadata and confidence of an
+// existing event with the same key, and reports whether it was new.
+func (s *MemoryEventStore) upsert(event *models.Event) (bool, error) {
+	if !json.Valid(event.Data) {
+		return false, fmt.Errorf("data is not valid JSON")
+	}
+	if event.Metadata != nil && !json.Valid(event.Metadata) {
+		return false, fmt.Errorf("metadata is not valid JSON")
+	}
+	if c := event.Confidence; c != nil && (*c < 0 || *c > 1) {
+		return false, fmt.Errorf("confidence %v is outside [0, 1]", *c)
+	}
+
+	stored := *event
+	stored.Time = event.Time.Truncate(time.Microsecond)
+	stored.Data = append(json.RawMessage(nil), event.Data...)
+	if event.Metadata != nil {
+		stored.Metadata = append(json.RawMessage(nil), event.Metadata...)
+	}
+	if event.Confidence != nil {
+		confidence := *event.Confidence
+		stored.Confidence = &confidence
+	}
+
+	k := eventKey{stored.Time.UnixMicro(), stored.UserID, stored.EventType, stored.Source}
+	_, exists := s.events[k]
+	s.events[k] = stored
+	return !exists, nil
+}
+
+// GetEventsByUserAndType implements EventStore.
+func (s *MemoryEventStore) GetEventsByUserAndType(ctx context.Context, userID, eventType string, startTime, endTime time.Time) ([]models.Event, error) {
+	return s.query(userID, startTime, endTime, func(e *models.Event) bool { return e.EventType == eventType }), nil
+}
+
+// GetEventsByUser implements EventStore.
+func (s *MemoryEventStore) GetEventsByUser(ctx context.Context, userID string, startTime, endTime time.Time) ([]models.Event, error) {
+	return s.query(userID, startTime, endTime, func(*models.Event) bool { return true }), nil
+}
+
+// query returns copies of userID's events from start to end inclusive that
+// match, newest first.
+func (s *MemoryEventStore) query(userID string, start, end time.Time, match func(*models.Event) bool) []models.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []models.Event
+	for _, e := range s.events {
+		if e.UserID != userID || e.Time.Before(start) || e.Time.After(end) || !match(&e) {
+			continue
+		}
+		e.Data = append(json.RawMessage(nil), e.Data...)
+		events = append(events, e)
+	}
+
+	// Postgres leaves ties in any order; break them so results are stable
+	sort.Slice(events, func(i, j int) bool {
+		a, b := &events[i], &events[j]
+		if !a.Time.Equal(b.Time) {
+			return a.Time.After(b.Time)
+		}
+		if a.EventType != b.EventType {
+			return a.EventType < b.EventType
+		}
+		return a.Source < b.Source
+	})
+	return events
+}
+
+// DeleteEvent implements EventStore. Like EventRepository, it deletes the
+// event from every source and returns pgx.ErrNoRows if there was none.
+func (s *MemoryEventStore) DeleteEvent(ctx context.Context, userID, eventType string, eventTime time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	micros := eventTime.Truncate(time.Microsecond).UnixMicro()
+	deleted := 0
+	for k := range s.events {
+		if k.time == micros && k.userID == userID && k.eventType == eventType {
+			delete(s.events, k)
+			deleted++
+		}
+	}
+	if deleted == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// CountEventsByType implements EventStore.
+func (s *MemoryEventStore) CountEventsByType(ctx context.Context, userID string, startTime, endTime time.Time) (map[string]int64, error) {
+	counts := make(map[string]int64)
+	for _, e := range s.query(userID, startTime, endTime, func(*models.Event) bool { return true }) {
+		counts[e.EventType]++
+	}
+	return counts, nil
+}
+
+// NewUUID returns a random (version 4) UUID, the kind of ID Postgres
+// generates, for the in-memory stores.
+func NewUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestMemoryEventStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryEventStore()
+	day := time.Date(2026, 1, 28, 0, 0, 0, 0, time.UTC)
+	event := func(at time.Time, source, data string) *models.Event {
+		return &models.Event{Time: at, UserID: "u1", EventType: models.EventTypeGarminHRV, Source: source, Data: json.RawMessage(data)}
+	}
+
+	// Same key, to the microsecond: the second write replaces the first
+	for _, tt := range []struct {
+		event *models.Event
+		want  bool
+	}{
+		{event(day, models.SourceGarmin, `{"last_night_avg":40}`), true},
+		{event(day.Add(300*time.Nanosecond), models.SourceGarmin, `{"last_night_avg":42}`), false},
+		{event(day, models.SourceOura, `{"last_night_avg":45}`), true},
+		{event(day.Add(24*time.Hour), models.SourceGarmin, `{"last_night_avg":50}`), true},
+	} {
+		result, err := s.InsertEvent(ctx, tt.event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.WasInserted != tt.want {
+			t.Errorf("InsertEvent(%s, %s) inserted = %v, want %v", tt.event.Time, tt.event.Source, result.WasInserted, tt.want)
+		}
+	}
+	if _, err := s.InsertEvent(ctx, event(day, models.SourceGarmin, `{`)); err == nil {
+		t.Error("InsertEvent() accepted invalid JSON")
+	}
+
+	// Both ends of the range are included, newest first
+	events, err := s.GetEventsByUserAndType(ctx, "u1", models.EventTypeGarminHRV, day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Source+" "+string(e.Data))
+	}
+	want := []string{
+		`garmin {"last_night_avg":50}`,
+		`garmin {"last_night_avg":42}`,
+		`oura {"last_night_avg":45}`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("events[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if events, _ := s.GetEventsByUser(ctx, "u2", day, day.Add(24*time.Hour)); len(events) != 0 {
+		t.Errorf("another user's query returned %d events", len(events))
+	}
+
+	// Deleting removes the event from every source
+	if err := s.DeleteEvent(ctx, "u1", models.EventTypeGarminHRV, day); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := s.CountEventsByType(ctx, "u1", day, day.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[models.EventTypeGarminHRV] != 1 {
+		t.Errorf("%d HRV events left, want 1", counts[models.EventTypeGarminHRV])
+	}
+	if err := s.DeleteEvent(ctx, "u1", models.EventTypeGarminHRV, day); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("DeleteEvent() of a deleted event error = %v, want pgx.ErrNoRows", err)
+	}
+}
@@ -0,0 +1,21 @@
+package fit
+
+var crcTable = [16]uint16{
+	0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+	0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+}
+
+// crc computes the FIT CRC-16 over data, processing each byte a nibble at a time.
+func crc(data []byte) uint16 {
+	var c uint16
+	for _, b := range data {
+		tmp := crcTable[c&0xF]
+		c = (c >> 4) & 0x0FFF
+		c = c ^ tmp ^ crcTable[b&0xF]
+
+		tmp = crcTable[c&0xF]
+		c = (c >> 4) & 0x0FFF
+		c = c ^ tmp ^ crcTable[(b>>4)&0xF]
+	}
+	return c
+}
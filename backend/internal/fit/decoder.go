@@ -0,0 +1,455 @@
+// Package fit decodes the subset of the Garmin/ANT+ FIT binary protocol needed
+// to import activities: sessions, laps and per-second records.
+package fit
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// File holds the activity messages decoded from a FIT file.
+type File struct {
+	Sessions []Session
+	Laps     []Lap
+	Records  []Record
+}
+
+// Session summarises one sport within an activity file.
+type Session struct {
+	StartTime        time.Time
+	Timestamp        time.Time
+	Sport            string
+	TotalElapsedTime float64 // seconds
+	TotalTimerTime   float64 // seconds
+	TotalDistance    float64 // meters
+	TotalCalories    int
+	AvgHeartRate     int
+	MaxHeartRate     int
+	AvgCadence       int
+	MaxCadence       int
+	AvgPower         int
+	MaxPower         int
+}
+
+// Lap summarises one lap within a session.
+type Lap struct {
+	StartTime        time.Time
+	Timestamp        time.Time
+	TotalElapsedTime float64 // seconds
+	TotalTimerTime   float64 // seconds
+	TotalDistance    float64 // meters
+	TotalCalories    int
+	AvgHeartRate     int
+	MaxHeartRate     int
+	AvgCadence       int
+	AvgPower         int
+	MaxPower         int
+}
+
+// Record is a single intraday sample. Zero values mean the field was not recorded.
+type Record struct {
+	Timestamp   time.Time
+	HeartRate   int
+	Cadence     int
+	Power       int
+	HasPosition bool
+	Latitude    float64 // degrees
+	Longitude   float64 // degrees
+	HasAltitude bool
+	Altitude    float64 // meters
+	Speed       float64 // meters per second
+	Distance    float64 // meters
+}
+
+// Global message numbers from the FIT profile.
+const (
+	mesgNumSession = 18
+	mesgNumLap     = 19
+	mesgNumRecord  = 20
+)
+
+// Field number shared by all messages carrying a timestamp.
+const fieldTimestamp = 253
+
+// FIT date_time values count seconds since 1989-12-31T00:00:00Z.
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// Values below this are relative to device power-on rather than the epoch.
+const minAbsoluteDateTime = 0x10000000
+
+var (
+	ErrInvalidHeader = errors.New("fit: invalid file header")
+	ErrCRCMismatch   = errors.New("fit: file CRC mismatch")
+	ErrTruncated     = errors.New("fit: unexpected end of data")
+)
+
+type fieldDef struct {
+	num      byte
+	size     int
+	baseType byte
+}
+
+type messageDef struct {
+	globalNum uint16
+	order     binary.ByteOrder
+	fields    []fieldDef
+	devSize   int
+}
+
+type decoder struct {
+	data          []byte
+	pos           int
+	defs          [16]*messageDef
+	lastTimestamp uint32
+	file          *File
+}
+
+// Decode reads a complete FIT file (including chained files) from r.
+func Decode(r io.Reader) (*File, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("fit: reading file: %w", err)
+	}
+
+	file := &File{}
+	for offset := 0; offset < len(data); {
+		n, err := decodeChunk(data[offset:], file)
+		if err != nil {
+			return nil, err
+		}
+		offset += n
+	}
+
+	return file, nil
+}
+
+// decodeChunk decodes a single FIT file (header, records, CRC) and returns the bytes consumed.
+func decodeChunk(data []byte, file *File) (int, error) {
+	if len(data) < 12 {
+		return 0, ErrInvalidHeader
+	}
+
+	headerSize := int(data[0])
+	if (headerSize != 12 && headerSize != 14) || len(data) < headerSize || string(data[8:12]) != ".FIT" {
+		return 0, ErrInvalidHeader
+	}
+
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	end := headerSize + dataSize
+	if len(data) < end+2 {
+		return 0, ErrTruncated
+	}
+
+	if want := binary.LittleEndian.Uint16(data[end : end+2]); crc(data[:end]) != want {
+		return 0, ErrCRCMismatch
+	}
+
+	d := &decoder{data: data[:end], pos: headerSize, file: file}
+	for d.pos < end {
+		if err := d.decodeMessage(); err != nil {
+			return 0, err
+		}
+	}
+
+	return end + 2, nil
+}
+
+func (d *decoder) decodeMessage() error {
+	header, err := d.readByte()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case header&0x80 != 0:
+		// Compressed timestamp header: 5-bit rolling offset from the last full timestamp
+		localNum := (header >> 5) & 0x03
+		offset := uint32(header & 0x1F)
+		timestamp := (d.lastTimestamp &^ 0x1F) + offset
+		if offset < d.lastTimestamp&0x1F {
+			timestamp += 0x20
+		}
+		d.lastTimestamp = timestamp
+		return d.decodeData(localNum, &timestamp)
+	case header&0x40 != 0:
+		return d.decodeDefinition(header&0x0F, header&0x20 != 0)
+	default:
+		return d.decodeData(header&0x0F, nil)
+	}
+}
+
+func (d *decoder) decodeDefinition(localNum byte, hasDevFields bool) error {
+	fixed, err := d.read(5)
+	if err != nil {
+		return err
+	}
+
+	def := &messageDef{order: binary.LittleEndian}
+	if fixed[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.globalNum = def.order.Uint16(fixed[2:4])
+
+	numFields := int(fixed[4])
+	raw, err := d.read(numFields * 3)
+	if err != nil {
+		return err
+	}
+	def.fields = make([]fieldDef, numFields)
+	for i := range def.fields {
+		def.fields[i] = fieldDef{num: raw[i*3], size: int(raw[i*3+1]), baseType: raw[i*3+2]}
+	}
+
+	if hasDevFields {
+		numDev, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		devRaw, err := d.read(int(numDev) * 3)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < int(numDev); i++ {
+			def.devSize += int(devRaw[i*3+1])
+		}
+	}
+
+	d.defs[localNum] = def
+	return nil
+}
+
+func (d *decoder) decodeData(localNum byte, compressedTimestamp *uint32) error {
+	def := d.defs[localNum]
+	if def == nil {
+		return fmt.Errorf("fit: data message for undefined local message type %d", localNum)
+	}
+
+	values := make(map[byte]int64, len(def.fields))
+	for _, f := range def.fields {
+		raw, err := d.read(f.size)
+		if err != nil {
+			return err
+		}
+		if v, ok := decodeValue(raw, f.baseType, def.order); ok {
+			values[f.num] = v
+		}
+	}
+	if _, err := d.read(def.devSize); err != nil {
+		return err
+	}
+
+	if ts, ok := values[fieldTimestamp]; ok {
+		d.lastTimestamp = uint32(ts)
+	} else if compressedTimestamp != nil {
+		values[fieldTimestamp] = int64(*compressedTimestamp)
+	}
+
+	switch def.globalNum {
+	case mesgNumSession:
+		d.file.Sessions = append(d.file.Sessions, newSession(values))
+	case mesgNumLap:
+		d.file.Laps = append(d.file.Laps, newLap(values))
+	case mesgNumRecord:
+		d.file.Records = append(d.file.Records, newRecord(values))
+	}
+
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, ErrTruncated
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) readByte() (byte, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// decodeValue decodes a single integer field, reporting false for the base
+// type's invalid sentinel, arrays, strings and floating point fields.
+func decodeValue(raw []byte, baseType byte, order binary.ByteOrder) (int64, bool) {
+	switch baseType {
+	case 0x00, 0x02, 0x0D: // enum, uint8, byte
+		if len(raw) != 1 || raw[0] == 0xFF {
+			return 0, false
+		}
+		return int64(raw[0]), true
+	case 0x0A: // uint8z
+		if len(raw) != 1 || raw[0] == 0 {
+			return 0, false
+		}
+		return int64(raw[0]), true
+	case 0x01: // sint8
+		if len(raw) != 1 || raw[0] == 0x7F {
+			return 0, false
+		}
+		return int64(int8(raw[0])), true
+	case 0x84, 0x8B: // uint16, uint16z
+		if len(raw) != 2 {
+			return 0, false
+		}
+		v := order.Uint16(raw)
+		if (baseType == 0x84 && v == 0xFFFF) || (baseType == 0x8B && v == 0) {
+			return 0, false
+		}
+		return int64(v), true
+	case 0x83: // sint16
+		if len(raw) != 2 {
+			return 0, false
+		}
+		v := int16(order.Uint16(raw))
+		if v == math.MaxInt16 {
+			return 0, false
+		}
+		return int64(v), true
+	case 0x86, 0x8C: // uint32, uint32z
+		if len(raw) != 4 {
+			return 0, false
+		}
+		v := order.Uint32(raw)
+		if (baseType == 0x86 && v == 0xFFFFFFFF) || (baseType == 0x8C && v == 0) {
+			return 0, false
+		}
+		return int64(v), true
+	case 0x85: // sint32
+		if len(raw) != 4 {
+			return 0, false
+		}
+		v := int32(order.Uint32(raw))
+		if v == math.MaxInt32 {
+			return 0, false
+		}
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func newSession(v map[byte]int64) Session {
+	return Session{
+		Timestamp:        toTime(v, fieldTimestamp),
+		StartTime:        toTime(v, 2),
+		Sport:            sportName(v, 5),
+		TotalElapsedTime: scaled(v, 7, 1000),
+		TotalTimerTime:   scaled(v, 8, 1000),
+		TotalDistance:    scaled(v, 9, 100),
+		TotalCalories:    int(v[11]),
+		AvgHeartRate:     int(v[16]),
+		MaxHeartRate:     int(v[17]),
+		AvgCadence:       int(v[18]),
+		MaxCadence:       int(v[19]),
+		AvgPower:         int(v[20]),
+		MaxPower:         int(v[21]),
+	}
+}
+
+func newLap(v map[byte]int64) Lap {
+	return Lap{
+		Timestamp:        toTime(v, fieldTimestamp),
+		StartTime:        toTime(v, 2),
+		TotalElapsedTime: scaled(v, 7, 1000),
+		TotalTimerTime:   scaled(v, 8, 1000),
+		TotalDistance:    scaled(v, 9, 100),
+		TotalCalories:    int(v[11]),
+		AvgHeartRate:     int(v[15]),
+		MaxHeartRate:     int(v[16]),
+		AvgCadence:       int(v[17]),
+		AvgPower:         int(v[19]),
+		MaxPower:         int(v[20]),
+	}
+}
+
+func newRecord(v map[byte]int64) Record {
+	rec := Record{
+		Timestamp: toTime(v, fieldTimestamp),
+		HeartRate: int(v[3]),
+		Cadence:   int(v[4]),
+		Power:     int(v[7]),
+		Distance:  scaled(v, 5, 100),
+		Speed:     scaled(v, 6, 1000),
+	}
+
+	lat, hasLat := v[0]
+	long, hasLong := v[1]
+	if hasLat && hasLong {
+		rec.HasPosition = true
+		rec.Latitude = semicirclesToDegrees(lat)
+		rec.Longitude = semicirclesToDegrees(long)
+	}
+
+	// Enhanced fields supersede the 16-bit originals when both are present
+	if speed, ok := v[73]; ok {
+		rec.Speed = float64(speed) / 1000
+	}
+	if alt, ok := v[78]; ok {
+		rec.HasAltitude = true
+		rec.Altitude = float64(alt)/5 - 500
+	} else if alt, ok := v[2]; ok {
+		rec.HasAltitude = true
+		rec.Altitude = float64(alt)/5 - 500
+	}
+
+	return rec
+}
+
+func toTime(v map[byte]int64, field byte) time.Time {
+	secs, ok := v[field]
+	if !ok || secs < minAbsoluteDateTime {
+		return time.Time{}
+	}
+	return fitEpoch.Add(time.Duration(secs) * time.Second)
+}
+
+func scaled(v map[byte]int64, field byte, scale float64) float64 {
+	return float64(v[field]) / scale
+}
+
+func semicirclesToDegrees(s int64) float64 {
+	return float64(s) * (180.0 / math.Exp2(31))
+}
+
+var sportNames = map[int64]string{
+	0:  "generic",
+	1:  "running",
+	2:  "cycling",
+	3:  "transition",
+	4:  "fitness_equipment",
+	5:  "swimming",
+	6:  "basketball",
+	7:  "soccer",
+	8:  "tennis",
+	9:  "american_football",
+	10: "training",
+	11: "walking",
+	12: "cross_country_skiing",
+	13: "alpine_skiing",
+	14: "snowboarding",
+	15: "rowing",
+	16: "mountaineering",
+	17: "hiking",
+	18: "multisport",
+	19: "paddling",
+}
+
+func sportName(v map[byte]int64, field byte) string {
+	sport, ok := v[field]
+	if !ok {
+		return "generic"
+	}
+	if name, ok := sportNames[sport]; ok {
+		return name
+	}
+	return fmt.Sprintf("sport_%d", sport)
+}
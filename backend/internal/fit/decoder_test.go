@@ -0,0 +1,215 @@
+package fit
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fitBuilder assembles minimal FIT files for tests.
+type fitBuilder struct {
+	body bytes.Buffer
+}
+
+type testField struct {
+	num      byte
+	baseType byte
+	size     int
+	value    int64
+}
+
+func (b *fitBuilder) define(localNum byte, globalNum uint16, order binary.ByteOrder, fields []testField, devSizes ...byte) {
+	header := 0x40 | localNum
+	if len(devSizes) > 0 {
+		header |= 0x20
+	}
+	b.body.WriteByte(header)
+	b.body.WriteByte(0) // reserved
+	if order == binary.BigEndian {
+		b.body.WriteByte(1)
+	} else {
+		b.body.WriteByte(0)
+	}
+	num := make([]byte, 2)
+	order.PutUint16(num, globalNum)
+	b.body.Write(num)
+	b.body.WriteByte(byte(len(fields)))
+	for _, f := range fields {
+		b.body.Write([]byte{f.num, byte(f.size), f.baseType})
+	}
+	if len(devSizes) > 0 {
+		b.body.WriteByte(byte(len(devSizes)))
+		for i, size := range devSizes {
+			b.body.Write([]byte{byte(i), size, 0})
+		}
+	}
+}
+
+func (b *fitBuilder) data(header byte, order binary.ByteOrder, fields []testField, devBytes ...byte) {
+	b.body.WriteByte(header)
+	for _, f := range fields {
+		buf := make([]byte, f.size)
+		switch f.size {
+		case 1:
+			buf[0] = byte(f.value)
+		case 2:
+			order.PutUint16(buf, uint16(f.value))
+		case 4:
+			order.PutUint32(buf, uint32(f.value))
+		}
+		b.body.Write(buf)
+	}
+	b.body.Write(devBytes)
+}
+
+func (b *fitBuilder) bytes() []byte {
+	header := make([]byte, 14)
+	header[0] = 14
+	header[1] = 0x10
+	binary.LittleEndian.PutUint16(header[2:4], 2100)
+	binary.LittleEndian.PutUint32(header[4:8], uint32(b.body.Len()))
+	copy(header[8:12], ".FIT")
+	binary.LittleEndian.PutUint16(header[12:14], crc(header[:12]))
+
+	out := append(header, b.body.Bytes()...)
+	sum := make([]byte, 2)
+	binary.LittleEndian.PutUint16(sum, crc(out))
+	return append(out, sum...)
+}
+
+func fitTime(t time.Time) int64 {
+	return int64(t.Sub(fitEpoch) / time.Second)
+}
+
+func degreesToSemicircles(deg float64) int64 {
+	return int64(deg * math.Exp2(31) / 180)
+}
+
+func TestDecode(t *testing.T) {
+	start := time.Date(2026, 1, 28, 6, 30, 0, 0, time.UTC)
+	le := binary.LittleEndian
+
+	var b fitBuilder
+
+	recordFields := func(ts time.Time, hr int64) []testField {
+		return []testField{
+			{num: 253, baseType: 0x86, size: 4, value: fitTime(ts)},
+			{num: 0, baseType: 0x85, size: 4, value: degreesToSemicircles(51.5)},
+			{num: 1, baseType: 0x85, size: 4, value: degreesToSemicircles(-0.12)},
+			{num: 3, baseType: 0x02, size: 1, value: hr},
+			{num: 4, baseType: 0x02, size: 1, value: 85},
+			{num: 7, baseType: 0x84, size: 2, value: 0xFFFF}, // invalid power
+			{num: 78, baseType: 0x86, size: 4, value: (35 + 500) * 5},
+		}
+	}
+	b.define(0, mesgNumRecord, le, recordFields(start, 0))
+	b.data(0x00, le, recordFields(start, 120))
+
+	// Second record uses a compressed timestamp header with only HR, defined with a developer field
+	compressed := []testField{{num: 3, baseType: 0x02, size: 1, value: 130}}
+	b.define(1, mesgNumRecord, le, compressed, 2)
+	offset := byte((fitTime(start) + 5) & 0x1F)
+	b.data(0x80|(1<<5)|offset, le, compressed, 0xAA, 0xBB)
+
+	lapFields := []testField{
+		{num: 253, baseType: 0x86, size: 4, value: fitTime(start.Add(30 * time.Minute))},
+		{num: 2, baseType: 0x86, size: 4, value: fitTime(start)},
+		{num: 7, baseType: 0x86, size: 4, value: 1800 * 1000},
+		{num: 9, baseType: 0x86, size: 4, value: 5000 * 100},
+		{num: 15, baseType: 0x02, size: 1, value: 125},
+	}
+	b.define(2, mesgNumLap, le, lapFields)
+	b.data(0x02, le, lapFields)
+
+	// Session is defined big-endian to exercise the architecture flag
+	be := binary.BigEndian
+	sessionFields := []testField{
+		{num: 253, baseType: 0x86, size: 4, value: fitTime(start.Add(30 * time.Minute))},
+		{num: 2, baseType: 0x86, size: 4, value: fitTime(start)},
+		{num: 5, baseType: 0x00, size: 1, value: 1},
+		{num: 7, baseType: 0x86, size: 4, value: 1800 * 1000},
+		{num: 9, baseType: 0x86, size: 4, value: 5000 * 100},
+		{num: 11, baseType: 0x84, size: 2, value: 410},
+		{num: 16, baseType: 0x02, size: 1, value: 125},
+		{num: 17, baseType: 0x02, size: 1, value: 171},
+		{num: 20, baseType: 0x84, size: 2, value: 240},
+	}
+	b.define(3, mesgNumSession, be, sessionFields)
+	b.data(0x03, be, sessionFields)
+
+	file, err := Decode(bytes.NewReader(b.bytes()))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if len(file.Records) != 2 {
+		t.Fatalf("got %d records, want 2", len(file.Records))
+	}
+	first := file.Records[0]
+	if !first.Timestamp.Equal(start) || first.HeartRate != 120 || first.Cadence != 85 || first.Power != 0 {
+		t.Errorf("first record = %+v", first)
+	}
+	if !first.HasPosition || math.Abs(first.Latitude-51.5) > 1e-6 || math.Abs(first.Longitude+0.12) > 1e-6 {
+		t.Errorf("first record position = %v,%v", first.Latitude, first.Longitude)
+	}
+	if !first.HasAltitude || math.Abs(first.Altitude-35) > 1e-6 {
+		t.Errorf("first record altitude = %v", first.Altitude)
+	}
+	second := file.Records[1]
+	if !second.Timestamp.Equal(start.Add(5*time.Second)) || second.HeartRate != 130 {
+		t.Errorf("compressed record = %+v", second)
+	}
+
+	if len(file.Laps) != 1 || file.Laps[0].TotalDistance != 5000 || file.Laps[0].AvgHeartRate != 125 {
+		t.Errorf("laps = %+v", file.Laps)
+	}
+
+	if len(file.Sessions) != 1 {
+		t.Fatalf("got %d sessions, want 1", len(file.Sessions))
+	}
+	session := file.Sessions[0]
+	if session.Sport != "running" || !session.StartTime.Equal(start) || session.TotalElapsedTime != 1800 ||
+		session.TotalDistance != 5000 || session.TotalCalories != 410 || session.MaxHeartRate != 171 || session.AvgPower != 240 {
+		t.Errorf("session = %+v", session)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var b fitBuilder
+	fields := []testField{{num: 3, baseType: 0x02, size: 1, value: 100}}
+	b.define(0, mesgNumRecord, binary.LittleEndian, fields)
+	b.data(0x00, binary.LittleEndian, fields)
+	valid := b.bytes()
+
+	corrupted := append([]byte(nil), valid...)
+	corrupted[len(corrupted)-3] ^= 0xFF
+
+	undefined := fitBuilder{}
+	undefined.data(0x05, binary.LittleEndian, fields)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "not a fit file", data: []byte("definitely not a FIT file"), wantErr: ErrInvalidHeader},
+		{name: "crc mismatch", data: corrupted, wantErr: ErrCRCMismatch},
+		{name: "truncated", data: valid[:len(valid)-4], wantErr: ErrTruncated},
+		{name: "undefined local message", data: undefined.bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(bytes.NewReader(tt.data))
+			if err == nil {
+				t.Fatal("Decode() expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package garmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// requestTokenTTL bounds how long a user has to approve access on Garmin Connect.
+const requestTokenTTL = 15 * time.Minute
+
+// ErrRequestTokenNotFound is returned when a callback's request token is unknown or expired.
+var ErrRequestTokenNotFound = errors.New("garmin request token not found or expired")
+
+// Connection is a user's Health API authorization, stored as JSON in
+// users.garmin_oauth_token.
+type Connection struct {
+	UserID       string     `json:"-"`
+	GarminUserID string     `json:"garmin_user_id"`
+	Token        OAuthToken `json:"access_token"`
+	ConnectedAt  time.Time  `json:"connected_at"`
+}
+
+// ConnectionStore holds users' Health API connections and the request
+// tokens of connect flows in progress. ConnectionRepository implements it on
+// Postgres and MemoryConnectionStore in memory, for tests.
+type ConnectionStore interface {
+	SaveRequestToken(ctx context.Context, userID string, token *OAuthToken) error
+	TakeRequestToken(ctx context.Context, oauthToken string) (string, *OAuthToken, error)
+	SaveConnection(ctx context.Context, conn *Connection) error
+	GetConnection(ctx context.Context, userID string) (*Connection, error)
+	FindConnectionByGarminUserID(ctx context.Context, garminUserID string) (*Connection, error)
+	DeleteConnection(ctx context.Context, userID string) error
+	DeleteConnectionByGarminUserID(ctx context.Context, garminUserID string) error
+}
+
+// ConnectionRepository handles database operations for Garmin Health API connections.
+type ConnectionRepository struct {
+	db *db.Database
+}
+
+// NewConnectionRepository creates a new ConnectionRepository.
+func NewConnectionRepository(database *db.Database) *ConnectionRepository {
+	return &ConnectionRepository{db: database}
+}
+
+// SaveRequestToken remembers a pending request token until Garmin redirects
+// the user back to the callback, which carries no session of its own.
+func (r *ConnectionRepository) SaveRequestToken(ctx context.Context, userID string, token *OAuthToken) error {
+	// Abandoned flows leave tokens behind; clear them out as new ones arrive
+	cleanup := `DELETE FROM garmin_oauth_requests WHERE created_at < $1`
+	if _, err := r.db.Pool.Exec(ctx, cleanup, time.Now().Add(-requestTokenTTL)); err != nil {
+		return fmt.Errorf("failed to clear expired garmin request tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO garmin_oauth_requests (oauth_token, token_secret, user_id)
+		VALUES ($1, $2, $3)
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, token.Token, token.Secret, userID); err != nil {
+		return fmt.Errorf("failed to save garmin request token: %w", err)
+	}
+	return nil
+}
+
+// TakeRequestToken consumes a pending request token, returning the user who
+// started the flow. Each request token can be used once.
+func (r *ConnectionRepository) TakeRequestToken(ctx context.Context, oauthToken string) (string, *OAuthToken, error) {
+	query := `
+		DELETE FROM garmin_oauth_requests
+		WHERE oauth_token = $1
+		RETURNING user_id, token_secret, created_at
+	`
+
+	var userID string
+	var createdAt time.Time
+	token := &OAuthToken{Token: oauthToken}
+	err := r.db.Pool.QueryRow(ctx, query, oauthToken).Scan(&userID, &token.Secret, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil, ErrRequestTokenNotFound
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to take garmin request token: %w", err)
+	}
+	if time.Since(createdAt) > requestTokenTTL {
+		return "", nil, ErrRequestTokenNotFound
+	}
+
+	return userID, token, nil
+}
+
+// SaveConnection stores a user's access token. A Garmin account can only be
+// linked to one user, so any other user holding it is disconnected.
+func (r *ConnectionRepository) SaveConnection(ctx context.Context, conn *Connection) error {
+	data, err := json.Marshal(conn)
+	if err != nil {
+		return fmt.Errorf("failed to marshal garmin connection: %w", err)
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	release := `
+		UPDATE users SET garmin_oauth_token = NULL
+		WHERE garmin_oauth_token->>'garmin_user_id' = $1 AND id <> $2
+	`
+	if _, err := tx.Exec(ctx, release, conn.GarminUserID, conn.UserID); err != nil {
+		return fmt.Errorf("failed to release garmin connection: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE users SET garmin_oauth_token = $1 WHERE id = $2`, data, conn.UserID); err != nil {
+		return fmt.Errorf("failed to save garmin connection: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetConnection retrieves a user's connection, or nil if they haven't connected.
+func (r *ConnectionRepository) GetConnection(ctx context.Context, userID string) (*Connection, error) {
+	query := `SELECT id, garmin_oauth_token FROM users WHERE id = $1 AND garmin_oauth_token IS NOT NULL`
+	return r.queryConnection(ctx, query, userID)
+}
+
+// FindConnectionByGarminUserID retrieves the connection pings for a Garmin user refer to.
+func (r *ConnectionRepository) FindConnectionByGarminUserID(ctx context.Context, garminUserID string) (*Connection, error) {
+	query := `SELECT id, garmin_oauth_token FROM users WHERE garmin_oauth_token->>'garmin_user_id' = $1`
+	return r.queryConnection(ctx, query, garminUserID)
+}
+
+func (r *ConnectionRepository) queryConnection(ctx context.Context, query string, arg string) (*Connection, error) {
+	var userID string
+	var data []byte
+	err := r.db.Pool.QueryRow(ctx, query, arg).Scan(&userID, &data)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get garmin connection: %w", err)
+	}
+
+	var conn Connection
+	if err := json.Unmarshal(data, &conn); err != nil {
+		return nil, fmt.Errorf("failed to parse garmin connection: %w", err)
+	}
+	conn.UserID = userID
+	return &conn, nil
+}
+
+// DeleteConnection removes a user's access token.
+func (r *ConnectionRepository) DeleteConnection(ctx context.Context, userID string) error {
+	if _, err := r.db.Pool.Exec(ctx, `UPDATE users SET garmin_oauth_token = NULL WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete garmin connection: %w", err)
+	}
+	return nil
+}
+
+// DeleteConnectionByGarminUserID removes the access token for a Garmin user,
+// e.g. after they revoke access from Garmin Connect.
+func (r *ConnectionRepository) DeleteConnectionByGarminUserID(ctx context.Context, garminUserID string) error {
+	query := `UPDATE users SET garmin_oauth_token = NULL WHERE garmin_oauth_token->>'garmin_user_id' = $1`
+	if _, err := r.db.Pool.Exec(ctx, query, garminUserID); err != nil {
+		return fmt.Errorf("failed to delete garmin connection: %w", err)
+	}
+	return nil
+}
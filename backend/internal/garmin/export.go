@@ -0,0 +1,352 @@
+package garmin
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Garmin Connect data export ("Export Your Data") file kinds we understand.
+// Files are JSON arrays of camelCase records, one per night, day or activity.
+const (
+	exportFileUnknown    = ""
+	exportFileSleep      = "sleep"      // DI-Connect-Wellness/*_sleepData.json
+	exportFileWellness   = "wellness"   // DI-Connect-Aggregator/UDSFile_*.json
+	exportFileHRV        = "hrv"        // DI-Connect-Wellness/*hrv*.json
+	exportFileActivities = "activities" // DI-Connect-Fitness/*_summarizedActivities.json
+)
+
+// exportTimeLayouts are the timestamp formats used inside export files.
+var exportTimeLayouts = []string{
+	"2006-01-02T15:04:05.0",
+	"2006-01-02T15:04:05",
+	time.RFC3339,
+}
+
+// IsExportDataFile reports whether a file inside a Garmin export archive
+// carries data we import.
+func IsExportDataFile(name string) bool {
+	return classifyExportFile(name) != exportFileUnknown
+}
+
+func classifyExportFile(name string) string {
+	base := path.Base(name)
+	if !strings.HasSuffix(strings.ToLower(base), ".json") {
+		return exportFileUnknown
+	}
+
+	switch {
+	case strings.HasSuffix(base, "_sleepData.json"):
+		return exportFileSleep
+	case strings.HasPrefix(base, "UDSFile_"):
+		return exportFileWellness
+	case strings.HasSuffix(base, "_summarizedActivities.json"):
+		return exportFileActivities
+	case strings.Contains(strings.ToLower(base), "hrv"):
+		return exportFileHRV
+	default:
+		return exportFileUnknown
+	}
+}
+
+// EventsFromExportFile maps one JSON file from a Garmin Connect export onto
+// the ingestion payloads and runs them through the same validation and
+// transforms as live ingestion. Records that fail validation are skipped and
+// counted rather than failing the whole file.
+func EventsFromExportFile(userID, name string, data []byte) ([]*models.Event, int, error) {
+	kind := classifyExportFile(name)
+	if kind == exportFileUnknown {
+		return nil, 0, fmt.Errorf("unsupported export file: %s", name)
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	var events []*models.Event
+	skipped := 0
+
+	for _, record := range exportRecords(raw) {
+		var recordEvents []*models.Event
+		var err error
+
+		switch kind {
+		case exportFileSleep:
+			recordEvents, err = sleepEventsFromExport(userID, record)
+		case exportFileWellness:
+			recordEvents, err = wellnessEventsFromExport(userID, record)
+		case exportFileHRV:
+			recordEvents, err = hrvEventsFromExport(userID, record)
+		case exportFileActivities:
+			recordEvents, err = activityEventsFromExport(userID, record)
+		}
+
+		if err != nil {
+			skipped++
+			continue
+		}
+		events = append(events, recordEvents...)
+	}
+
+	return events, skipped, nil
+}
+
+// exportRecords flattens an export file into its records. Activity files wrap
+// their records as [{"summarizedActivitiesExport": [...]}].
+func exportRecords(raw interface{}) []map[string]interface{} {
+	var records []map[string]interface{}
+
+	var collect func(v interface{})
+	collect = func(v interface{}) {
+		switch val := v.(type) {
+		case []interface{}:
+			for _, item := range val {
+				collect(item)
+			}
+		case map[string]interface{}:
+			if nested, ok := val["summarizedActivitiesExport"]; ok {
+				collect(nested)
+				return
+			}
+			records = append(records, val)
+		}
+	}
+	collect(raw)
+
+	return records
+}
+
+func sleepEventsFromExport(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	deep := getFloat64Value(record, "deepSleepSeconds")
+	light := getFloat64Value(record, "lightSleepSeconds")
+	rem := getFloat64Value(record, "remSleepSeconds")
+
+	sleepData := map[string]interface{}{
+		"sleep_time_seconds":  deep + light + rem,
+		"deep_sleep_seconds":  deep,
+		"light_sleep_seconds": light,
+		"rem_sleep_seconds":   rem,
+		"awake_seconds":       getFloat64Value(record, "awakeSleepSeconds"),
+	}
+	if scores, ok := record["sleepScores"].(map[string]interface{}); ok {
+		sleepData["sleep_scores"] = map[string]interface{}{
+			"overall_score": getFloat64Value(scores, "overallScore"),
+		}
+	}
+	if start, ok := exportTime(record["sleepStartTimestampGMT"]); ok {
+		sleepData["sleep_start_timestamp_gmt"] = start.Format(time.RFC3339)
+	}
+	if end, ok := exportTime(record["sleepEndTimestampGMT"]); ok {
+		sleepData["sleep_end_timestamp_gmt"] = end.Format(time.RFC3339)
+	}
+
+	payload := &SleepPayload{
+		UserID:    userID,
+		Date:      exportDate(record),
+		SleepData: sleepData,
+	}
+	if err := ValidateSleepPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformSleepToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+// wellnessEventsFromExport maps a daily UDS summary onto daily stats, plus
+// stress and body battery when the day has them.
+func wellnessEventsFromExport(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	date := exportDate(record)
+
+	statsPayload := &DailyStatsPayload{
+		UserID: userID,
+		Date:   date,
+		DailyStatsData: map[string]interface{}{
+			"steps":                      getFloat64Value(record, "totalSteps"),
+			"calories":                   getFloat64Value(record, "totalKilocalories"),
+			"distance_meters":            getFloat64Value(record, "totalDistanceMeters"),
+			"active_calories":            getFloat64Value(record, "activeKilocalories"),
+			"bmr_calories":               getFloat64Value(record, "bmrKilocalories"),
+			"min_heart_rate":             getFloat64Value(record, "minHeartRate"),
+			"max_heart_rate":             getFloat64Value(record, "maxHeartRate"),
+			"resting_heart_rate":         getFloat64Value(record, "restingHeartRate"),
+			"moderate_intensity_minutes": getFloat64Value(record, "moderateIntensityMinutes"),
+			"vigorous_intensity_minutes": getFloat64Value(record, "vigorousIntensityMinutes"),
+		},
+	}
+	if err := ValidateDailyStatsPayload(statsPayload); err != nil {
+		return nil, err
+	}
+	statsEvent, err := transformDailyStatsToEvent(statsPayload)
+	if err != nil {
+		return nil, err
+	}
+	events := []*models.Event{statsEvent}
+
+	if stress := exportStressTotals(record); stress != nil {
+		stressPayload := &StressPayload{
+			UserID: userID,
+			Date:   date,
+			StressData: map[string]interface{}{
+				"average_stress_level": getFloat64Value(stress, "averageStressLevel"),
+				"max_stress_level":     getFloat64Value(stress, "maxStressLevel"),
+				"rest_stress_duration": getFloat64Value(stress, "restDuration"),
+			},
+		}
+		if ValidateStressPayload(stressPayload) == nil {
+			if event, err := transformStressToEvent(stressPayload); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	if battery, ok := record["bodyBattery"].(map[string]interface{}); ok {
+		batteryData := map[string]interface{}{
+			"charged": getFloat64Value(battery, "chargedValue"),
+			"drained": getFloat64Value(battery, "drainedValue"),
+		}
+		if stats, ok := battery["bodyBatteryStatList"].([]interface{}); ok {
+			for _, entry := range stats {
+				stat, ok := entry.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				switch getStringValue(stat, "bodyBatteryStatType") {
+				case "HIGHEST":
+					batteryData["highest_value"] = getFloat64Value(stat, "statsValue")
+				case "LOWEST":
+					batteryData["lowest_value"] = getFloat64Value(stat, "statsValue")
+				}
+			}
+		}
+
+		batteryPayload := &BodyBatteryPayload{UserID: userID, Date: date, BodyBatteryData: batteryData}
+		if ValidateBodyBatteryPayload(batteryPayload) == nil {
+			if event, err := transformBodyBatteryToEvent(batteryPayload); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+// exportStressTotals returns the whole-day stress aggregate, which the export
+// stores as the TOTAL entry of allDayStress.aggregatorList.
+func exportStressTotals(record map[string]interface{}) map[string]interface{} {
+	allDay, ok := record["allDayStress"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	aggregators, ok := allDay["aggregatorList"].([]interface{})
+	if !ok {
+		return nil
+	}
+	for _, entry := range aggregators {
+		if agg, ok := entry.(map[string]interface{}); ok && getStringValue(agg, "type") == "TOTAL" {
+			return agg
+		}
+	}
+	return nil
+}
+
+func hrvEventsFromExport(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	summary := record
+	if nested, ok := record["hrvSummary"].(map[string]interface{}); ok {
+		summary = nested
+	}
+
+	hrvData := healthHRVData(summary)
+
+	date := exportDate(summary)
+	if date == "" {
+		date = exportDate(record)
+	}
+
+	payload := &HRVPayload{UserID: userID, Date: date, HRVData: hrvData}
+	if err := ValidateHRVPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformHRVToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+// activityEventsFromExport maps a summarized activity. The export stores
+// duration in milliseconds and distance in centimeters.
+func activityEventsFromExport(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	start, ok := exportTime(record["startTimeGmt"])
+	if !ok {
+		return nil, fmt.Errorf("activity has no startTimeGmt")
+	}
+
+	date := start.Format("2006-01-02")
+	if local, ok := exportTime(record["startTimeLocal"]); ok {
+		date = local.Format("2006-01-02")
+	}
+
+	payload := &ActivityPayload{
+		UserID: userID,
+		Date:   date,
+		ActivityData: map[string]interface{}{
+			"activity_type":      getStringValue(record, "activityType"),
+			"start_time_gmt":     start.Format(time.RFC3339),
+			"duration_seconds":   getFloat64Value(record, "duration") / 1000,
+			"distance_meters":    getFloat64Value(record, "distance") / 100,
+			"calories":           getFloat64Value(record, "calories"),
+			"average_heart_rate": getFloat64Value(record, "avgHr"),
+			"max_heart_rate":     getFloat64Value(record, "maxHr"),
+		},
+	}
+	if err := ValidateActivityPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformActivityToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+// exportDate returns a record's calendarDate, which is either a string or
+// {"date": "..."} depending on the export version.
+func exportDate(record map[string]interface{}) string {
+	switch v := record["calendarDate"].(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		return getStringValue(v, "date")
+	default:
+		return ""
+	}
+}
+
+// exportTime parses a GMT timestamp that is either a layout string or epoch milliseconds.
+func exportTime(v interface{}) (time.Time, bool) {
+	switch val := v.(type) {
+	case float64:
+		if val <= 0 {
+			return time.Time{}, false
+		}
+		return time.UnixMilli(int64(val)).UTC(), true
+	case string:
+		for _, layout := range exportTimeLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t.UTC(), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
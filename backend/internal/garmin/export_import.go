@@ -0,0 +1,114 @@
+package garmin
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+)
+
+// maxExportFileBytes bounds a single JSON file read from an export archive.
+const maxExportFileBytes = 256 << 20
+
+// ExportProcessor imports Garmin Connect export archives as importer jobs.
+type ExportProcessor struct {
+	eventRepo  db.EventStore
+	quarantine quarantine.Store
+}
+
+// NewExportProcessor creates a new ExportProcessor.
+func NewExportProcessor(eventRepo db.EventStore, quarantineRepo quarantine.Store) *ExportProcessor {
+	return &ExportProcessor{eventRepo: eventRepo, quarantine: quarantineRepo}
+}
+
+// Process imports every supported file in the archive, one file per progress
+// checkpoint. Files are visited in name order so a resumed job skips exactly
+// the files it already finished.
+func (p *ExportProcessor) Process(ctx context.Context, job *importer.Job, report func(importer.Progress) error) error {
+	archive, err := zip.OpenReader(job.FilePath)
+	if err != nil {
+		return fmt.Errorf("failed to open export archive: %w", err)
+	}
+	defer archive.Close()
+
+	var files []*zip.File
+	for _, f := range archive.File {
+		if !f.FileInfo().IsDir() && IsExportDataFile(f.Name) {
+			files = append(files, f)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+
+	progress := importer.Progress{
+		TotalItems:     len(files),
+		ProcessedItems: job.ProcessedItems,
+		EventsImported: job.EventsImported,
+		SkippedRecords: job.SkippedRecords,
+	}
+	if err := report(progress); err != nil {
+		return err
+	}
+
+	for i := job.ProcessedItems; i < len(files); i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		f := files[i]
+		data, err := readZipFile(f)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+
+		events, skipped, err := EventsFromExportFile(job.UserID, f.Name, data)
+		if err != nil {
+			// A malformed file shouldn't sink the rest of the export
+			log.Printf("Skipping export file %s: %v", f.Name, err)
+			skipped++
+		}
+
+		// Quarantined records count as skipped; they can be released after review
+		events, held, err := p.quarantine.Screen(ctx, events)
+		if err != nil {
+			return fmt.Errorf("failed to screen events from %s: %w", f.Name, err)
+		}
+		skipped += held
+
+		inserted, err := p.eventRepo.InsertEvents(ctx, events)
+		if err != nil {
+			return fmt.Errorf("failed to store events from %s: %w", f.Name, err)
+		}
+
+		progress.ProcessedItems = i + 1
+		progress.EventsImported += inserted
+		progress.SkippedRecords += skipped
+		if err := report(progress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxExportFileBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxExportFileBytes {
+		return nil, fmt.Errorf("file exceeds %d bytes", maxExportFileBytes)
+	}
+	return data, nil
+}
@@ -0,0 +1,154 @@
+package garmin
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestIsExportDataFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"DI_CONNECT/DI-Connect-Wellness/2024-01-01_2024-04-10_123_sleepData.json", true},
+		{"DI_CONNECT/DI-Connect-Aggregator/UDSFile_2024-01-01_2024-04-10.json", true},
+		{"DI_CONNECT/DI-Connect-Fitness/user@example.com_0_summarizedActivities.json", true},
+		{"DI_CONNECT/DI-Connect-Wellness/123_hrvStatus.json", true},
+		{"DI_CONNECT/DI-Connect-Uploaded-Files/UploadedFiles_0-_Part1.zip", false},
+		{"DI_CONNECT/DI-Connect-User/user_profile.json", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsExportDataFile(tt.name); got != tt.want {
+				t.Errorf("IsExportDataFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventsFromExportFile(t *testing.T) {
+	userID := "123e4567-e89b-12d3-a456-426614174000"
+
+	tests := []struct {
+		name        string
+		file        string
+		data        string
+		wantTypes   []string
+		wantSkipped int
+		check       func(t *testing.T, events []*models.Event)
+	}{
+		{
+			name: "sleep",
+			file: "DI-Connect-Wellness/2024_sleepData.json",
+			data: `[
+				{"calendarDate": "2024-03-02", "sleepStartTimestampGMT": "2024-03-01T22:30:00.0",
+				 "sleepEndTimestampGMT": "2024-03-02T06:30:00.0", "deepSleepSeconds": 5400,
+				 "lightSleepSeconds": 14400, "remSleepSeconds": 7200, "awakeSleepSeconds": 1800,
+				 "sleepScores": {"overallScore": 84}},
+				{"calendarDate": "2024-03-03", "deepSleepSeconds": 0}
+			]`,
+			wantTypes:   []string{models.EventTypeGarminSleep},
+			wantSkipped: 1,
+			check: func(t *testing.T, events []*models.Event) {
+				var s models.GarminSleep
+				json.Unmarshal(events[0].Data, &s)
+				if s.DurationMinutes != 450 || s.DeepSleepMinutes != 90 || s.SleepScore != 84 {
+					t.Errorf("sleep = %+v", s)
+				}
+				wantEnd := time.Date(2024, 3, 2, 6, 30, 0, 0, time.UTC)
+				if !events[0].Time.Equal(wantEnd) {
+					t.Errorf("event time = %v, want %v", events[0].Time, wantEnd)
+				}
+			},
+		},
+		{
+			name: "wellness with stress and body battery",
+			file: "DI-Connect-Aggregator/UDSFile_2024-03-01_2024-06-09.json",
+			data: `[{
+				"calendarDate": "2024-03-02", "totalSteps": 10432, "totalKilocalories": 2450,
+				"restingHeartRate": 52, "totalDistanceMeters": 8100,
+				"allDayStress": {"aggregatorList": [
+					{"type": "AWAKE", "averageStressLevel": 40},
+					{"type": "TOTAL", "averageStressLevel": 31, "maxStressLevel": 88, "restDuration": 21000}
+				]},
+				"bodyBattery": {"chargedValue": 60, "drainedValue": 55, "bodyBatteryStatList": [
+					{"bodyBatteryStatType": "HIGHEST", "statsValue": 92},
+					{"bodyBatteryStatType": "LOWEST", "statsValue": 18}
+				]}
+			}]`,
+			wantTypes: []string{models.EventTypeGarminDailyStats, models.EventTypeGarminStress, models.EventTypeGarminBodyBattery},
+			check: func(t *testing.T, events []*models.Event) {
+				var stats models.GarminDailyStats
+				json.Unmarshal(events[0].Data, &stats)
+				if stats.Steps != 10432 || stats.RestingHeartRate != 52 {
+					t.Errorf("daily stats = %+v", stats)
+				}
+				var stress map[string]float64
+				json.Unmarshal(events[1].Data, &stress)
+				if stress["average_stress_level"] != 31 {
+					t.Errorf("stress = %+v", stress)
+				}
+				var battery models.GarminBodyBattery
+				json.Unmarshal(events[2].Data, &battery)
+				if battery.HighestValue != 92 || battery.LowestValue != 18 {
+					t.Errorf("body battery = %+v", battery)
+				}
+			},
+		},
+		{
+			name:      "hrv",
+			file:      "DI-Connect-Wellness/123_hrvStatus.json",
+			data:      `[{"hrvSummary": {"calendarDate": "2024-03-02", "lastNightAvg": 48, "lastNight5MinHigh": 71}}]`,
+			wantTypes: []string{models.EventTypeGarminHRV},
+		},
+		{
+			name: "activities",
+			file: "DI-Connect-Fitness/me_0_summarizedActivities.json",
+			data: `[{"summarizedActivitiesExport": [
+				{"activityType": "running", "startTimeGmt": 1709366400000, "duration": 1800000,
+				 "distance": 500000, "calories": 410, "avgHr": 148, "maxHr": 171}
+			]}]`,
+			wantTypes: []string{models.EventTypeGarminActivity},
+			check: func(t *testing.T, events []*models.Event) {
+				var a models.GarminActivity
+				json.Unmarshal(events[0].Data, &a)
+				if a.DurationMinutes != 30 || a.Distance != 5000 || a.AvgHR != 148 {
+					t.Errorf("activity = %+v", a)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, skipped, err := EventsFromExportFile(userID, tt.file, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("EventsFromExportFile() error = %v", err)
+			}
+			if skipped != tt.wantSkipped {
+				t.Errorf("skipped = %d, want %d", skipped, tt.wantSkipped)
+			}
+			if len(events) != len(tt.wantTypes) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.wantTypes))
+			}
+			for i, event := range events {
+				if event.EventType != tt.wantTypes[i] || event.UserID != userID {
+					t.Errorf("event %d = %s for %s", i, event.EventType, event.UserID)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, events)
+			}
+		})
+	}
+}
+
+func TestEventsFromExportFile_InvalidJSON(t *testing.T) {
+	if _, _, err := EventsFromExportFile("u", "x_sleepData.json", []byte("{not json")); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
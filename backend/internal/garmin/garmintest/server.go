@@ -0,0 +1,182 @@
+// Package garmintest provides a fake Garmin Health API for tests and local
+// development: the OAuth 1.0a connect flow, the user endpoints and summary pulls.
+package garmintest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+)
+
+// Credentials the fake API issues and accepts.
+const (
+	RequestToken       = "test-request-token"
+	RequestTokenSecret = "test-request-secret"
+	Verifier           = "test-verifier"
+	AccessToken        = "test-access-token"
+	AccessTokenSecret  = "test-access-secret"
+	UserID             = "test-garmin-user"
+)
+
+// Fixture holds the summaries served by the fake API, keyed by summary type
+// (dailies, sleeps, hrv, activities).
+//
+//go:embed fixtures.json
+var Fixture []byte
+
+// Server is a fake Garmin Health API serving Fixture.
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts a fake Garmin Health API that accepts consumerKey.
+func NewServer(consumerKey string) *Server {
+	return &Server{Server: httptest.NewServer(Handler(consumerKey))}
+}
+
+// Ping returns a ping notification body for summaryType whose callbackURL
+// points back at this server.
+func (s *Server) Ping(summaryType string) []byte {
+	return PingBody(s.URL, summaryType)
+}
+
+// PingBody returns a ping notification for summaryType with a callbackURL on baseURL.
+func PingBody(baseURL, summaryType string) []byte {
+	body, _ := json.Marshal(map[string]interface{}{
+		summaryType: []map[string]interface{}{{
+			"userId":                   UserID,
+			"userAccessToken":          AccessToken,
+			"uploadStartTimeInSeconds": 1772323200,
+			"uploadEndTimeInSeconds":   1772409600,
+			"callbackURL": fmt.Sprintf("%s/wellness-api/rest/%s?uploadStartTimeInSeconds=1772323200&uploadEndTimeInSeconds=1772409600",
+				baseURL, summaryType),
+		}},
+	})
+	return body
+}
+
+// Handler returns the fake API's handler, for serving it outside tests.
+// Requests must carry an OAuth header with consumerKey and, past the request
+// token step, the token the previous step issued. Signatures aren't verified.
+func Handler(consumerKey string) http.Handler {
+	var summaries map[string]json.RawMessage
+	if err := json.Unmarshal(Fixture, &summaries); err != nil {
+		panic("garmintest: invalid fixture: " + err.Error())
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/oauth-service/oauth/request_token", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := authorize(w, r, consumerKey, "")
+		if !ok {
+			return
+		}
+		if params["oauth_callback"] == "" {
+			http.Error(w, "oauth_callback is required", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "oauth_token=%s&oauth_token_secret=%s&oauth_callback_confirmed=true", RequestToken, RequestTokenSecret)
+	})
+
+	// The consent page approves immediately and sends the browser back
+	mux.HandleFunc("/oauthConfirm", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("oauth_token") != RequestToken {
+			http.Error(w, "unknown oauth_token", http.StatusBadRequest)
+			return
+		}
+		callback, err := url.Parse(r.URL.Query().Get("oauth_callback"))
+		if err != nil || callback.Scheme == "" {
+			http.Error(w, "invalid oauth_callback", http.StatusBadRequest)
+			return
+		}
+		query := callback.Query()
+		query.Set("oauth_token", RequestToken)
+		query.Set("oauth_verifier", Verifier)
+		callback.RawQuery = query.Encode()
+		http.Redirect(w, r, callback.String(), http.StatusFound)
+	})
+
+	mux.HandleFunc("/oauth-service/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		params, ok := authorize(w, r, consumerKey, RequestToken)
+		if !ok {
+			return
+		}
+		if params["oauth_verifier"] != Verifier {
+			http.Error(w, "invalid oauth_verifier", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, "oauth_token=%s&oauth_token_secret=%s", AccessToken, AccessTokenSecret)
+	})
+
+	mux.HandleFunc("/wellness-api/rest/user/id", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorize(w, r, consumerKey, AccessToken); !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"userId":%q}`, UserID)
+	})
+
+	mux.HandleFunc("/wellness-api/rest/user/registration", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if _, ok := authorize(w, r, consumerKey, AccessToken); !ok {
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("/wellness-api/rest/{summaryType}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := authorize(w, r, consumerKey, AccessToken); !ok {
+			return
+		}
+		data, ok := summaries[r.PathValue("summaryType")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+
+	return mux
+}
+
+// authorize checks the OAuth header's consumer key and token, writing a 401
+// when they don't match.
+func authorize(w http.ResponseWriter, r *http.Request, consumerKey, token string) (map[string]string, bool) {
+	params := parseAuthorization(r.Header.Get("Authorization"))
+	if params == nil || params["oauth_consumer_key"] != consumerKey || params["oauth_signature"] == "" ||
+		params["oauth_signature_method"] != "HMAC-SHA1" || params["oauth_token"] != token {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return params, true
+}
+
+// parseAuthorization parses `OAuth k="v", ...` into its decoded parameters.
+func parseAuthorization(header string) map[string]string {
+	rest, ok := strings.CutPrefix(header, "OAuth ")
+	if !ok {
+		return nil
+	}
+
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return nil
+		}
+		decoded, err := url.PathUnescape(strings.Trim(value, `"`))
+		if err != nil {
+			return nil
+		}
+		params[key] = decoded
+	}
+	return params
+}
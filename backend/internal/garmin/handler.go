@@ -1,24 +1,87 @@
 package garmin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
 	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quality"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
 )
 
 // Handler handles Garmin data ingestion endpoints.
 type Handler struct {
-	eventRepo *db.EventRepository
+	eventRepo  db.EventStore
+	quarantine quarantine.Store
 }
 
 // NewHandler creates a new garmin Handler.
-func NewHandler(eventRepo *db.EventRepository) *Handler {
-	return &Handler{eventRepo: eventRepo}
+func NewHandler(eventRepo db.EventStore, quarantineRepo quarantine.Store) *Handler {
+	return &Handler{eventRepo: eventRepo, quarantine: quarantineRepo}
+}
+
+// ingestUser returns the user WithIngestAuth authenticated the request as:
+// the ingest token's user, or for a signed request the user it named, which
+// its signing key is bound to. That user takes precedence over the
+// payload's user_id, which is only kept outside the middleware.
+func ingestUser(r *http.Request, payloadUserID string) string {
+	if userID := middleware.UserIDFromContext(r.Context()); userID != "" {
+		return userID
+	}
+	return payloadUserID
+}
+
+// reject quarantines a payload that failed validation so it can be fixed
+// and replayed. The caller still answers 400: the sender's data was wrong.
+func (h *Handler) reject(ctx context.Context, dataType, userID string, payload interface{}, reason error) {
+	quarantine.Observe(dataType, true)
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode rejected %s payload: %v", dataType, err)
+		return
+	}
+	if _, err := h.quarantine.Reject(ctx, dataType, models.SourceGarmin, userID, raw, reason.Error()); err != nil {
+		log.Printf("Failed to quarantine rejected %s payload: %v", dataType, err)
+	}
+}
+
+// hold checks event for implausible values. An implausible event is
+// quarantined for review rather than stored, and hold writes a 202 saying so
+// and returns true.
+func (h *Handler) hold(w http.ResponseWriter, r *http.Request, dataType string, event *models.Event) bool {
+	reasons := quality.Check(event)
+	quarantine.Observe(dataType, len(reasons) > 0)
+	if len(reasons) == 0 {
+		return false
+	}
+
+	id, err := h.quarantine.Hold(r.Context(), event, reasons)
+	if err != nil {
+		log.Printf("Failed to quarantine %s event: %v", event.EventType, err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return true
+	}
+
+	log.Printf("Quarantined %s event for user %s: %v", event.EventType, event.UserID, reasons)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":        "quarantined",
+		"quarantine_id": id,
+		"reasons":       reasons,
+	})
+	return true
 }
 
 // HandleSleepIngestion handles POST /api/v1/garmin/ingest/sleep
@@ -34,9 +97,11 @@ func (h *Handler) HandleSleepIngestion(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateSleepPayload(&payload); err != nil {
 		log.Printf("Sleep payload validation failed: %v", err)
+		h.reject(r.Context(), DataTypeSleep, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -48,6 +113,27 @@ func (h *Handler) HandleSleepIngestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	hypnogramEvent, err := transformHypnogramToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform hypnogram: %v", err)
+		http.Error(w, fmt.Sprintf("Transformation error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// The stage timeline is stored even when the summary is held, as the
+	// Health API path does, so releasing the summary restores the whole night
+	if hypnogramEvent != nil {
+		if _, err := h.eventRepo.InsertEvent(r.Context(), hypnogramEvent); err != nil {
+			log.Printf("Failed to insert hypnogram event: %v", err)
+			http.Error(w, "Failed to store event", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if h.hold(w, r, DataTypeSleep, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to insert sleep event: %v", err)
@@ -83,9 +169,11 @@ func (h *Handler) HandleActivityIngestion(w http.ResponseWriter, r *http.Request
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateActivityPayload(&payload); err != nil {
 		log.Printf("Activity payload validation failed: %v", err)
+		h.reject(r.Context(), DataTypeActivity, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -97,6 +185,10 @@ func (h *Handler) HandleActivityIngestion(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if h.hold(w, r, DataTypeActivity, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to insert activity event: %v", err)
@@ -132,9 +224,11 @@ func (h *Handler) HandleHRVIngestion(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateHRVPayload(&payload); err != nil {
 		log.Printf("HRV payload validation failed: %v", err)
+		h.reject(r.Context(), DataTypeHRV, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -146,6 +240,10 @@ func (h *Handler) HandleHRVIngestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if h.hold(w, r, DataTypeHRV, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to insert HRV event: %v", err)
@@ -181,9 +279,11 @@ func (h *Handler) HandleStressIngestion(w http.ResponseWriter, r *http.Request)
 		http.Error(w, fmt.Sprintf("Invalid JSON: %v", err), http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateStressPayload(&payload); err != nil {
 		log.Printf("Stress payload validation failed: %v", err)
+		h.reject(r.Context(), DataTypeStress, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -195,6 +295,10 @@ func (h *Handler) HandleStressIngestion(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if h.hold(w, r, DataTypeStress, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to insert stress event: %v", err)
@@ -230,9 +334,11 @@ func (h *Handler) HandleDailyStatsIngestion(w http.ResponseWriter, r *http.Reque
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateDailyStatsPayload(&payload); err != nil {
 		log.Printf("Validation failed for daily stats: %v", err)
+		h.reject(r.Context(), DataTypeDailyStats, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -244,6 +350,10 @@ func (h *Handler) HandleDailyStatsIngestion(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if h.hold(w, r, DataTypeDailyStats, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to store daily stats: %v", err)
@@ -273,9 +383,11 @@ func (h *Handler) HandleBodyBatteryIngestion(w http.ResponseWriter, r *http.Requ
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
+	payload.UserID = ingestUser(r, payload.UserID)
 
 	if err := ValidateBodyBatteryPayload(&payload); err != nil {
 		log.Printf("Validation failed for body battery: %v", err)
+		h.reject(r.Context(), DataTypeBodyBattery, payload.UserID, &payload, err)
 		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
 		return
 	}
@@ -287,6 +399,10 @@ func (h *Handler) HandleBodyBatteryIngestion(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if h.hold(w, r, DataTypeBodyBattery, event) {
+		return
+	}
+
 	result, err := h.eventRepo.InsertEvent(r.Context(), event)
 	if err != nil {
 		log.Printf("Failed to store body battery: %v", err)
@@ -303,21 +419,305 @@ func (h *Handler) HandleBodyBatteryIngestion(w http.ResponseWriter, r *http.Requ
 	})
 }
 
-// Transform functions
+// HandleSpO2Ingestion handles POST /api/v1/garmin/ingest/spo2
+func (h *Handler) HandleSpO2Ingestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
 
-func transformSleepToEvent(payload *SleepPayload) (*models.Event, error) {
-	var eventTime time.Time
-	if endTimestamp, ok := payload.SleepData["sleep_end_timestamp_gmt"].(string); ok {
-		t, err := time.Parse(time.RFC3339, endTimestamp)
-		if err == nil {
-			eventTime = t
-		}
+	var payload SpO2Payload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in SpO2 request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
 	}
-	if eventTime.IsZero() {
-		t, _ := time.Parse("2006-01-02", payload.Date)
-		eventTime = t.Add(8 * time.Hour)
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateSpO2Payload(&payload); err != nil {
+		log.Printf("Validation failed for SpO2: %v", err)
+		h.reject(r.Context(), DataTypeSpO2, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformSpO2ToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform SpO2: %v", err)
+		http.Error(w, "Failed to process SpO2", http.StatusInternalServerError)
+		return
+	}
+
+	if h.hold(w, r, DataTypeSpO2, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store SpO2: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted SpO2 for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// HandleRespirationIngestion handles POST /api/v1/garmin/ingest/respiration
+func (h *Handler) HandleRespirationIngestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload RespirationPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in respiration request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateRespirationPayload(&payload); err != nil {
+		log.Printf("Validation failed for respiration: %v", err)
+		h.reject(r.Context(), DataTypeRespiration, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformRespirationToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform respiration: %v", err)
+		http.Error(w, "Failed to process respiration", http.StatusInternalServerError)
+		return
+	}
+
+	if h.hold(w, r, DataTypeRespiration, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store respiration: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted respiration for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// HandleTrainingReadinessIngestion handles POST /api/v1/garmin/ingest/training-readiness
+func (h *Handler) HandleTrainingReadinessIngestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload TrainingReadinessPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in training readiness request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateTrainingReadinessPayload(&payload); err != nil {
+		log.Printf("Validation failed for training readiness: %v", err)
+		h.reject(r.Context(), DataTypeTrainingReadiness, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformTrainingReadinessToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform training readiness: %v", err)
+		http.Error(w, "Failed to process training readiness", http.StatusInternalServerError)
+		return
+	}
+
+	if h.hold(w, r, DataTypeTrainingReadiness, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store training readiness: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted training readiness for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// HandleTrainingStatusIngestion handles POST /api/v1/garmin/ingest/training-status
+func (h *Handler) HandleTrainingStatusIngestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload TrainingStatusPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in training status request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateTrainingStatusPayload(&payload); err != nil {
+		log.Printf("Validation failed for training status: %v", err)
+		h.reject(r.Context(), DataTypeTrainingStatus, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformTrainingStatusToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform training status: %v", err)
+		http.Error(w, "Failed to process training status", http.StatusInternalServerError)
+		return
+	}
+
+	if h.hold(w, r, DataTypeTrainingStatus, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store training status: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted training status for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// HandleVO2MaxIngestion handles POST /api/v1/garmin/ingest/vo2max
+func (h *Handler) HandleVO2MaxIngestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload VO2MaxPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in VO2max request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateVO2MaxPayload(&payload); err != nil {
+		log.Printf("Validation failed for VO2max: %v", err)
+		h.reject(r.Context(), DataTypeVO2Max, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformVO2MaxToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform VO2max: %v", err)
+		http.Error(w, "Failed to process VO2max", http.StatusInternalServerError)
+		return
 	}
 
+	if h.hold(w, r, DataTypeVO2Max, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store VO2max: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted VO2max for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// HandleIntensityMinutesIngestion handles POST /api/v1/garmin/ingest/intensity-minutes
+func (h *Handler) HandleIntensityMinutesIngestion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var payload IntensityMinutesPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Printf("Invalid JSON in intensity minutes request: %v", err)
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+	payload.UserID = ingestUser(r, payload.UserID)
+
+	if err := ValidateIntensityMinutesPayload(&payload); err != nil {
+		log.Printf("Validation failed for intensity minutes: %v", err)
+		h.reject(r.Context(), DataTypeIntensityMinutes, payload.UserID, &payload, err)
+		http.Error(w, fmt.Sprintf("Validation error: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	event, err := transformIntensityMinutesToEvent(&payload)
+	if err != nil {
+		log.Printf("Failed to transform intensity minutes: %v", err)
+		http.Error(w, "Failed to process intensity minutes", http.StatusInternalServerError)
+		return
+	}
+
+	if h.hold(w, r, DataTypeIntensityMinutes, event) {
+		return
+	}
+
+	result, err := h.eventRepo.InsertEvent(r.Context(), event)
+	if err != nil {
+		log.Printf("Failed to store intensity minutes: %v", err)
+		http.Error(w, "Failed to store event", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully inserted intensity minutes for user %s on %s", payload.UserID, payload.Date)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":       "success",
+		"was_inserted": result.WasInserted,
+	})
+}
+
+// Transform functions
+
+func transformSleepToEvent(payload *SleepPayload) (*models.Event, error) {
+	eventTime := sleepEventTime(payload)
+
 	garminSleep := models.GarminSleep{
 		DurationMinutes:   int(getFloat64Value(payload.SleepData, "sleep_time_seconds") / 60),
 		DeepSleepMinutes:  int(getFloat64Value(payload.SleepData, "deep_sleep_seconds") / 60),
@@ -331,6 +731,16 @@ func transformSleepToEvent(payload *SleepPayload) (*models.Event, error) {
 		garminSleep.SleepScore = int(getFloat64Value(sleepScores, "overall_score"))
 	}
 
+	if start := getTimeValue(payload.SleepData, "sleep_start_timestamp_gmt"); !start.IsZero() {
+		garminSleep.SleepStart = &start
+	}
+	if end := getTimeValue(payload.SleepData, "sleep_end_timestamp_gmt"); !end.IsZero() {
+		garminSleep.SleepEnd = &end
+	}
+	if hypnogram := buildHypnogram(payload); hypnogram != nil {
+		sleep.ApplyMetrics(&garminSleep, hypnogram)
+	}
+
 	dataJSON, err := json.Marshal(garminSleep)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal sleep data: %w", err)
@@ -345,6 +755,84 @@ func transformSleepToEvent(payload *SleepPayload) (*models.Event, error) {
 	}, nil
 }
 
+// transformHypnogramToEvent builds the sleep stage timeline event for a night.
+// Returns nil when the payload carries no sleep_levels.
+func transformHypnogramToEvent(payload *SleepPayload) (*models.Event, error) {
+	hypnogram := buildHypnogram(payload)
+	if hypnogram == nil {
+		return nil, nil
+	}
+
+	dataJSON, err := json.Marshal(hypnogram)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hypnogram: %w", err)
+	}
+
+	return &models.Event{
+		Time:      sleepEventTime(payload),
+		UserID:    payload.UserID,
+		EventType: models.EventTypeSleepHypnogram,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+// sleepEventTime anchors sleep events at the sleep end timestamp, falling back to 08:00 on the date.
+func sleepEventTime(payload *SleepPayload) time.Time {
+	if end := getTimeValue(payload.SleepData, "sleep_end_timestamp_gmt"); !end.IsZero() {
+		return end
+	}
+	t, _ := time.Parse("2006-01-02", payload.Date)
+	return t.Add(8 * time.Hour)
+}
+
+func buildHypnogram(payload *SleepPayload) *models.SleepHypnogram {
+	levels, ok := payload.SleepData["sleep_levels"].([]interface{})
+	if !ok || len(levels) == 0 {
+		return nil
+	}
+
+	hypnogram := &models.SleepHypnogram{
+		Date:       payload.Date,
+		SleepStart: getTimeValue(payload.SleepData, "sleep_start_timestamp_gmt"),
+		SleepEnd:   getTimeValue(payload.SleepData, "sleep_end_timestamp_gmt"),
+		Stages:     make([]models.SleepStageInterval, 0, len(levels)),
+	}
+
+	for _, entry := range levels {
+		level, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		start := getTimeValue(level, "start_gmt")
+		end := getTimeValue(level, "end_gmt")
+		if start.IsZero() || end.IsZero() {
+			continue
+		}
+		hypnogram.Stages = append(hypnogram.Stages, models.SleepStageInterval{
+			Stage: getStringValue(level, "stage"),
+			Start: start,
+			End:   end,
+		})
+	}
+
+	if len(hypnogram.Stages) == 0 {
+		return nil
+	}
+
+	sort.Slice(hypnogram.Stages, func(i, j int) bool {
+		return hypnogram.Stages[i].Start.Before(hypnogram.Stages[j].Start)
+	})
+	if hypnogram.SleepStart.IsZero() {
+		hypnogram.SleepStart = hypnogram.Stages[0].Start
+	}
+	if hypnogram.SleepEnd.IsZero() {
+		hypnogram.SleepEnd = hypnogram.Stages[len(hypnogram.Stages)-1].End
+	}
+
+	return hypnogram
+}
+
 func transformActivityToEvent(payload *ActivityPayload) (*models.Event, error) {
 	var eventTime time.Time
 	if startTime, ok := payload.ActivityData["start_time_gmt"].(string); ok {
@@ -358,13 +846,18 @@ func transformActivityToEvent(payload *ActivityPayload) (*models.Event, error) {
 		eventTime = t.Add(12 * time.Hour)
 	}
 
+	distance, err := distanceMeters(payload.ActivityData)
+	if err != nil {
+		return nil, err
+	}
+
 	garminActivity := models.GarminActivity{
 		ActivityType:    getStringValue(payload.ActivityData, "activity_type"),
 		DurationMinutes: int(getFloat64Value(payload.ActivityData, "duration_seconds") / 60),
 		Calories:        int(getFloat64Value(payload.ActivityData, "calories")),
 		AvgHR:           int(getFloat64Value(payload.ActivityData, "average_heart_rate")),
 		MaxHR:           int(getFloat64Value(payload.ActivityData, "max_heart_rate")),
-		Distance:        getFloat64Value(payload.ActivityData, "distance_meters"),
+		Distance:        distance,
 	}
 
 	dataJSON, err := json.Marshal(garminActivity)
@@ -384,17 +877,27 @@ func transformActivityToEvent(payload *ActivityPayload) (*models.Event, error) {
 func transformHRVToEvent(payload *HRVPayload) (*models.Event, error) {
 	eventTime, _ := time.Parse("2006-01-02", payload.Date)
 
-	hrvData := map[string]interface{}{
-		"average_hrv": getFloat64Value(payload.HRVData, "average_hrv"),
+	lastNightAvg, _ := hrvAverage(payload.HRVData)
+	hrv := models.GarminHRV{
+		LastNightAvg:      lastNightAvg,
+		LastNight5MinHigh: getOptionalFloat64(payload.HRVData, "last_night_5min_high"),
+		WeeklyAvg:         getOptionalFloat64(payload.HRVData, "weekly_avg"),
+		Status:            getStringValue(payload.HRVData, "status"),
+		Min:               getOptionalFloat64(payload.HRVData, "min_hrv"),
 	}
-	if maxHRV, ok := payload.HRVData["max_hrv"]; ok {
-		hrvData["max_hrv"] = maxHRV
+	// Older schedulers sent the 5-minute high as max_hrv
+	if hrv.LastNight5MinHigh == nil {
+		hrv.LastNight5MinHigh = getOptionalFloat64(payload.HRVData, "max_hrv")
 	}
-	if minHRV, ok := payload.HRVData["min_hrv"]; ok {
-		hrvData["min_hrv"] = minHRV
+	if baseline, ok := payload.HRVData["baseline"].(map[string]interface{}); ok {
+		hrv.Baseline = &models.HRVBaseline{
+			LowUpper:      getFloat64Value(baseline, "low_upper"),
+			BalancedLow:   getFloat64Value(baseline, "balanced_low"),
+			BalancedUpper: getFloat64Value(baseline, "balanced_upper"),
+		}
 	}
 
-	dataJSON, err := json.Marshal(hrvData)
+	dataJSON, err := json.Marshal(hrv)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal HRV data: %w", err)
 	}
@@ -408,20 +911,24 @@ func transformHRVToEvent(payload *HRVPayload) (*models.Event, error) {
 	}, nil
 }
 
+// hrvAverage reads the overnight average, accepting the older average_hrv key.
+func hrvAverage(data map[string]interface{}) (float64, bool) {
+	if avg, ok := getFloat64(data, "last_night_avg"); ok {
+		return avg, true
+	}
+	return getFloat64(data, "average_hrv")
+}
+
 func transformStressToEvent(payload *StressPayload) (*models.Event, error) {
 	eventTime, _ := time.Parse("2006-01-02", payload.Date)
 
-	stressData := map[string]interface{}{
-		"average_stress_level": getFloat64Value(payload.StressData, "average_stress_level"),
-	}
-	if maxStress, ok := payload.StressData["max_stress_level"]; ok {
-		stressData["max_stress_level"] = maxStress
-	}
-	if restStress, ok := payload.StressData["rest_stress_duration"]; ok {
-		stressData["rest_stress_duration"] = restStress
+	stress := models.GarminStress{
+		AverageStressLevel: int(math.Round(getFloat64Value(payload.StressData, "average_stress_level"))),
+		MaxStressLevel:     getOptionalInt(payload.StressData, "max_stress_level"),
+		RestStressDuration: getOptionalInt(payload.StressData, "rest_stress_duration"),
 	}
 
-	dataJSON, err := json.Marshal(stressData)
+	dataJSON, err := json.Marshal(stress)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal stress data: %w", err)
 	}
@@ -438,10 +945,15 @@ func transformStressToEvent(payload *StressPayload) (*models.Event, error) {
 func transformDailyStatsToEvent(payload *DailyStatsPayload) (*models.Event, error) {
 	eventTime, _ := time.Parse("2006-01-02", payload.Date)
 
+	distance, err := distanceMeters(payload.DailyStatsData)
+	if err != nil {
+		return nil, err
+	}
+
 	dailyStats := models.GarminDailyStats{
 		Steps:                    int(getFloat64Value(payload.DailyStatsData, "steps")),
 		Calories:                 int(getFloat64Value(payload.DailyStatsData, "calories")),
-		DistanceMeters:           int(getFloat64Value(payload.DailyStatsData, "distance_meters")),
+		DistanceMeters:           int(distance),
 		ActiveCalories:           int(getFloat64Value(payload.DailyStatsData, "active_calories")),
 		BMRCalories:              int(getFloat64Value(payload.DailyStatsData, "bmr_calories")),
 		MinHeartRate:             int(getFloat64Value(payload.DailyStatsData, "min_heart_rate")),
@@ -489,6 +1001,158 @@ func transformBodyBatteryToEvent(payload *BodyBatteryPayload) (*models.Event, er
 	}, nil
 }
 
+func transformSpO2ToEvent(payload *SpO2Payload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	spo2 := models.SpO2{
+		AverageSpO2:      getFloat64Value(payload.SpO2Data, "average_spo2"),
+		LowestSpO2:       getOptionalFloat64(payload.SpO2Data, "lowest_spo2"),
+		AverageSleepSpO2: getOptionalFloat64(payload.SpO2Data, "average_sleep_spo2"),
+	}
+
+	dataJSON, err := json.Marshal(spo2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SpO2: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeSpO2,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+func transformRespirationToEvent(payload *RespirationPayload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	respiration := models.Respiration{
+		AverageWaking: getOptionalFloat64(payload.RespirationData, "average_waking"),
+		AverageSleep:  getOptionalFloat64(payload.RespirationData, "average_sleep"),
+		Lowest:        getOptionalFloat64(payload.RespirationData, "lowest"),
+		Highest:       getOptionalFloat64(payload.RespirationData, "highest"),
+	}
+
+	dataJSON, err := json.Marshal(respiration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal respiration: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeRespiration,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+// transformTrainingReadinessToEvent stores Garmin's training readiness as a
+// readiness event, so it competes with Oura readiness and Whoop recovery
+// under the user's source priorities.
+func transformTrainingReadinessToEvent(payload *TrainingReadinessPayload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	readiness := models.Readiness{
+		Score:               int(math.Round(getFloat64Value(payload.TrainingReadinessData, "score"))),
+		HRVAvg:              getFloat64Value(payload.TrainingReadinessData, "hrv_weekly_avg"),
+		Level:               getStringValue(payload.TrainingReadinessData, "level"),
+		RecoveryTimeMinutes: getOptionalInt(payload.TrainingReadinessData, "recovery_time_minutes"),
+	}
+
+	dataJSON, err := json.Marshal(readiness)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal training readiness: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeReadiness,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+func transformTrainingStatusToEvent(payload *TrainingStatusPayload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	status := models.TrainingStatus{
+		Status:            getStringValue(payload.TrainingStatusData, "status"),
+		AcuteLoad:         getOptionalFloat64(payload.TrainingStatusData, "acute_load"),
+		ChronicLoad:       getOptionalFloat64(payload.TrainingStatusData, "chronic_load"),
+		AcuteChronicRatio: getOptionalFloat64(payload.TrainingStatusData, "acute_chronic_ratio"),
+	}
+
+	dataJSON, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal training status: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeTrainingStatus,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+func transformVO2MaxToEvent(payload *VO2MaxPayload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	vo2max := models.VO2Max{
+		Running:    getOptionalFloat64(payload.VO2MaxData, "running"),
+		Cycling:    getOptionalFloat64(payload.VO2MaxData, "cycling"),
+		FitnessAge: getOptionalInt(payload.VO2MaxData, "fitness_age"),
+	}
+
+	dataJSON, err := json.Marshal(vo2max)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal VO2max: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeVO2Max,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
+func transformIntensityMinutesToEvent(payload *IntensityMinutesPayload) (*models.Event, error) {
+	eventTime, _ := time.Parse("2006-01-02", payload.Date)
+
+	moderate := int(math.Round(getFloat64Value(payload.IntensityMinutesData, "weekly_moderate")))
+	vigorous := int(math.Round(getFloat64Value(payload.IntensityMinutesData, "weekly_vigorous")))
+	total, ok := getFloat64(payload.IntensityMinutesData, "weekly_total")
+	if !ok {
+		total = float64(moderate + 2*vigorous)
+	}
+
+	minutes := models.IntensityMinutes{
+		WeeklyModerate: moderate,
+		WeeklyVigorous: vigorous,
+		WeeklyTotal:    int(math.Round(total)),
+		WeeklyGoal:     int(math.Round(getFloat64Value(payload.IntensityMinutesData, "weekly_goal"))),
+	}
+
+	dataJSON, err := json.Marshal(minutes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal intensity minutes: %w", err)
+	}
+
+	return &models.Event{
+		Time:      eventTime,
+		UserID:    payload.UserID,
+		EventType: models.EventTypeIntensityMinutes,
+		Source:    models.SourceGarmin,
+		Data:      dataJSON,
+	}, nil
+}
+
 func getFloat64Value(data map[string]interface{}, key string) float64 {
 	val, exists := data[key]
 	if !exists {
@@ -509,6 +1173,48 @@ func getFloat64Value(data map[string]interface{}, key string) float64 {
 	}
 }
 
+// distanceMeters reads distance_meters, or a distance in distance_unit
+// converted to meters.
+func distanceMeters(data map[string]interface{}) (float64, error) {
+	if unit := getStringValue(data, "distance_unit"); unit != "" {
+		meters, err := quality.Normalize(quality.MeasureDistance, getFloat64Value(data, "distance"), unit)
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert distance: %w", err)
+		}
+		return meters, nil
+	}
+	return getFloat64Value(data, "distance_meters"), nil
+}
+
+// getOptionalFloat64 returns nil when key is absent or not a number.
+func getOptionalFloat64(data map[string]interface{}, key string) *float64 {
+	if v, ok := getFloat64(data, key); ok {
+		return &v
+	}
+	return nil
+}
+
+// getOptionalInt returns nil when key is absent or not a number.
+func getOptionalInt(data map[string]interface{}, key string) *int {
+	if v, ok := getFloat64(data, key); ok {
+		i := int(math.Round(v))
+		return &i
+	}
+	return nil
+}
+
+func getTimeValue(data map[string]interface{}, key string) time.Time {
+	str, ok := data[key].(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 func getStringValue(data map[string]interface{}, key string) string {
 	if val, ok := data[key].(string); ok {
 		return val
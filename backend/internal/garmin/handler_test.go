@@ -0,0 +1,98 @@
+package garmin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestTransformIntensityMinutesToEvent(t *testing.T) {
+	payload := &IntensityMinutesPayload{
+		UserID: "00000000-0000-0000-0000-000000000001",
+		Date:   "2026-01-28",
+		IntensityMinutesData: map[string]interface{}{
+			"weekly_moderate": float64(90),
+			"weekly_vigorous": float64(25),
+			"weekly_goal":     float64(150),
+		},
+	}
+	if err := ValidateIntensityMinutesPayload(payload); err != nil {
+		t.Fatalf("ValidateIntensityMinutesPayload() error = %v", err)
+	}
+
+	event, err := transformIntensityMinutesToEvent(payload)
+	if err != nil {
+		t.Fatalf("transformIntensityMinutesToEvent() error = %v", err)
+	}
+
+	var got models.IntensityMinutes
+	if err := json.Unmarshal(event.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	// Vigorous minutes count double when Garmin doesn't send the total
+	want := models.IntensityMinutes{WeeklyModerate: 90, WeeklyVigorous: 25, WeeklyTotal: 140, WeeklyGoal: 150}
+	if got != want {
+		t.Errorf("intensity minutes = %+v, want %+v", got, want)
+	}
+	if event.EventType != models.EventTypeIntensityMinutes {
+		t.Errorf("event type = %q, want %q", event.EventType, models.EventTypeIntensityMinutes)
+	}
+}
+
+func TestTransformHRVToEvent_LegacyMaxHRV(t *testing.T) {
+	payload := &HRVPayload{
+		UserID:  "00000000-0000-0000-0000-000000000001",
+		Date:    "2026-01-28",
+		HRVData: map[string]interface{}{"average_hrv": float64(48), "max_hrv": float64(71)},
+	}
+
+	event, err := transformHRVToEvent(payload)
+	if err != nil {
+		t.Fatalf("transformHRVToEvent() error = %v", err)
+	}
+
+	var got models.GarminHRV
+	if err := json.Unmarshal(event.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	// max_hrv was Garmin's 5-minute high
+	if got.LastNightAvg != 48 || got.LastNight5MinHigh == nil || *got.LastNight5MinHigh != 71 {
+		t.Errorf("hrv = %+v, want average 48 and 5-minute high 71", got)
+	}
+}
+
+func TestDistanceMeters(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		want    float64
+		wantErr bool
+	}{
+		{name: "meters", data: map[string]interface{}{"distance_meters": float64(5000)}, want: 5000},
+		{name: "kilometers", data: map[string]interface{}{"distance": float64(5), "distance_unit": "km"}, want: 5000},
+		{name: "unknown unit", data: map[string]interface{}{"distance": float64(5), "distance_unit": "furlongs"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := distanceMeters(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("distanceMeters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("distanceMeters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// An unconverted distance is not stored as 0
+	payload := &ActivityPayload{
+		UserID:       "00000000-0000-0000-0000-000000000001",
+		Date:         "2026-01-28",
+		ActivityData: map[string]interface{}{"activity_type": "running", "distance": float64(5), "distance_unit": "furlongs"},
+	}
+	if _, err := transformActivityToEvent(payload); err == nil {
+		t.Error("transformActivityToEvent() succeeded with an unknown distance unit")
+	}
+}
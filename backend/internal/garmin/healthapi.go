@@ -0,0 +1,196 @@
+package garmin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/config"
+)
+
+// Garmin Health API paths, relative to the configured hosts.
+const (
+	requestTokenPath = "/oauth-service/oauth/request_token"
+	accessTokenPath  = "/oauth-service/oauth/access_token"
+	authorizePath    = "/oauthConfirm"
+	userIDPath       = "/wellness-api/rest/user/id"
+	registrationPath = "/wellness-api/rest/user/registration"
+	summaryPathRoot  = "/wellness-api/rest/"
+)
+
+// ErrHealthAPINotConfigured is returned when no consumer key is configured.
+var ErrHealthAPINotConfigured = errors.New("garmin health API consumer key is not configured")
+
+// OAuthToken is an OAuth 1.0a token and its secret.
+type OAuthToken struct {
+	Token  string `json:"token"`
+	Secret string `json:"secret"`
+}
+
+// HealthAPIClient talks to the Garmin Health API on behalf of connected users.
+type HealthAPIClient struct {
+	signer         *oauthSigner
+	oauthBaseURL   string
+	connectBaseURL string
+	apiBaseURL     string
+	httpClient     *http.Client
+}
+
+// NewHealthAPIClient creates a new HealthAPIClient from the Garmin config.
+func NewHealthAPIClient(cfg config.GarminConfig) *HealthAPIClient {
+	return &HealthAPIClient{
+		signer:         newOAuthSigner(cfg.ConsumerKey, cfg.ConsumerSecret),
+		oauthBaseURL:   strings.TrimRight(cfg.OAuthBaseURL, "/"),
+		connectBaseURL: strings.TrimRight(cfg.ConnectBaseURL, "/"),
+		apiBaseURL:     strings.TrimRight(cfg.APIBaseURL, "/"),
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Configured reports whether the client has consumer credentials.
+func (c *HealthAPIClient) Configured() bool {
+	return c.signer.consumerKey != "" && c.signer.consumerSecret != ""
+}
+
+// RequestToken starts the connect flow by obtaining an unauthorized request token.
+func (c *HealthAPIClient) RequestToken(ctx context.Context, callbackURL string) (*OAuthToken, error) {
+	extra := map[string]string{"oauth_callback": callbackURL}
+	return c.tokenRequest(ctx, requestTokenPath, "", "", extra)
+}
+
+// AuthorizeURL returns the Garmin Connect page where the user approves access.
+func (c *HealthAPIClient) AuthorizeURL(requestToken, callbackURL string) string {
+	params := url.Values{}
+	params.Set("oauth_token", requestToken)
+	params.Set("oauth_callback", callbackURL)
+	return c.connectBaseURL + authorizePath + "?" + params.Encode()
+}
+
+// AccessToken exchanges an authorized request token and its verifier for the
+// user's long-lived access token.
+func (c *HealthAPIClient) AccessToken(ctx context.Context, requestToken *OAuthToken, verifier string) (*OAuthToken, error) {
+	extra := map[string]string{"oauth_verifier": verifier}
+	return c.tokenRequest(ctx, accessTokenPath, requestToken.Token, requestToken.Secret, extra)
+}
+
+func (c *HealthAPIClient) tokenRequest(ctx context.Context, path, token, secret string, extra map[string]string) (*OAuthToken, error) {
+	if !c.Configured() {
+		return nil, ErrHealthAPINotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.oauthBaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.signer.sign(req, token, secret, extra)
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("garmin token request failed: %w", err)
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse garmin token response: %w", err)
+	}
+	result := &OAuthToken{Token: values.Get("oauth_token"), Secret: values.Get("oauth_token_secret")}
+	if result.Token == "" || result.Secret == "" {
+		return nil, errors.New("garmin token response is missing oauth_token")
+	}
+	return result, nil
+}
+
+// UserID returns the Garmin user ID behind an access token. Pings identify
+// users by this ID.
+func (c *HealthAPIClient) UserID(ctx context.Context, token *OAuthToken) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiBaseURL+userIDPath, nil)
+	if err != nil {
+		return "", err
+	}
+	c.signer.sign(req, token.Token, token.Secret, nil)
+
+	body, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch garmin user ID: %w", err)
+	}
+
+	var result struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.UserID == "" {
+		return "", errors.New("garmin user ID response is missing userId")
+	}
+	return result.UserID, nil
+}
+
+// Deregister revokes the user's access token so Garmin stops sending pings.
+func (c *HealthAPIClient) Deregister(ctx context.Context, token *OAuthToken) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.apiBaseURL+registrationPath, nil)
+	if err != nil {
+		return err
+	}
+	c.signer.sign(req, token.Token, token.Secret, nil)
+
+	if _, err := c.do(req); err != nil {
+		return fmt.Errorf("failed to deregister garmin user: %w", err)
+	}
+	return nil
+}
+
+// FetchSummaries pulls the summaries a ping refers to. The callback URL comes
+// from Garmin in the ping body, so it must point at the configured API host
+// before we sign a request to it with the user's token.
+func (c *HealthAPIClient) FetchSummaries(ctx context.Context, callbackURL string, token *OAuthToken) ([]map[string]interface{}, error) {
+	u, err := url.Parse(callbackURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid callback URL: %w", err)
+	}
+	api, _ := url.Parse(c.apiBaseURL)
+	if api == nil || u.Scheme != api.Scheme || u.Host != api.Host || !strings.HasPrefix(u.Path, summaryPathRoot) {
+		return nil, fmt.Errorf("callback URL %q is not a Garmin Health API URL", callbackURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.signer.sign(req, token.Token, token.Secret, nil)
+
+	body, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch garmin summaries: %w", err)
+	}
+
+	var summaries []map[string]interface{}
+	if err := json.Unmarshal(body, &summaries); err != nil {
+		return nil, fmt.Errorf("failed to parse garmin summaries: %w", err)
+	}
+	return summaries, nil
+}
+
+func (c *HealthAPIClient) do(req *http.Request) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet := body
+		if len(snippet) > 512 {
+			snippet = snippet[:512]
+		}
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(snippet)))
+	}
+	return body, nil
+}
@@ -0,0 +1,221 @@
+package garmin
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxNotificationBytes bounds a ping or push body. Push bodies carry full
+// summaries, so this is generous.
+const maxNotificationBytes = 16 << 20
+
+// HealthAPIHandler handles the Garmin Health API connect flow and webhook.
+type HealthAPIHandler struct {
+	client      *HealthAPIClient
+	conns       ConnectionStore
+	processor   *PushProcessor
+	callbackURL string
+}
+
+// NewHealthAPIHandler creates a new HealthAPIHandler. callbackURL is where
+// Garmin Connect sends the user after they approve access.
+func NewHealthAPIHandler(client *HealthAPIClient, conns ConnectionStore, processor *PushProcessor, callbackURL string) *HealthAPIHandler {
+	return &HealthAPIHandler{
+		client:      client,
+		conns:       conns,
+		processor:   processor,
+		callbackURL: callbackURL,
+	}
+}
+
+// HandleConnect handles POST /api/v1/garmin/connect
+// Returns the Garmin Connect URL the app should open for the user to approve access.
+func (h *HealthAPIHandler) HandleConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if !h.client.Configured() {
+		http.Error(w, "Garmin Health API is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	requestToken, err := h.client.RequestToken(r.Context(), h.callbackURL)
+	if err != nil {
+		log.Printf("Failed to get garmin request token: %v", err)
+		http.Error(w, "Failed to start Garmin connection", http.StatusBadGateway)
+		return
+	}
+
+	if err := h.conns.SaveRequestToken(r.Context(), userID, requestToken); err != nil {
+		log.Printf("Failed to save garmin request token: %v", err)
+		http.Error(w, "Failed to start Garmin connection", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":        "success",
+		"authorize_url": h.client.AuthorizeURL(requestToken.Token, h.callbackURL),
+	})
+}
+
+// HandleCallback handles GET /api/v1/garmin/callback?oauth_token=...&oauth_verifier=...
+// Garmin Connect redirects the user's browser here, so the user is identified
+// by the pending request token rather than a JWT.
+func (h *HealthAPIHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	oauthToken := r.URL.Query().Get("oauth_token")
+	verifier := r.URL.Query().Get("oauth_verifier")
+	if oauthToken == "" || verifier == "" {
+		http.Error(w, "oauth_token and oauth_verifier are required", http.StatusBadRequest)
+		return
+	}
+
+	userID, requestToken, err := h.conns.TakeRequestToken(r.Context(), oauthToken)
+	if errors.Is(err, ErrRequestTokenNotFound) {
+		http.Error(w, "Connection request expired, please try again", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("Failed to look up garmin request token: %v", err)
+		http.Error(w, "Failed to connect Garmin", http.StatusInternalServerError)
+		return
+	}
+
+	accessToken, err := h.client.AccessToken(r.Context(), requestToken, verifier)
+	if err != nil {
+		log.Printf("Failed to get garmin access token: %v", err)
+		http.Error(w, "Failed to connect Garmin", http.StatusBadGateway)
+		return
+	}
+
+	garminUserID, err := h.client.UserID(r.Context(), accessToken)
+	if err != nil {
+		log.Printf("Failed to get garmin user ID: %v", err)
+		http.Error(w, "Failed to connect Garmin", http.StatusBadGateway)
+		return
+	}
+
+	conn := &Connection{
+		UserID:       userID,
+		GarminUserID: garminUserID,
+		Token:        *accessToken,
+		ConnectedAt:  time.Now().UTC(),
+	}
+	if err := h.conns.SaveConnection(r.Context(), conn); err != nil {
+		log.Printf("Failed to save garmin connection: %v", err)
+		http.Error(w, "Failed to connect Garmin", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Connected garmin user %s to user %s", garminUserID, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "success",
+		"garmin_user_id": garminUserID,
+	})
+}
+
+// HandleConnection handles GET and DELETE /api/v1/garmin/connection
+// GET reports whether the user is connected; DELETE deregisters with Garmin
+// and forgets the access token.
+func (h *HealthAPIHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := h.conns.GetConnection(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to get garmin connection: %v", err)
+		http.Error(w, "Failed to get Garmin connection", http.StatusInternalServerError)
+		return
+	}
+
+	if r.Method == http.MethodDelete && conn != nil {
+		// Still forget the token locally if Garmin can't be reached
+		if err := h.client.Deregister(r.Context(), &conn.Token); err != nil {
+			log.Printf("Failed to deregister garmin user %s: %v", conn.GarminUserID, err)
+		}
+		if err := h.conns.DeleteConnection(r.Context(), userID); err != nil {
+			log.Printf("Failed to delete garmin connection: %v", err)
+			http.Error(w, "Failed to disconnect Garmin", http.StatusInternalServerError)
+			return
+		}
+		conn = nil
+	}
+
+	response := map[string]interface{}{
+		"status":    "success",
+		"connected": conn != nil,
+	}
+	if conn != nil {
+		response["garmin_user_id"] = conn.GarminUserID
+		response["connected_at"] = conn.ConnectedAt
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandlePush handles POST /api/v1/garmin/push
+// Garmin posts ping and push notifications here. Notifications are matched to
+// users by Garmin user ID and access token. Summaries are pulled before
+// responding; a failed pull returns 503 so Garmin retries the notification.
+func (h *HealthAPIHandler) HandlePush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var notification Notification
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNotificationBytes)).Decode(&notification); err != nil {
+		log.Printf("Failed to decode garmin notification: %v", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.processor.Process(r.Context(), notification)
+	if err != nil {
+		log.Printf("Failed to process garmin notification: %v", err)
+		http.Error(w, "Failed to process notification", http.StatusServiceUnavailable)
+		return
+	}
+
+	log.Printf("Garmin notification: %d summaries, %d new events, %d skipped",
+		result.Summaries, result.EventsInserted, result.Skipped)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"result": result,
+	})
+}
@@ -0,0 +1,312 @@
+package garmin
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Health API summary types, as keyed in ping and push notifications.
+const (
+	SummaryDailies    = "dailies"
+	SummarySleeps     = "sleeps"
+	SummaryHRV        = "hrv"
+	SummaryActivities = "activities"
+)
+
+// Notification types that carry no summaries.
+const (
+	notificationDeregistrations = "deregistrations"
+	notificationPermissions     = "userPermissionsChange"
+)
+
+// healthAPISleepLevels maps sleepLevelsMap keys onto our stage names.
+var healthAPISleepLevels = map[string]string{
+	"deep":  models.SleepStageDeep,
+	"light": models.SleepStageLight,
+	"rem":   models.SleepStageREM,
+	"awake": models.SleepStageAwake,
+}
+
+// IsSupportedSummary reports whether summaries of this type produce events.
+func IsSupportedSummary(summaryType string) bool {
+	switch summaryType {
+	case SummaryDailies, SummarySleeps, SummaryHRV, SummaryActivities:
+		return true
+	}
+	return false
+}
+
+// EventsFromHealthSummary maps one Health API summary onto the ingestion
+// payloads and runs it through the same validation and transforms as the
+// ingest endpoints. The Health API uses camelCase fields with durations in
+// seconds and timestamps in epoch seconds.
+func EventsFromHealthSummary(userID, summaryType string, record map[string]interface{}) ([]*models.Event, error) {
+	switch summaryType {
+	case SummaryDailies:
+		return dailyEventsFromHealthAPI(userID, record)
+	case SummarySleeps:
+		return sleepEventsFromHealthAPI(userID, record)
+	case SummaryHRV:
+		return hrvEventsFromHealthAPI(userID, record)
+	case SummaryActivities:
+		return activityEventsFromHealthAPI(userID, record)
+	default:
+		return nil, fmt.Errorf("unsupported summary type: %s", summaryType)
+	}
+}
+
+func dailyEventsFromHealthAPI(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	date := getStringValue(record, "calendarDate")
+	active := getFloat64Value(record, "activeKilocalories")
+	bmr := getFloat64Value(record, "bmrKilocalories")
+
+	statsPayload := &DailyStatsPayload{
+		UserID: userID,
+		Date:   date,
+		DailyStatsData: map[string]interface{}{
+			"steps":                      getFloat64Value(record, "steps"),
+			"calories":                   active + bmr,
+			"distance_meters":            getFloat64Value(record, "distanceInMeters"),
+			"active_calories":            active,
+			"bmr_calories":               bmr,
+			"min_heart_rate":             getFloat64Value(record, "minHeartRateInBeatsPerMinute"),
+			"max_heart_rate":             getFloat64Value(record, "maxHeartRateInBeatsPerMinute"),
+			"resting_heart_rate":         getFloat64Value(record, "restingHeartRateInBeatsPerMinute"),
+			"moderate_intensity_minutes": getFloat64Value(record, "moderateIntensityDurationInSeconds") / 60,
+			"vigorous_intensity_minutes": getFloat64Value(record, "vigorousIntensityDurationInSeconds") / 60,
+		},
+	}
+	if err := ValidateDailyStatsPayload(statsPayload); err != nil {
+		return nil, err
+	}
+	statsEvent, err := transformDailyStatsToEvent(statsPayload)
+	if err != nil {
+		return nil, err
+	}
+	events := []*models.Event{statsEvent}
+
+	// Garmin reports -1 when there wasn't enough data for a stress average
+	if avg, ok := getFloat64(record, "averageStressLevel"); ok && avg >= 0 {
+		stressPayload := &StressPayload{
+			UserID: userID,
+			Date:   date,
+			StressData: map[string]interface{}{
+				"average_stress_level": avg,
+				"max_stress_level":     getFloat64Value(record, "maxStressLevel"),
+				"rest_stress_duration": getFloat64Value(record, "restStressDurationInSeconds"),
+			},
+		}
+		if ValidateStressPayload(stressPayload) == nil {
+			if event, err := transformStressToEvent(stressPayload); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	charged, okCharged := getFloat64(record, "bodyBatteryChargedValue")
+	drained, okDrained := getFloat64(record, "bodyBatteryDrainedValue")
+	if okCharged || okDrained {
+		batteryPayload := &BodyBatteryPayload{
+			UserID: userID,
+			Date:   date,
+			BodyBatteryData: map[string]interface{}{
+				"charged": charged,
+				"drained": drained,
+			},
+		}
+		if ValidateBodyBatteryPayload(batteryPayload) == nil {
+			if event, err := transformBodyBatteryToEvent(batteryPayload); err == nil {
+				events = append(events, event)
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func sleepEventsFromHealthAPI(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	deep := getFloat64Value(record, "deepSleepDurationInSeconds")
+	light := getFloat64Value(record, "lightSleepDurationInSeconds")
+	rem := getFloat64Value(record, "remSleepInSeconds")
+
+	sleepSeconds := deep + light + rem
+	if sleepSeconds == 0 {
+		sleepSeconds = getFloat64Value(record, "durationInSeconds")
+	}
+
+	sleepData := map[string]interface{}{
+		"sleep_time_seconds":  sleepSeconds,
+		"deep_sleep_seconds":  deep,
+		"light_sleep_seconds": light,
+		"rem_sleep_seconds":   rem,
+		"awake_seconds":       getFloat64Value(record, "awakeDurationInSeconds"),
+	}
+	if score, ok := record["overallSleepScore"].(map[string]interface{}); ok {
+		sleepData["sleep_scores"] = map[string]interface{}{
+			"overall_score": getFloat64Value(score, "value"),
+		}
+	}
+
+	levels, levelsEnd := healthAPISleepLevelsToPayload(record)
+	if len(levels) > 0 {
+		sleepData["sleep_levels"] = levels
+	}
+
+	if start, ok := epochSeconds(record, "startTimeInSeconds"); ok {
+		sleepData["sleep_start_timestamp_gmt"] = start.Format(time.RFC3339)
+
+		// The summary has no end time; the stage timeline's last interval is
+		// the most accurate, otherwise time in bed is asleep plus awake.
+		end := levelsEnd
+		if end.IsZero() {
+			inBed := getFloat64Value(record, "durationInSeconds") + getFloat64Value(record, "awakeDurationInSeconds")
+			end = start.Add(time.Duration(inBed) * time.Second)
+		}
+		sleepData["sleep_end_timestamp_gmt"] = end.Format(time.RFC3339)
+	}
+
+	payload := &SleepPayload{
+		UserID:    userID,
+		Date:      getStringValue(record, "calendarDate"),
+		SleepData: sleepData,
+	}
+	if err := ValidateSleepPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformSleepToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	events := []*models.Event{event}
+
+	hypnogramEvent, err := transformHypnogramToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	if hypnogramEvent != nil {
+		events = append(events, hypnogramEvent)
+	}
+
+	return events, nil
+}
+
+// healthAPISleepLevelsToPayload flattens sleepLevelsMap ({"deep": [{startTimeInSeconds,
+// endTimeInSeconds}], ...}) into the sleep_levels array the ingest payload uses,
+// and returns the end of the last interval.
+func healthAPISleepLevelsToPayload(record map[string]interface{}) ([]interface{}, time.Time) {
+	levelsMap, ok := record["sleepLevelsMap"].(map[string]interface{})
+	if !ok {
+		return nil, time.Time{}
+	}
+
+	var levels []interface{}
+	var last time.Time
+	for key, stage := range healthAPISleepLevels {
+		intervals, ok := levelsMap[key].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, entry := range intervals {
+			interval, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			start, okStart := epochSeconds(interval, "startTimeInSeconds")
+			end, okEnd := epochSeconds(interval, "endTimeInSeconds")
+			if !okStart || !okEnd || !end.After(start) {
+				continue
+			}
+			levels = append(levels, map[string]interface{}{
+				"stage":     stage,
+				"start_gmt": start.Format(time.RFC3339),
+				"end_gmt":   end.Format(time.RFC3339),
+			})
+			if end.After(last) {
+				last = end
+			}
+		}
+	}
+	return levels, last
+}
+
+func hrvEventsFromHealthAPI(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	payload := &HRVPayload{UserID: userID, Date: getStringValue(record, "calendarDate"), HRVData: healthHRVData(record)}
+	if err := ValidateHRVPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformHRVToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+func activityEventsFromHealthAPI(userID string, record map[string]interface{}) ([]*models.Event, error) {
+	start, ok := epochSeconds(record, "startTimeInSeconds")
+	if !ok {
+		return nil, fmt.Errorf("activity has no startTimeInSeconds")
+	}
+	offset := time.Duration(getFloat64Value(record, "startTimeOffsetInSeconds")) * time.Second
+
+	payload := &ActivityPayload{
+		UserID: userID,
+		Date:   start.Add(offset).Format("2006-01-02"),
+		ActivityData: map[string]interface{}{
+			"activity_type":      getStringValue(record, "activityType"),
+			"start_time_gmt":     start.Format(time.RFC3339),
+			"duration_seconds":   getFloat64Value(record, "durationInSeconds"),
+			"distance_meters":    getFloat64Value(record, "distanceInMeters"),
+			"calories":           getFloat64Value(record, "activeKilocalories"),
+			"average_heart_rate": getFloat64Value(record, "averageHeartRateInBeatsPerMinute"),
+			"max_heart_rate":     getFloat64Value(record, "maxHeartRateInBeatsPerMinute"),
+		},
+	}
+	if err := ValidateActivityPayload(payload); err != nil {
+		return nil, err
+	}
+
+	event, err := transformActivityToEvent(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+// healthHRVData maps Garmin's camelCase HRV summary, shared by the Health API
+// and data exports, onto the HRV ingest payload.
+func healthHRVData(summary map[string]interface{}) map[string]interface{} {
+	hrvData := map[string]interface{}{}
+	if avg, ok := getFloat64(summary, "lastNightAvg"); ok {
+		hrvData["last_night_avg"] = avg
+	}
+	if high, ok := getFloat64(summary, "lastNight5MinHigh"); ok {
+		hrvData["last_night_5min_high"] = high
+	}
+	if weekly, ok := getFloat64(summary, "weeklyAvg"); ok {
+		hrvData["weekly_avg"] = weekly
+	}
+	if status := getStringValue(summary, "status"); status != "" {
+		hrvData["status"] = status
+	}
+	if baseline, ok := summary["baseline"].(map[string]interface{}); ok {
+		hrvData["baseline"] = map[string]interface{}{
+			"low_upper":      getFloat64Value(baseline, "lowUpper"),
+			"balanced_low":   getFloat64Value(baseline, "balancedLow"),
+			"balanced_upper": getFloat64Value(baseline, "balancedUpper"),
+		}
+	}
+	return hrvData
+}
+
+// epochSeconds reads a positive epoch-seconds field as a UTC time.
+func epochSeconds(record map[string]interface{}, key string) (time.Time, bool) {
+	v, ok := getFloat64(record, key)
+	if !ok || v <= 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0).UTC(), true
+}
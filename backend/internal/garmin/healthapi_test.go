@@ -0,0 +1,214 @@
+package garmin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/config"
+	"github.com/satishthakur/health-assistant/backend/internal/garmin/garmintest"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+const testConsumerKey = "test-consumer-key"
+
+func newTestHealthAPIClient(baseURL string) *HealthAPIClient {
+	return NewHealthAPIClient(config.GarminConfig{
+		ConsumerKey:    testConsumerKey,
+		ConsumerSecret: "test-consumer-secret",
+		OAuthBaseURL:   baseURL,
+		ConnectBaseURL: baseURL,
+		APIBaseURL:     baseURL,
+	})
+}
+
+// The example request from Twitter's OAuth 1.0a signing guide. Its body
+// parameters are sent in the query here, which signs identically.
+func TestOAuthSignature(t *testing.T) {
+	s := newOAuthSigner("xvz1evFS4wEEPTGEFPHBog", "kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw")
+	s.now = func() time.Time { return time.Unix(1318622958, 0) }
+	s.nonce = func() string { return "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg" }
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.twitter.com/1.1/statuses/update.json?include_entities=true&status="+
+		url.QueryEscape("Hello Ladies + Gentlemen, a signed OAuth request!"), nil)
+	s.sign(req, "370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb", "LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE", nil)
+
+	want := `oauth_signature="hCtSmYh%2BiHYCEqBWrE7C7hYmtUk%3D"`
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, want) {
+		t.Errorf("Authorization = %s, want it to contain %s", got, want)
+	}
+}
+
+func TestHealthAPIConnectFlow(t *testing.T) {
+	server := garmintest.NewServer(testConsumerKey)
+	defer server.Close()
+
+	client := newTestHealthAPIClient(server.URL)
+	ctx := context.Background()
+	callbackURL := "http://localhost:8083/api/v1/garmin/callback"
+
+	requestToken, err := client.RequestToken(ctx, callbackURL)
+	if err != nil {
+		t.Fatalf("RequestToken() error = %v", err)
+	}
+	if requestToken.Token != garmintest.RequestToken {
+		t.Errorf("request token = %q, want %q", requestToken.Token, garmintest.RequestToken)
+	}
+
+	// The consent page redirects back to our callback with the verifier
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := noRedirect.Get(client.AuthorizeURL(requestToken.Token, callbackURL))
+	if err != nil {
+		t.Fatalf("authorize request failed: %v", err)
+	}
+	resp.Body.Close()
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), callbackURL) {
+		t.Fatalf("authorize redirected to %q, want %s", resp.Header.Get("Location"), callbackURL)
+	}
+
+	accessToken, err := client.AccessToken(ctx, requestToken, location.Query().Get("oauth_verifier"))
+	if err != nil {
+		t.Fatalf("AccessToken() error = %v", err)
+	}
+	if accessToken.Token != garmintest.AccessToken || accessToken.Secret != garmintest.AccessTokenSecret {
+		t.Errorf("access token = %+v", accessToken)
+	}
+
+	userID, err := client.UserID(ctx, accessToken)
+	if err != nil {
+		t.Fatalf("UserID() error = %v", err)
+	}
+	if userID != garmintest.UserID {
+		t.Errorf("UserID() = %q, want %q", userID, garmintest.UserID)
+	}
+
+	if err := client.Deregister(ctx, accessToken); err != nil {
+		t.Errorf("Deregister() error = %v", err)
+	}
+
+	if _, err := client.AccessToken(ctx, requestToken, "wrong-verifier"); err == nil {
+		t.Error("AccessToken() with a wrong verifier should fail")
+	}
+}
+
+func TestHealthAPINotConfigured(t *testing.T) {
+	client := NewHealthAPIClient(config.GarminConfig{})
+	if _, err := client.RequestToken(context.Background(), "http://localhost/callback"); err != ErrHealthAPINotConfigured {
+		t.Errorf("RequestToken() error = %v, want ErrHealthAPINotConfigured", err)
+	}
+}
+
+func TestFetchSummariesFromPing(t *testing.T) {
+	server := garmintest.NewServer(testConsumerKey)
+	defer server.Close()
+
+	client := newTestHealthAPIClient(server.URL)
+	token := &OAuthToken{Token: garmintest.AccessToken, Secret: garmintest.AccessTokenSecret}
+
+	tests := []struct {
+		summaryType string
+		wantTypes   []string
+	}{
+		{SummaryDailies, []string{
+			models.EventTypeGarminDailyStats, models.EventTypeGarminStress, models.EventTypeGarminBodyBattery,
+			models.EventTypeGarminDailyStats,
+		}},
+		{SummarySleeps, []string{models.EventTypeGarminSleep, models.EventTypeSleepHypnogram}},
+		{SummaryHRV, []string{models.EventTypeGarminHRV}},
+		{SummaryActivities, []string{models.EventTypeGarminActivity}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.summaryType, func(t *testing.T) {
+			var notification Notification
+			if err := json.Unmarshal(server.Ping(tt.summaryType), &notification); err != nil {
+				t.Fatalf("invalid ping: %v", err)
+			}
+			entry := notification[tt.summaryType][0]
+
+			summaries, err := client.FetchSummaries(context.Background(), getStringValue(entry, "callbackURL"), token)
+			if err != nil {
+				t.Fatalf("FetchSummaries() error = %v", err)
+			}
+
+			var gotTypes []string
+			for _, summary := range summaries {
+				events, err := EventsFromHealthSummary("user-1", tt.summaryType, summary)
+				if err != nil {
+					t.Fatalf("EventsFromHealthSummary() error = %v", err)
+				}
+				for _, e := range events {
+					if e.UserID != "user-1" || e.Source != models.SourceGarmin {
+						t.Errorf("event %s has user %q source %q", e.EventType, e.UserID, e.Source)
+					}
+					gotTypes = append(gotTypes, e.EventType)
+				}
+			}
+
+			if strings.Join(gotTypes, ",") != strings.Join(tt.wantTypes, ",") {
+				t.Errorf("event types = %v, want %v", gotTypes, tt.wantTypes)
+			}
+		})
+	}
+}
+
+func TestFetchSummaries_RejectsForeignCallback(t *testing.T) {
+	server := garmintest.NewServer(testConsumerKey)
+	defer server.Close()
+
+	client := newTestHealthAPIClient(server.URL)
+	token := &OAuthToken{Token: garmintest.AccessToken, Secret: garmintest.AccessTokenSecret}
+
+	for _, callbackURL := range []string{
+		"http://169.254.169.254/wellness-api/rest/sleeps",
+		server.URL + "/oauth-service/oauth/access_token",
+	} {
+		if _, err := client.FetchSummaries(context.Background(), callbackURL, token); err == nil {
+			t.Errorf("FetchSummaries(%q) should be rejected", callbackURL)
+		}
+	}
+}
+
+func TestEventsFromHealthSummary_Sleep(t *testing.T) {
+	var fixture map[string][]map[string]interface{}
+	if err := json.Unmarshal(garmintest.Fixture, &fixture); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+
+	events, err := EventsFromHealthSummary("user-1", SummarySleeps, fixture[SummarySleeps][0])
+	if err != nil {
+		t.Fatalf("EventsFromHealthSummary() error = %v", err)
+	}
+
+	wantEnd := time.Date(2026, 3, 1, 6, 45, 0, 0, time.UTC)
+	if !events[0].Time.Equal(wantEnd) {
+		t.Errorf("sleep event time = %v, want %v", events[0].Time, wantEnd)
+	}
+
+	var sleep models.GarminSleep
+	if err := json.Unmarshal(events[0].Data, &sleep); err != nil {
+		t.Fatalf("invalid sleep data: %v", err)
+	}
+	if sleep.DurationMinutes != 450 || sleep.DeepSleepMinutes != 150 || sleep.REMSleepMinutes != 90 {
+		t.Errorf("sleep minutes = %d total, %d deep, %d rem", sleep.DurationMinutes, sleep.DeepSleepMinutes, sleep.REMSleepMinutes)
+	}
+	if sleep.SleepScore != 84 {
+		t.Errorf("SleepScore = %d, want 84", sleep.SleepScore)
+	}
+	if sleep.Awakenings == nil || *sleep.Awakenings != 1 {
+		t.Errorf("Awakenings = %v, want 1", sleep.Awakenings)
+	}
+
+	var hypnogram models.SleepHypnogram
+	if err := json.Unmarshal(events[1].Data, &hypnogram); err != nil {
+		t.Fatalf("invalid hypnogram: %v", err)
+	}
+	if len(hypnogram.Stages) != 6 || hypnogram.Stages[0].Stage != models.SleepStageLight {
+		t.Errorf("hypnogram stages = %+v", hypnogram.Stages)
+	}
+}
@@ -0,0 +1,118 @@
+package garmin
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryConnectionStore keeps Health API connections and pending request
+// tokens in memory with the same semantics as ConnectionRepository. It is
+// for tests.
+type MemoryConnectionStore struct {
+	mu          sync.Mutex
+	requests    map[string]pendingRequest // by request token
+	connections map[string]Connection     // by user
+}
+
+type pendingRequest struct {
+	userID    string
+	secret    string
+	createdAt time.Time
+}
+
+// NewMemoryConnectionStore creates an empty MemoryConnectionStore.
+func NewMemoryConnectionStore() *MemoryConnectionStore {
+	return &MemoryConnectionStore{
+		requests:    make(map[string]pendingRequest),
+		connections: make(map[string]Connection),
+	}
+}
+
+// SaveRequestToken implements ConnectionStore.
+func (s *MemoryConnectionStore) SaveRequestToken(ctx context.Context, userID string, token *OAuthToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for t, req := range s.requests {
+		if time.Since(req.createdAt) > requestTokenTTL {
+			delete(s.requests, t)
+		}
+	}
+	s.requests[token.Token] = pendingRequest{userID: userID, secret: token.Secret, createdAt: time.Now()}
+	return nil
+}
+
+// TakeRequestToken implements ConnectionStore.
+func (s *MemoryConnectionStore) TakeRequestToken(ctx context.Context, oauthToken string) (string, *OAuthToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.requests[oauthToken]
+	delete(s.requests, oauthToken)
+	if !ok || time.Since(req.createdAt) > requestTokenTTL {
+		return "", nil, ErrRequestTokenNotFound
+	}
+	return req.userID, &OAuthToken{Token: oauthToken, Secret: req.secret}, nil
+}
+
+// SaveConnection implements ConnectionStore.
+func (s *MemoryConnectionStore) SaveConnection(ctx context.Context, conn *Connection) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, c := range s.connections {
+		if c.GarminUserID == conn.GarminUserID && userID != conn.UserID {
+			delete(s.connections, userID)
+		}
+	}
+	s.connections[conn.UserID] = *conn
+	return nil
+}
+
+// GetConnection implements ConnectionStore.
+func (s *MemoryConnectionStore) GetConnection(ctx context.Context, userID string) (*Connection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conn, ok := s.connections[userID]
+	if !ok {
+		return nil, nil
+	}
+	return &conn, nil
+}
+
+// FindConnectionByGarminUserID implements ConnectionStore.
+func (s *MemoryConnectionStore) FindConnectionByGarminUserID(ctx context.Context, garminUserID string) (*Connection, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, conn := range s.connections {
+		if conn.GarminUserID == garminUserID {
+			return &conn, nil
+		}
+	}
+	return nil, nil
+}
+
+// DeleteConnection implements ConnectionStore.
+func (s *MemoryConnectionStore) DeleteConnection(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.connections, userID)
+	return nil
+}
+
+// DeleteConnectionByGarminUserID implements ConnectionStore.
+func (s *MemoryConnectionStore) DeleteConnectionByGarminUserID(ctx context.Context, garminUserID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for userID, conn := range s.connections {
+		if conn.GarminUserID == garminUserID {
+			delete(s.connections, userID)
+		}
+	}
+	return nil
+}
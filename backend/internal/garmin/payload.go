@@ -0,0 +1,131 @@
+package garmin
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Data types of the ingest endpoints, as recorded in the quarantine.
+const (
+	DataTypeSleep             = "sleep"
+	DataTypeActivity          = "activity"
+	DataTypeHRV               = "hrv"
+	DataTypeStress            = "stress"
+	DataTypeDailyStats        = "daily_stats"
+	DataTypeBodyBattery       = "body_battery"
+	DataTypeSpO2              = "spo2"
+	DataTypeRespiration       = "respiration"
+	DataTypeTrainingReadiness = "training_readiness"
+	DataTypeTrainingStatus    = "training_status"
+	DataTypeVO2Max            = "vo2max"
+	DataTypeIntensityMinutes  = "intensity_minutes"
+)
+
+// eventTypes maps each data type to the event type its data is stored as.
+var eventTypes = map[string]string{
+	DataTypeSleep:             models.EventTypeGarminSleep,
+	DataTypeActivity:          models.EventTypeGarminActivity,
+	DataTypeHRV:               models.EventTypeGarminHRV,
+	DataTypeStress:            models.EventTypeGarminStress,
+	DataTypeDailyStats:        models.EventTypeGarminDailyStats,
+	DataTypeBodyBattery:       models.EventTypeGarminBodyBattery,
+	DataTypeSpO2:              models.EventTypeSpO2,
+	DataTypeRespiration:       models.EventTypeRespiration,
+	DataTypeTrainingReadiness: models.EventTypeReadiness,
+	DataTypeTrainingStatus:    models.EventTypeTrainingStatus,
+	DataTypeVO2Max:            models.EventTypeVO2Max,
+	DataTypeIntensityMinutes:  models.EventTypeIntensityMinutes,
+}
+
+// EventType returns the event type data of dataType is stored as, or ""
+// for an unknown data type.
+func EventType(dataType string) string {
+	return eventTypes[dataType]
+}
+
+// EventsFromPayload decodes, validates and transforms an ingest payload of
+// dataType the same way its endpoint does, so a quarantined payload can be
+// replayed once fixed.
+func EventsFromPayload(dataType string, raw json.RawMessage) ([]*models.Event, error) {
+	switch dataType {
+	case DataTypeSleep:
+		var payload SleepPayload
+		if err := decodePayload(raw, &payload); err != nil {
+			return nil, err
+		}
+		if err := ValidateSleepPayload(&payload); err != nil {
+			return nil, err
+		}
+		event, err := transformSleepToEvent(&payload)
+		if err != nil {
+			return nil, err
+		}
+		hypnogram, err := transformHypnogramToEvent(&payload)
+		if err != nil {
+			return nil, err
+		}
+		if hypnogram == nil {
+			return []*models.Event{event}, nil
+		}
+		return []*models.Event{event, hypnogram}, nil
+	case DataTypeActivity:
+		var payload ActivityPayload
+		return convert(raw, &payload, ValidateActivityPayload, transformActivityToEvent)
+	case DataTypeHRV:
+		var payload HRVPayload
+		return convert(raw, &payload, ValidateHRVPayload, transformHRVToEvent)
+	case DataTypeStress:
+		var payload StressPayload
+		return convert(raw, &payload, ValidateStressPayload, transformStressToEvent)
+	case DataTypeDailyStats:
+		var payload DailyStatsPayload
+		return convert(raw, &payload, ValidateDailyStatsPayload, transformDailyStatsToEvent)
+	case DataTypeBodyBattery:
+		var payload BodyBatteryPayload
+		return convert(raw, &payload, ValidateBodyBatteryPayload, transformBodyBatteryToEvent)
+	case DataTypeSpO2:
+		var payload SpO2Payload
+		return convert(raw, &payload, ValidateSpO2Payload, transformSpO2ToEvent)
+	case DataTypeRespiration:
+		var payload RespirationPayload
+		return convert(raw, &payload, ValidateRespirationPayload, transformRespirationToEvent)
+	case DataTypeTrainingReadiness:
+		var payload TrainingReadinessPayload
+		return convert(raw, &payload, ValidateTrainingReadinessPayload, transformTrainingReadinessToEvent)
+	case DataTypeTrainingStatus:
+		var payload TrainingStatusPayload
+		return convert(raw, &payload, ValidateTrainingStatusPayload, transformTrainingStatusToEvent)
+	case DataTypeVO2Max:
+		var payload VO2MaxPayload
+		return convert(raw, &payload, ValidateVO2MaxPayload, transformVO2MaxToEvent)
+	case DataTypeIntensityMinutes:
+		var payload IntensityMinutesPayload
+		return convert(raw, &payload, ValidateIntensityMinutesPayload, transformIntensityMinutesToEvent)
+	default:
+		return nil, fmt.Errorf("unknown data type %q", dataType)
+	}
+}
+
+// convert handles the single-event data types.
+func convert[P any](raw json.RawMessage, payload *P, validate func(*P) error, transform func(*P) (*models.Event, error)) ([]*models.Event, error) {
+	if err := decodePayload(raw, payload); err != nil {
+		return nil, err
+	}
+	if err := validate(payload); err != nil {
+		return nil, err
+	}
+	event, err := transform(payload)
+	if err != nil {
+		return nil, err
+	}
+	return []*models.Event{event}, nil
+}
+
+func decodePayload(raw json.RawMessage, payload interface{}) error {
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
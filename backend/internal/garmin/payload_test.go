@@ -0,0 +1,75 @@
+package garmin
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestEventsFromPayload(t *testing.T) {
+	tests := []struct {
+		name      string
+		dataType  string
+		payload   string
+		wantTypes []string
+		wantErr   bool
+	}{
+		{
+			name:      "stress",
+			dataType:  DataTypeStress,
+			payload:   `{"user_id":"u1","date":"2026-01-28","stress_data":{"average_stress_level":32}}`,
+			wantTypes: []string{models.EventTypeGarminStress},
+		},
+		{
+			name:     "sleep with levels adds hypnogram",
+			dataType: DataTypeSleep,
+			payload: `{"user_id":"u1","date":"2026-01-28","sleep_data":{
+				"sleep_time_seconds":25200,"light_sleep_seconds":25200,
+				"sleep_start_timestamp_gmt":"2026-01-27T23:00:00Z","sleep_end_timestamp_gmt":"2026-01-28T06:00:00Z",
+				"sleep_levels":[{"start_gmt":"2026-01-27T23:00:00Z","end_gmt":"2026-01-28T06:00:00Z","stage":"light"}]}}`,
+			wantTypes: []string{models.EventTypeGarminSleep, models.EventTypeSleepHypnogram},
+		},
+		{
+			name:     "still invalid",
+			dataType: DataTypeStress,
+			payload:  `{"user_id":"u1","date":"2026-01-28","stress_data":{"average_stress_level":140}}`,
+			wantErr:  true,
+		},
+		{
+			name:     "malformed JSON",
+			dataType: DataTypeHRV,
+			payload:  `{"user_id":`,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown data type",
+			dataType: "steps",
+			payload:  `{}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events, err := EventsFromPayload(tt.dataType, json.RawMessage(tt.payload))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("EventsFromPayload() expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EventsFromPayload() error = %v", err)
+			}
+			if len(events) != len(tt.wantTypes) {
+				t.Fatalf("got %d events, want %d", len(events), len(tt.wantTypes))
+			}
+			for i, event := range events {
+				if event.EventType != tt.wantTypes[i] {
+					t.Errorf("event %d type = %q, want %q", i, event.EventType, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package garmin
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quarantine"
+)
+
+// Notification is a Health API ping or push body: entries keyed by summary
+// type, e.g. {"sleeps": [{"userId", "userAccessToken", "callbackURL", ...}]}.
+// Ping entries carry a callbackURL to pull; push entries are the summaries.
+type Notification map[string][]map[string]interface{}
+
+// PushResult summarizes what a notification produced.
+type PushResult struct {
+	Summaries      int `json:"summaries"`
+	EventsInserted int `json:"events_inserted"`
+	Skipped        int `json:"skipped"`
+	Quarantined    int `json:"quarantined"`
+}
+
+// PushProcessor turns Health API notifications into events for connected users.
+type PushProcessor struct {
+	client     *HealthAPIClient
+	conns      ConnectionStore
+	eventRepo  db.EventStore
+	quarantine quarantine.Store
+}
+
+// NewPushProcessor creates a new PushProcessor.
+func NewPushProcessor(client *HealthAPIClient, conns ConnectionStore, eventRepo db.EventStore, quarantineRepo quarantine.Store) *PushProcessor {
+	return &PushProcessor{client: client, conns: conns, eventRepo: eventRepo, quarantine: quarantineRepo}
+}
+
+// Process handles every entry in a notification. Entries for unknown users,
+// or whose access token doesn't match the stored one, are skipped. A failed
+// pull doesn't stop the remaining entries but is returned so the webhook can
+// ask Garmin to retry; re-processing is safe because events are upserted.
+func (p *PushProcessor) Process(ctx context.Context, notification Notification) (*PushResult, error) {
+	result := &PushResult{}
+	var firstErr error
+
+	types := make([]string, 0, len(notification))
+	for summaryType := range notification {
+		types = append(types, summaryType)
+	}
+	sort.Strings(types)
+
+	for _, summaryType := range types {
+		for _, entry := range notification[summaryType] {
+			garminUserID := getStringValue(entry, "userId")
+
+			conn, err := p.conns.FindConnectionByGarminUserID(ctx, garminUserID)
+			if err != nil {
+				return result, err
+			}
+			if conn == nil || conn.Token.Token != getStringValue(entry, "userAccessToken") {
+				log.Printf("Ignoring garmin %s notification for unknown user %s", summaryType, garminUserID)
+				result.Skipped++
+				continue
+			}
+
+			switch {
+			case summaryType == notificationDeregistrations:
+				if err := p.conns.DeleteConnection(ctx, conn.UserID); err != nil {
+					return result, err
+				}
+				log.Printf("Garmin user %s deregistered; disconnected user %s", garminUserID, conn.UserID)
+				continue
+			case summaryType == notificationPermissions:
+				log.Printf("Garmin user %s changed permissions: %v", garminUserID, entry["permissions"])
+				continue
+			case !IsSupportedSummary(summaryType):
+				result.Skipped++
+				continue
+			}
+
+			summaries := []map[string]interface{}{entry}
+			if callbackURL := getStringValue(entry, "callbackURL"); callbackURL != "" {
+				summaries, err = p.client.FetchSummaries(ctx, callbackURL, &conn.Token)
+				if err != nil {
+					log.Printf("Failed to pull garmin %s for user %s: %v", summaryType, conn.UserID, err)
+					if firstErr == nil {
+						firstErr = err
+					}
+					continue
+				}
+			}
+
+			var events []*models.Event
+			for _, summary := range summaries {
+				summaryEvents, err := EventsFromHealthSummary(conn.UserID, summaryType, summary)
+				if err != nil {
+					result.Skipped++
+					continue
+				}
+				result.Summaries++
+				events = append(events, summaryEvents...)
+			}
+
+			events, held, err := p.quarantine.Screen(ctx, events)
+			if err != nil {
+				return result, err
+			}
+			result.Quarantined += held
+
+			inserted, err := p.eventRepo.InsertEvents(ctx, events)
+			if err != nil {
+				return result, fmt.Errorf("failed to store garmin %s: %w", summaryType, err)
+			}
+			result.EventsInserted += inserted
+		}
+	}
+
+	return result, firstErr
+}
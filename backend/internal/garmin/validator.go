@@ -2,7 +2,11 @@ package garmin
 
 import (
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quality"
 )
 
 // SleepPayload represents the incoming sleep data from Python scheduler.
@@ -47,6 +51,48 @@ type BodyBatteryPayload struct {
 	BodyBatteryData map[string]interface{} `json:"body_battery_data"`
 }
 
+// SpO2Payload represents the incoming pulse ox data from Python scheduler.
+type SpO2Payload struct {
+	UserID   string                 `json:"user_id"`
+	Date     string                 `json:"date"`
+	SpO2Data map[string]interface{} `json:"spo2_data"`
+}
+
+// RespirationPayload represents the incoming respiration data from Python scheduler.
+type RespirationPayload struct {
+	UserID          string                 `json:"user_id"`
+	Date            string                 `json:"date"`
+	RespirationData map[string]interface{} `json:"respiration_data"`
+}
+
+// TrainingReadinessPayload represents the incoming training readiness from Python scheduler.
+type TrainingReadinessPayload struct {
+	UserID                string                 `json:"user_id"`
+	Date                  string                 `json:"date"`
+	TrainingReadinessData map[string]interface{} `json:"training_readiness_data"`
+}
+
+// TrainingStatusPayload represents the incoming training status from Python scheduler.
+type TrainingStatusPayload struct {
+	UserID             string                 `json:"user_id"`
+	Date               string                 `json:"date"`
+	TrainingStatusData map[string]interface{} `json:"training_status_data"`
+}
+
+// VO2MaxPayload represents the incoming VO2max estimates from Python scheduler.
+type VO2MaxPayload struct {
+	UserID     string                 `json:"user_id"`
+	Date       string                 `json:"date"`
+	VO2MaxData map[string]interface{} `json:"vo2max_data"`
+}
+
+// IntensityMinutesPayload represents the incoming intensity minutes from Python scheduler.
+type IntensityMinutesPayload struct {
+	UserID               string                 `json:"user_id"`
+	Date                 string                 `json:"date"`
+	IntensityMinutesData map[string]interface{} `json:"intensity_minutes_data"`
+}
+
 // ValidateSleepPayload validates the sleep data payload.
 func ValidateSleepPayload(payload *SleepPayload) error {
 	if payload.UserID == "" {
@@ -78,6 +124,59 @@ func ValidateSleepPayload(payload *SleepPayload) error {
 		}
 	}
 
+	if startTimestamp, exists := payload.SleepData["sleep_start_timestamp_gmt"]; exists {
+		if str, ok := startTimestamp.(string); ok {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return errors.New("sleep_start_timestamp_gmt must be in RFC3339 format")
+			}
+		}
+	}
+
+	if levels, exists := payload.SleepData["sleep_levels"]; exists {
+		if err := validateSleepLevels(levels); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateSleepLevels validates the per-epoch sleep stage timeline.
+func validateSleepLevels(levels interface{}) error {
+	entries, ok := levels.([]interface{})
+	if !ok {
+		return errors.New("sleep_levels must be an array")
+	}
+
+	for i, entry := range entries {
+		level, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("sleep_levels[%d] must be an object", i)
+		}
+
+		switch level["stage"] {
+		case models.SleepStageDeep, models.SleepStageLight, models.SleepStageREM, models.SleepStageAwake:
+		default:
+			return fmt.Errorf("sleep_levels[%d].stage must be one of deep, light, rem, awake", i)
+		}
+
+		startStr, _ := level["start_gmt"].(string)
+		start, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return fmt.Errorf("sleep_levels[%d].start_gmt must be in RFC3339 format", i)
+		}
+
+		endStr, _ := level["end_gmt"].(string)
+		end, err := time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			return fmt.Errorf("sleep_levels[%d].end_gmt must be in RFC3339 format", i)
+		}
+
+		if !end.After(start) {
+			return fmt.Errorf("sleep_levels[%d].end_gmt must be after start_gmt", i)
+		}
+	}
+
 	return nil
 }
 
@@ -117,7 +216,7 @@ func ValidateActivityPayload(payload *ActivityPayload) error {
 		}
 	}
 
-	return nil
+	return validateDistance(payload.ActivityData)
 }
 
 // ValidateHRVPayload validates the HRV data payload.
@@ -138,9 +237,27 @@ func ValidateHRVPayload(payload *HRVPayload) error {
 		return errors.New("hrv_data is required")
 	}
 
-	hrvValue, ok := getFloat64(payload.HRVData, "average_hrv")
+	hrvValue, ok := hrvAverage(payload.HRVData)
 	if !ok || hrvValue < 0 {
-		return errors.New("average_hrv must be a non-negative number")
+		return errors.New("last_night_avg must be a non-negative number")
+	}
+
+	if status, exists := payload.HRVData["status"]; exists {
+		if _, ok := status.(string); !ok {
+			return errors.New("status must be a string")
+		}
+	}
+
+	if baseline, exists := payload.HRVData["baseline"]; exists {
+		band, ok := baseline.(map[string]interface{})
+		if !ok {
+			return errors.New("baseline must be an object")
+		}
+		low, _ := getFloat64(band, "balanced_low")
+		high, _ := getFloat64(band, "balanced_upper")
+		if low > high {
+			return errors.New("baseline balanced_low must not exceed balanced_upper")
+		}
 	}
 
 	return nil
@@ -198,7 +315,7 @@ func ValidateDailyStatsPayload(payload *DailyStatsPayload) error {
 		return errors.New("steps must be a non-negative number")
 	}
 
-	return nil
+	return validateDistance(payload.DailyStatsData)
 }
 
 // ValidateBodyBatteryPayload validates the body battery payload.
@@ -229,6 +346,170 @@ func ValidateBodyBatteryPayload(payload *BodyBatteryPayload) error {
 	return nil
 }
 
+// ValidateSpO2Payload validates the pulse ox payload.
+func ValidateSpO2Payload(payload *SpO2Payload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.SpO2Data, "spo2_data"); err != nil {
+		return err
+	}
+
+	avg, ok := getFloat64(payload.SpO2Data, "average_spo2")
+	if !ok || avg <= 0 || avg > 100 {
+		return errors.New("average_spo2 must be a percentage between 0 and 100")
+	}
+	for _, key := range []string{"lowest_spo2", "average_sleep_spo2"} {
+		if v, ok := getFloat64(payload.SpO2Data, key); ok && (v <= 0 || v > 100) {
+			return fmt.Errorf("%s must be a percentage between 0 and 100", key)
+		}
+	}
+
+	return nil
+}
+
+// ValidateRespirationPayload validates the respiration payload.
+func ValidateRespirationPayload(payload *RespirationPayload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.RespirationData, "respiration_data"); err != nil {
+		return err
+	}
+
+	found := false
+	for _, key := range []string{"average_waking", "average_sleep", "lowest", "highest"} {
+		v, ok := getFloat64(payload.RespirationData, key)
+		if !ok {
+			continue
+		}
+		if v <= 0 || v > 100 {
+			return fmt.Errorf("%s must be between 0 and 100 breaths per minute", key)
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("average_waking or average_sleep is required")
+	}
+
+	return nil
+}
+
+// ValidateTrainingReadinessPayload validates the training readiness payload.
+func ValidateTrainingReadinessPayload(payload *TrainingReadinessPayload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.TrainingReadinessData, "training_readiness_data"); err != nil {
+		return err
+	}
+
+	score, ok := getFloat64(payload.TrainingReadinessData, "score")
+	if !ok || score < 0 || score > 100 {
+		return errors.New("score must be between 0 and 100")
+	}
+	if v, ok := getFloat64(payload.TrainingReadinessData, "recovery_time_minutes"); ok && v < 0 {
+		return errors.New("recovery_time_minutes must be non-negative")
+	}
+
+	return nil
+}
+
+// ValidateTrainingStatusPayload validates the training status payload.
+func ValidateTrainingStatusPayload(payload *TrainingStatusPayload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.TrainingStatusData, "training_status_data"); err != nil {
+		return err
+	}
+
+	if getStringValue(payload.TrainingStatusData, "status") == "" {
+		return errors.New("status is required")
+	}
+	for _, key := range []string{"acute_load", "chronic_load", "acute_chronic_ratio"} {
+		if v, ok := getFloat64(payload.TrainingStatusData, key); ok && v < 0 {
+			return fmt.Errorf("%s must be non-negative", key)
+		}
+	}
+
+	return nil
+}
+
+// ValidateVO2MaxPayload validates the VO2max payload.
+func ValidateVO2MaxPayload(payload *VO2MaxPayload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.VO2MaxData, "vo2max_data"); err != nil {
+		return err
+	}
+
+	found := false
+	for _, key := range []string{"running", "cycling"} {
+		v, ok := getFloat64(payload.VO2MaxData, key)
+		if !ok {
+			continue
+		}
+		if v <= 0 || v > 100 {
+			return fmt.Errorf("%s must be between 0 and 100 ml/kg/min", key)
+		}
+		found = true
+	}
+	if !found {
+		return errors.New("running or cycling is required")
+	}
+
+	return nil
+}
+
+// ValidateIntensityMinutesPayload validates the intensity minutes payload.
+func ValidateIntensityMinutesPayload(payload *IntensityMinutesPayload) error {
+	if err := validateDailyPayload(payload.UserID, payload.Date, payload.IntensityMinutesData, "intensity_minutes_data"); err != nil {
+		return err
+	}
+
+	goal, ok := getFloat64(payload.IntensityMinutesData, "weekly_goal")
+	if !ok || goal < 0 {
+		return errors.New("weekly_goal must be a non-negative number")
+	}
+	for _, key := range []string{"weekly_moderate", "weekly_vigorous", "weekly_total"} {
+		if v, ok := getFloat64(payload.IntensityMinutesData, key); ok && v < 0 {
+			return fmt.Errorf("%s must be non-negative", key)
+		}
+	}
+
+	return nil
+}
+
+// validateDistance checks a distance given with an explicit unit. Senders
+// may pass "distance" with "distance_unit" (m, km or mi) instead of
+// distance_meters.
+func validateDistance(data map[string]interface{}) error {
+	unitValue, exists := data["distance_unit"]
+	if !exists {
+		return nil
+	}
+
+	unit, ok := unitValue.(string)
+	distance, okDistance := getFloat64(data, "distance")
+	if !ok || !okDistance {
+		return errors.New("distance_unit requires a numeric distance")
+	}
+	if distance < 0 {
+		return errors.New("distance must be non-negative")
+	}
+
+	_, err := quality.Normalize(quality.MeasureDistance, distance, unit)
+	return err
+}
+
+// validateDailyPayload checks the fields every once-a-day payload shares.
+func validateDailyPayload(userID, date string, data map[string]interface{}, field string) error {
+	if userID == "" {
+		return errors.New("user_id is required")
+	}
+
+	if date == "" {
+		return errors.New("date is required")
+	}
+
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return errors.New("date must be in YYYY-MM-DD format")
+	}
+
+	if data == nil {
+		return fmt.Errorf("%s is required", field)
+	}
+
+	return nil
+}
+
 func getFloat64(data map[string]interface{}, key string) (float64, bool) {
 	val, exists := data[key]
 	if !exists {
@@ -75,6 +75,62 @@ func TestValidateSleepPayload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid payload with sleep levels",
+			payload: &SleepPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				SleepData: map[string]interface{}{
+					"sleep_time_seconds":        float64(28800),
+					"sleep_start_timestamp_gmt": "2026-01-27T22:30:00Z",
+					"sleep_levels": []interface{}{
+						map[string]interface{}{"stage": "light", "start_gmt": "2026-01-27T22:40:00Z", "end_gmt": "2026-01-27T23:30:00Z"},
+						map[string]interface{}{"stage": "deep", "start_gmt": "2026-01-27T23:30:00Z", "end_gmt": "2026-01-28T00:30:00Z"},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sleep_start_timestamp_gmt",
+			payload: &SleepPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				SleepData: map[string]interface{}{
+					"sleep_time_seconds":        float64(28800),
+					"sleep_start_timestamp_gmt": "2026-01-27 22:30",
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown sleep stage",
+			payload: &SleepPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				SleepData: map[string]interface{}{
+					"sleep_time_seconds": float64(28800),
+					"sleep_levels": []interface{}{
+						map[string]interface{}{"stage": "napping", "start_gmt": "2026-01-27T22:40:00Z", "end_gmt": "2026-01-27T23:30:00Z"},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sleep level ends before it starts",
+			payload: &SleepPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				SleepData: map[string]interface{}{
+					"sleep_time_seconds": float64(28800),
+					"sleep_levels": []interface{}{
+						map[string]interface{}{"stage": "deep", "start_gmt": "2026-01-27T23:30:00Z", "end_gmt": "2026-01-27T23:00:00Z"},
+					},
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -128,6 +184,34 @@ func TestValidateActivityPayload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "distance in miles",
+			payload: &ActivityPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				ActivityData: map[string]interface{}{
+					"activity_type":    "running",
+					"duration_seconds": float64(2700),
+					"distance":         float64(3.1),
+					"distance_unit":    "mi",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown distance unit",
+			payload: &ActivityPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				ActivityData: map[string]interface{}{
+					"activity_type":    "running",
+					"duration_seconds": float64(2700),
+					"distance":         float64(5),
+					"distance_unit":    "furlongs",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,6 +252,44 @@ func TestValidateHRVPayload(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "last night average with baseline",
+			payload: &HRVPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				HRVData: map[string]interface{}{
+					"last_night_avg": float64(62),
+					"status":         "BALANCED",
+					"baseline": map[string]interface{}{
+						"low_upper": float64(50), "balanced_low": float64(55), "balanced_upper": float64(70),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "inverted baseline band",
+			payload: &HRVPayload{
+				UserID: "00000000-0000-0000-0000-000000000001",
+				Date:   "2026-01-28",
+				HRVData: map[string]interface{}{
+					"last_night_avg": float64(62),
+					"baseline": map[string]interface{}{
+						"balanced_low": float64(70), "balanced_upper": float64(55),
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing average",
+			payload: &HRVPayload{
+				UserID:  "00000000-0000-0000-0000-000000000001",
+				Date:    "2026-01-28",
+				HRVData: map[string]interface{}{"weekly_avg": float64(60)},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -231,6 +353,59 @@ func TestValidateStressPayload(t *testing.T) {
 	}
 }
 
+func TestValidateSpO2Payload(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr bool
+	}{
+		{"valid payload", map[string]interface{}{"average_spo2": float64(95), "lowest_spo2": float64(88)}, false},
+		{"missing average", map[string]interface{}{"lowest_spo2": float64(88)}, true},
+		{"average above 100", map[string]interface{}{"average_spo2": float64(105)}, true},
+		{"lowest out of range", map[string]interface{}{"average_spo2": float64(95), "lowest_spo2": float64(0)}, true},
+		{"missing data", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateSpO2Payload(&SpO2Payload{
+				UserID:   "00000000-0000-0000-0000-000000000001",
+				Date:     "2026-01-28",
+				SpO2Data: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateSpO2Payload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateVO2MaxPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    map[string]interface{}
+		wantErr bool
+	}{
+		{"running only", map[string]interface{}{"running": float64(51.3)}, false},
+		{"cycling with fitness age", map[string]interface{}{"cycling": float64(48), "fitness_age": float64(34)}, false},
+		{"fitness age only", map[string]interface{}{"fitness_age": float64(34)}, true},
+		{"implausible estimate", map[string]interface{}{"running": float64(150)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVO2MaxPayload(&VO2MaxPayload{
+				UserID:     "00000000-0000-0000-0000-000000000001",
+				Date:       "2026-01-28",
+				VO2MaxData: tt.data,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateVO2MaxPayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestGetFloat64(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,220 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxUploadBytes bounds archive uploads; larger exports should use the CLI.
+const maxUploadBytes = 4 << 30
+
+// uploadTimeout extends the server's read/write deadlines for archive uploads.
+const uploadTimeout = 30 * time.Minute
+
+// Handler handles bulk import endpoints.
+type Handler struct {
+	repo      Store
+	runner    *Runner
+	uploadDir string
+}
+
+// NewHandler creates a new importer Handler.
+func NewHandler(repo Store, runner *Runner, uploadDir string) *Handler {
+	return &Handler{repo: repo, runner: runner, uploadDir: uploadDir}
+}
+
+// HandleGarminExportUpload handles POST /api/v1/import/garmin-export
+//
+// Accepts the Garmin Connect data export zip as a multipart "file" field or as
+// the raw request body, then queues a background import job.
+func (h *Handler) HandleGarminExportUpload(w http.ResponseWriter, r *http.Request) {
+	h.handleUpload(w, r, KindGarminExport, "*.zip")
+}
+
+// HandleAppleHealthUpload handles POST /api/v1/import/apple-health
+//
+// Accepts the Health app's export.zip, or a bare export.xml, as a multipart
+// "file" field or as the raw request body, then queues a background import job.
+func (h *Handler) HandleAppleHealthUpload(w http.ResponseWriter, r *http.Request) {
+	h.handleUpload(w, r, KindAppleHealthExport, "*.upload")
+}
+
+func (h *Handler) handleUpload(w http.ResponseWriter, r *http.Request, kind, pattern string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(uploadTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		log.Printf("Failed to extend upload read deadline: %v", err)
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		log.Printf("Failed to extend upload write deadline: %v", err)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	path, err := h.saveUpload(r, pattern)
+	if err != nil {
+		log.Printf("Failed to save import upload: %v", err)
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	job := &Job{UserID: userID, Kind: kind, FilePath: path}
+	if err := h.repo.CreateJob(r.Context(), job); err != nil {
+		log.Printf("Failed to create import job: %v", err)
+		os.Remove(path)
+		http.Error(w, "Failed to create import job", http.StatusInternalServerError)
+		return
+	}
+
+	h.runner.Enqueue(job.ID)
+
+	log.Printf("Queued %s import job %s for user %s", kind, job.ID, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"job":    jobResponse(job),
+	})
+}
+
+func (h *Handler) saveUpload(r *http.Request, pattern string) (string, error) {
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			return "", err
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				return "", fmt.Errorf("multipart field \"file\" is required")
+			}
+			if err != nil {
+				return "", err
+			}
+			if part.FormName() == "file" {
+				body = part
+				break
+			}
+		}
+	}
+
+	if err := os.MkdirAll(h.uploadDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	f, err := os.CreateTemp(h.uploadDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload file: %w", err)
+	}
+
+	n, err := io.Copy(f, body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil && n == 0 {
+		err = fmt.Errorf("upload is empty")
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// HandleListJobs handles GET /api/v1/import/jobs
+func (h *Handler) HandleListJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	jobs, err := h.repo.ListJobsByUser(r.Context(), userID, 50)
+	if err != nil {
+		log.Printf("Failed to list import jobs: %v", err)
+		http.Error(w, "Failed to retrieve import jobs", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]map[string]interface{}, 0, len(jobs))
+	for i := range jobs {
+		response = append(response, jobResponse(&jobs[i]))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"count":  len(response),
+		"jobs":   response,
+	})
+}
+
+// HandleGetJob handles GET /api/v1/import/jobs/{id}
+func (h *Handler) HandleGetJob(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	job, err := h.repo.GetJob(r.Context(), r.PathValue("id"))
+	if err != nil || job.UserID != userID {
+		http.Error(w, "Import job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"job":    jobResponse(job),
+	})
+}
+
+func jobResponse(job *Job) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               job.ID,
+		"kind":             job.Kind,
+		"status":           job.Status,
+		"total_items":      job.TotalItems,
+		"processed_items":  job.ProcessedItems,
+		"percent_complete": job.PercentComplete(),
+		"events_imported":  job.EventsImported,
+		"skipped_records":  job.SkippedRecords,
+		"error_message":    job.ErrorMessage,
+		"created_at":       job.CreatedAt,
+		"updated_at":       job.UpdatedAt,
+		"completed_at":     job.CompletedAt,
+	}
+}
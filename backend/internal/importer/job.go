@@ -0,0 +1,68 @@
+// Package importer runs long-lived, resumable bulk import jobs (e.g. full
+// account exports) in the background and tracks their progress.
+package importer
+
+import (
+	"context"
+	"time"
+)
+
+// Job kind constants
+const (
+	KindGarminExport      = "garmin_export"
+	KindAppleHealthExport = "apple_health_export"
+)
+
+// Job status constants
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Job represents a bulk import and its progress.
+type Job struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	Kind           string     `json:"kind"`
+	Status         string     `json:"status"`
+	FilePath       string     `json:"-"`
+	TotalItems     int        `json:"total_items"`
+	ProcessedItems int        `json:"processed_items"`
+	EventsImported int        `json:"events_imported"`
+	SkippedRecords int        `json:"skipped_records"`
+	ErrorMessage   *string    `json:"error_message,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// Progress is a checkpoint reported by a Processor after finishing an item.
+type Progress struct {
+	TotalItems     int
+	ProcessedItems int
+	EventsImported int
+	SkippedRecords int
+}
+
+// Processor imports one kind of archive.
+//
+// Process should skip the first job.ProcessedItems items so an interrupted job
+// resumes where it left off, and call report after each completed item.
+// Processors that cannot seek may restart from the beginning, as long as
+// re-importing is idempotent.
+type Processor interface {
+	Process(ctx context.Context, job *Job, report func(Progress) error) error
+}
+
+// PercentComplete returns the job's progress as a percentage.
+func (j *Job) PercentComplete() float64 {
+	if j.Status == StatusCompleted {
+		return 100
+	}
+	if j.TotalItems == 0 {
+		return 0
+	}
+	return float64(j.ProcessedItems) / float64(j.TotalItems) * 100
+}
@@ -0,0 +1,163 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// MemoryStore keeps import jobs in memory with the same semantics as
+// Repository. It is for tests.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs []*Job // in creation order
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// update applies change to the job with the given ID, if there is one, and
+// bumps its UpdatedAt.
+func (s *MemoryStore) update(id string, change func(*Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.ID == id {
+			change(j)
+			j.UpdatedAt = time.Now()
+			return
+		}
+	}
+}
+
+// CreateJob implements Store.
+func (s *MemoryStore) CreateJob(ctx context.Context, job *Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	created := &Job{
+		ID:        db.NewUUID(),
+		UserID:    job.UserID,
+		Kind:      job.Kind,
+		Status:    StatusQueued,
+		FilePath:  job.FilePath,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.jobs = append(s.jobs, created)
+
+	*job = *created
+	return nil
+}
+
+// GetJob implements Store.
+func (s *MemoryStore) GetJob(ctx context.Context, id string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.ID == id {
+			job := *j
+			return &job, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get import job: %w", pgx.ErrNoRows)
+}
+
+// ListJobsByUser implements Store.
+func (s *MemoryStore) ListJobsByUser(ctx context.Context, userID string, limit int) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []Job
+	for i := len(s.jobs) - 1; i >= 0 && len(jobs) < limit; i-- {
+		if s.jobs[i].UserID == userID {
+			jobs = append(jobs, *s.jobs[i])
+		}
+	}
+	return jobs, nil
+}
+
+// ListUnfinishedJobs implements Store.
+func (s *MemoryStore) ListUnfinishedJobs(ctx context.Context) ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []Job
+	for _, j := range s.jobs {
+		if j.Status == StatusQueued || j.Status == StatusRunning {
+			jobs = append(jobs, *j)
+		}
+	}
+	return jobs, nil
+}
+
+// Claim implements Store.
+func (s *MemoryStore) Claim(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.ID != id {
+			continue
+		}
+		stale := j.Status == StatusRunning && j.UpdatedAt.Before(staleBefore)
+		if j.Status != StatusQueued && j.Status != StatusFailed && !stale {
+			return false, nil
+		}
+		j.Status = StatusRunning
+		j.ErrorMessage = nil
+		j.UpdatedAt = time.Now()
+		return true, nil
+	}
+	return false, nil
+}
+
+// Requeue implements Store.
+func (s *MemoryStore) Requeue(ctx context.Context, id string) error {
+	s.update(id, func(j *Job) {
+		if j.Status == StatusRunning {
+			j.Status = StatusQueued
+		}
+	})
+	return nil
+}
+
+// UpdateProgress implements Store.
+func (s *MemoryStore) UpdateProgress(ctx context.Context, id string, p Progress) error {
+	s.update(id, func(j *Job) {
+		j.TotalItems = p.TotalItems
+		j.ProcessedItems = p.ProcessedItems
+		j.EventsImported = p.EventsImported
+		j.SkippedRecords = p.SkippedRecords
+	})
+	return nil
+}
+
+// MarkCompleted implements Store.
+func (s *MemoryStore) MarkCompleted(ctx context.Context, id string) error {
+	s.update(id, func(j *Job) {
+		now := time.Now()
+		j.Status = StatusCompleted
+		j.CompletedAt = &now
+	})
+	return nil
+}
+
+// MarkFailed implements Store.
+func (s *MemoryStore) MarkFailed(ctx context.Context, id string, message string) error {
+	s.update(id, func(j *Job) {
+		j.Status = StatusFailed
+		j.ErrorMessage = &message
+	})
+	return nil
+}
@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Store holds import jobs and their progress. Repository implements it on
+// Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	CreateJob(ctx context.Context, job *Job) error
+	GetJob(ctx context.Context, id string) (*Job, error)
+	ListJobsByUser(ctx context.Context, userID string, limit int) ([]Job, error)
+	ListUnfinishedJobs(ctx context.Context) ([]Job, error)
+	Claim(ctx context.Context, id string, staleBefore time.Time) (bool, error)
+	Requeue(ctx context.Context, id string) error
+	UpdateProgress(ctx context.Context, id string, p Progress) error
+	MarkCompleted(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, message string) error
+}
+
+// Repository handles database operations for import jobs.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new importer Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+const jobColumns = `
+	id, user_id, kind, status, file_path,
+	total_items, processed_items, events_imported, skipped_records,
+	error_message, created_at, updated_at, completed_at
+`
+
+func scanJob(row interface{ Scan(...interface{}) error }) (*Job, error) {
+	var j Job
+	err := row.Scan(
+		&j.ID, &j.UserID, &j.Kind, &j.Status, &j.FilePath,
+		&j.TotalItems, &j.ProcessedItems, &j.EventsImported, &j.SkippedRecords,
+		&j.ErrorMessage, &j.CreatedAt, &j.UpdatedAt, &j.CompletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// CreateJob inserts a new queued job and fills in its generated fields.
+func (r *Repository) CreateJob(ctx context.Context, job *Job) error {
+	query := `
+		INSERT INTO import_jobs (user_id, kind, status, file_path)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + jobColumns
+
+	created, err := scanJob(r.db.Pool.QueryRow(ctx, query, job.UserID, job.Kind, StatusQueued, job.FilePath))
+	if err != nil {
+		return fmt.Errorf("failed to create import job: %w", err)
+	}
+
+	*job = *created
+	return nil
+}
+
+// GetJob retrieves a job by ID.
+func (r *Repository) GetJob(ctx context.Context, id string) (*Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM import_jobs WHERE id = $1`
+
+	job, err := scanJob(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// ListJobsByUser retrieves a user's jobs, newest first.
+func (r *Repository) ListJobsByUser(ctx context.Context, userID string, limit int) ([]Job, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM import_jobs
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+	return r.queryJobs(ctx, query, userID, limit)
+}
+
+// ListUnfinishedJobs retrieves queued and running jobs, oldest first.
+// Running jobs found at startup may have been interrupted; Claim decides
+// whether they are safe to resume.
+func (r *Repository) ListUnfinishedJobs(ctx context.Context) ([]Job, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM import_jobs
+		WHERE status IN ('queued', 'running')
+		ORDER BY created_at ASC
+	`
+	return r.queryJobs(ctx, query)
+}
+
+func (r *Repository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]Job, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query import jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan import job: %w", err)
+		}
+		jobs = append(jobs, *job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating import jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim sets a job's status to running unless another runner holds it. A
+// queued or failed job can be claimed, and so can a running one whose last
+// checkpoint is before staleBefore: its runner died. It reports whether the
+// job was claimed.
+func (r *Repository) Claim(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE import_jobs
+		SET status = 'running', error_message = NULL, updated_at = NOW()
+		WHERE id = $1
+			AND (status IN ('queued', 'failed') OR (status = 'running' AND updated_at < $2))
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, staleBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim import job: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Requeue returns a running job to the queue, for when its runner stops.
+func (r *Repository) Requeue(ctx context.Context, id string) error {
+	query := `UPDATE import_jobs SET status = 'queued', updated_at = NOW() WHERE id = $1 AND status = 'running'`
+	if _, err := r.db.Pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to requeue import job: %w", err)
+	}
+	return nil
+}
+
+// UpdateProgress records a progress checkpoint.
+func (r *Repository) UpdateProgress(ctx context.Context, id string, p Progress) error {
+	query := `
+		UPDATE import_jobs
+		SET total_items = $2, processed_items = $3, events_imported = $4,
+			skipped_records = $5, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, p.TotalItems, p.ProcessedItems, p.EventsImported, p.SkippedRecords)
+	if err != nil {
+		return fmt.Errorf("failed to update import job progress: %w", err)
+	}
+	return nil
+}
+
+// MarkCompleted sets a job's status to completed.
+func (r *Repository) MarkCompleted(ctx context.Context, id string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = 'completed', updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark import job completed: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed sets a job's status to failed with an error message.
+func (r *Repository) MarkFailed(ctx context.Context, id string, message string) error {
+	query := `
+		UPDATE import_jobs
+		SET status = 'failed', error_message = $2, updated_at = NOW()
+		WHERE id = $1
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, id, message); err != nil {
+		return fmt.Errorf("failed to mark import job failed: %w", err)
+	}
+	return nil
+}
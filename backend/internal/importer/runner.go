@@ -0,0 +1,180 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// staleAfter is how long a running job can go without a checkpoint before
+// its runner is taken to have died and another may claim it.
+const staleAfter = 30 * time.Minute
+
+// ErrJobClaimed is returned by Run for a job that is already completed or
+// that another runner, in the server or the import command, is processing.
+var ErrJobClaimed = errors.New("import job is already running or completed")
+
+// Runner executes import jobs one at a time on a background worker.
+type Runner struct {
+	repo       Store
+	processors map[string]Processor
+	uploadDir  string
+
+	queue chan string
+	mu    sync.Mutex
+	// queued tracks job IDs waiting in or being handled by the worker
+	queued map[string]bool
+}
+
+// NewRunner creates a new Runner. Uploaded archives under uploadDir are
+// deleted once their job completes.
+func NewRunner(repo Store, processors map[string]Processor, uploadDir string) *Runner {
+	return &Runner{
+		repo:       repo,
+		processors: processors,
+		uploadDir:  uploadDir,
+		queue:      make(chan string, 64),
+		queued:     make(map[string]bool),
+	}
+}
+
+// Start resumes unfinished jobs and processes newly enqueued ones until ctx is cancelled.
+func (r *Runner) Start(ctx context.Context) error {
+	jobs, err := r.repo.ListUnfinishedJobs(ctx)
+	if err != nil {
+		return err
+	}
+
+	go r.work(ctx)
+
+	for _, job := range jobs {
+		log.Printf("Resuming import job %s (%s) at item %d", job.ID, job.Kind, job.ProcessedItems)
+		r.Enqueue(job.ID)
+	}
+	return nil
+}
+
+// Enqueue schedules a job for background processing.
+func (r *Runner) Enqueue(jobID string) {
+	r.mu.Lock()
+	if r.queued[jobID] {
+		r.mu.Unlock()
+		return
+	}
+	r.queued[jobID] = true
+	r.mu.Unlock()
+
+	// Never block the caller (usually an HTTP handler) on a full queue;
+	// the job stays queued in the database and is picked up on restart.
+	go func() { r.queue <- jobID }()
+}
+
+func (r *Runner) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case jobID := <-r.queue:
+			job, err := r.repo.GetJob(ctx, jobID)
+			if err != nil {
+				log.Printf("Failed to load import job %s: %v", jobID, err)
+			} else if err := r.Run(ctx, job); errors.Is(err, ErrJobClaimed) && job.Status == StatusRunning {
+				// Another runner has it, or had it and died: look again once
+				// a dead runner's claim would have gone stale
+				log.Printf("Import job %s is held by another runner; retrying in %s", jobID, staleAfter)
+				time.AfterFunc(staleAfter, func() { r.Enqueue(jobID) })
+			} else if err != nil {
+				log.Printf("Import job %s failed: %v", jobID, err)
+			}
+
+			r.mu.Lock()
+			delete(r.queued, jobID)
+			r.mu.Unlock()
+		}
+	}
+}
+
+// Run claims a job and executes it synchronously, checkpointing progress as
+// it goes. It returns ErrJobClaimed if another runner holds the job. A job
+// interrupted by ctx cancellation is requeued so it can be resumed.
+func (r *Runner) Run(ctx context.Context, job *Job) error {
+	processor, ok := r.processors[job.Kind]
+	if !ok {
+		err := fmt.Errorf("no processor for import kind %q", job.Kind)
+		r.fail(job, err)
+		return err
+	}
+
+	claimed, err := r.repo.Claim(ctx, job.ID, time.Now().Add(-staleAfter))
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("%w: %s", ErrJobClaimed, job.ID)
+	}
+	job.Status = StatusRunning
+
+	report := func(p Progress) error {
+		job.TotalItems = p.TotalItems
+		job.ProcessedItems = p.ProcessedItems
+		job.EventsImported = p.EventsImported
+		job.SkippedRecords = p.SkippedRecords
+		return r.repo.UpdateProgress(ctx, job.ID, p)
+	}
+
+	if err := processor.Process(ctx, job, report); err != nil {
+		if ctx.Err() != nil {
+			r.requeue(job)
+			return ctx.Err()
+		}
+		r.fail(job, err)
+		return err
+	}
+
+	if err := r.repo.MarkCompleted(ctx, job.ID); err != nil {
+		return err
+	}
+	job.Status = StatusCompleted
+
+	log.Printf("Import job %s completed: %d items, %d events imported, %d records skipped",
+		job.ID, job.ProcessedItems, job.EventsImported, job.SkippedRecords)
+
+	r.removeUpload(job)
+	return nil
+}
+
+func (r *Runner) fail(job *Job, err error) {
+	// Use a fresh context so the failure is recorded even if ctx is done
+	if markErr := r.repo.MarkFailed(context.Background(), job.ID, err.Error()); markErr != nil {
+		log.Printf("Failed to record import job failure: %v", markErr)
+	}
+	job.Status = StatusFailed
+}
+
+func (r *Runner) requeue(job *Job) {
+	// Use a fresh context: ctx is done
+	if err := r.repo.Requeue(context.Background(), job.ID); err != nil {
+		log.Printf("Failed to requeue import job %s: %v", job.ID, err)
+		return
+	}
+	job.Status = StatusQueued
+}
+
+func (r *Runner) removeUpload(job *Job) {
+	if r.uploadDir == "" {
+		return
+	}
+	rel, err := filepath.Rel(r.uploadDir, job.FilePath)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return
+	}
+	if err := os.Remove(job.FilePath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Failed to remove import upload %s: %v", job.FilePath, err)
+	}
+}
@@ -0,0 +1,99 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// stubProcessor counts its calls and can cancel the run partway through.
+type stubProcessor struct {
+	calls  int
+	cancel context.CancelFunc
+}
+
+func (p *stubProcessor) Process(ctx context.Context, job *Job, report func(Progress) error) error {
+	p.calls++
+	if p.cancel != nil {
+		p.cancel()
+		return ctx.Err()
+	}
+	return report(Progress{TotalItems: 1, ProcessedItems: 1})
+}
+
+func newTestJob(t *testing.T, store *MemoryStore) *Job {
+	t.Helper()
+	job := &Job{UserID: "user-1", Kind: KindGarminExport}
+	if err := store.CreateJob(context.Background(), job); err != nil {
+		t.Fatalf("CreateJob: %v", err)
+	}
+	return job
+}
+
+func TestRunRefusesClaimedJob(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	job := newTestJob(t, store)
+
+	// Another runner has claimed the job
+	if claimed, err := store.Claim(ctx, job.ID, time.Now().Add(-staleAfter)); err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v, want true", claimed, err)
+	}
+
+	processor := &stubProcessor{}
+	runner := NewRunner(store, map[string]Processor{KindGarminExport: processor}, "")
+	if err := runner.Run(ctx, job); !errors.Is(err, ErrJobClaimed) {
+		t.Fatalf("Run error = %v, want ErrJobClaimed", err)
+	}
+	if processor.calls != 0 {
+		t.Errorf("processor called %d times, want 0", processor.calls)
+	}
+}
+
+func TestClaimStaleJob(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	job := newTestJob(t, store)
+
+	if claimed, _ := store.Claim(ctx, job.ID, time.Now().Add(-staleAfter)); !claimed {
+		t.Fatal("first Claim = false, want true")
+	}
+	if claimed, _ := store.Claim(ctx, job.ID, time.Now().Add(-staleAfter)); claimed {
+		t.Error("Claim of a fresh running job = true, want false")
+	}
+	if claimed, _ := store.Claim(ctx, job.ID, time.Now().Add(time.Minute)); !claimed {
+		t.Error("Claim of a stale running job = false, want true")
+	}
+}
+
+func TestRunRequeuesInterruptedJob(t *testing.T) {
+	store := NewMemoryStore()
+	job := newTestJob(t, store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	processor := &stubProcessor{cancel: cancel}
+	runner := NewRunner(store, map[string]Processor{KindGarminExport: processor}, "")
+	if err := runner.Run(ctx, job); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+
+	got, err := store.GetJob(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if got.Status != StatusQueued {
+		t.Fatalf("status after interrupt = %q, want %q", got.Status, StatusQueued)
+	}
+
+	// The job can be resumed at once
+	processor.cancel = nil
+	if err := runner.Run(context.Background(), got); err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	got, _ = store.GetJob(context.Background(), job.ID)
+	if got.Status != StatusCompleted {
+		t.Errorf("status after resume = %q, want %q", got.Status, StatusCompleted)
+	}
+}
@@ -0,0 +1,23 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenUpload returns an uploaded file from either the multipart "file" field
+// or the raw request body. Intended for small files imported inline; archives
+// should go through a queued job instead.
+func OpenUpload(r *http.Request) (io.ReadCloser, error) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, nil
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		return nil, fmt.Errorf("multipart field \"file\" is required: %w", err)
+	}
+	return file, nil
+}
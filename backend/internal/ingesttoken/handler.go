@@ -0,0 +1,118 @@
+package ingesttoken
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxNameLength matches the ingest_tokens.name column.
+const maxNameLength = 100
+
+// Handler lets users manage their own ingest tokens.
+type Handler struct {
+	repo Store
+}
+
+// NewHandler creates a new ingesttoken Handler.
+func NewHandler(repo Store) *Handler {
+	return &Handler{repo: repo}
+}
+
+// HandleTokens handles GET and POST /api/v1/settings/ingest-tokens
+// POST {"name": "garmin-scheduler"} returns the token secret, once.
+func (h *Handler) HandleTokens(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		tokens, err := h.repo.ListByUser(r.Context(), userID)
+		if err != nil {
+			log.Printf("Failed to list ingest tokens: %v", err)
+			http.Error(w, "Failed to retrieve ingest tokens", http.StatusInternalServerError)
+			return
+		}
+		if tokens == nil {
+			tokens = []Token{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"count":  len(tokens),
+			"tokens": tokens,
+		})
+		return
+	}
+
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(payload.Name)
+	if name == "" || len(name) > maxNameLength {
+		http.Error(w, "name is required (max 100 characters)", http.StatusBadRequest)
+		return
+	}
+
+	token, secret, err := h.repo.Create(r.Context(), userID, name)
+	if err != nil {
+		log.Printf("Failed to create ingest token: %v", err)
+		http.Error(w, "Failed to create ingest token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"token":  token,
+		"secret": secret,
+	})
+}
+
+// HandleRevoke handles DELETE /api/v1/settings/ingest-tokens/{id}
+func (h *Handler) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	id := r.PathValue("id")
+	revoked, err := h.repo.Revoke(r.Context(), userID, id)
+	if err != nil {
+		log.Printf("Failed to revoke ingest token: %v", err)
+		http.Error(w, "Failed to revoke ingest token", http.StatusInternalServerError)
+		return
+	}
+	if !revoked {
+		http.Error(w, "Ingest token not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"id":     id,
+	})
+}
@@ -0,0 +1,146 @@
+package ingesttoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Store issues, lists, revokes and authenticates ingest tokens. Repository
+// implements it on Postgres and MemoryStore in memory, for tests. Both also
+// implement middleware.IngestTokenValidator.
+type Store interface {
+	Create(ctx context.Context, userID, name string) (*Token, string, error)
+	ListByUser(ctx context.Context, userID string) ([]Token, error)
+	Revoke(ctx context.Context, userID, id string) (bool, error)
+	RevokeAll(ctx context.Context, userID string) error
+	Authenticate(ctx context.Context, secret string) (string, error)
+}
+
+// Repository handles database operations for ingest tokens.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new ingesttoken Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+const tokenColumns = `id, user_id, name, token_prefix, created_at, last_used_at, revoked_at`
+
+func scanToken(row interface{ Scan(...interface{}) error }) (*Token, error) {
+	var t Token
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.CreatedAt, &t.LastUsedAt, &t.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Create issues a new token for userID and returns it with its secret. The
+// secret cannot be retrieved again.
+func (r *Repository) Create(ctx context.Context, userID, name string) (*Token, string, error) {
+	secret, err := Generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	query := `
+		INSERT INTO ingest_tokens (user_id, name, token_prefix, token_hash)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + tokenColumns
+
+	token, err := scanToken(r.db.Pool.QueryRow(ctx, query, userID, name, Prefix(secret), Hash(secret)))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create ingest token: %w", err)
+	}
+	return token, secret, nil
+}
+
+// ListByUser retrieves a user's tokens, including revoked ones, newest first.
+func (r *Repository) ListByUser(ctx context.Context, userID string) ([]Token, error) {
+	query := `
+		SELECT ` + tokenColumns + `
+		FROM ingest_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ingest tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []Token
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ingest token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ingest tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes one of userID's tokens. It returns false if the user has
+// no active token with that ID.
+func (r *Repository) Revoke(ctx context.Context, userID, id string) (bool, error) {
+	query := `
+		UPDATE ingest_tokens
+		SET revoked_at = NOW()
+		WHERE id::text = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke ingest token: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RevokeAll revokes all of userID's tokens, for when someone else may hold
+// them.
+func (r *Repository) RevokeAll(ctx context.Context, userID string) error {
+	query := `
+		UPDATE ingest_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to revoke ingest tokens: %w", err)
+	}
+	return nil
+}
+
+// Authenticate resolves a token secret to its user and records the use.
+// It returns ErrInvalidToken for unknown or revoked tokens.
+func (r *Repository) Authenticate(ctx context.Context, secret string) (string, error) {
+	query := `
+		UPDATE ingest_tokens
+		SET last_used_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL
+		RETURNING user_id
+	`
+
+	var userID string
+	err := r.db.Pool.QueryRow(ctx, query, Hash(secret)).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInvalidToken
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to authenticate ingest token: %w", err)
+	}
+	return userID, nil
+}
@@ -0,0 +1,59 @@
+// Package ingesttoken issues per-user credentials for the ingest endpoints.
+// A token is bound to the user it was created for, so whoever holds it can
+// only write that user's data.
+package ingesttoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// tokenPrefix marks ingest tokens so they are recognisable in config files
+// and secret scanners.
+const tokenPrefix = "hai_"
+
+// displayLength is how much of a token is kept in clear to tell tokens apart.
+const displayLength = len(tokenPrefix) + 8
+
+// ErrInvalidToken is returned for unknown or revoked tokens.
+var ErrInvalidToken = errors.New("invalid ingest token")
+
+// Token is an issued ingest token. The secret itself is never stored.
+type Token struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Generate returns a new random token secret.
+func Generate() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate ingest token: %w", err)
+	}
+	return tokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Hash returns the stored form of a token secret. Tokens are long random
+// strings, so a plain SHA-256 is enough.
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Prefix returns the part of a token secret that is safe to display.
+func Prefix(secret string) string {
+	if len(secret) < displayLength {
+		return secret
+	}
+	return secret[:displayLength]
+}
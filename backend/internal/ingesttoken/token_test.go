@@ -0,0 +1,55 @@
+package ingesttoken
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	a, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	b, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	if !strings.HasPrefix(a, tokenPrefix) {
+		t.Errorf("token %q does not start with %q", a, tokenPrefix)
+	}
+	if a == b {
+		t.Error("Generate() returned the same token twice")
+	}
+	if len(a) != len(tokenPrefix)+43 {
+		t.Errorf("token length = %d, want %d", len(a), len(tokenPrefix)+43)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("hai_abc") != Hash("hai_abc") {
+		t.Error("Hash() is not deterministic")
+	}
+	if Hash("hai_abc") == Hash("hai_abd") {
+		t.Error("Hash() collides on different tokens")
+	}
+	if got := len(Hash("hai_abc")); got != 64 {
+		t.Errorf("hash length = %d, want 64", got)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	tests := []struct {
+		secret string
+		want   string
+	}{
+		{"hai_0123456789abcdef", "hai_01234567"},
+		{"hai_0123", "hai_0123"},
+	}
+
+	for _, tt := range tests {
+		if got := Prefix(tt.secret); got != tt.want {
+			t.Errorf("Prefix(%q) = %q, want %q", tt.secret, got, tt.want)
+		}
+	}
+}
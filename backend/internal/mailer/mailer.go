@@ -0,0 +1,58 @@
+// Package mailer sends transactional email such as address verification and
+// password reset messages.
+package mailer
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends email.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// MemoryMailer keeps sent messages in memory instead of delivering them.
+// It is for tests.
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewMemoryMailer creates an empty MemoryMailer.
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+// Send implements Mailer.
+func (m *MemoryMailer) Send(ctx context.Context, msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent returns the messages sent so far, oldest first.
+func (m *MemoryMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Message(nil), m.sent...)
+}
+
+// LogMailer writes messages to the server log instead of delivering them,
+// for development without an SMTP server.
+type LogMailer struct{}
+
+// Send implements Mailer.
+func (LogMailer) Send(ctx context.Context, msg Message) error {
+	log.Printf("Email to %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
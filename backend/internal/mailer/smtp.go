@@ -0,0 +1,73 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is how to reach the outgoing mail server.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // optional; PLAIN auth when set
+	Password string
+	From     string
+}
+
+// SMTPMailer delivers mail through an SMTP server, using STARTTLS when the
+// server offers it.
+type SMTPMailer struct {
+	config SMTPConfig
+	now    func() time.Time
+}
+
+// NewSMTPMailer creates an SMTPMailer.
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" || config.From == "" {
+		return nil, errors.New("SMTP host and from address are required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	return &SMTPMailer{config: config, now: time.Now}, nil
+}
+
+// Send implements Mailer.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	if strings.ContainsAny(msg.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", msg.To)
+	}
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	err := smtp.SendMail(addr, auth, m.config.From, []string{msg.To}, buildMessage(m.config.From, msg, m.now()))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// buildMessage renders msg as an RFC 5322 message with CRLF line endings.
+func buildMessage(from string, msg Message, date time.Time) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return b.Bytes()
+}
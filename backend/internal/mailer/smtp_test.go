@@ -0,0 +1,57 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	got := string(buildMessage("Health Assistant <noreply@example.com>", Message{
+		To:      "runner@example.com",
+		Subject: "Verify your email",
+		Body:    "Hello\nClick the link.",
+	}, date))
+
+	want := "From: Health Assistant <noreply@example.com>\r\n" +
+		"To: runner@example.com\r\n" +
+		"Subject: Verify your email\r\n" +
+		"Date: Wed, 28 Jan 2026 12:00:00 +0000\r\n" +
+		"MIME-Version: 1.0\r\n" +
+		"Content-Type: text/plain; charset=utf-8\r\n" +
+		"\r\n" +
+		"Hello\r\nClick the link."
+	if got != want {
+		t.Errorf("buildMessage() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestBuildMessageEncodesSubject(t *testing.T) {
+	got := string(buildMessage("a@example.com", Message{To: "b@example.com", Subject: "Réinitialiser"}, time.Now()))
+	if !strings.Contains(got, "Subject: =?utf-8?q?R=C3=A9initialiser?=\r\n") {
+		t.Errorf("subject not encoded: %q", got)
+	}
+}
+
+func TestSMTPMailerRejectsHeaderInjection(t *testing.T) {
+	m, err := NewSMTPMailer(SMTPConfig{Host: "localhost", From: "a@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), Message{To: "b@example.com\r\nBcc: c@example.com"}); err == nil {
+		t.Error("Send() with CRLF in recipient expected error, got nil")
+	}
+}
+
+func TestMemoryMailer(t *testing.T) {
+	m := NewMemoryMailer()
+	m.Send(context.Background(), Message{To: "a@example.com", Subject: "one"})
+	m.Send(context.Background(), Message{To: "b@example.com", Subject: "two"})
+
+	sent := m.Sent()
+	if len(sent) != 2 || sent[0].Subject != "one" || sent[1].To != "b@example.com" {
+		t.Errorf("Sent() = %+v", sent)
+	}
+}
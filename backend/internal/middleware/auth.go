@@ -4,8 +4,6 @@ import (
 	"context"
 	"net/http"
 	"strings"
-
-	"github.com/satishthakur/health-assistant/backend/internal/auth"
 )
 
 // contextKey is a private type to avoid context key collisions.
@@ -13,10 +11,25 @@ type contextKey string
 
 const userIDKey contextKey = "userID"
 
-// WithAuth returns middleware that validates Bearer JWT tokens.
+// TokenValidator validates a bearer token and returns the user it belongs
+// to. auth.TokenService implements it.
+type TokenValidator interface {
+	ValidateToken(token string) (string, error)
+}
+
+// AccessTokenValidator resolves a personal access token to its user and the
+// scopes it grants. accesstoken.Repository implements it.
+type AccessTokenValidator interface {
+	AuthenticateAccessToken(ctx context.Context, token string) (userID string, scopes []string, err error)
+}
+
+// WithAuth returns middleware that validates Bearer tokens: session JWTs,
+// and personal access tokens that grant every one of scopes. With no scopes
+// the route is for signed-in sessions only, and access tokens are refused.
 // On success the userID is injected into the request context.
-// On failure a 401 response is returned immediately.
-func WithAuth(tokenService *auth.TokenService) func(http.Handler) http.Handler {
+// On failure a 401 (or, for an access token without the scopes, 403)
+// response is returned immediately.
+func WithAuth(tokenService TokenValidator, accessTokens AccessTokenValidator, scopes ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -32,10 +45,26 @@ func WithAuth(tokenService *auth.TokenService) func(http.Handler) http.Handler {
 			}
 
 			userID, err := tokenService.ValidateToken(parts[1])
+			if err == nil {
+				ctx := context.WithValue(r.Context(), userIDKey, userID)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			if accessTokens == nil {
+				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+
+			userID, granted, err := accessTokens.AuthenticateAccessToken(r.Context(), parts[1])
 			if err != nil {
 				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
 				return
 			}
+			if len(scopes) == 0 || !hasScopes(granted, scopes) {
+				http.Error(w, `{"error":"insufficient_scope"}`, http.StatusForbidden)
+				return
+			}
 
 			ctx := context.WithValue(r.Context(), userIDKey, userID)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -49,3 +78,20 @@ func UserIDFromContext(ctx context.Context) string {
 	v, _ := ctx.Value(userIDKey).(string)
 	return v
 }
+
+// hasScopes reports whether granted includes every required scope.
+func hasScopes(granted, required []string) bool {
+	for _, scope := range required {
+		found := false
+		for _, g := range granted {
+			if g == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
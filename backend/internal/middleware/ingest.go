@@ -1,26 +1,103 @@
 package middleware
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
 	"net/http"
+	"slices"
+
+	"github.com/satishthakur/health-assistant/backend/internal/signing"
 )
 
-// WithIngestSecret returns middleware that validates the X-Ingest-Secret header.
-// This is used for server-to-server Garmin ingestion routes (Python sync script).
-func WithIngestSecret(secret string) func(http.Handler) http.Handler {
+// maxSignedBodyBytes caps how much of a signed request is read to hash it.
+const maxSignedBodyBytes = 10 << 20
+
+// IngestTokenValidator resolves a per-user ingest token to its user.
+type IngestTokenValidator interface {
+	Authenticate(ctx context.Context, token string) (userID string, err error)
+}
+
+// WithIngestAuth returns middleware for the server-to-server ingestion
+// routes. A request must carry a per-user X-Ingest-Token, an HMAC signature
+// from a trusted sender (see package signing), or both.
+//
+// A token authenticates the request as the token's user. A signed request
+// without a token acts for the user it names in its user_id query parameter
+// or top-level JSON field, or for the key's only user if it names none.
+// Each signing key may only act for the users keyUsers binds it to: a key
+// bound to no users is refused outright, and a signed request for anyone
+// else is forbidden. Either way the user is injected into the request
+// context like WithAuth does, so handlers never trust the payload's user_id.
+func WithIngestAuth(tokens IngestTokenValidator, verifier *signing.Verifier, keyUsers map[string][]string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			if secret == "" {
-				// No secret configured — reject all requests to protect the route.
+			signed := signing.Signed(r)
+			var body []byte
+			var keyID string
+			if signed {
+				var err error
+				body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+				if err != nil {
+					http.Error(w, "Failed to read request body", http.StatusBadRequest)
+					return
+				}
+				r.Body = io.NopCloser(bytes.NewReader(body))
+
+				if keyID, err = verifier.Verify(r, body); err != nil {
+					log.Printf("Rejected signed ingest request to %s: %v", r.URL.Path, err)
+					http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+					return
+				}
+				if len(keyUsers[keyID]) == 0 {
+					log.Printf("Rejected signed ingest request to %s: key %q is bound to no users", r.URL.Path, keyID)
+					http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+					return
+				}
+			}
+
+			var userID string
+			if token := r.Header.Get("X-Ingest-Token"); token != "" {
+				var err error
+				if userID, err = tokens.Authenticate(r.Context(), token); err != nil {
+					http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+					return
+				}
+			} else if !signed {
 				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
 				return
+			} else if userID = claimedUser(r, body); userID == "" {
+				if len(keyUsers[keyID]) != 1 {
+					http.Error(w, "user_id is required", http.StatusBadRequest)
+					return
+				}
+				userID = keyUsers[keyID][0]
 			}
 
-			if r.Header.Get("X-Ingest-Secret") != secret {
-				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+			if signed && !slices.Contains(keyUsers[keyID], userID) {
+				log.Printf("Rejected signed ingest request to %s: key %q may not write for user %s", r.URL.Path, keyID, userID)
+				http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// claimedUser returns the user a request names, from its user_id query
+// parameter or else the user_id field of a JSON body. A body that is not
+// JSON names no one; the handler reports it.
+func claimedUser(r *http.Request, body []byte) string {
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		return userID
+	}
+	var payload struct {
+		UserID string `json:"user_id"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	return payload.UserID
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+)
+
+// RoleLookup returns a user's role. auth.UserRepository implements it.
+type RoleLookup interface {
+	UserRole(ctx context.Context, userID string) (string, error)
+}
+
+// RequireRole returns middleware that only lets users with one of roles
+// through. It must run after WithAuth. The role is read on every request,
+// so a change takes effect immediately.
+func RequireRole(lookup RoleLookup, roles ...string) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID := UserIDFromContext(r.Context())
+			if userID == "" {
+				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+				return
+			}
+
+			role, err := lookup.UserRole(r.Context(), userID)
+			if err != nil {
+				log.Printf("Failed to look up role of user %s: %v", userID, err)
+				http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
+				return
+			}
+			if !allowed[role] {
+				http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
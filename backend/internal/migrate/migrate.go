@@ -0,0 +1,369 @@
+// Package migrate applies the database schema migrations embedded in the
+// binary, in version order, and records each in schema_migrations with a
+// checksum so an edited migration is caught rather than silently skipped.
+//
+// Migrations are migrations/NNN_name.sql, optionally with a
+// NNN_name.down.sql that reverts it. Each runs in a transaction unless its
+// first line is "-- migrate: no-transaction", in which case its statements
+// run one at a time (TimescaleDB continuous aggregates need this).
+package migrate
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+//go:embed migrations/*.sql
+var embedded embed.FS
+
+// lockKey is the advisory lock held while migrating, so replicas starting
+// together take turns.
+const lockKey = 7_318_426_001
+
+const noTransaction = "-- migrate: no-transaction"
+
+// ErrUntracked is returned when the database already has tables but no
+// migration history, as when its schema was applied by hand. Record what
+// was applied with Baseline first.
+var ErrUntracked = errors.New("database has a schema but no migration history; run `server migrate baseline -version N` with the last migration applied by hand")
+
+// Migration is one schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string // SHA-256 of the up script
+	Up       string
+	Down     string // empty if it cannot be reverted
+
+	// NoTransaction runs the statements one by one outside a transaction.
+	NoTransaction bool
+}
+
+// Status is a migration and whether it has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+	// Modified reports that the migration changed after it was applied.
+	Modified bool
+	// Unknown reports an applied migration this binary does not have,
+	// such as one from a newer release.
+	Unknown bool
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+?)(\.down)?\.sql$`)
+
+// load reads the migrations in fsys, ordered by version.
+func load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		m := fileName.FindStringSubmatch(entry.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migration %s is not named NNN_name.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration := byVersion[version]
+		if migration == nil {
+			migration = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != m[2] {
+			return nil, fmt.Errorf("migrations %03d_%s and %03d_%s share a version", version, migration.Name, version, m[2])
+		}
+
+		if m[3] != "" {
+			migration.Down = string(content)
+			continue
+		}
+		if migration.Up != "" {
+			return nil, fmt.Errorf("migration %s is defined twice", entry.Name())
+		}
+		sum := sha256.Sum256(content)
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migration.Up = string(content)
+		migration.NoTransaction = strings.HasPrefix(migration.Up, noTransaction)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %03d_%s has a down script but no up script", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies migrations to a database.
+type Migrator struct {
+	db         *db.Database
+	migrations []Migration
+}
+
+// New creates a Migrator for the embedded migrations.
+func New(database *db.Database) (*Migrator, error) {
+	fsys, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: database, migrations: migrations}, nil
+}
+
+type appliedMigration struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock runs fn on one connection holding the migration lock, after
+// making sure schema_migrations exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn, applied map[int]appliedMigration) error) error {
+	conn, err := m.db.Pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("failed to take migration lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockKey)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   CHAR(64) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	rows, err := conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.name, &a.checksum, &a.appliedAt); err != nil {
+			return fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[version] = a
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating schema_migrations: %w", err)
+	}
+
+	return fn(conn, applied)
+}
+
+// Up applies every migration not yet applied, in version order, and
+// returns how many it applied. It refuses to run if an applied migration
+// has since been modified.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]appliedMigration) error {
+		if len(applied) == 0 {
+			var untracked bool
+			if err := conn.QueryRow(ctx, `SELECT to_regclass('public.users') IS NOT NULL`).Scan(&untracked); err != nil {
+				return fmt.Errorf("failed to inspect schema: %w", err)
+			}
+			if untracked {
+				return ErrUntracked
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if a, ok := applied[migration.Version]; ok && a.checksum != migration.Checksum {
+				return fmt.Errorf("migration %03d_%s was modified after it was applied", migration.Version, migration.Name)
+			}
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			log.Printf("Applying migration %03d_%s", migration.Version, migration.Name)
+			if err := apply(ctx, conn, migration); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+func apply(ctx context.Context, conn *pgxpool.Conn, migration Migration) error {
+	record := `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`
+
+	if migration.NoTransaction {
+		for _, statement := range splitStatements(migration.Up) {
+			if _, err := conn.Exec(ctx, statement); err != nil {
+				return fmt.Errorf("migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+			}
+		}
+		if _, err := conn.Exec(ctx, record, migration.Version, migration.Name, migration.Checksum); err != nil {
+			return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		return nil
+	}
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, migration.Up); err != nil {
+		return fmt.Errorf("migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.Exec(ctx, record, migration.Version, migration.Name, migration.Checksum); err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// Down reverts the latest applied migration and returns it, or nil if
+// none is applied.
+func (m *Migrator) Down(ctx context.Context) (*Migration, error) {
+	var reverted *Migration
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]appliedMigration) error {
+		latest := -1
+		for version := range applied {
+			if version > latest {
+				latest = version
+			}
+		}
+		if latest < 0 {
+			return nil
+		}
+
+		var migration *Migration
+		for i := range m.migrations {
+			if m.migrations[i].Version == latest {
+				migration = &m.migrations[i]
+			}
+		}
+		if migration == nil {
+			return fmt.Errorf("latest applied migration %03d_%s is not in this binary", latest, applied[latest].name)
+		}
+		if migration.Down == "" {
+			return fmt.Errorf("migration %03d_%s cannot be reverted: it has no down script", migration.Version, migration.Name)
+		}
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback(ctx)
+
+		if _, err := tx.Exec(ctx, migration.Down); err != nil {
+			return fmt.Errorf("reverting migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version); err != nil {
+			return fmt.Errorf("failed to unrecord migration %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+		reverted = migration
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every migration, applied or not, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]appliedMigration) error {
+		statuses = status(m.migrations, applied)
+		return nil
+	})
+	return statuses, err
+}
+
+func status(migrations []Migration, applied map[int]appliedMigration) []Status {
+	var statuses []Status
+	known := make(map[int]bool)
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		s := Status{Version: migration.Version, Name: migration.Name}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.appliedAt
+			s.AppliedAt = &appliedAt
+			s.Modified = a.checksum != migration.Checksum
+		}
+		statuses = append(statuses, s)
+	}
+
+	for version, a := range applied {
+		if !known[version] {
+			appliedAt := a.appliedAt
+			statuses = append(statuses, Status{Version: version, Name: a.name, AppliedAt: &appliedAt, Unknown: true})
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses
+}
+
+// Baseline records the migrations up to version as applied without running
+// them, for a database whose schema was applied by hand. It returns how
+// many it recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int) (int, error) {
+	count := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn, applied map[int]appliedMigration) error {
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			_, err := conn.Exec(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+				migration.Version, migration.Name, migration.Checksum)
+			if err != nil {
+				return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,156 @@
+package migrate
+
+import (
+	"io/fs"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":      {Data: []byte("CREATE TABLE later (id INT);")},
+		"002_first.sql":      {Data: []byte("CREATE TABLE first (id INT);")},
+		"002_first.down.sql": {Data: []byte("DROP TABLE first;")},
+		"003_view.sql":       {Data: []byte(noTransaction + "\nCREATE MATERIALIZED VIEW v AS SELECT 1;")},
+	}
+
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatalf("load() error = %v", err)
+	}
+
+	var versions []int
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	if !reflect.DeepEqual(versions, []int{2, 3, 10}) {
+		t.Fatalf("versions = %v, want [2 3 10]", versions)
+	}
+
+	first := migrations[0]
+	if first.Name != "first" || first.Down != "DROP TABLE first;" || first.NoTransaction {
+		t.Errorf("first = %+v", first)
+	}
+	if len(first.Checksum) != 64 {
+		t.Errorf("Checksum = %q, want a SHA-256 hex digest", first.Checksum)
+	}
+	if !migrations[1].NoTransaction {
+		t.Error("003_view should run outside a transaction")
+	}
+	if migrations[2].Down != "" {
+		t.Error("010_later should have no down script")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "bad name", fsys: fstest.MapFS{"init.sql": {Data: []byte("SELECT 1;")}}},
+		{name: "shared version", fsys: fstest.MapFS{
+			"001_a.sql": {Data: []byte("SELECT 1;")},
+			"001_b.sql": {Data: []byte("SELECT 2;")},
+		}},
+		{name: "down without up", fsys: fstest.MapFS{"001_a.down.sql": {Data: []byte("SELECT 1;")}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := load(tt.fsys); err == nil {
+				t.Error("load() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	fsys, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := load(fsys)
+	if err != nil {
+		t.Fatalf("embedded migrations do not load: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 || migrations[0].Name != "init" {
+		t.Fatalf("first migration = %+v, want 001_init", migrations[0])
+	}
+	for _, m := range migrations {
+		if m.Name == "daily_metrics" && !m.NoTransaction {
+			t.Error("daily_metrics creates a continuous aggregate and must run outside a transaction")
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	appliedAt := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	migrations := []Migration{
+		{Version: 1, Name: "init", Checksum: "aaa"},
+		{Version: 2, Name: "edited", Checksum: "bbb"},
+		{Version: 3, Name: "pending", Checksum: "ccc"},
+	}
+	applied := map[int]appliedMigration{
+		1: {name: "init", checksum: "aaa", appliedAt: appliedAt},
+		2: {name: "edited", checksum: "old", appliedAt: appliedAt},
+		9: {name: "from_newer_release", checksum: "zzz", appliedAt: appliedAt},
+	}
+
+	got := status(migrations, applied)
+	want := []Status{
+		{Version: 1, Name: "init", AppliedAt: &appliedAt},
+		{Version: 2, Name: "edited", AppliedAt: &appliedAt, Modified: true},
+		{Version: 3, Name: "pending"},
+		{Version: 9, Name: "from_newer_release", AppliedAt: &appliedAt, Unknown: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("status() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "simple",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "no trailing semicolon",
+			script: "SELECT 1;\nSELECT 2",
+			want:   []string{"SELECT 1", "SELECT 2"},
+		},
+		{
+			name:   "comments",
+			script: "-- header; not a statement\nSELECT 1; /* a; b */\n-- trailing comment\n",
+			want:   []string{"-- header; not a statement\nSELECT 1"},
+		},
+		{
+			name:   "quotes",
+			script: "SELECT 'a;b', 'it''s;', \"odd;name\";SELECT 2;",
+			want:   []string{"SELECT 'a;b', 'it''s;', \"odd;name\"", "SELECT 2"},
+		},
+		{
+			name:   "dollar quoting",
+			script: "DO $$\nBEGIN\n  RAISE NOTICE 'x';\nEND $$;\nCREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;",
+			want: []string{
+				"DO $$\nBEGIN\n  RAISE NOTICE 'x';\nEND $$",
+				"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,6 @@
 -- Initial database schema for Health Assistant
--- PostgreSQL + TimescaleDB
+-- PostgreSQL + TimescaleDB. The daily_metrics continuous aggregate is in
+-- 003, as it cannot be created inside a transaction.
 
 -- Enable TimescaleDB extension
 CREATE EXTENSION IF NOT EXISTS timescaledb CASCADE;
@@ -58,27 +59,6 @@ CREATE TABLE IF NOT EXISTS experiments (
 CREATE INDEX IF NOT EXISTS idx_experiments_user_status ON experiments (user_id, status);
 CREATE INDEX IF NOT EXISTS idx_experiments_dates ON experiments (start_date, end_date);
 
--- Continuous aggregates for common queries (TimescaleDB feature)
--- Daily metrics rollup
-CREATE MATERIALIZED VIEW IF NOT EXISTS daily_metrics
-WITH (timescaledb.continuous) AS
-SELECT
-    time_bucket('1 day', time) AS day,
-    user_id,
-    event_type,
-    COUNT(*) as event_count,
-    data
-FROM events
-GROUP BY day, user_id, event_type, data
-WITH NO DATA;
-
--- Refresh policy for continuous aggregate
-SELECT add_continuous_aggregate_policy('daily_metrics',
-    start_offset => INTERVAL '3 days',
-    end_offset => INTERVAL '1 hour',
-    schedule_interval => INTERVAL '1 hour',
-    if_not_exists => TRUE);
-
 -- Data retention policy (optional - keep data for 2 years)
 -- SELECT add_retention_policy('events', INTERVAL '2 years', if_not_exists => TRUE);
 
@@ -92,10 +72,6 @@ VALUES (
 )
 ON CONFLICT (email) DO NOTHING;
 
--- Grant permissions
-GRANT ALL PRIVILEGES ON ALL TABLES IN SCHEMA public TO healthuser;
-GRANT ALL PRIVILEGES ON ALL SEQUENCES IN SCHEMA public TO healthuser;
-
 -- Success message
 DO $$
 BEGIN
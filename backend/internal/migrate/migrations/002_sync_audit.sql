@@ -1,5 +1,7 @@
 -- Migration: Add sync_audit table for tracking data ingestion runs
--- This table provides observability into the sync process
+-- This table provides observability into the sync process. It is the one
+-- sync_audit schema; 020_sync_audit_lifecycle.sql brings databases created
+-- from the former 001 migration in line with it.
 
 CREATE TABLE IF NOT EXISTS sync_audit (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -9,9 +11,9 @@ CREATE TABLE IF NOT EXISTS sync_audit (
     user_id UUID NOT NULL,
     data_type VARCHAR(50) NOT NULL, -- 'sleep', 'activity', 'hrv', 'stress'
     target_date DATE NOT NULL,
-    records_fetched INT DEFAULT 0,
-    records_inserted INT DEFAULT 0,
-    records_updated INT DEFAULT 0,
+    records_fetched INT NOT NULL DEFAULT 0,
+    records_inserted INT NOT NULL DEFAULT 0,
+    records_updated INT NOT NULL DEFAULT 0,
     earliest_timestamp TIMESTAMPTZ,
     latest_timestamp TIMESTAMPTZ,
     status VARCHAR(20) NOT NULL CHECK (status IN ('running', 'success', 'partial', 'failed')),
@@ -22,13 +24,11 @@ CREATE TABLE IF NOT EXISTS sync_audit (
 
 -- Indexes for querying audit logs
 CREATE INDEX IF NOT EXISTS idx_sync_audit_user_date ON sync_audit (user_id, target_date DESC);
+CREATE INDEX IF NOT EXISTS idx_sync_audit_user_started ON sync_audit (user_id, sync_started_at DESC);
 CREATE INDEX IF NOT EXISTS idx_sync_audit_status ON sync_audit (status, sync_started_at DESC);
 CREATE INDEX IF NOT EXISTS idx_sync_audit_data_type ON sync_audit (data_type, sync_started_at DESC);
 CREATE INDEX IF NOT EXISTS idx_sync_audit_started_at ON sync_audit (sync_started_at DESC);
 
--- Grant permissions
-GRANT ALL PRIVILEGES ON sync_audit TO healthuser;
-
 -- Success message
 DO $$
 BEGIN
@@ -0,0 +1,25 @@
+-- migrate: no-transaction
+-- Migration: daily_metrics continuous aggregate, split out of 001_init.sql.
+-- TimescaleDB refuses to create continuous aggregates inside a transaction,
+-- so this migration runs statement by statement.
+
+-- Continuous aggregates for common queries (TimescaleDB feature)
+-- Daily metrics rollup
+CREATE MATERIALIZED VIEW IF NOT EXISTS daily_metrics
+WITH (timescaledb.continuous) AS
+SELECT
+    time_bucket('1 day', time) AS day,
+    user_id,
+    event_type,
+    COUNT(*) as event_count,
+    data
+FROM events
+GROUP BY day, user_id, event_type, data
+WITH NO DATA;
+
+-- Refresh policy for continuous aggregate
+SELECT add_continuous_aggregate_policy('daily_metrics',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE);
@@ -0,0 +1,21 @@
+-- Migration: Add import_jobs table for resumable bulk imports
+-- (e.g. Garmin Connect data export archives)
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL, -- 'garmin_export'
+    status VARCHAR(20) NOT NULL CHECK (status IN ('queued', 'running', 'completed', 'failed')),
+    file_path TEXT NOT NULL,
+    total_items INT NOT NULL DEFAULT 0,
+    processed_items INT NOT NULL DEFAULT 0, -- resume checkpoint
+    events_imported INT NOT NULL DEFAULT 0,
+    skipped_records INT NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_user_created ON import_jobs (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_import_jobs_unfinished ON import_jobs (created_at) WHERE status IN ('queued', 'running');
@@ -0,0 +1,6 @@
+-- Migration: Include source in the events primary key
+-- Lets several devices (e.g. Garmin and Apple Health) report the same metric
+-- for the same time without overwriting each other.
+
+ALTER TABLE events DROP CONSTRAINT IF EXISTS events_pkey;
+ALTER TABLE events ADD PRIMARY KEY (time, user_id, event_type, source);
@@ -0,0 +1,10 @@
+-- Migration: Add per-user source priorities used to pick a winner when
+-- several devices report the same metric (e.g. Garmin and Apple Health steps)
+
+CREATE TABLE IF NOT EXISTS source_priorities (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL, -- 'sleep', 'daily_stats', 'hrv', ...
+    sources TEXT[] NOT NULL, -- highest priority first, e.g. {garmin,apple_health}
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, metric)
+);
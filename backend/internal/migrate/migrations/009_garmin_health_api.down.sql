@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS garmin_oauth_requests;
+DROP INDEX IF EXISTS idx_users_garmin_user_id;
@@ -0,0 +1,15 @@
+-- Migration: Garmin Health API push model
+-- users.garmin_oauth_token holds {"garmin_user_id", "access_token": {"token", "secret"}, "connected_at"}
+
+-- Pings identify users by Garmin user ID; one Garmin account links to one user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_garmin_user_id
+    ON users ((garmin_oauth_token->>'garmin_user_id'))
+    WHERE garmin_oauth_token IS NOT NULL;
+
+-- Request tokens waiting for the user to approve access on Garmin Connect
+CREATE TABLE IF NOT EXISTS garmin_oauth_requests (
+    oauth_token TEXT PRIMARY KEY,
+    token_secret TEXT NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
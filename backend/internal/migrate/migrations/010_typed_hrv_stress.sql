@@ -0,0 +1,25 @@
+-- Migration: Rewrite HRV and stress events to the typed models.GarminHRV and
+-- models.GarminStress shapes. HRV used to be stored as average_hrv (which the
+-- dashboard never read), with max_hrv being Garmin's 5-minute high; stress
+-- levels were stored as floats.
+
+UPDATE events
+SET data = jsonb_strip_nulls(
+    (data - 'average_hrv' - 'average' - 'max_hrv' - 'min_hrv')
+    || jsonb_build_object(
+        'last_night_avg', COALESCE(data->'last_night_avg', data->'average_hrv', data->'average', '0'::jsonb),
+        'last_night_5min_high', COALESCE(data->'last_night_5min_high', data->'max_hrv'),
+        'min', COALESCE(data->'min', data->'min_hrv')
+    )
+)
+WHERE event_type = 'garmin_hrv'
+  AND (data ? 'average_hrv' OR data ? 'average' OR data ? 'max_hrv' OR data ? 'min_hrv');
+
+UPDATE events
+SET data = jsonb_strip_nulls(jsonb_build_object(
+    'average_stress_level', ROUND((data->>'average_stress_level')::numeric)::int,
+    'max_stress_level', ROUND((data->>'max_stress_level')::numeric)::int,
+    'rest_stress_duration', ROUND((data->>'rest_stress_duration')::numeric)::int
+))
+WHERE event_type = 'garmin_stress'
+  AND jsonb_typeof(data->'average_stress_level') = 'number';
@@ -0,0 +1,19 @@
+-- Migration: Add ingest_quarantine for events that failed plausibility checks
+-- (e.g. heart rate outside 25-250 bpm, sleep stages not adding up). They are
+-- held here for review instead of being stored in events or dropped.
+
+CREATE TABLE IF NOT EXISTS ingest_quarantine (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    event_time TIMESTAMPTZ NOT NULL,
+    data JSONB NOT NULL,
+    reasons TEXT[] NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'released', 'discarded')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_quarantine_pending ON ingest_quarantine (created_at DESC) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_ingest_quarantine_user ON ingest_quarantine (user_id, created_at DESC);
@@ -0,0 +1,21 @@
+-- Migration: Also keep ingest payloads that failed validation in
+-- ingest_quarantine, so they can be fixed and replayed instead of lost.
+-- Validation rejects have no event yet: they store the payload as posted,
+-- and the user only when the payload named an existing one.
+
+ALTER TABLE ingest_quarantine
+    ADD COLUMN IF NOT EXISTS stage VARCHAR(20) NOT NULL DEFAULT 'plausibility'
+        CHECK (stage IN ('validation', 'plausibility')),
+    ADD COLUMN IF NOT EXISTS data_type VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS payload JSONB;
+
+UPDATE ingest_quarantine SET data_type = event_type WHERE data_type IS NULL;
+
+ALTER TABLE ingest_quarantine
+    ALTER COLUMN data_type SET NOT NULL,
+    ALTER COLUMN user_id DROP NOT NULL,
+    ALTER COLUMN event_type DROP NOT NULL,
+    ALTER COLUMN event_time DROP NOT NULL,
+    ALTER COLUMN data DROP NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_ingest_quarantine_data_type ON ingest_quarantine (data_type, created_at DESC);
@@ -0,0 +1,16 @@
+-- Migration: Per-user ingest tokens. Each token is bound to one user, so
+-- the ingest endpoints no longer trust the user_id in the payload.
+-- Only a SHA-256 hash of the token is stored; the token itself is shown once.
+
+CREATE TABLE IF NOT EXISTS ingest_tokens (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash   CHAR(64) NOT NULL UNIQUE,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingest_tokens_user ON ingest_tokens (user_id, created_at DESC);
@@ -0,0 +1,18 @@
+-- Migration: Login sessions with rotating refresh tokens. One row per signed-in
+-- device. Only a SHA-256 hash of the current refresh token is kept; each
+-- refresh replaces it, and presenting a replaced token revokes the session.
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id                 UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id            UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash CHAR(64) NOT NULL,
+    device_name        VARCHAR(100) NOT NULL DEFAULT '',
+    user_agent         TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at         TIMESTAMPTZ NOT NULL,
+    revoked_at         TIMESTAMPTZ,
+    revoke_reason      VARCHAR(20)
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user ON sessions (user_id, last_used_at DESC);
@@ -0,0 +1,26 @@
+-- Migration: Sign-in identities. A user can sign in with several providers
+-- (Google, Apple, a generic OIDC issuer); each provider account is one row,
+-- keyed by the provider's stable subject identifier. Replaces users.google_id.
+
+CREATE TABLE IF NOT EXISTS user_identities (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider      VARCHAR(50) NOT NULL,
+    subject       TEXT NOT NULL,
+    email         TEXT NOT NULL DEFAULT '',
+    private_email BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_login_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON user_identities (user_id);
+
+-- Carry over existing Google sign-ins
+INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+SELECT id, 'google', google_id, email, created_at
+FROM users
+WHERE google_id IS NOT NULL
+ON CONFLICT (provider, subject) DO NOTHING;
+
+ALTER TABLE users DROP COLUMN IF EXISTS google_id;
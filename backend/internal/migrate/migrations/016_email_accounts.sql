@@ -0,0 +1,25 @@
+-- Migration: Email/password accounts. users.password_hash (bcrypt) is now
+-- used; email_verified_at records when the user proved they own the
+-- address. email_tokens holds the single-use links sent for verification and
+-- password reset, stored as SHA-256 hashes.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+-- Emails that came from a sign-in provider were verified by it
+UPDATE users u
+SET email_verified_at = u.created_at
+WHERE email_verified_at IS NULL
+  AND EXISTS (SELECT 1 FROM user_identities i WHERE i.user_id = u.id);
+
+CREATE TABLE IF NOT EXISTS email_tokens (
+    id         UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose    VARCHAR(20) NOT NULL CHECK (purpose IN ('verify_email', 'reset_password')),
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_tokens_user ON email_tokens (user_id, purpose);
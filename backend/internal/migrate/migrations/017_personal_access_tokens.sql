@@ -0,0 +1,18 @@
+-- Migration: Personal access tokens. Users create them for their own
+-- scripts and notebooks; each is limited to the scopes it was granted and
+-- may expire. Only a SHA-256 hash of the token is stored.
+
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name         VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    token_hash   CHAR(64) NOT NULL UNIQUE,
+    scopes       TEXT[] NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user ON personal_access_tokens (user_id, created_at DESC);
@@ -0,0 +1,9 @@
+-- Migration: User roles. Admins can use the /api/v1/admin endpoints;
+-- coaches get their read-only views. Replaces the ADMIN_USER_IDS setting;
+-- grant roles with `server set-role`.
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user'
+        CHECK (role IN ('user', 'admin', 'coach'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON users (role) WHERE role <> 'user';
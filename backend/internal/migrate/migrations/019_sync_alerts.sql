@@ -0,0 +1,12 @@
+-- Migration: Sync failure alerts. One row per user whose syncs of some data
+-- types keep failing; a data type is removed when it syncs again, and the
+-- row when none are left. Alerts are per user, so one outage that breaks
+-- every data type (say, expired Garmin credentials) sends one alert, and
+-- alerted_at holds back repeats until the cooldown has passed.
+
+CREATE TABLE IF NOT EXISTS sync_alerts (
+    user_id         UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    data_types      TEXT[] NOT NULL DEFAULT '{}',
+    first_failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    alerted_at      TIMESTAMPTZ
+);
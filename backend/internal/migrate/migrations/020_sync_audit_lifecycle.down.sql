@@ -0,0 +1,3 @@
+-- target_date stays a DATE and the 'running' status stays allowed: that is
+-- the 002 schema. Only the index this migration added for it goes.
+DROP INDEX IF EXISTS idx_sync_audit_running;
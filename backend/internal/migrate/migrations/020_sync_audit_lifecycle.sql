@@ -0,0 +1,30 @@
+-- Migration: One sync_audit schema. The removed 001 migration created the
+-- table with a text target_date and without the 'running' status, and
+-- whichever of 001 and 002 ran first won. Bring such databases in line
+-- with 002, which is now authoritative; on others this changes nothing.
+-- Syncs are now started as 'running' and completed or failed later.
+
+ALTER TABLE sync_audit
+    ALTER COLUMN target_date TYPE DATE USING target_date::date;
+
+ALTER TABLE sync_audit DROP CONSTRAINT IF EXISTS sync_audit_status_check;
+ALTER TABLE sync_audit
+    ADD CONSTRAINT sync_audit_status_check CHECK (status IN ('running', 'success', 'partial', 'failed'));
+
+UPDATE sync_audit SET records_fetched = 0 WHERE records_fetched IS NULL;
+UPDATE sync_audit SET records_inserted = 0 WHERE records_inserted IS NULL;
+UPDATE sync_audit SET records_updated = 0 WHERE records_updated IS NULL;
+ALTER TABLE sync_audit
+    ALTER COLUMN records_fetched SET DEFAULT 0,
+    ALTER COLUMN records_fetched SET NOT NULL,
+    ALTER COLUMN records_inserted SET DEFAULT 0,
+    ALTER COLUMN records_inserted SET NOT NULL,
+    ALTER COLUMN records_updated SET DEFAULT 0,
+    ALTER COLUMN records_updated SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sync_audit_user_date ON sync_audit (user_id, target_date DESC);
+CREATE INDEX IF NOT EXISTS idx_sync_audit_user_started ON sync_audit (user_id, sync_started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_sync_audit_started_at ON sync_audit (sync_started_at DESC);
+
+-- Finding syncs left running
+CREATE INDEX IF NOT EXISTS idx_sync_audit_running ON sync_audit (sync_started_at) WHERE status = 'running';
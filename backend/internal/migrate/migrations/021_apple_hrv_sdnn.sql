@@ -0,0 +1,12 @@
+-- Migration: Apple Health HRV is SDNN, which can't be compared with the
+-- RMSSD garmin_hrv events the dashboard and trends read. Move it to its own
+-- hrv_sdnn event type, in the models.HRVSDNN shape.
+
+UPDATE events
+SET event_type = 'hrv_sdnn',
+    data = jsonb_build_object(
+        'avg', COALESCE(data->'last_night_avg', '0'::jsonb),
+        'min', COALESCE(data->'min', data->'last_night_avg', '0'::jsonb),
+        'max', COALESCE(data->'max', data->'last_night_avg', '0'::jsonb)
+    )
+WHERE event_type = 'garmin_hrv' AND source = 'apple_health';
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"regexp"
+	"strings"
+)
+
+var dollarTag = regexp.MustCompile(`^\$[A-Za-z_0-9]*\$`)
+
+// splitStatements splits a script into its statements at semicolons that
+// are outside quotes, dollar-quoted bodies and comments. Statements that
+// are only comments are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	start := 0
+	hasCode := false
+
+	add := func(end int) {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(script[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+			}
+		case c == '\'' || c == '"':
+			hasCode = true
+			for i++; i < len(script); i++ {
+				if script[i] == c {
+					// a doubled quote is an escaped one
+					if i+1 < len(script) && script[i+1] == c {
+						i++
+						continue
+					}
+					break
+				}
+			}
+		case c == '$' && dollarTag.MatchString(script[i:]):
+			hasCode = true
+			tag := dollarTag.FindString(script[i:])
+			if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+				i += len(tag) + end + len(tag) - 1
+			} else {
+				i = len(script)
+			}
+		case c == ';':
+			add(i)
+		case c != ' ' && c != '\t' && c != '\n' && c != '\r':
+			hasCode = true
+		}
+	}
+
+	if start < len(script) {
+		add(len(script))
+	}
+	return statements
+}
@@ -19,11 +19,22 @@ type Event struct {
 // EventType constants
 const (
 	EventTypeGarminSleep       = "garmin_sleep"
+	EventTypeSleepHypnogram    = "sleep_hypnogram"
 	EventTypeGarminActivity    = "garmin_activity"
+	EventTypeActivitySample    = "activity_sample"
 	EventTypeGarminHRV         = "garmin_hrv"
+	EventTypeHRVSDNN           = "hrv_sdnn"
 	EventTypeGarminStress      = "garmin_stress"
 	EventTypeGarminDailyStats  = "garmin_daily_stats"
 	EventTypeGarminBodyBattery = "garmin_body_battery"
+	EventTypeBodyMass          = "body_mass"
+	EventTypeReadiness         = "readiness"
+	EventTypeStrain            = "strain"
+	EventTypeSpO2              = "spo2"
+	EventTypeRespiration       = "respiration"
+	EventTypeTrainingStatus    = "training_status"
+	EventTypeVO2Max            = "vo2max"
+	EventTypeIntensityMinutes  = "intensity_minutes"
 	EventTypeSubjectiveFeeling = "subjective_feeling"
 	EventTypeMeal              = "meal"
 	EventTypeSupplement        = "supplement"
@@ -32,10 +43,14 @@ const (
 
 // Source constants
 const (
-	SourceGarmin  = "garmin"
-	SourceManual  = "manual"
-	SourceParsed  = "parsed"
-	SourceLLM     = "llm"
+	SourceGarmin      = "garmin"
+	SourceFITFile     = "fit_file"
+	SourceAppleHealth = "apple_health"
+	SourceOura        = "oura"
+	SourceWhoop       = "whoop"
+	SourceManual      = "manual"
+	SourceParsed      = "parsed"
+	SourceLLM         = "llm"
 )
 
 // SubjectiveFeeling represents daily subjective assessment
@@ -83,6 +98,37 @@ type GarminSleep struct {
 	AwakeMinutes        int     `json:"awake_minutes"`
 	SleepScore          int     `json:"sleep_score"`
 	HRVAvg              float64 `json:"hrv_avg,omitempty"`
+
+	SleepStart *time.Time `json:"sleep_start,omitempty"`
+	SleepEnd   *time.Time `json:"sleep_end,omitempty"`
+
+	// Continuity metrics derived from the hypnogram; nil when no stage timeline was ingested
+	SleepOnsetLatencyMinutes *int `json:"sleep_onset_latency_minutes,omitempty"`
+	WASOMinutes              *int `json:"waso_minutes,omitempty"` // wake after sleep onset
+	Awakenings               *int `json:"awakenings,omitempty"`
+}
+
+// Sleep stage constants used in hypnograms
+const (
+	SleepStageDeep  = "deep"
+	SleepStageLight = "light"
+	SleepStageREM   = "rem"
+	SleepStageAwake = "awake"
+)
+
+// SleepStageInterval represents one contiguous run of a single sleep stage
+type SleepStageInterval struct {
+	Stage string    `json:"stage"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// SleepHypnogram represents the per-epoch sleep stage timeline for one night
+type SleepHypnogram struct {
+	Date       string               `json:"date"` // calendar date the night is attributed to (wake-up day)
+	SleepStart time.Time            `json:"sleep_start"`
+	SleepEnd   time.Time            `json:"sleep_end"`
+	Stages     []SleepStageInterval `json:"stages"`
 }
 
 // GarminActivity represents Garmin activity data
@@ -93,6 +139,34 @@ type GarminActivity struct {
 	AvgHR        int     `json:"avg_hr,omitempty"`
 	MaxHR        int     `json:"max_hr,omitempty"`
 	Distance     float64 `json:"distance,omitempty"` // in meters
+	AvgCadence   int     `json:"avg_cadence,omitempty"`
+	AvgPower     int     `json:"avg_power,omitempty"`
+	MaxPower     int     `json:"max_power,omitempty"`
+	Laps         []ActivityLap `json:"laps,omitempty"`
+}
+
+// ActivityLap represents a single lap within an activity
+type ActivityLap struct {
+	StartTime       time.Time `json:"start_time"`
+	DurationSeconds int       `json:"duration_seconds"`
+	Distance        float64   `json:"distance,omitempty"` // in meters
+	Calories        int       `json:"calories,omitempty"`
+	AvgHR           int       `json:"avg_hr,omitempty"`
+	MaxHR           int       `json:"max_hr,omitempty"`
+	AvgCadence      int       `json:"avg_cadence,omitempty"`
+	AvgPower        int       `json:"avg_power,omitempty"`
+}
+
+// ActivitySample represents one intraday sample recorded during an activity
+type ActivitySample struct {
+	HeartRate int      `json:"heart_rate,omitempty"`
+	Power     int      `json:"power,omitempty"`
+	Cadence   int      `json:"cadence,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Altitude  *float64 `json:"altitude,omitempty"` // in meters
+	Speed     float64  `json:"speed,omitempty"`    // in meters per second
+	Distance  float64  `json:"distance,omitempty"` // cumulative, in meters
 }
 
 // Biomarker represents a lab test result
@@ -118,6 +192,76 @@ type GarminDailyStats struct {
 	VigorousIntensityMinutes  int `json:"vigorous_intensity_minutes,omitempty"`
 }
 
+// HRV status values reported by Garmin against the user's baseline
+const (
+	HRVStatusBalanced   = "BALANCED"
+	HRVStatusUnbalanced = "UNBALANCED"
+	HRVStatusLow        = "LOW"
+	HRVStatusPoor       = "POOR"
+)
+
+// GarminHRV represents overnight heart rate variability. Values are RMSSD in
+// ms.
+type GarminHRV struct {
+	LastNightAvg      float64      `json:"last_night_avg"`
+	LastNight5MinHigh *float64     `json:"last_night_5min_high,omitempty"`
+	WeeklyAvg         *float64     `json:"weekly_avg,omitempty"`
+	Status            string       `json:"status,omitempty"` // BALANCED, UNBALANCED, LOW, POOR
+	Baseline          *HRVBaseline `json:"baseline,omitempty"`
+
+	// Lowest reading, from older payloads that sent min_hrv
+	Min *float64 `json:"min,omitempty"`
+}
+
+// HRVSDNN is a day's heart rate variability as SDNN in ms, which Apple Health
+// reports. SDNN runs higher than RMSSD, so it is kept apart from GarminHRV and
+// out of the views built on it.
+type HRVSDNN struct {
+	Avg float64 `json:"avg"`
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// HRVBaseline is the user's personal HRV baseline band. Readings between
+// BalancedLow and BalancedUpper are balanced; below LowUpper is low.
+type HRVBaseline struct {
+	LowUpper      float64 `json:"low_upper"`
+	BalancedLow   float64 `json:"balanced_low"`
+	BalancedUpper float64 `json:"balanced_upper"`
+}
+
+// GarminStress represents daily stress from Garmin (0-100 scale)
+type GarminStress struct {
+	AverageStressLevel int  `json:"average_stress_level"`
+	MaxStressLevel     *int `json:"max_stress_level,omitempty"`
+	RestStressDuration *int `json:"rest_stress_duration,omitempty"` // in seconds
+}
+
+// BodyMass represents a single body weight measurement
+type BodyMass struct {
+	WeightKg float64 `json:"weight_kg"`
+}
+
+// Readiness represents a device's daily readiness or recovery score
+// (Oura readiness, Whoop recovery, Garmin training readiness)
+type Readiness struct {
+	Score                int      `json:"score"` // 0-100
+	RestingHeartRate     int      `json:"resting_heart_rate,omitempty"`
+	HRVAvg               float64  `json:"hrv_avg,omitempty"`               // RMSSD, in ms
+	TemperatureDeviation *float64 `json:"temperature_deviation,omitempty"` // in °C from baseline
+	SpO2                 *float64 `json:"spo2,omitempty"`                  // in percent
+	Level                string   `json:"level,omitempty"`                 // Garmin: POOR, LOW, MODERATE, HIGH, PRIME
+	RecoveryTimeMinutes  *int     `json:"recovery_time_minutes,omitempty"` // Garmin's time until fully recovered
+}
+
+// Strain represents cardiovascular load for a day (Whoop day strain)
+type Strain struct {
+	Score      float64 `json:"score"` // 0-21
+	Kilojoules float64 `json:"kilojoules,omitempty"`
+	AvgHR      int     `json:"avg_hr,omitempty"`
+	MaxHR      int     `json:"max_hr,omitempty"`
+}
+
 // GarminBodyBattery represents body battery energy data from Garmin
 type GarminBodyBattery struct {
 	Charged      int `json:"charged"`
@@ -125,3 +269,42 @@ type GarminBodyBattery struct {
 	HighestValue int `json:"highest_value,omitempty"`
 	LowestValue  int `json:"lowest_value,omitempty"`
 }
+
+// SpO2 represents daily blood oxygen saturation (Garmin pulse ox), in percent
+type SpO2 struct {
+	AverageSpO2      float64  `json:"average_spo2"`
+	LowestSpO2       *float64 `json:"lowest_spo2,omitempty"`
+	AverageSleepSpO2 *float64 `json:"average_sleep_spo2,omitempty"`
+}
+
+// Respiration represents daily respiration rate, in breaths per minute
+type Respiration struct {
+	AverageWaking *float64 `json:"average_waking,omitempty"`
+	AverageSleep  *float64 `json:"average_sleep,omitempty"`
+	Lowest        *float64 `json:"lowest,omitempty"`
+	Highest       *float64 `json:"highest,omitempty"`
+}
+
+// TrainingStatus represents Garmin's training status and training load
+type TrainingStatus struct {
+	Status            string   `json:"status"` // e.g. PRODUCTIVE, MAINTAINING, RECOVERY, UNPRODUCTIVE, DETRAINING, OVERREACHING, PEAKING
+	AcuteLoad         *float64 `json:"acute_load,omitempty"`
+	ChronicLoad       *float64 `json:"chronic_load,omitempty"`
+	AcuteChronicRatio *float64 `json:"acute_chronic_ratio,omitempty"`
+}
+
+// VO2Max represents the latest VO2max estimates, in ml/kg/min
+type VO2Max struct {
+	Running    *float64 `json:"running,omitempty"`
+	Cycling    *float64 `json:"cycling,omitempty"`
+	FitnessAge *int     `json:"fitness_age,omitempty"`
+}
+
+// IntensityMinutes represents progress toward the weekly intensity-minute
+// goal. Vigorous minutes count double toward WeeklyTotal.
+type IntensityMinutes struct {
+	WeeklyModerate int `json:"weekly_moderate"`
+	WeeklyVigorous int `json:"weekly_vigorous"`
+	WeeklyTotal    int `json:"weekly_total"`
+	WeeklyGoal     int `json:"weekly_goal"`
+}
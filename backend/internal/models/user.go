@@ -10,13 +10,26 @@ type User struct {
 	ID               string          `json:"id" db:"id"`
 	Email            string          `json:"email" db:"email"`
 	PasswordHash     string          `json:"-" db:"password_hash"` // Never expose in JSON
-	GoogleID         string          `json:"-" db:"google_id"`
 	DisplayName      string          `json:"display_name,omitempty" db:"display_name"`
+	EmailVerifiedAt  *time.Time      `json:"email_verified_at,omitempty" db:"email_verified_at"`
+	Role             string          `json:"role" db:"role"` // RoleUser, RoleAdmin or RoleCoach
 	GarminOAuthToken json.RawMessage `json:"-" db:"garmin_oauth_token"`
 	Preferences      json.RawMessage `json:"preferences,omitempty" db:"preferences"`
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 }
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin" // all /api/v1/admin endpoints
+	RoleCoach = "coach" // no cross-user access until coaches are assigned users
+)
+
+// ValidRole reports whether role is one of the user roles.
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin || role == RoleCoach
+}
+
 // UserPreferences represents user preferences
 type UserPreferences struct {
 	TimeZone             string   `json:"timezone"`
@@ -0,0 +1,103 @@
+package oura
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Oura API host.
+const DefaultBaseURL = "https://api.ouraring.com"
+
+// Client talks to the Oura v2 REST API with a personal access token or an
+// OAuth2 access token.
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+// NewClient creates a new Oura Client. An empty baseURL uses DefaultBaseURL.
+func NewClient(baseURL, accessToken string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Fetch retrieves the sleep, daily_sleep and daily_readiness collections for
+// the days from start to end, inclusive.
+func (c *Client) Fetch(ctx context.Context, start, end time.Time) (*Data, error) {
+	data := &Data{}
+
+	if err := fetchCollection(ctx, c, "sleep", start, end, &data.Sleep); err != nil {
+		return nil, err
+	}
+	if err := fetchCollection(ctx, c, "daily_sleep", start, end, &data.DailySleep); err != nil {
+		return nil, err
+	}
+	if err := fetchCollection(ctx, c, "daily_readiness", start, end, &data.DailyReadiness); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// fetchCollection follows next_token pagination until the collection is exhausted.
+func fetchCollection[T any](ctx context.Context, c *Client, collection string, start, end time.Time, out *[]T) error {
+	nextToken := ""
+	for {
+		params := url.Values{}
+		params.Set("start_date", start.Format("2006-01-02"))
+		// end_date is exclusive
+		params.Set("end_date", end.AddDate(0, 0, 1).Format("2006-01-02"))
+		if nextToken != "" {
+			params.Set("next_token", nextToken)
+		}
+
+		var page struct {
+			Data      []T     `json:"data"`
+			NextToken *string `json:"next_token"`
+		}
+		if err := c.get(ctx, "/v2/usercollection/"+collection+"?"+params.Encode(), &page); err != nil {
+			return fmt.Errorf("failed to fetch Oura %s: %w", collection, err)
+		}
+
+		*out = append(*out, page.Data...)
+
+		if page.NextToken == nil || *page.NextToken == "" {
+			return nil
+		}
+		nextToken = *page.NextToken
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,51 @@
+package oura
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/oura/ouratest"
+)
+
+func TestClientFetch(t *testing.T) {
+	server := ouratest.NewServer("test-token", 1)
+	defer server.Close()
+
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name          string
+		start, end    time.Time
+		wantSleep     int
+		wantReadiness int
+	}{
+		{name: "both days across pages", start: day(1), end: day(3), wantSleep: 3, wantReadiness: 2},
+		{name: "single day", start: day(3), end: day(3), wantSleep: 1, wantReadiness: 1},
+		{name: "no data", start: day(10), end: day(12)},
+	}
+
+	client := NewClient(server.URL, "test-token")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := client.Fetch(context.Background(), tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(data.Sleep) != tt.wantSleep || len(data.DailyReadiness) != tt.wantReadiness {
+				t.Errorf("got %d sleep, %d readiness; want %d, %d",
+					len(data.Sleep), len(data.DailyReadiness), tt.wantSleep, tt.wantReadiness)
+			}
+		})
+	}
+}
+
+func TestClientFetch_Unauthorized(t *testing.T) {
+	server := ouratest.NewServer("test-token", 10)
+	defer server.Close()
+
+	client := NewClient(server.URL, "wrong-token")
+	if _, err := client.Fetch(context.Background(), time.Now(), time.Now()); err == nil {
+		t.Error("Fetch() expected error for bad token")
+	}
+}
@@ -0,0 +1,86 @@
+package oura
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxExportBytes bounds uploads; several years of Oura data is a few MB.
+const maxExportBytes = 64 << 20
+
+// Handler handles Oura import endpoints.
+type Handler struct {
+	eventRepo db.EventStore
+}
+
+// NewHandler creates a new Oura Handler.
+func NewHandler(eventRepo db.EventStore) *Handler {
+	return &Handler{eventRepo: eventRepo}
+}
+
+// HandleImport handles POST /api/v1/import/oura
+//
+// Accepts an Oura JSON export as a multipart "file" field or as the raw body.
+func (h *Handler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxExportBytes)
+
+	body, err := importer.OpenUpload(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	data, err := ParseExport(body)
+	if err != nil {
+		log.Printf("Failed to parse Oura export: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrNoData) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	events, err := Normalize(userID, data)
+	if err != nil {
+		log.Printf("Failed to normalize Oura export: %v", err)
+		http.Error(w, fmt.Sprintf("Transformation error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	inserted, err := h.eventRepo.InsertEvents(r.Context(), events)
+	if err != nil {
+		log.Printf("Failed to insert Oura events: %v", err)
+		http.Error(w, "Failed to store events", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported Oura export for user %s: %d events (%d new)", userID, len(events), inserted)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"events":          len(events),
+		"events_inserted": inserted,
+	})
+}
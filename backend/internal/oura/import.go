@@ -0,0 +1,82 @@
+package oura
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// ErrNoData is returned when an export contains none of the collections we import.
+var ErrNoData = errors.New("Oura export contains no sleep or readiness data")
+
+// ParseExport reads an Oura JSON export: an object keyed by collection name
+// (sleep, daily_sleep, daily_readiness). Each collection may be a plain array
+// of documents or an API page of the form {"data": [...]}.
+func ParseExport(r io.Reader) (*Data, error) {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid Oura export: %w", err)
+	}
+
+	data := &Data{}
+	if err := decodeCollection(raw["sleep"], &data.Sleep); err != nil {
+		return nil, fmt.Errorf("invalid sleep collection: %w", err)
+	}
+	if err := decodeCollection(raw["daily_sleep"], &data.DailySleep); err != nil {
+		return nil, fmt.Errorf("invalid daily_sleep collection: %w", err)
+	}
+	if err := decodeCollection(raw["daily_readiness"], &data.DailyReadiness); err != nil {
+		return nil, fmt.Errorf("invalid daily_readiness collection: %w", err)
+	}
+
+	if len(data.Sleep) == 0 && len(data.DailySleep) == 0 && len(data.DailyReadiness) == 0 {
+		return nil, ErrNoData
+	}
+	return data, nil
+}
+
+func decodeCollection(raw json.RawMessage, out interface{}) error {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	if raw[0] == '{' {
+		var page struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		raw = page.Data
+		if len(raw) == 0 {
+			return nil
+		}
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// Sync fetches the days from start to end from the Oura API and upserts the
+// normalized events. Returns the number of newly inserted events.
+func Sync(ctx context.Context, client *Client, eventRepo db.EventStore, userID string, start, end time.Time) (int, error) {
+	data, err := client.Fetch(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := Normalize(userID, data)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted, err := eventRepo.InsertEvents(ctx, events)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store Oura events: %w", err)
+	}
+	return inserted, nil
+}
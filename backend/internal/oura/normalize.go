@@ -0,0 +1,236 @@
+// Package oura imports Oura ring data, from either a JSON export or the Oura
+// v2 REST API, normalized into the same event types as Garmin data.
+package oura
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/sleep"
+)
+
+// Sleep period types. A night's main sleep is "long_sleep"; naps and short
+// periods are "sleep", "late_nap" or "rest".
+const (
+	sleepTypeLong = "long_sleep"
+)
+
+// phaseStages maps sleep_phase_5_min characters onto hypnogram stages.
+var phaseStages = map[rune]string{
+	'1': models.SleepStageDeep,
+	'2': models.SleepStageLight,
+	'3': models.SleepStageREM,
+	'4': models.SleepStageAwake,
+}
+
+// SleepPeriod is a document from the sleep collection. Durations are in seconds.
+type SleepPeriod struct {
+	Day                string   `json:"day"`
+	Type               string   `json:"type"`
+	BedtimeStart       string   `json:"bedtime_start"`
+	BedtimeEnd         string   `json:"bedtime_end"`
+	TotalSleepDuration *int     `json:"total_sleep_duration"`
+	DeepSleepDuration  *int     `json:"deep_sleep_duration"`
+	LightSleepDuration *int     `json:"light_sleep_duration"`
+	REMSleepDuration   *int     `json:"rem_sleep_duration"`
+	AwakeTime          *int     `json:"awake_time"`
+	AverageHRV         *float64 `json:"average_hrv"`
+	AverageHeartRate   *float64 `json:"average_heart_rate"`
+	LowestHeartRate    *int     `json:"lowest_heart_rate"`
+	SleepPhase5Min     string   `json:"sleep_phase_5_min"`
+}
+
+// DailySleep is a document from the daily_sleep collection.
+type DailySleep struct {
+	Day   string `json:"day"`
+	Score *int   `json:"score"`
+}
+
+// DailyReadiness is a document from the daily_readiness collection.
+type DailyReadiness struct {
+	Day                  string   `json:"day"`
+	Score                *int     `json:"score"`
+	TemperatureDeviation *float64 `json:"temperature_deviation"`
+}
+
+// Data holds the Oura collections we import.
+type Data struct {
+	Sleep          []SleepPeriod    `json:"sleep"`
+	DailySleep     []DailySleep     `json:"daily_sleep"`
+	DailyReadiness []DailyReadiness `json:"daily_readiness"`
+}
+
+// Normalize converts Oura documents into sleep, hypnogram, HRV and readiness events.
+// Documents that are incomplete (e.g. still being processed by Oura) are skipped.
+func Normalize(userID string, data *Data) ([]*models.Event, error) {
+	var events []*models.Event
+
+	add := func(t time.Time, eventType string, v interface{}) error {
+		dataJSON, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+		}
+		events = append(events, &models.Event{
+			Time:      t,
+			UserID:    userID,
+			EventType: eventType,
+			Source:    models.SourceOura,
+			Data:      dataJSON,
+		})
+		return nil
+	}
+
+	scores := make(map[string]int)
+	for _, d := range data.DailySleep {
+		if d.Score != nil {
+			scores[d.Day] = *d.Score
+		}
+	}
+
+	mainSleeps := mainSleepByDay(data.Sleep)
+
+	for _, day := range sortedDays(mainSleeps) {
+		period := mainSleeps[day]
+		start, errStart := time.Parse(time.RFC3339, period.BedtimeStart)
+		end, errEnd := time.Parse(time.RFC3339, period.BedtimeEnd)
+		if errStart != nil || errEnd != nil || period.TotalSleepDuration == nil {
+			continue
+		}
+		start, end = start.UTC(), end.UTC()
+
+		summary := models.GarminSleep{
+			DurationMinutes:   *period.TotalSleepDuration / 60,
+			DeepSleepMinutes:  intValue(period.DeepSleepDuration) / 60,
+			LightSleepMinutes: intValue(period.LightSleepDuration) / 60,
+			REMSleepMinutes:   intValue(period.REMSleepDuration) / 60,
+			AwakeMinutes:      intValue(period.AwakeTime) / 60,
+			SleepScore:        scores[day],
+			SleepStart:        &start,
+			SleepEnd:          &end,
+		}
+		if period.AverageHRV != nil {
+			summary.HRVAvg = *period.AverageHRV
+		}
+
+		hypnogram := buildHypnogram(day, start, end, period.SleepPhase5Min)
+		if hypnogram != nil {
+			sleep.ApplyMetrics(&summary, hypnogram)
+		}
+
+		if err := add(end, models.EventTypeGarminSleep, summary); err != nil {
+			return nil, err
+		}
+		if hypnogram != nil {
+			if err := add(end, models.EventTypeSleepHypnogram, hypnogram); err != nil {
+				return nil, err
+			}
+		}
+
+		if period.AverageHRV != nil {
+			dayTime, _ := time.Parse("2006-01-02", day)
+			hrv := models.GarminHRV{LastNightAvg: *period.AverageHRV}
+			if err := add(dayTime, models.EventTypeGarminHRV, hrv); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, r := range data.DailyReadiness {
+		dayTime, err := time.Parse("2006-01-02", r.Day)
+		if err != nil || r.Score == nil {
+			continue
+		}
+
+		readiness := models.Readiness{
+			Score:                *r.Score,
+			TemperatureDeviation: r.TemperatureDeviation,
+		}
+		if period, ok := mainSleeps[r.Day]; ok {
+			readiness.RestingHeartRate = intValue(period.LowestHeartRate)
+			if period.AverageHRV != nil {
+				readiness.HRVAvg = *period.AverageHRV
+			}
+		}
+
+		if err := add(dayTime, models.EventTypeReadiness, readiness); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// mainSleepByDay picks each day's long_sleep period, falling back to the
+// longest period when Oura did not classify one as the main sleep.
+func mainSleepByDay(periods []SleepPeriod) map[string]SleepPeriod {
+	byDay := make(map[string]SleepPeriod)
+	for _, p := range periods {
+		current, exists := byDay[p.Day]
+		switch {
+		case !exists:
+			byDay[p.Day] = p
+		case current.Type != sleepTypeLong && p.Type == sleepTypeLong:
+			byDay[p.Day] = p
+		case (current.Type == sleepTypeLong) == (p.Type == sleepTypeLong) &&
+			intValue(p.TotalSleepDuration) > intValue(current.TotalSleepDuration):
+			byDay[p.Day] = p
+		}
+	}
+	return byDay
+}
+
+// buildHypnogram expands sleep_phase_5_min, one character per 5-minute
+// epoch starting at bedtime, into merged stage intervals.
+func buildHypnogram(day string, start, end time.Time, phases string) *models.SleepHypnogram {
+	if phases == "" {
+		return nil
+	}
+
+	hypnogram := &models.SleepHypnogram{Date: day, SleepStart: start, SleepEnd: end}
+	epoch := 5 * time.Minute
+
+	for i, c := range phases {
+		stage, ok := phaseStages[c]
+		if !ok {
+			continue
+		}
+		epochStart := start.Add(time.Duration(i) * epoch)
+		epochEnd := epochStart.Add(epoch)
+		if epochEnd.After(end) {
+			epochEnd = end
+		}
+		if !epochEnd.After(epochStart) {
+			break
+		}
+
+		if n := len(hypnogram.Stages); n > 0 && hypnogram.Stages[n-1].Stage == stage && hypnogram.Stages[n-1].End.Equal(epochStart) {
+			hypnogram.Stages[n-1].End = epochEnd
+			continue
+		}
+		hypnogram.Stages = append(hypnogram.Stages, models.SleepStageInterval{Stage: stage, Start: epochStart, End: epochEnd})
+	}
+
+	if len(hypnogram.Stages) == 0 {
+		return nil
+	}
+	return hypnogram
+}
+
+func sortedDays(m map[string]SleepPeriod) []string {
+	days := make([]string, 0, len(m))
+	for day := range m {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+func intValue(v *int) int {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,131 @@
+package oura
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/oura/ouratest"
+)
+
+func TestNormalize(t *testing.T) {
+	data, err := ParseExport(bytes.NewReader(ouratest.Fixture))
+	if err != nil {
+		t.Fatalf("ParseExport() error = %v", err)
+	}
+
+	events, err := Normalize("user-1", data)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	counts := make(map[string]int)
+	for _, e := range events {
+		if e.Source != models.SourceOura || e.UserID != "user-1" {
+			t.Errorf("event %s has source %q user %q", e.EventType, e.Source, e.UserID)
+		}
+		counts[e.EventType]++
+	}
+
+	// Two main sleeps (the nap is dropped), one hypnogram and one HRV (the
+	// second night has neither), and one scored readiness day
+	want := map[string]int{
+		models.EventTypeGarminSleep:    2,
+		models.EventTypeSleepHypnogram: 1,
+		models.EventTypeGarminHRV:      1,
+		models.EventTypeReadiness:      1,
+	}
+	for eventType, n := range want {
+		if counts[eventType] != n {
+			t.Errorf("got %d %s events, want %d", counts[eventType], eventType, n)
+		}
+	}
+
+	first := events[0]
+	var sleep models.GarminSleep
+	json.Unmarshal(first.Data, &sleep)
+	if sleep.DurationMinutes != 450 || sleep.DeepSleepMinutes != 100 || sleep.SleepScore != 82 || sleep.HRVAvg != 45 {
+		t.Errorf("sleep = %+v", sleep)
+	}
+	if want := time.Date(2024, 3, 2, 6, 0, 0, 0, time.UTC); !first.Time.Equal(want) {
+		t.Errorf("sleep event time = %v, want %v", first.Time, want)
+	}
+	if sleep.SleepOnsetLatencyMinutes == nil || *sleep.SleepOnsetLatencyMinutes != 10 {
+		t.Errorf("onset latency = %v, want 10", sleep.SleepOnsetLatencyMinutes)
+	}
+
+	for _, e := range events {
+		if e.EventType != models.EventTypeReadiness {
+			continue
+		}
+		var readiness models.Readiness
+		json.Unmarshal(e.Data, &readiness)
+		if readiness.Score != 88 || readiness.RestingHeartRate != 49 || readiness.HRVAvg != 45 ||
+			readiness.TemperatureDeviation == nil || *readiness.TemperatureDeviation != -0.12 {
+			t.Errorf("readiness = %+v", readiness)
+		}
+	}
+}
+
+func TestBuildHypnogram(t *testing.T) {
+	start := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		phases     string
+		end        time.Time
+		wantStages []string
+		wantEnd    time.Time
+	}{
+		{
+			name:       "merges consecutive epochs",
+			phases:     "4221134",
+			end:        start.Add(35 * time.Minute),
+			wantStages: []string{"awake", "light", "deep", "rem", "awake"},
+			wantEnd:    start.Add(35 * time.Minute),
+		},
+		{
+			name:       "clamps to bedtime end",
+			phases:     "222",
+			end:        start.Add(12 * time.Minute),
+			wantStages: []string{"light"},
+			wantEnd:    start.Add(12 * time.Minute),
+		},
+		{
+			name:   "empty",
+			phases: "",
+			end:    start.Add(time.Hour),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := buildHypnogram("2024-03-02", start, tt.end, tt.phases)
+			if tt.wantStages == nil {
+				if h != nil {
+					t.Errorf("buildHypnogram() = %+v, want nil", h)
+				}
+				return
+			}
+			if len(h.Stages) != len(tt.wantStages) {
+				t.Fatalf("got %d stages, want %d", len(h.Stages), len(tt.wantStages))
+			}
+			for i, stage := range tt.wantStages {
+				if h.Stages[i].Stage != stage {
+					t.Errorf("stage %d = %s, want %s", i, h.Stages[i].Stage, stage)
+				}
+			}
+			if last := h.Stages[len(h.Stages)-1]; !last.End.Equal(tt.wantEnd) {
+				t.Errorf("last stage ends %v, want %v", last.End, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestParseExport_Empty(t *testing.T) {
+	if _, err := ParseExport(bytes.NewReader([]byte(`{"heart_rate": []}`))); err != ErrNoData {
+		t.Errorf("ParseExport() error = %v, want ErrNoData", err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package ouratest provides a fake Oura v2 API for tests and local development.
+package ouratest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// Fixture is a two-night Oura export, also served by the fake API.
+//
+//go:embed fixtures.json
+var Fixture []byte
+
+// Server is a fake Oura API serving Fixture.
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts a fake Oura API that accepts token as its bearer token
+// and returns pageSize documents per page.
+func NewServer(token string, pageSize int) *Server {
+	return &Server{Server: httptest.NewServer(Handler(token, pageSize))}
+}
+
+// Handler returns the fake API's handler, for serving it outside tests.
+func Handler(token string, pageSize int) http.Handler {
+	var collections map[string][]map[string]interface{}
+	if err := json.Unmarshal(Fixture, &collections); err != nil {
+		panic("ouratest: invalid fixture: " + err.Error())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, `{"detail":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		name, ok := strings.CutPrefix(r.URL.Path, "/v2/usercollection/")
+		docs, known := collections[name]
+		if !ok || !known {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Filter on day with start_date inclusive and end_date exclusive, as the real API does
+		start, end := r.URL.Query().Get("start_date"), r.URL.Query().Get("end_date")
+		var matched []map[string]interface{}
+		for _, doc := range docs {
+			day, _ := doc["day"].(string)
+			if (start == "" || day >= start) && (end == "" || day < end) {
+				matched = append(matched, doc)
+			}
+		}
+
+		offset, _ := strconv.Atoi(r.URL.Query().Get("next_token"))
+		page := map[string]interface{}{"data": []interface{}{}, "next_token": nil}
+		if offset < len(matched) {
+			last := min(offset+pageSize, len(matched))
+			page["data"] = matched[offset:last]
+			if last < len(matched) {
+				page["next_token"] = strconv.Itoa(last)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+}
@@ -0,0 +1,123 @@
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Plausibility bounds. Values outside them are almost always sensor or
+// export glitches rather than physiology.
+const (
+	MinHeartRate    = 25
+	MaxHeartRate    = 250
+	MaxSleepMinutes = 24 * 60
+	// Long wakes happen, but not most of a day inside one night's record
+	MaxAwakeMinutes = 6 * 60
+	MaxDailySteps   = 150000
+
+	// Stage minutes are rounded separately from the total, and devices
+	// disagree on whether brief wakes count, so allow some slack.
+	stageSumToleranceMinutes = 10
+	stageSumTolerancePercent = 5
+)
+
+// Check returns the reasons an event's values are implausible, or nil when
+// they look fine. Event types without rules always pass.
+func Check(event *models.Event) []string {
+	switch event.EventType {
+	case models.EventTypeGarminSleep:
+		var sleep models.GarminSleep
+		if err := json.Unmarshal(event.Data, &sleep); err != nil {
+			return []string{"data is not a valid sleep record"}
+		}
+		return checkSleep(sleep)
+	case models.EventTypeGarminDailyStats:
+		var stats models.GarminDailyStats
+		if err := json.Unmarshal(event.Data, &stats); err != nil {
+			return []string{"data is not a valid daily stats record"}
+		}
+		return checkDailyStats(stats)
+	case models.EventTypeGarminActivity:
+		var activity models.GarminActivity
+		if err := json.Unmarshal(event.Data, &activity); err != nil {
+			return []string{"data is not a valid activity record"}
+		}
+		return checkHeartRates(heartRate{"avg_hr", activity.AvgHR}, heartRate{"max_hr", activity.MaxHR})
+	case models.EventTypeReadiness:
+		var readiness models.Readiness
+		if err := json.Unmarshal(event.Data, &readiness); err != nil {
+			return []string{"data is not a valid readiness record"}
+		}
+		return checkHeartRates(heartRate{"resting_heart_rate", readiness.RestingHeartRate})
+	case models.EventTypeStrain:
+		var strain models.Strain
+		if err := json.Unmarshal(event.Data, &strain); err != nil {
+			return []string{"data is not a valid strain record"}
+		}
+		return checkHeartRates(heartRate{"avg_hr", strain.AvgHR}, heartRate{"max_hr", strain.MaxHR})
+	}
+	return nil
+}
+
+func checkSleep(sleep models.GarminSleep) []string {
+	var reasons []string
+
+	if sleep.DurationMinutes >= MaxSleepMinutes {
+		reasons = append(reasons, fmt.Sprintf("sleep duration %d min is not under 24h", sleep.DurationMinutes))
+	}
+	if inBed := sleep.DurationMinutes + sleep.AwakeMinutes; inBed > MaxSleepMinutes {
+		reasons = append(reasons, fmt.Sprintf("sleep plus awake time %d min exceeds 24h", inBed))
+	}
+	if sleep.AwakeMinutes > MaxAwakeMinutes {
+		reasons = append(reasons, fmt.Sprintf("awake time %d min exceeds %d min", sleep.AwakeMinutes, MaxAwakeMinutes))
+	}
+
+	// Only check the breakdown when stages were reported at all
+	stages := sleep.DeepSleepMinutes + sleep.LightSleepMinutes + sleep.REMSleepMinutes
+	if stages > 0 {
+		tolerance := sleep.DurationMinutes * stageSumTolerancePercent / 100
+		if tolerance < stageSumToleranceMinutes {
+			tolerance = stageSumToleranceMinutes
+		}
+		if diff := stages - sleep.DurationMinutes; diff > tolerance || -diff > tolerance {
+			reasons = append(reasons, fmt.Sprintf("sleep stages sum to %d min but duration is %d min", stages, sleep.DurationMinutes))
+		}
+	}
+
+	return reasons
+}
+
+func checkDailyStats(stats models.GarminDailyStats) []string {
+	var reasons []string
+
+	if stats.Steps >= MaxDailySteps {
+		reasons = append(reasons, fmt.Sprintf("steps %d is not under %d", stats.Steps, MaxDailySteps))
+	}
+	reasons = append(reasons, checkHeartRates(
+		heartRate{"min_heart_rate", stats.MinHeartRate},
+		heartRate{"max_heart_rate", stats.MaxHeartRate},
+		heartRate{"resting_heart_rate", stats.RestingHeartRate},
+	)...)
+
+	return reasons
+}
+
+// heartRate is a named heart rate field, in bpm.
+type heartRate struct {
+	field string
+	bpm   int
+}
+
+// checkHeartRates checks each reported heart rate against the plausible
+// range. Zero means the field wasn't reported.
+func checkHeartRates(rates ...heartRate) []string {
+	var reasons []string
+	for _, hr := range rates {
+		if hr.bpm != 0 && (hr.bpm < MinHeartRate || hr.bpm > MaxHeartRate) {
+			reasons = append(reasons, fmt.Sprintf("%s %d bpm is outside %d-%d", hr.field, hr.bpm, MinHeartRate, MaxHeartRate))
+		}
+	}
+	return reasons
+}
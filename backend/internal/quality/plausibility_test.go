@@ -0,0 +1,110 @@
+package quality
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func event(t *testing.T, eventType string, data interface{}) *models.Event {
+	t.Helper()
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &models.Event{EventType: eventType, Data: dataJSON}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		event       *models.Event
+		wantReasons int
+	}{
+		{
+			name: "normal night",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{
+				DurationMinutes: 432, DeepSleepMinutes: 92, LightSleepMinutes: 254, REMSleepMinutes: 86, AwakeMinutes: 12,
+			}),
+		},
+		{
+			name:  "night without stage breakdown",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{DurationMinutes: 420}),
+		},
+		{
+			name: "stages don't add up to duration",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{
+				DurationMinutes: 480, LightSleepMinutes: 200, REMSleepMinutes: 60,
+			}),
+			wantReasons: 1,
+		},
+		{
+			name: "corrupt night with hours awake",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{
+				DurationMinutes: 600, LightSleepMinutes: 500, REMSleepMinutes: 100, AwakeMinutes: 900,
+			}),
+			wantReasons: 2,
+		},
+		{
+			name: "corrupt night with no deep sleep and 900 min awake",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{
+				DurationMinutes: 420, DeepSleepMinutes: 0, LightSleepMinutes: 330, REMSleepMinutes: 90, AwakeMinutes: 900,
+			}),
+			wantReasons: 1,
+		},
+		{
+			name: "restless night at the awake bound",
+			event: event(t, models.EventTypeGarminSleep, models.GarminSleep{
+				DurationMinutes: 300, LightSleepMinutes: 220, REMSleepMinutes: 80, AwakeMinutes: 360,
+			}),
+		},
+		{
+			name:        "sleep of a full day",
+			event:       event(t, models.EventTypeGarminSleep, models.GarminSleep{DurationMinutes: 1440}),
+			wantReasons: 1,
+		},
+		{
+			name:  "normal day",
+			event: event(t, models.EventTypeGarminDailyStats, models.GarminDailyStats{Steps: 12000, MinHeartRate: 48, MaxHeartRate: 171, RestingHeartRate: 52}),
+		},
+		{
+			name:        "step counter glitch",
+			event:       event(t, models.EventTypeGarminDailyStats, models.GarminDailyStats{Steps: 150000}),
+			wantReasons: 1,
+		},
+		{
+			name:        "heart rates out of range",
+			event:       event(t, models.EventTypeGarminDailyStats, models.GarminDailyStats{Steps: 8000, MinHeartRate: 12, MaxHeartRate: 251}),
+			wantReasons: 2,
+		},
+		{
+			name:        "activity max hr too high",
+			event:       event(t, models.EventTypeGarminActivity, models.GarminActivity{ActivityType: "running", AvgHR: 150, MaxHR: 300}),
+			wantReasons: 1,
+		},
+		{
+			name:        "strain at the bounds",
+			event:       event(t, models.EventTypeStrain, models.Strain{Score: 12, AvgHR: 25, MaxHR: 250}),
+			wantReasons: 0,
+		},
+		{
+			name:  "types without rules pass",
+			event: event(t, models.EventTypeGarminStress, models.GarminStress{AverageStressLevel: 40}),
+		},
+		{
+			name:        "unreadable data",
+			event:       &models.Event{EventType: models.EventTypeGarminSleep, Data: []byte(`"oops"`)},
+			wantReasons: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons := Check(tt.event)
+			if len(reasons) != tt.wantReasons {
+				t.Errorf("Check() = %v, want %d reasons", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package quality normalizes incoming measurements to the units we store and
+// flags physiologically implausible values before they reach the events table.
+package quality
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Measures with a unit normalization. Values are stored in meters and
+// kilograms respectively.
+const (
+	MeasureDistance = "distance"
+	MeasureWeight   = "weight"
+)
+
+// unitFactors maps each measure's accepted units onto the factor that
+// converts them to the stored unit.
+var unitFactors = map[string]map[string]float64{
+	MeasureDistance: {
+		"m":  1,
+		"km": 1000,
+		"mi": 1609.344,
+	},
+	MeasureWeight: {
+		"kg": 1,
+		"g":  0.001,
+		"lb": 0.45359237,
+	},
+}
+
+// Normalize converts value in unit to measure's stored unit. Units are
+// case-insensitive; an unknown measure or unit is an error.
+func Normalize(measure string, value float64, unit string) (float64, error) {
+	factors, ok := unitFactors[measure]
+	if !ok {
+		return 0, fmt.Errorf("unknown measure %q", measure)
+	}
+	factor, ok := factors[strings.ToLower(strings.TrimSpace(unit))]
+	if !ok {
+		return 0, fmt.Errorf("unsupported %s unit %q", measure, unit)
+	}
+	return value * factor, nil
+}
@@ -0,0 +1,36 @@
+package quality
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		measure string
+		value   float64
+		unit    string
+		want    float64
+		wantErr bool
+	}{
+		{MeasureDistance, 5000, "m", 5000, false},
+		{MeasureDistance, 5, "km", 5000, false},
+		{MeasureDistance, 1, "mi", 1609.344, false},
+		{MeasureDistance, 3, "KM", 3000, false},
+		{MeasureDistance, 1, "yd", 0, true},
+		{MeasureWeight, 70, "kg", 70, false},
+		{MeasureWeight, 200, "lb", 90.718474, false},
+		{MeasureWeight, 70500, "g", 70.5, false},
+		{MeasureWeight, 11, "st", 0, true},
+		{"temperature", 37, "c", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.measure+"_"+tt.unit, func(t *testing.T) {
+			got, err := Normalize(tt.measure, tt.value, tt.unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+// Package quarantine holds ingested data that failed validation or
+// plausibility checks for review, instead of storing it or dropping it.
+package quarantine
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Entry status constants
+const (
+	StatusPending   = "pending"
+	StatusReleased  = "released"
+	StatusDiscarded = "discarded"
+)
+
+// Entry stage constants: the check an entry failed.
+const (
+	// StageValidation entries were rejected by a Validate*Payload function
+	// and keep the payload exactly as it was posted.
+	StageValidation = "validation"
+	// StagePlausibility entries were transformed into an event that failed
+	// the quality checks.
+	StagePlausibility = "plausibility"
+)
+
+// Entry is ingested data held back from the events table, with why.
+// Validation entries carry Payload; plausibility entries carry the event
+// fields and Data.
+type Entry struct {
+	ID         string          `json:"id"`
+	Stage      string          `json:"stage"`
+	DataType   string          `json:"data_type"`
+	UserID     *string         `json:"user_id"`
+	EventType  *string         `json:"event_type,omitempty"`
+	Source     string          `json:"source"`
+	EventTime  *time.Time      `json:"event_time,omitempty"`
+	Data       json.RawMessage `json:"data,omitempty"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Reasons    []string        `json:"reasons"`
+	Status     string          `json:"status"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ReviewedAt *time.Time      `json:"reviewed_at,omitempty"`
+}
@@ -0,0 +1,359 @@
+package quarantine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quality"
+)
+
+// Converter turns a raw ingest payload of dataType into events, validating
+// it the same way the ingest endpoint does.
+type Converter func(dataType string, payload json.RawMessage) ([]*models.Event, error)
+
+// Handler serves the admin review endpoints for quarantined data.
+type Handler struct {
+	repo       Store
+	eventRepo  db.EventStore
+	converters map[string]Converter // keyed by source
+}
+
+// NewHandler creates a new quarantine Handler. converters replay
+// validation rejects, keyed by the source that sent them.
+func NewHandler(repo Store, eventRepo db.EventStore, converters map[string]Converter) *Handler {
+	return &Handler{repo: repo, eventRepo: eventRepo, converters: converters}
+}
+
+// HandleList handles GET /api/v1/admin/quarantine?status=&data_type=&limit=
+// Status defaults to pending; pass status=all for every entry.
+func (h *Handler) HandleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := ListFilter{
+		Status:   query.Get("status"),
+		DataType: query.Get("data_type"),
+		Limit:    50,
+	}
+	switch filter.Status {
+	case "":
+		filter.Status = StatusPending
+	case "all":
+		filter.Status = ""
+	case StatusPending, StatusReleased, StatusDiscarded:
+	default:
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 500 {
+			http.Error(w, "Invalid limit (must be 1-500)", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		log.Printf("Failed to list quarantine: %v", err)
+		http.Error(w, "Failed to retrieve quarantine", http.StatusInternalServerError)
+		return
+	}
+	if entries == nil {
+		entries = []Entry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"count":   len(entries),
+		"entries": entries,
+	})
+}
+
+// HandleStats handles GET /api/v1/admin/quarantine/stats
+// Rejection rates cover ingest endpoint traffic since the server started.
+func (h *Handler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pending, err := h.repo.PendingCounts(r.Context())
+	if err != nil {
+		log.Printf("Failed to count quarantine: %v", err)
+		http.Error(w, "Failed to retrieve quarantine stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"pending":         pending,
+		"rejection_rates": RejectionRates(),
+	})
+}
+
+// HandleEntry handles GET and DELETE /api/v1/admin/quarantine/{id}
+// DELETE discards a pending entry.
+func (h *Handler) HandleEntry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	entry, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.repo.Review(r.Context(), entry.ID, StatusDiscarded); err != nil {
+			h.reviewFailed(w, err)
+			return
+		}
+		log.Printf("Discarded quarantined %s entry %s", entry.DataType, entry.ID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"id":     entry.ID,
+			"action": StatusDiscarded,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status": "success",
+		"entry":  entry,
+	})
+}
+
+// replayRequest optionally replaces what was quarantined before replaying
+// it: the payload of a validation entry, or the event data of a
+// plausibility entry. Force skips the plausibility checks.
+type replayRequest struct {
+	Payload json.RawMessage `json:"payload"`
+	Data    json.RawMessage `json:"data"`
+	Force   bool            `json:"force"`
+}
+
+// HandleReplay handles POST /api/v1/admin/quarantine/{id}/replay
+// The entry is stored as events and released. If it still fails
+// validation or plausibility it stays pending and 422 says why.
+func (h *Handler) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req replayRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	entry, ok := h.lookup(w, r)
+	if !ok {
+		return
+	}
+	if entry.Status != StatusPending {
+		h.reviewFailed(w, ErrNotPending)
+		return
+	}
+
+	events, err := h.events(entry, req)
+	if err != nil {
+		writeUnprocessable(w, []string{err.Error()})
+		return
+	}
+	if !req.Force {
+		var reasons []string
+		for _, event := range events {
+			reasons = append(reasons, quality.Check(event)...)
+		}
+		if len(reasons) > 0 {
+			writeUnprocessable(w, reasons)
+			return
+		}
+	}
+
+	inserted, err := h.eventRepo.InsertEvents(r.Context(), events)
+	if err != nil {
+		log.Printf("Failed to replay quarantine entry %s: %v", entry.ID, err)
+		http.Error(w, "Failed to store events", http.StatusInternalServerError)
+		return
+	}
+	if err := h.repo.Review(r.Context(), entry.ID, StatusReleased); err != nil {
+		h.reviewFailed(w, err)
+		return
+	}
+	log.Printf("Replayed quarantined %s entry %s (%d events)", entry.DataType, entry.ID, len(events))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"id":              entry.ID,
+		"action":          StatusReleased,
+		"events_stored":   len(events),
+		"events_inserted": inserted,
+	})
+}
+
+// maxReprocess caps how many entries one reprocess request works through.
+const maxReprocess = 500
+
+// HandleReprocessUser handles POST /api/v1/admin/users/{id}/reprocess
+// It replays every pending entry of the user, optionally only of one
+// data_type, unedited: useful after a validator or plausibility rule was
+// fixed. Entries that now pass are stored and released; the rest stay
+// pending.
+func (h *Handler) HandleReprocessUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		DataType string `json:"data_type"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	userID := r.PathValue("id")
+	entries, err := h.repo.List(r.Context(), ListFilter{
+		Status:   StatusPending,
+		DataType: req.DataType,
+		UserID:   userID,
+		Limit:    maxReprocess,
+	})
+	if err != nil {
+		log.Printf("Failed to list quarantine for reprocessing: %v", err)
+		http.Error(w, "Failed to retrieve quarantine", http.StatusInternalServerError)
+		return
+	}
+
+	released, stillPending, inserted := 0, 0, 0
+	for i := range entries {
+		entry := &entries[i]
+		events, err := h.events(entry, replayRequest{})
+		if err != nil {
+			stillPending++
+			continue
+		}
+		var reasons []string
+		for _, event := range events {
+			reasons = append(reasons, quality.Check(event)...)
+		}
+		if len(reasons) > 0 {
+			stillPending++
+			continue
+		}
+
+		n, err := h.eventRepo.InsertEvents(r.Context(), events)
+		if err != nil {
+			log.Printf("Failed to reprocess quarantine entry %s: %v", entry.ID, err)
+			http.Error(w, "Failed to store events", http.StatusInternalServerError)
+			return
+		}
+		if err := h.repo.Review(r.Context(), entry.ID, StatusReleased); err != nil && !errors.Is(err, ErrNotPending) {
+			h.reviewFailed(w, err)
+			return
+		}
+		released++
+		inserted += n
+	}
+	log.Printf("Reprocessed quarantine for user %s: %d released, %d still pending", userID, released, stillPending)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"user_id":         userID,
+		"checked":         len(entries),
+		"released":        released,
+		"still_pending":   stillPending,
+		"events_inserted": inserted,
+	})
+}
+
+// events rebuilds the events an entry stands for, applying req's edits.
+func (h *Handler) events(entry *Entry, req replayRequest) ([]*models.Event, error) {
+	if entry.Stage == StageValidation {
+		payload := entry.Payload
+		if len(req.Payload) > 0 {
+			payload = req.Payload
+		}
+		convert, ok := h.converters[entry.Source]
+		if !ok {
+			return nil, fmt.Errorf("cannot replay %s payloads", entry.Source)
+		}
+		return convert(entry.DataType, payload)
+	}
+
+	if entry.UserID == nil || entry.EventType == nil || entry.EventTime == nil {
+		return nil, errors.New("entry is missing event fields")
+	}
+	data := entry.Data
+	if len(req.Data) > 0 {
+		if !json.Valid(req.Data) {
+			return nil, errors.New("data is not valid JSON")
+		}
+		data = req.Data
+	}
+	return []*models.Event{{
+		Time:      *entry.EventTime,
+		UserID:    *entry.UserID,
+		EventType: *entry.EventType,
+		Source:    entry.Source,
+		Data:      data,
+	}}, nil
+}
+
+func (h *Handler) lookup(w http.ResponseWriter, r *http.Request) (*Entry, bool) {
+	entry, err := h.repo.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		log.Printf("Failed to get quarantine entry: %v", err)
+		http.Error(w, "Failed to retrieve quarantine entry", http.StatusInternalServerError)
+		return nil, false
+	}
+	if entry == nil {
+		http.Error(w, "Quarantine entry not found", http.StatusNotFound)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (h *Handler) reviewFailed(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotPending) {
+		http.Error(w, "Quarantine entry already reviewed", http.StatusConflict)
+		return
+	}
+	log.Printf("Failed to review quarantine entry: %v", err)
+	http.Error(w, "Failed to update quarantine entry", http.StatusInternalServerError)
+}
+
+func writeUnprocessable(w http.ResponseWriter, reasons []string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "error",
+		"reasons": reasons,
+	})
+}
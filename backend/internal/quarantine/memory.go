@@ -0,0 +1,138 @@
+package quarantine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// MemoryStore keeps quarantine entries in memory with the same semantics
+// as Repository, except that Reject links any user ID it is given: there
+// is no users table to check it against. It is for tests.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries []*Entry // in insertion order
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) add(e *Entry) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e.ID = db.NewUUID()
+	e.Status = StatusPending
+	e.CreatedAt = time.Now()
+	s.entries = append(s.entries, e)
+	return e.ID
+}
+
+// Hold implements Store.
+func (s *MemoryStore) Hold(ctx context.Context, event *models.Event, reasons []string) (string, error) {
+	userID, eventType, eventTime := event.UserID, event.EventType, event.Time
+	return s.add(&Entry{
+		Stage:     StagePlausibility,
+		DataType:  event.EventType,
+		UserID:    &userID,
+		EventType: &eventType,
+		Source:    event.Source,
+		EventTime: &eventTime,
+		Data:      append([]byte(nil), event.Data...),
+		Reasons:   append([]string(nil), reasons...),
+	}), nil
+}
+
+// Reject implements Store.
+func (s *MemoryStore) Reject(ctx context.Context, dataType, source, userID string, payload []byte, reason string) (string, error) {
+	var linked *string
+	if userID != "" {
+		linked = &userID
+	}
+	return s.add(&Entry{
+		Stage:    StageValidation,
+		DataType: dataType,
+		UserID:   linked,
+		Source:   source,
+		Payload:  append([]byte(nil), payload...),
+		Reasons:  []string{reason},
+	}), nil
+}
+
+// Screen implements Store.
+func (s *MemoryStore) Screen(ctx context.Context, events []*models.Event) ([]*models.Event, int, error) {
+	return screen(ctx, s, events)
+}
+
+// List implements Store.
+func (s *MemoryStore) List(ctx context.Context, filter ListFilter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var entries []Entry
+	for i := len(s.entries) - 1; i >= 0 && len(entries) < filter.Limit; i-- {
+		e := s.entries[i]
+		if (filter.Status != "" && e.Status != filter.Status) ||
+			(filter.DataType != "" && e.DataType != filter.DataType) ||
+			(filter.UserID != "" && (e.UserID == nil || *e.UserID != filter.UserID)) {
+			continue
+		}
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.entries {
+		if e.ID == id {
+			entry := *e
+			return &entry, nil
+		}
+	}
+	return nil, nil
+}
+
+// Review implements Store.
+func (s *MemoryStore) Review(ctx context.Context, id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch status {
+	case StatusReleased, StatusDiscarded:
+	default:
+		return fmt.Errorf("failed to review quarantine entry: invalid status %q", status)
+	}
+	for _, e := range s.entries {
+		if e.ID == id && e.Status == StatusPending {
+			now := time.Now()
+			e.Status = status
+			e.ReviewedAt = &now
+			return nil
+		}
+	}
+	return ErrNotPending
+}
+
+// PendingCounts implements Store.
+func (s *MemoryStore) PendingCounts(ctx context.Context) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, e := range s.entries {
+		if e.Status == StatusPending {
+			counts[e.DataType]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,63 @@
+package quarantine
+
+import (
+	"expvar"
+	"sort"
+	"sync"
+)
+
+// Ingest counters per data type since the process started, published on
+// /debug/vars as ingest_received and ingest_rejected.
+var (
+	received = expvar.NewMap("ingest_received")
+	rejected = expvar.NewMap("ingest_rejected")
+)
+
+var (
+	mu     sync.Mutex
+	counts = map[string]*Rate{}
+)
+
+// Rate is how much of one data type was quarantined.
+type Rate struct {
+	DataType string  `json:"data_type"`
+	Received int64   `json:"received"`
+	Rejected int64   `json:"rejected"`
+	Rate     float64 `json:"rate"`
+}
+
+// Observe records one ingested payload of dataType, and whether it was
+// quarantined.
+func Observe(dataType string, wasRejected bool) {
+	received.Add(dataType, 1)
+	if wasRejected {
+		rejected.Add(dataType, 1)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	c, ok := counts[dataType]
+	if !ok {
+		c = &Rate{DataType: dataType}
+		counts[dataType] = c
+	}
+	c.Received++
+	if wasRejected {
+		c.Rejected++
+	}
+	c.Rate = float64(c.Rejected) / float64(c.Received)
+}
+
+// RejectionRates returns the rejection rate of every observed data type,
+// sorted by data type.
+func RejectionRates() []Rate {
+	mu.Lock()
+	defer mu.Unlock()
+
+	rates := make([]Rate, 0, len(counts))
+	for _, c := range counts {
+		rates = append(rates, *c)
+	}
+	sort.Slice(rates, func(i, j int) bool { return rates[i].DataType < rates[j].DataType })
+	return rates
+}
@@ -0,0 +1,44 @@
+package quarantine
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestRejectionRates(t *testing.T) {
+	Observe("test_sleep", false)
+	Observe("test_sleep", true)
+	Observe("test_sleep", false)
+	Observe("test_sleep", true)
+	Observe("test_hrv", false)
+
+	rates := map[string]Rate{}
+	for _, r := range RejectionRates() {
+		rates[r.DataType] = r
+	}
+
+	tests := []struct {
+		dataType string
+		received int64
+		rejected int64
+		rate     float64
+	}{
+		{"test_sleep", 4, 2, 0.5},
+		{"test_hrv", 1, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			got, ok := rates[tt.dataType]
+			if !ok {
+				t.Fatalf("no rate for %s", tt.dataType)
+			}
+			if got.Received != tt.received || got.Rejected != tt.rejected || got.Rate != tt.rate {
+				t.Errorf("got %+v, want received=%d rejected=%d rate=%v", got, tt.received, tt.rejected, tt.rate)
+			}
+			if v := received.Get(tt.dataType); v == nil || v.String() != strconv.FormatInt(tt.received, 10) {
+				t.Errorf("expvar ingest_received[%s] = %v, want %d", tt.dataType, v, tt.received)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package quarantine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/quality"
+)
+
+// ErrNotPending is returned when reviewing an entry that was already
+// released or discarded.
+var ErrNotPending = errors.New("quarantine entry is not pending")
+
+// Store holds quarantined payloads and events for review. Repository
+// implements it on Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	Hold(ctx context.Context, event *models.Event, reasons []string) (string, error)
+	Reject(ctx context.Context, dataType, source, userID string, payload []byte, reason string) (string, error)
+	Screen(ctx context.Context, events []*models.Event) ([]*models.Event, int, error)
+	List(ctx context.Context, filter ListFilter) ([]Entry, error)
+	Get(ctx context.Context, id string) (*Entry, error)
+	Review(ctx context.Context, id, status string) error
+	PendingCounts(ctx context.Context) (map[string]int, error)
+}
+
+// Repository handles database operations for quarantined events.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new quarantine Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+const entryColumns = `
+	id, stage, data_type, user_id, event_type, source, event_time,
+	data, payload, reasons, status, created_at, reviewed_at
+`
+
+func scanEntry(row interface{ Scan(...interface{}) error }) (*Entry, error) {
+	var e Entry
+	err := row.Scan(
+		&e.ID, &e.Stage, &e.DataType, &e.UserID, &e.EventType, &e.Source, &e.EventTime,
+		&e.Data, &e.Payload, &e.Reasons, &e.Status, &e.CreatedAt, &e.ReviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Hold quarantines event for the given reasons and returns the entry ID.
+func (r *Repository) Hold(ctx context.Context, event *models.Event, reasons []string) (string, error) {
+	query := `
+		INSERT INTO ingest_quarantine (stage, data_type, user_id, event_type, source, event_time, data, reasons)
+		VALUES ($1, $2, $3, $2, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id string
+	err := r.db.Pool.QueryRow(ctx, query,
+		StagePlausibility, event.EventType, event.UserID, event.Source, event.Time, event.Data, reasons,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to quarantine event: %w", err)
+	}
+	return id, nil
+}
+
+// Reject quarantines a payload that failed validation and returns the
+// entry ID. userID is whatever the payload claimed; it is only linked when
+// it names an existing user.
+func (r *Repository) Reject(ctx context.Context, dataType, source, userID string, payload []byte, reason string) (string, error) {
+	query := `
+		INSERT INTO ingest_quarantine (stage, data_type, user_id, source, payload, reasons)
+		VALUES ($1, $2, (SELECT id FROM users WHERE id::text = $3), $4, $5, $6)
+		RETURNING id
+	`
+
+	var id string
+	err := r.db.Pool.QueryRow(ctx, query,
+		StageValidation, dataType, userID, source, payload, []string{reason},
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("failed to quarantine payload: %w", err)
+	}
+	return id, nil
+}
+
+// Screen runs every event through the plausibility checks, quarantining
+// the implausible ones. It returns the events that passed and how many
+// were held.
+func (r *Repository) Screen(ctx context.Context, events []*models.Event) ([]*models.Event, int, error) {
+	return screen(ctx, r, events)
+}
+
+// screen implements Screen for any Store, holding events with store.
+func screen(ctx context.Context, store Store, events []*models.Event) ([]*models.Event, int, error) {
+	passed := make([]*models.Event, 0, len(events))
+	held := 0
+	for _, event := range events {
+		reasons := quality.Check(event)
+		if len(reasons) == 0 {
+			passed = append(passed, event)
+			continue
+		}
+		if _, err := store.Hold(ctx, event, reasons); err != nil {
+			return nil, held, err
+		}
+		held++
+	}
+	return passed, held, nil
+}
+
+// ListFilter narrows List. Empty fields match everything.
+type ListFilter struct {
+	Status   string
+	DataType string
+	UserID   string
+	Limit    int
+}
+
+// List retrieves entries matching filter, newest first.
+func (r *Repository) List(ctx context.Context, filter ListFilter) ([]Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM ingest_quarantine
+		WHERE ($1 = '' OR status = $1)
+		  AND ($2 = '' OR data_type = $2)
+		  AND ($4 = '' OR user_id::text = $4)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.Status, filter.DataType, filter.Limit, filter.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quarantine: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quarantine entry: %w", err)
+		}
+		entries = append(entries, *entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quarantine: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Get retrieves an entry by ID. It returns nil if there is no such entry.
+func (r *Repository) Get(ctx context.Context, id string) (*Entry, error) {
+	query := `SELECT ` + entryColumns + ` FROM ingest_quarantine WHERE id::text = $1`
+
+	entry, err := scanEntry(r.db.Pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quarantine entry: %w", err)
+	}
+	return entry, nil
+}
+
+// Review moves a pending entry to status (released or discarded). It
+// returns ErrNotPending if the entry was already reviewed.
+func (r *Repository) Review(ctx context.Context, id, status string) error {
+	query := `
+		UPDATE ingest_quarantine
+		SET status = $2, reviewed_at = NOW()
+		WHERE id::text = $1 AND status = 'pending'
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id, status)
+	if err != nil {
+		return fmt.Errorf("failed to review quarantine entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotPending
+	}
+	return nil
+}
+
+// PendingCounts returns the number of pending entries per data type.
+func (r *Repository) PendingCounts(ctx context.Context) (map[string]int, error) {
+	query := `
+		SELECT data_type, COUNT(*)
+		FROM ingest_quarantine
+		WHERE status = 'pending'
+		GROUP BY data_type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count quarantine: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var dataType string
+		var count int
+		if err := rows.Scan(&dataType, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan quarantine count: %w", err)
+		}
+		counts[dataType] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quarantine counts: %w", err)
+	}
+
+	return counts, nil
+}
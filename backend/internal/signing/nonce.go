@@ -0,0 +1,50 @@
+package signing
+
+import (
+	"sync"
+	"time"
+)
+
+// NonceCache remembers recently used nonces in memory. Each server instance
+// keeps its own, which is enough for the single ingestion service.
+type NonceCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	expires map[string]time.Time
+	pruned  time.Time
+}
+
+// NewNonceCache creates a NonceCache that forgets nonces after ttl.
+func NewNonceCache(ttl time.Duration) *NonceCache {
+	return &NonceCache{ttl: ttl, expires: make(map[string]time.Time)}
+}
+
+// Add records nonce as used at now. It returns false if the nonce was
+// already used within the TTL.
+func (c *NonceCache) Add(nonce string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.pruned) >= c.ttl {
+		for n, expiry := range c.expires {
+			if !now.Before(expiry) {
+				delete(c.expires, n)
+			}
+		}
+		c.pruned = now
+	}
+
+	if expiry, ok := c.expires[nonce]; ok && now.Before(expiry) {
+		return false
+	}
+	c.expires[nonce] = now.Add(c.ttl)
+	return true
+}
+
+// Len returns how many nonces are remembered.
+func (c *NonceCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.expires)
+}
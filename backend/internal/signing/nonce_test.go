@@ -0,0 +1,35 @@
+package signing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNonceCache(t *testing.T) {
+	start := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	c := NewNonceCache(10 * time.Minute)
+
+	steps := []struct {
+		nonce string
+		at    time.Duration
+		want  bool
+	}{
+		{"a", 0, true},
+		{"a", time.Minute, false},
+		{"b", 2 * time.Minute, true},
+		{"a", 9 * time.Minute, false},
+		{"a", 10 * time.Minute, true}, // expired, usable again
+	}
+
+	for i, s := range steps {
+		if got := c.Add(s.nonce, start.Add(s.at)); got != s.want {
+			t.Errorf("step %d: Add(%q) = %v, want %v", i, s.nonce, got, s.want)
+		}
+	}
+
+	// Pruning drops expired nonces once a TTL has passed
+	c.Add("c", start.Add(25*time.Minute))
+	if got := c.Len(); got != 1 {
+		t.Errorf("Len() after pruning = %d, want 1", got)
+	}
+}
@@ -0,0 +1,120 @@
+package signing
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStringToSign(t *testing.T) {
+	got := StringToSign("post", "/api/v1/garmin/ingest/sleep", "1769600000", "n1", []byte("{}"))
+	want := "POST\n/api/v1/garmin/ingest/sleep\n1769600000\nn1\n" +
+		"44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	if got != want {
+		t.Errorf("StringToSign() = %q, want %q", got, want)
+	}
+}
+
+// The Python scheduler's test uses the same vector.
+func TestSign(t *testing.T) {
+	got := Sign([]byte("k"), "POST", "/p", "1", "n", []byte("{}"))
+	want := "dc533d121478677b31b401e711c106dd27430e570fd95d76d2af2cccdb71f11c"
+	if got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"date":"2026-01-28"}`)
+
+	type request struct {
+		keyID     string
+		secret    string
+		path      string
+		signPath  string
+		timestamp time.Time
+		nonce     string
+		body      []byte
+	}
+	valid := func() request {
+		return request{
+			keyID: "k2", secret: "secret-two",
+			path: "/api/v1/garmin/ingest/hrv", signPath: "/api/v1/garmin/ingest/hrv",
+			timestamp: now, nonce: "nonce-1", body: body,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*request)
+		wantErr error
+	}{
+		{name: "valid", modify: func(*request) {}},
+		{name: "older key still active", modify: func(r *request) { r.keyID, r.secret = "k1", "secret-one" }},
+		{name: "within skew", modify: func(r *request) { r.timestamp = now.Add(-4 * time.Minute) }},
+		{name: "unknown key", modify: func(r *request) { r.keyID = "k3" }, wantErr: ErrUnknownKey},
+		{name: "wrong secret", modify: func(r *request) { r.secret = "secret-one" }, wantErr: ErrBadSignature},
+		{name: "too old", modify: func(r *request) { r.timestamp = now.Add(-6 * time.Minute) }, wantErr: ErrBadTimestamp},
+		{name: "too far ahead", modify: func(r *request) { r.timestamp = now.Add(6 * time.Minute) }, wantErr: ErrBadTimestamp},
+		{name: "missing nonce", modify: func(r *request) { r.nonce = "" }, wantErr: ErrBadNonce},
+		{name: "signed for another path", modify: func(r *request) { r.signPath = "/api/v1/garmin/ingest/sleep" }, wantErr: ErrBadSignature},
+		{name: "body tampered", modify: func(r *request) { r.body = []byte(`{"date":"2026-01-29"}`) }, wantErr: ErrBadSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(map[string]string{"k1": "secret-one", "k2": "secret-two"}, 5*time.Minute)
+			v.now = func() time.Time { return now }
+
+			req := valid()
+			tt.modify(&req)
+			timestamp := strconv.FormatInt(req.timestamp.Unix(), 10)
+
+			r := httptest.NewRequest("POST", req.path, nil)
+			r.Header.Set(HeaderKeyID, req.keyID)
+			r.Header.Set(HeaderTimestamp, timestamp)
+			r.Header.Set(HeaderNonce, req.nonce)
+			r.Header.Set(HeaderSignature, Sign([]byte(req.secret), "POST", req.signPath, timestamp, req.nonce, body))
+
+			keyID, err := v.Verify(r, req.body)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && keyID != req.keyID {
+				t.Errorf("Verify() key = %q, want %q", keyID, req.keyID)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsReplay(t *testing.T) {
+	now := time.Date(2026, 1, 28, 12, 0, 0, 0, time.UTC)
+	v := NewVerifier(map[string]string{"k1": "secret"}, 5*time.Minute)
+	v.now = func() time.Time { return now }
+
+	body := []byte(`{}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	for i, tt := range []struct {
+		nonce   string
+		wantErr error
+	}{
+		{"a", nil},
+		{"a", ErrReplay},
+		{"b", nil},
+	} {
+		r := httptest.NewRequest("POST", "/ingest", strings.NewReader(string(body)))
+		r.Header.Set(HeaderKeyID, "k1")
+		r.Header.Set(HeaderTimestamp, timestamp)
+		r.Header.Set(HeaderNonce, tt.nonce)
+		r.Header.Set(HeaderSignature, Sign([]byte("secret"), "POST", "/ingest", timestamp, tt.nonce, body))
+
+		if _, err := v.Verify(r, body); !errors.Is(err, tt.wantErr) {
+			t.Errorf("request %d: Verify() error = %v, want %v", i, err, tt.wantErr)
+		}
+	}
+}
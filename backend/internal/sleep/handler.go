@@ -0,0 +1,86 @@
+package sleep
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Handler handles sleep detail endpoints.
+type Handler struct {
+	eventRepo db.EventStore
+}
+
+// NewHandler creates a new sleep Handler.
+func NewHandler(eventRepo db.EventStore) *Handler {
+	return &Handler{eventRepo: eventRepo}
+}
+
+// HandleGetHypnogram handles GET /api/v1/sleep/{date}/hypnogram
+func (h *Handler) HandleGetHypnogram(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	dateParam := r.PathValue("date")
+	date, err := time.Parse("2006-01-02", dateParam)
+	if err != nil {
+		http.Error(w, "date must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+
+	// Hypnograms are stored at the sleep end timestamp (GMT), which can fall on the
+	// neighbouring UTC day depending on the user's timezone, so search around the date
+	// and match on the calendar date recorded at ingestion.
+	events, err := h.eventRepo.GetEventsByUserAndType(
+		r.Context(), userID, models.EventTypeSleepHypnogram, date.AddDate(0, 0, -1), date.AddDate(0, 0, 2),
+	)
+	if err != nil {
+		log.Printf("Failed to fetch hypnogram: %v", err)
+		http.Error(w, "Failed to fetch hypnogram", http.StatusInternalServerError)
+		return
+	}
+
+	var hypnogram *models.SleepHypnogram
+	for _, event := range events {
+		var candidate models.SleepHypnogram
+		if err := json.Unmarshal(event.Data, &candidate); err != nil {
+			log.Printf("Failed to parse hypnogram data: %v", err)
+			continue
+		}
+		if candidate.Date == dateParam {
+			hypnogram = &candidate
+			break
+		}
+	}
+
+	if hypnogram == nil {
+		http.Error(w, "No hypnogram for date", http.StatusNotFound)
+		return
+	}
+
+	response := map[string]interface{}{
+		"status":    "success",
+		"date":      dateParam,
+		"hypnogram": hypnogram,
+	}
+	if metrics, ok := DeriveMetrics(hypnogram); ok {
+		response["metrics"] = metrics
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,103 @@
+package sleep
+
+import (
+	"sort"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Metrics holds sleep continuity measures derived from a hypnogram.
+type Metrics struct {
+	OnsetLatencyMinutes int `json:"sleep_onset_latency_minutes"`
+	WASOMinutes         int `json:"waso_minutes"`
+	Awakenings          int `json:"awakenings"`
+}
+
+// DeriveMetrics computes sleep onset latency, wake after sleep onset (WASO) and
+// the number of awakenings from a hypnogram.
+//
+// Onset is the start of the first non-awake stage and final wake is the end of the
+// last non-awake stage; only awake intervals between the two count towards WASO
+// and awakenings. Returns false if the hypnogram contains no sleep at all.
+func DeriveMetrics(h *models.SleepHypnogram) (*Metrics, bool) {
+	if h == nil || len(h.Stages) == 0 {
+		return nil, false
+	}
+
+	stages := make([]models.SleepStageInterval, len(h.Stages))
+	copy(stages, h.Stages)
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Start.Before(stages[j].Start) })
+
+	var onset, finalWake time.Time
+	for _, s := range stages {
+		if s.Stage == models.SleepStageAwake {
+			continue
+		}
+		if onset.IsZero() {
+			onset = s.Start
+		}
+		if s.End.After(finalWake) {
+			finalWake = s.End
+		}
+	}
+	if onset.IsZero() {
+		return nil, false
+	}
+
+	sleepStart := h.SleepStart
+	if sleepStart.IsZero() || sleepStart.After(onset) {
+		sleepStart = stages[0].Start
+	}
+
+	metrics := &Metrics{OnsetLatencyMinutes: minutes(onset.Sub(sleepStart))}
+
+	var waso time.Duration
+	for _, s := range stages {
+		if s.Stage != models.SleepStageAwake {
+			continue
+		}
+		start, end := s.Start, s.End
+		if start.Before(onset) {
+			start = onset
+		}
+		if end.After(finalWake) {
+			end = finalWake
+		}
+		if !end.After(start) {
+			continue
+		}
+		waso += end.Sub(start)
+		metrics.Awakenings++
+	}
+	metrics.WASOMinutes = minutes(waso)
+
+	return metrics, true
+}
+
+// ApplyMetrics copies the hypnogram's sleep window and derived continuity metrics onto a sleep summary.
+func ApplyMetrics(summary *models.GarminSleep, h *models.SleepHypnogram) {
+	if !h.SleepStart.IsZero() {
+		start := h.SleepStart
+		summary.SleepStart = &start
+	}
+	if !h.SleepEnd.IsZero() {
+		end := h.SleepEnd
+		summary.SleepEnd = &end
+	}
+
+	metrics, ok := DeriveMetrics(h)
+	if !ok {
+		return
+	}
+	summary.SleepOnsetLatencyMinutes = &metrics.OnsetLatencyMinutes
+	summary.WASOMinutes = &metrics.WASOMinutes
+	summary.Awakenings = &metrics.Awakenings
+}
+
+func minutes(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(d.Round(time.Minute) / time.Minute)
+}
@@ -0,0 +1,81 @@
+package sleep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestDeriveMetrics(t *testing.T) {
+	base := time.Date(2026, 1, 27, 22, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return base.Add(time.Duration(minutes) * time.Minute) }
+	interval := func(stage string, from, to int) models.SleepStageInterval {
+		return models.SleepStageInterval{Stage: stage, Start: at(from), End: at(to)}
+	}
+
+	tests := []struct {
+		name      string
+		hypnogram *models.SleepHypnogram
+		want      *Metrics
+		wantOk    bool
+	}{
+		{
+			name: "awake before onset, two awakenings, awake after final wake",
+			hypnogram: &models.SleepHypnogram{
+				SleepStart: at(0),
+				SleepEnd:   at(480),
+				Stages: []models.SleepStageInterval{
+					interval(models.SleepStageAwake, 0, 20),
+					interval(models.SleepStageLight, 20, 120),
+					interval(models.SleepStageAwake, 120, 135),
+					interval(models.SleepStageDeep, 135, 240),
+					interval(models.SleepStageAwake, 240, 250),
+					interval(models.SleepStageREM, 250, 460),
+					interval(models.SleepStageAwake, 460, 480),
+				},
+			},
+			want:   &Metrics{OnsetLatencyMinutes: 20, WASOMinutes: 25, Awakenings: 2},
+			wantOk: true,
+		},
+		{
+			name: "unsorted stages and onset latency from sleep start",
+			hypnogram: &models.SleepHypnogram{
+				SleepStart: at(0),
+				Stages: []models.SleepStageInterval{
+					interval(models.SleepStageDeep, 60, 200),
+					interval(models.SleepStageLight, 15, 60),
+				},
+			},
+			want:   &Metrics{OnsetLatencyMinutes: 15, WASOMinutes: 0, Awakenings: 0},
+			wantOk: true,
+		},
+		{
+			name: "only awake",
+			hypnogram: &models.SleepHypnogram{
+				Stages: []models.SleepStageInterval{interval(models.SleepStageAwake, 0, 30)},
+			},
+			wantOk: false,
+		},
+		{
+			name:      "empty hypnogram",
+			hypnogram: &models.SleepHypnogram{},
+			wantOk:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DeriveMetrics(tt.hypnogram)
+			if ok != tt.wantOk {
+				t.Fatalf("DeriveMetrics() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if !ok {
+				return
+			}
+			if *got != *tt.want {
+				t.Errorf("DeriveMetrics() = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package sources
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// Handler handles source priority settings.
+type Handler struct {
+	repo Store
+}
+
+// NewHandler creates a new sources Handler.
+func NewHandler(repo Store) *Handler {
+	return &Handler{repo: repo}
+}
+
+// metricPriority is the effective source order for one metric.
+type metricPriority struct {
+	Sources []string `json:"sources"`
+	Custom  bool     `json:"custom"`
+}
+
+// HandleGetPriorities handles GET /api/v1/settings/source-priorities
+func (h *Handler) HandleGetPriorities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	priorities, err := h.repo.GetPriorities(r.Context(), userID)
+	if err != nil {
+		log.Printf("Failed to fetch source priorities: %v", err)
+		http.Error(w, "Failed to fetch source priorities", http.StatusInternalServerError)
+		return
+	}
+
+	effective := make(map[string]metricPriority)
+	for _, metric := range Metrics() {
+		_, custom := priorities[metric]
+		effective[metric] = metricPriority{Sources: priorities.Order(metric), Custom: custom}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     "success",
+		"priorities": effective,
+		"default":    DefaultOrder(),
+	})
+}
+
+// HandleMetricPriority handles PUT and DELETE /api/v1/settings/source-priorities/{metric}.
+// PUT takes {"sources": [...]}, highest priority first; DELETE restores the default.
+func (h *Handler) HandleMetricPriority(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	metric := r.PathValue("metric")
+
+	if r.Method == http.MethodDelete {
+		if !KnownMetric(metric) {
+			http.Error(w, "Unknown metric", http.StatusBadRequest)
+			return
+		}
+		if err := h.repo.DeletePriority(r.Context(), userID, metric); err != nil {
+			log.Printf("Failed to reset source priority: %v", err)
+			http.Error(w, "Failed to reset source priority", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  "success",
+			"metric":  metric,
+			"sources": DefaultOrder(),
+		})
+		return
+	}
+
+	var payload struct {
+		Sources []string `json:"sources"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := Validate(metric, payload.Sources); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":   "Validation failed",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.repo.SetPriority(r.Context(), userID, metric, payload.Sources); err != nil {
+		log.Printf("Failed to set source priority: %v", err)
+		http.Error(w, "Failed to set source priority", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  "success",
+		"metric":  metric,
+		"sources": payload.Sources,
+	})
+}
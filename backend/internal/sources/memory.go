@@ -0,0 +1,51 @@
+package sources
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryStore keeps source priorities in memory with the same semantics as
+// Repository. It is for tests.
+type MemoryStore struct {
+	mu         sync.Mutex
+	priorities map[string]Priorities // by user
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{priorities: make(map[string]Priorities)}
+}
+
+// GetPriorities implements Store.
+func (s *MemoryStore) GetPriorities(ctx context.Context, userID string) (Priorities, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	priorities := make(Priorities)
+	for metric, order := range s.priorities[userID] {
+		priorities[metric] = append([]string(nil), order...)
+	}
+	return priorities, nil
+}
+
+// SetPriority implements Store.
+func (s *MemoryStore) SetPriority(ctx context.Context, userID, metric string, order []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.priorities[userID] == nil {
+		s.priorities[userID] = make(Priorities)
+	}
+	s.priorities[userID][metric] = append([]string(nil), order...)
+	return nil
+}
+
+// DeletePriority implements Store.
+func (s *MemoryStore) DeletePriority(ctx context.Context, userID, metric string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.priorities[userID], metric)
+	return nil
+}
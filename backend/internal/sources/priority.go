@@ -0,0 +1,208 @@
+// Package sources resolves which device wins when several report the same
+// metric, using per-user source priorities with sensible defaults.
+package sources
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// Metrics that can be prioritized. Names match the dashboard's JSON fields.
+const (
+	MetricCheckin          = "checkin"
+	MetricSleep            = "sleep"
+	MetricActivity         = "activity"
+	MetricHRV              = "hrv"
+	MetricStress           = "stress"
+	MetricDailyStats       = "daily_stats"
+	MetricBodyBattery      = "body_battery"
+	MetricBodyMass         = "body_mass"
+	MetricReadiness        = "readiness"
+	MetricStrain           = "strain"
+	MetricSpO2             = "spo2"
+	MetricRespiration      = "respiration"
+	MetricTrainingStatus   = "training_status"
+	MetricVO2Max           = "vo2max"
+	MetricIntensityMinutes = "intensity_minutes"
+)
+
+// eventTypeMetrics maps event types onto the metric they report.
+var eventTypeMetrics = map[string]string{
+	models.EventTypeSubjectiveFeeling: MetricCheckin,
+	models.EventTypeGarminSleep:       MetricSleep,
+	models.EventTypeGarminActivity:    MetricActivity,
+	models.EventTypeGarminHRV:         MetricHRV,
+	models.EventTypeGarminStress:      MetricStress,
+	models.EventTypeGarminDailyStats:  MetricDailyStats,
+	models.EventTypeGarminBodyBattery: MetricBodyBattery,
+	models.EventTypeBodyMass:          MetricBodyMass,
+	models.EventTypeReadiness:         MetricReadiness,
+	models.EventTypeStrain:            MetricStrain,
+	models.EventTypeSpO2:              MetricSpO2,
+	models.EventTypeRespiration:       MetricRespiration,
+	models.EventTypeTrainingStatus:    MetricTrainingStatus,
+	models.EventTypeVO2Max:            MetricVO2Max,
+	models.EventTypeIntensityMinutes:  MetricIntensityMinutes,
+}
+
+// latestWins are metrics resolved by time alone. A check-in is the user's own
+// account of the day, so the latest one counts whichever source it came
+// through; they can't be prioritized.
+var latestWins = map[string]bool{
+	MetricCheckin: true,
+}
+
+// defaultOrder ranks sources when a user has no preference for a metric:
+// dedicated wearables first, then aggregators, then manual entry.
+var defaultOrder = []string{
+	models.SourceGarmin,
+	models.SourceOura,
+	models.SourceWhoop,
+	models.SourceFITFile,
+	models.SourceAppleHealth,
+	models.SourceManual,
+}
+
+// knownSources are the sources a priority list may name.
+var knownSources = map[string]bool{
+	models.SourceGarmin:      true,
+	models.SourceFITFile:     true,
+	models.SourceAppleHealth: true,
+	models.SourceOura:        true,
+	models.SourceWhoop:       true,
+	models.SourceManual:      true,
+	models.SourceParsed:      true,
+	models.SourceLLM:         true,
+}
+
+// MetricForEventType returns the metric an event type reports, if any.
+func MetricForEventType(eventType string) (string, bool) {
+	metric, ok := eventTypeMetrics[eventType]
+	return metric, ok
+}
+
+// Metrics returns every prioritizable metric, sorted.
+func Metrics() []string {
+	metrics := make([]string, 0, len(eventTypeMetrics))
+	for _, metric := range eventTypeMetrics {
+		if !latestWins[metric] {
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Strings(metrics)
+	return metrics
+}
+
+// DefaultOrder returns the source order used when a user has no preference.
+func DefaultOrder() []string {
+	return append([]string(nil), defaultOrder...)
+}
+
+// KnownMetric reports whether metric can be prioritized.
+func KnownMetric(metric string) bool {
+	if latestWins[metric] {
+		return false
+	}
+	for _, m := range eventTypeMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks a priority list for a metric: the metric must exist and the
+// list must name known sources without repeats.
+func Validate(metric string, order []string) error {
+	if !KnownMetric(metric) {
+		return fmt.Errorf("unknown metric %q", metric)
+	}
+	if len(order) == 0 {
+		return fmt.Errorf("sources must not be empty")
+	}
+
+	seen := make(map[string]bool, len(order))
+	for _, source := range order {
+		if !knownSources[source] {
+			return fmt.Errorf("unknown source %q", source)
+		}
+		if seen[source] {
+			return fmt.Errorf("source %q listed more than once", source)
+		}
+		seen[source] = true
+	}
+	return nil
+}
+
+// Priorities holds a user's source order per metric. Metrics without an entry
+// use the default order.
+type Priorities map[string][]string
+
+// Order returns the effective source order for a metric.
+func (p Priorities) Order(metric string) []string {
+	if order, ok := p[metric]; ok && len(order) > 0 {
+		return order
+	}
+	return defaultOrder
+}
+
+// rank returns the position of source in the metric's order. Sources the
+// list does not name rank after every listed one.
+func (p Priorities) rank(metric, source string) int {
+	order := p.Order(metric)
+	for i, s := range order {
+		if s == source {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// Candidate is one source's reading of a metric.
+type Candidate struct {
+	Time   time.Time
+	Source string
+	Data   []byte
+}
+
+// Resolver picks one candidate per event type from rows offered in any order.
+// The source ranked highest for the event type's metric wins; between rows
+// from equally ranked sources, and for check-ins, the most recent wins.
+type Resolver struct {
+	priorities Priorities
+	winners    map[string]Candidate
+}
+
+// NewResolver creates a Resolver using the given priorities.
+func NewResolver(priorities Priorities) *Resolver {
+	return &Resolver{
+		priorities: priorities,
+		winners:    make(map[string]Candidate),
+	}
+}
+
+// Offer considers c as the reading for eventType.
+func (r *Resolver) Offer(eventType string, c Candidate) {
+	current, ok := r.winners[eventType]
+	if !ok || r.better(eventType, c, current) {
+		r.winners[eventType] = c
+	}
+}
+
+func (r *Resolver) better(eventType string, c, current Candidate) bool {
+	if metric, ok := eventTypeMetrics[eventType]; ok && !latestWins[metric] {
+		cRank, currentRank := r.priorities.rank(metric, c.Source), r.priorities.rank(metric, current.Source)
+		if cRank != currentRank {
+			return cRank < currentRank
+		}
+	}
+	return c.Time.After(current.Time)
+}
+
+// Winners returns the winning candidate for each event type offered.
+func (r *Resolver) Winners() map[string]Candidate {
+	return r.winners
+}
@@ -0,0 +1,138 @@
+package sources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+func TestResolver(t *testing.T) {
+	morning := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	evening := morning.Add(12 * time.Hour)
+
+	tests := []struct {
+		name       string
+		priorities Priorities
+		eventType  string
+		candidates []Candidate
+		wantSource string
+		wantTime   time.Time
+	}{
+		{
+			name:      "default order prefers garmin",
+			eventType: models.EventTypeGarminDailyStats,
+			candidates: []Candidate{
+				{Time: evening, Source: models.SourceAppleHealth},
+				{Time: morning, Source: models.SourceGarmin},
+			},
+			wantSource: models.SourceGarmin,
+			wantTime:   morning,
+		},
+		{
+			name:       "user priority overrides default",
+			priorities: Priorities{MetricDailyStats: {models.SourceAppleHealth, models.SourceGarmin}},
+			eventType:  models.EventTypeGarminDailyStats,
+			candidates: []Candidate{
+				{Time: morning, Source: models.SourceGarmin},
+				{Time: morning, Source: models.SourceAppleHealth},
+			},
+			wantSource: models.SourceAppleHealth,
+			wantTime:   morning,
+		},
+		{
+			name:       "override for one metric leaves others on default",
+			priorities: Priorities{MetricDailyStats: {models.SourceAppleHealth}},
+			eventType:  models.EventTypeGarminSleep,
+			candidates: []Candidate{
+				{Time: morning, Source: models.SourceAppleHealth},
+				{Time: morning, Source: models.SourceOura},
+			},
+			wantSource: models.SourceOura,
+			wantTime:   morning,
+		},
+		{
+			name:       "unlisted sources rank last",
+			priorities: Priorities{MetricSleep: {models.SourceWhoop}},
+			eventType:  models.EventTypeGarminSleep,
+			candidates: []Candidate{
+				{Time: evening, Source: models.SourceGarmin},
+				{Time: morning, Source: models.SourceWhoop},
+			},
+			wantSource: models.SourceWhoop,
+			wantTime:   morning,
+		},
+		{
+			name:      "same source keeps most recent",
+			eventType: models.EventTypeGarminActivity,
+			candidates: []Candidate{
+				{Time: evening, Source: models.SourceGarmin},
+				{Time: morning, Source: models.SourceGarmin},
+			},
+			wantSource: models.SourceGarmin,
+			wantTime:   evening,
+		},
+		{
+			name:      "check-ins keep most recent whatever the source",
+			eventType: models.EventTypeSubjectiveFeeling,
+			candidates: []Candidate{
+				{Time: evening, Source: models.SourceLLM},
+				{Time: morning, Source: models.SourceManual},
+			},
+			wantSource: models.SourceLLM,
+			wantTime:   evening,
+		},
+		{
+			name:      "event types without a metric keep most recent",
+			eventType: models.EventTypeSleepHypnogram,
+			candidates: []Candidate{
+				{Time: morning, Source: models.SourceGarmin},
+				{Time: evening, Source: models.SourceAppleHealth},
+			},
+			wantSource: models.SourceAppleHealth,
+			wantTime:   evening,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewResolver(tt.priorities)
+			for _, c := range tt.candidates {
+				resolver.Offer(tt.eventType, c)
+			}
+
+			winner, ok := resolver.Winners()[tt.eventType]
+			if !ok {
+				t.Fatal("expected a winner")
+			}
+			if winner.Source != tt.wantSource || !winner.Time.Equal(tt.wantTime) {
+				t.Errorf("winner = %s at %v, want %s at %v", winner.Source, winner.Time, tt.wantSource, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		metric  string
+		order   []string
+		wantErr bool
+	}{
+		{"valid", MetricDailyStats, []string{models.SourceAppleHealth, models.SourceGarmin}, false},
+		{"unknown metric", "vo2max_typo", []string{models.SourceGarmin}, true},
+		{"check-ins go by time", MetricCheckin, []string{models.SourceManual}, true},
+		{"empty order", MetricSleep, nil, true},
+		{"unknown source", MetricSleep, []string{"fitbit"}, true},
+		{"duplicate source", MetricSleep, []string{models.SourceOura, models.SourceOura}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.metric, tt.order)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// Store holds users' source priorities. Repository implements it on
+// Postgres and MemoryStore in memory, for tests.
+type Store interface {
+	GetPriorities(ctx context.Context, userID string) (Priorities, error)
+	SetPriority(ctx context.Context, userID, metric string, order []string) error
+	DeletePriority(ctx context.Context, userID, metric string) error
+}
+
+// Repository handles database operations for source priorities.
+type Repository struct {
+	db *db.Database
+}
+
+// NewRepository creates a new sources Repository.
+func NewRepository(database *db.Database) *Repository {
+	return &Repository{db: database}
+}
+
+// GetPriorities retrieves a user's source priorities. Metrics the user has
+// not configured are absent and fall back to the default order.
+func (r *Repository) GetPriorities(ctx context.Context, userID string) (Priorities, error) {
+	query := `
+		SELECT metric, sources
+		FROM source_priorities
+		WHERE user_id = $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query source priorities: %w", err)
+	}
+	defer rows.Close()
+
+	priorities := make(Priorities)
+	for rows.Next() {
+		var metric string
+		var order []string
+		if err := rows.Scan(&metric, &order); err != nil {
+			return nil, fmt.Errorf("failed to scan source priority: %w", err)
+		}
+		priorities[metric] = order
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating source priorities: %w", err)
+	}
+
+	return priorities, nil
+}
+
+// SetPriority stores a user's source order for a metric, replacing any existing one.
+func (r *Repository) SetPriority(ctx context.Context, userID, metric string, order []string) error {
+	query := `
+		INSERT INTO source_priorities (user_id, metric, sources, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, metric) DO UPDATE SET
+			sources = EXCLUDED.sources,
+			updated_at = NOW()
+	`
+
+	if _, err := r.db.Pool.Exec(ctx, query, userID, metric, order); err != nil {
+		return fmt.Errorf("failed to set source priority: %w", err)
+	}
+	return nil
+}
+
+// DeletePriority removes a user's source order for a metric, restoring the default.
+func (r *Repository) DeletePriority(ctx context.Context, userID, metric string) error {
+	query := `DELETE FROM source_priorities WHERE user_id = $1 AND metric = $2`
+
+	if _, err := r.db.Pool.Exec(ctx, query, userID, metric); err != nil {
+		return fmt.Errorf("failed to delete source priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package whoop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Whoop developer API host.
+const DefaultBaseURL = "https://api.prod.whoop.com/developer"
+
+// pageLimit is the largest page size the API accepts.
+const pageLimit = 25
+
+// Client talks to the Whoop v1 developer API with an OAuth2 access token.
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+	pageSize    int
+}
+
+// NewClient creates a new Whoop Client. An empty baseURL uses DefaultBaseURL.
+func NewClient(baseURL, accessToken string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{
+		baseURL:     strings.TrimRight(baseURL, "/"),
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 30 * time.Second},
+		pageSize:    pageLimit,
+	}
+}
+
+// Fetch retrieves cycles, recoveries and sleeps that start between start and end.
+func (c *Client) Fetch(ctx context.Context, start, end time.Time) (*Data, error) {
+	data := &Data{}
+
+	if err := fetchRecords(ctx, c, "/v1/cycle", start, end, &data.Cycles); err != nil {
+		return nil, err
+	}
+	if err := fetchRecords(ctx, c, "/v1/recovery", start, end, &data.Recoveries); err != nil {
+		return nil, err
+	}
+	if err := fetchRecords(ctx, c, "/v1/activity/sleep", start, end, &data.Sleeps); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// fetchRecords follows nextToken pagination until the collection is exhausted.
+func fetchRecords[T any](ctx context.Context, c *Client, path string, start, end time.Time, out *[]T) error {
+	nextToken := ""
+	for {
+		params := url.Values{}
+		params.Set("start", start.UTC().Format(time.RFC3339))
+		params.Set("end", end.UTC().Format(time.RFC3339))
+		params.Set("limit", fmt.Sprint(c.pageSize))
+		if nextToken != "" {
+			params.Set("nextToken", nextToken)
+		}
+
+		var page struct {
+			Records   []T    `json:"records"`
+			NextToken string `json:"next_token"`
+		}
+		if err := c.get(ctx, path+"?"+params.Encode(), &page); err != nil {
+			return fmt.Errorf("failed to fetch Whoop %s: %w", path, err)
+		}
+
+		*out = append(*out, page.Records...)
+
+		if page.NextToken == "" {
+			return nil
+		}
+		nextToken = page.NextToken
+	}
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,66 @@
+package whoop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/whoop/whooptest"
+)
+
+func TestClientFetch(t *testing.T) {
+	server := whooptest.NewServer("test-token")
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-token")
+	client.pageSize = 1
+
+	tests := []struct {
+		name           string
+		start, end     time.Time
+		wantCycles     int
+		wantRecoveries int
+		wantSleeps     int
+	}{
+		{
+			name:           "all records across pages",
+			start:          time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			end:            time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+			wantCycles:     2,
+			wantRecoveries: 2,
+			wantSleeps:     2,
+		},
+		{
+			name:           "first day only",
+			start:          time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			end:            time.Date(2024, 3, 1, 23, 59, 0, 0, time.UTC),
+			wantCycles:     1,
+			wantRecoveries: 1,
+			wantSleeps:     2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := client.Fetch(context.Background(), tt.start, tt.end)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if len(data.Cycles) != tt.wantCycles || len(data.Recoveries) != tt.wantRecoveries || len(data.Sleeps) != tt.wantSleeps {
+				t.Errorf("got %d cycles, %d recoveries, %d sleeps; want %d, %d, %d",
+					len(data.Cycles), len(data.Recoveries), len(data.Sleeps),
+					tt.wantCycles, tt.wantRecoveries, tt.wantSleeps)
+			}
+		})
+	}
+}
+
+func TestClientFetch_Unauthorized(t *testing.T) {
+	server := whooptest.NewServer("test-token")
+	defer server.Close()
+
+	client := NewClient(server.URL, "wrong-token")
+	if _, err := client.Fetch(context.Background(), time.Now().AddDate(0, 0, -1), time.Now()); err == nil {
+		t.Error("Fetch() expected error for bad token")
+	}
+}
@@ -0,0 +1,87 @@
+package whoop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+	"github.com/satishthakur/health-assistant/backend/internal/importer"
+	"github.com/satishthakur/health-assistant/backend/internal/middleware"
+)
+
+// maxExportBytes bounds uploads; the cycles CSV is a few hundred KB per year.
+const maxExportBytes = 64 << 20
+
+// Handler handles Whoop import endpoints.
+type Handler struct {
+	eventRepo db.EventStore
+}
+
+// NewHandler creates a new Whoop Handler.
+func NewHandler(eventRepo db.EventStore) *Handler {
+	return &Handler{eventRepo: eventRepo}
+}
+
+// HandleImport handles POST /api/v1/import/whoop
+//
+// Accepts a Whoop physiological_cycles.csv export as a multipart "file" field or
+// as the raw body.
+func (h *Handler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID := middleware.UserIDFromContext(r.Context())
+	if userID == "" {
+		http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxExportBytes)
+
+	body, err := importer.OpenUpload(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Invalid upload: %v", err), http.StatusBadRequest)
+		return
+	}
+	defer body.Close()
+
+	data, err := ParseCSV(body)
+	if err != nil {
+		log.Printf("Failed to parse Whoop export: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrNotCyclesCSV) {
+			status = http.StatusUnprocessableEntity
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	events, err := Normalize(userID, data)
+	if err != nil {
+		log.Printf("Failed to normalize Whoop export: %v", err)
+		http.Error(w, fmt.Sprintf("Transformation error: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	inserted, err := h.eventRepo.InsertEvents(r.Context(), events)
+	if err != nil {
+		log.Printf("Failed to insert Whoop events: %v", err)
+		http.Error(w, "Failed to store events", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Imported Whoop export for user %s: %d events (%d new)", userID, len(events), inserted)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":          "success",
+		"events":          len(events),
+		"events_inserted": inserted,
+	})
+}
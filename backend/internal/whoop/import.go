@@ -0,0 +1,203 @@
+package whoop
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/db"
+)
+
+// csvTimeLayout is the local-time format used in the app's CSV export.
+const csvTimeLayout = "2006-01-02 15:04:05"
+
+// Columns of physiological_cycles.csv that we read
+const (
+	colCycleStart       = "Cycle start time"
+	colCycleEnd         = "Cycle end time"
+	colCycleTimezone    = "Cycle timezone"
+	colRecoveryScore    = "Recovery score %"
+	colRestingHR        = "Resting heart rate (bpm)"
+	colHRV              = "Heart rate variability (ms)"
+	colSkinTemp         = "Skin temp (celsius)"
+	colSpO2             = "Blood oxygen %"
+	colDayStrain        = "Day Strain"
+	colEnergyBurned     = "Energy burned (cal)"
+	colMaxHR            = "Max HR (bpm)"
+	colAverageHR        = "Average HR (bpm)"
+	colSleepOnset       = "Sleep onset"
+	colWakeOnset        = "Wake onset"
+	colSleepPerformance = "Sleep performance %"
+	colInBed            = "In bed duration (min)"
+	colLightSleep       = "Light sleep duration (min)"
+	colDeepSleep        = "Deep (SWS) duration (min)"
+	colREMSleep         = "REM duration (min)"
+	colAwake            = "Awake duration (min)"
+)
+
+// ErrNotCyclesCSV is returned when the CSV lacks the physiological_cycles.csv columns.
+var ErrNotCyclesCSV = errors.New("not a Whoop physiological_cycles.csv export")
+
+// ParseCSV reads physiological_cycles.csv from the Whoop app's data export.
+// Each row carries a cycle plus, when scored, its recovery and main sleep.
+func ParseCSV(r io.Reader) (*Data, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	if _, ok := columns[colCycleStart]; !ok {
+		return nil, ErrNotCyclesCSV
+	}
+	if _, ok := columns[colCycleTimezone]; !ok {
+		return nil, ErrNotCyclesCSV
+	}
+
+	data := &Data{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		row := csvRow{columns: columns, record: record}
+		offset := strings.TrimPrefix(row.str(colCycleTimezone), "UTC")
+		loc, ok := parseOffset(offset)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid cycle timezone %q", line, row.str(colCycleTimezone))
+		}
+
+		start, ok := row.time(colCycleStart, loc)
+		if !ok {
+			return nil, fmt.Errorf("line %d: invalid cycle start time", line)
+		}
+
+		// The export has no record IDs; rows are linked by position
+		cycle := Cycle{ID: int64(line), Start: start, TimezoneOffset: offset}
+		if end, ok := row.time(colCycleEnd, loc); ok {
+			cycle.End = &end
+		}
+		if strain, ok := row.float(colDayStrain); ok {
+			kcal, _ := row.float(colEnergyBurned)
+			avgHR, _ := row.float(colAverageHR)
+			maxHR, _ := row.float(colMaxHR)
+			cycle.ScoreState = scoreStateScored
+			cycle.Score = &CycleScore{
+				Strain:           strain,
+				Kilojoule:        kcal * 4.184,
+				AverageHeartRate: int(avgHR),
+				MaxHeartRate:     int(maxHR),
+			}
+		}
+		data.Cycles = append(data.Cycles, cycle)
+
+		if score, ok := row.float(colRecoveryScore); ok {
+			rhr, _ := row.float(colRestingHR)
+			hrv, _ := row.float(colHRV)
+			recovery := Recovery{
+				CycleID:    cycle.ID,
+				ScoreState: scoreStateScored,
+				Score: &RecoveryScore{
+					RecoveryScore:    score,
+					RestingHeartRate: rhr,
+					HRVRMSSDMilli:    hrv,
+				},
+			}
+			if spo2, ok := row.float(colSpO2); ok {
+				recovery.Score.SpO2Percentage = &spo2
+			}
+			if temp, ok := row.float(colSkinTemp); ok {
+				recovery.Score.SkinTempCelsius = &temp
+			}
+			data.Recoveries = append(data.Recoveries, recovery)
+		}
+
+		onset, okOnset := row.time(colSleepOnset, loc)
+		wake, okWake := row.time(colWakeOnset, loc)
+		if okOnset && okWake {
+			minutes := func(col string) int64 {
+				v, _ := row.float(col)
+				return int64(v * 60000)
+			}
+			sleep := Sleep{
+				ID:             int64(line),
+				Start:          onset,
+				End:            wake,
+				TimezoneOffset: offset,
+				ScoreState:     scoreStateScored,
+				Score: &SleepScore{
+					StageSummary: StageSummary{
+						TotalInBedTimeMilli:         minutes(colInBed),
+						TotalAwakeTimeMilli:         minutes(colAwake),
+						TotalLightSleepTimeMilli:    minutes(colLightSleep),
+						TotalSlowWaveSleepTimeMilli: minutes(colDeepSleep),
+						TotalREMSleepTimeMilli:      minutes(colREMSleep),
+					},
+				},
+			}
+			if performance, ok := row.float(colSleepPerformance); ok {
+				sleep.Score.SleepPerformancePercentage = &performance
+			}
+			data.Sleeps = append(data.Sleeps, sleep)
+		}
+	}
+
+	return data, nil
+}
+
+type csvRow struct {
+	columns map[string]int
+	record  []string
+}
+
+func (r csvRow) str(column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(r.record) {
+		return ""
+	}
+	return strings.TrimSpace(r.record[i])
+}
+
+func (r csvRow) float(column string) (float64, bool) {
+	v, err := strconv.ParseFloat(r.str(column), 64)
+	return v, err == nil
+}
+
+func (r csvRow) time(column string, loc *time.Location) (time.Time, bool) {
+	t, err := time.ParseInLocation(csvTimeLayout, r.str(column), loc)
+	return t, err == nil
+}
+
+// Sync fetches records starting between start and end from the Whoop API
+// and upserts the normalized events. Returns the number of newly inserted events.
+func Sync(ctx context.Context, client *Client, eventRepo db.EventStore, userID string, start, end time.Time) (int, error) {
+	data, err := client.Fetch(ctx, start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	events, err := Normalize(userID, data)
+	if err != nil {
+		return 0, err
+	}
+
+	inserted, err := eventRepo.InsertEvents(ctx, events)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store Whoop events: %w", err)
+	}
+	return inserted, nil
+}
@@ -0,0 +1,208 @@
+// Package whoop imports Whoop data, from either the app's CSV export or the
+// Whoop developer REST API, normalized into the same event types as Garmin data.
+package whoop
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+)
+
+// scoreStateScored marks records Whoop has finished scoring; PENDING_SCORE and
+// UNSCORABLE records carry no score.
+const scoreStateScored = "SCORED"
+
+// Cycle is a physiological cycle (roughly, a day from waking to waking).
+type Cycle struct {
+	ID             int64       `json:"id"`
+	Start          time.Time   `json:"start"`
+	End            *time.Time  `json:"end"`
+	TimezoneOffset string      `json:"timezone_offset"`
+	ScoreState     string      `json:"score_state"`
+	Score          *CycleScore `json:"score"`
+}
+
+// CycleScore is a cycle's strain summary.
+type CycleScore struct {
+	Strain           float64 `json:"strain"`
+	Kilojoule        float64 `json:"kilojoule"`
+	AverageHeartRate int     `json:"average_heart_rate"`
+	MaxHeartRate     int     `json:"max_heart_rate"`
+}
+
+// Recovery is the recovery scored at the start of a cycle.
+type Recovery struct {
+	CycleID    int64          `json:"cycle_id"`
+	ScoreState string         `json:"score_state"`
+	Score      *RecoveryScore `json:"score"`
+}
+
+// RecoveryScore holds the recovery score and the vitals behind it.
+type RecoveryScore struct {
+	RecoveryScore    float64  `json:"recovery_score"`
+	RestingHeartRate float64  `json:"resting_heart_rate"`
+	HRVRMSSDMilli    float64  `json:"hrv_rmssd_milli"`
+	SpO2Percentage   *float64 `json:"spo2_percentage"`
+	SkinTempCelsius  *float64 `json:"skin_temp_celsius"`
+}
+
+// Sleep is a sleep or nap activity.
+type Sleep struct {
+	ID             int64       `json:"id"`
+	Start          time.Time   `json:"start"`
+	End            time.Time   `json:"end"`
+	TimezoneOffset string      `json:"timezone_offset"`
+	Nap            bool        `json:"nap"`
+	ScoreState     string      `json:"score_state"`
+	Score          *SleepScore `json:"score"`
+}
+
+// SleepScore holds a sleep's stage totals and performance.
+type SleepScore struct {
+	StageSummary               StageSummary `json:"stage_summary"`
+	SleepPerformancePercentage *float64     `json:"sleep_performance_percentage"`
+}
+
+// StageSummary holds time per sleep stage, in milliseconds.
+type StageSummary struct {
+	TotalInBedTimeMilli         int64 `json:"total_in_bed_time_milli"`
+	TotalAwakeTimeMilli         int64 `json:"total_awake_time_milli"`
+	TotalLightSleepTimeMilli    int64 `json:"total_light_sleep_time_milli"`
+	TotalSlowWaveSleepTimeMilli int64 `json:"total_slow_wave_sleep_time_milli"`
+	TotalREMSleepTimeMilli      int64 `json:"total_rem_sleep_time_milli"`
+	DisturbanceCount            int   `json:"disturbance_count"`
+}
+
+// Data holds the Whoop records we import.
+type Data struct {
+	Cycles     []Cycle
+	Recoveries []Recovery
+	Sleeps     []Sleep
+}
+
+// Normalize converts Whoop records into strain, readiness, HRV and sleep events.
+// Day-level events are attributed to the local calendar day the cycle started.
+func Normalize(userID string, data *Data) ([]*models.Event, error) {
+	var events []*models.Event
+
+	add := func(t time.Time, eventType string, v interface{}) error {
+		dataJSON, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s data: %w", eventType, err)
+		}
+		events = append(events, &models.Event{
+			Time:      t,
+			UserID:    userID,
+			EventType: eventType,
+			Source:    models.SourceWhoop,
+			Data:      dataJSON,
+		})
+		return nil
+	}
+
+	cycleDays := make(map[int64]time.Time)
+	for _, c := range data.Cycles {
+		day := localDay(c.Start, c.TimezoneOffset)
+		cycleDays[c.ID] = day
+
+		if c.ScoreState != scoreStateScored || c.Score == nil {
+			continue
+		}
+		strain := models.Strain{
+			Score:      c.Score.Strain,
+			Kilojoules: c.Score.Kilojoule,
+			AvgHR:      c.Score.AverageHeartRate,
+			MaxHR:      c.Score.MaxHeartRate,
+		}
+		if err := add(day, models.EventTypeStrain, strain); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range data.Recoveries {
+		day, ok := cycleDays[r.CycleID]
+		if !ok || r.ScoreState != scoreStateScored || r.Score == nil {
+			continue
+		}
+
+		readiness := models.Readiness{
+			Score:            int(math.Round(r.Score.RecoveryScore)),
+			RestingHeartRate: int(math.Round(r.Score.RestingHeartRate)),
+			HRVAvg:           r.Score.HRVRMSSDMilli,
+			SpO2:             r.Score.SpO2Percentage,
+		}
+		if err := add(day, models.EventTypeReadiness, readiness); err != nil {
+			return nil, err
+		}
+
+		hrv := models.GarminHRV{LastNightAvg: r.Score.HRVRMSSDMilli}
+		if err := add(day, models.EventTypeGarminHRV, hrv); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range data.Sleeps {
+		if s.Nap || s.ScoreState != scoreStateScored || s.Score == nil {
+			continue
+		}
+
+		stages := s.Score.StageSummary
+		start, end := s.Start.UTC(), s.End.UTC()
+		awakenings := stages.DisturbanceCount
+		summary := models.GarminSleep{
+			DurationMinutes:   millisToMinutes(stages.TotalLightSleepTimeMilli + stages.TotalSlowWaveSleepTimeMilli + stages.TotalREMSleepTimeMilli),
+			DeepSleepMinutes:  millisToMinutes(stages.TotalSlowWaveSleepTimeMilli),
+			LightSleepMinutes: millisToMinutes(stages.TotalLightSleepTimeMilli),
+			REMSleepMinutes:   millisToMinutes(stages.TotalREMSleepTimeMilli),
+			AwakeMinutes:      millisToMinutes(stages.TotalAwakeTimeMilli),
+			SleepStart:        &start,
+			SleepEnd:          &end,
+			Awakenings:        &awakenings,
+		}
+		if s.Score.SleepPerformancePercentage != nil {
+			summary.SleepScore = int(math.Round(*s.Score.SleepPerformancePercentage))
+		}
+		if summary.DurationMinutes == 0 {
+			continue
+		}
+
+		if err := add(end, models.EventTypeGarminSleep, summary); err != nil {
+			return nil, err
+		}
+	}
+
+	return events, nil
+}
+
+// localDay returns midnight UTC of the calendar day t falls on at the given
+// offset (e.g. "-05:00"), matching how day-level Garmin events are stored.
+func localDay(t time.Time, offset string) time.Time {
+	if loc, ok := parseOffset(offset); ok {
+		t = t.In(loc)
+	}
+	day, _ := time.Parse("2006-01-02", t.Format("2006-01-02"))
+	return day
+}
+
+// parseOffset parses "+HH:MM", "-HH:MM" or "Z", optionally prefixed with "UTC"
+// as in the CSV export.
+func parseOffset(offset string) (*time.Location, bool) {
+	offset = strings.TrimPrefix(strings.TrimSpace(offset), "UTC")
+	if offset == "" || offset == "Z" {
+		return time.UTC, true
+	}
+	t, err := time.Parse("-07:00", offset)
+	if err != nil {
+		return nil, false
+	}
+	_, seconds := t.Zone()
+	return time.FixedZone(offset, seconds), true
+}
+
+func millisToMinutes(ms int64) int {
+	return int(ms / 60000)
+}
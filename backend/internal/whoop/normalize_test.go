@@ -0,0 +1,129 @@
+package whoop
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/satishthakur/health-assistant/backend/internal/models"
+	"github.com/satishthakur/health-assistant/backend/internal/whoop/whooptest"
+)
+
+// checkNormalized asserts on the events both fixtures describe: a scored
+// cycle on 2024-03-01 (local) with 67% recovery, and the night before it.
+func checkNormalized(t *testing.T, events []*models.Event) {
+	t.Helper()
+
+	byType := make(map[string][]*models.Event)
+	for _, e := range events {
+		if e.Source != models.SourceWhoop {
+			t.Errorf("event %s has source %q", e.EventType, e.Source)
+		}
+		byType[e.EventType] = append(byType[e.EventType], e)
+	}
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	strains := byType[models.EventTypeStrain]
+	if len(strains) != 1 || !strains[0].Time.Equal(day) {
+		t.Fatalf("strain events = %v", strains)
+	}
+	var strain models.Strain
+	json.Unmarshal(strains[0].Data, &strain)
+	if strain.Score != 12.4 || strain.MaxHR != 168 {
+		t.Errorf("strain = %+v", strain)
+	}
+
+	if len(byType[models.EventTypeReadiness]) != 2 || len(byType[models.EventTypeGarminHRV]) != 2 {
+		t.Fatalf("got %d readiness, %d hrv events; want 2, 2",
+			len(byType[models.EventTypeReadiness]), len(byType[models.EventTypeGarminHRV]))
+	}
+	for _, e := range byType[models.EventTypeReadiness] {
+		if !e.Time.Equal(day) {
+			continue
+		}
+		var readiness models.Readiness
+		json.Unmarshal(e.Data, &readiness)
+		if readiness.Score != 67 || readiness.RestingHeartRate != 51 || readiness.HRVAvg != 62.3 {
+			t.Errorf("readiness = %+v", readiness)
+		}
+	}
+
+	wake := time.Date(2024, 3, 1, 12, 25, 0, 0, time.UTC)
+	found := false
+	for _, e := range byType[models.EventTypeGarminSleep] {
+		if !e.Time.Equal(wake) {
+			continue
+		}
+		found = true
+		var sleep models.GarminSleep
+		json.Unmarshal(e.Data, &sleep)
+		if sleep.DurationMinutes != 450 || sleep.DeepSleepMinutes != 105 || sleep.REMSleepMinutes != 120 || sleep.SleepScore != 88 {
+			t.Errorf("sleep = %+v", sleep)
+		}
+	}
+	if !found {
+		t.Errorf("no sleep event ending at %v", wake)
+	}
+}
+
+func TestNormalize_API(t *testing.T) {
+	server := whooptest.NewServer("test-token")
+	defer server.Close()
+
+	data, err := NewClient(server.URL, "test-token").Fetch(context.Background(),
+		time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+
+	events, err := Normalize("user-1", data)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+	checkNormalized(t, events)
+
+	// The nap is dropped
+	count := 0
+	for _, e := range events {
+		if e.EventType == models.EventTypeGarminSleep {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Errorf("got %d sleep events, want 1", count)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	f, err := os.Open("testdata/physiological_cycles.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	data, err := ParseCSV(f)
+	if err != nil {
+		t.Fatalf("ParseCSV() error = %v", err)
+	}
+	if len(data.Cycles) != 2 || len(data.Recoveries) != 2 || len(data.Sleeps) != 2 {
+		t.Fatalf("got %d cycles, %d recoveries, %d sleeps", len(data.Cycles), len(data.Recoveries), len(data.Sleeps))
+	}
+
+	events, err := Normalize("user-1", data)
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	checkNormalized(t, events)
+}
+
+func TestParseCSV_WrongFile(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("Workout start time,Activity name\n2024-03-01 07:00:00,Running\n"))
+	if err != ErrNotCyclesCSV {
+		t.Errorf("ParseCSV() error = %v, want ErrNotCyclesCSV", err)
+	}
+}
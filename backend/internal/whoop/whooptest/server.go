@@ -0,0 +1,92 @@
+// Package whooptest provides a fake Whoop developer API for tests and local development.
+package whooptest
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+)
+
+// Fixture holds the records served by the fake API, keyed by collection
+// (cycle, recovery, sleep): two cycles with their recoveries, a night and a nap.
+//
+//go:embed fixtures.json
+var Fixture []byte
+
+// collectionPaths maps API paths onto fixture collections.
+var collectionPaths = map[string]string{
+	"/v1/cycle":          "cycle",
+	"/v1/recovery":       "recovery",
+	"/v1/activity/sleep": "sleep",
+}
+
+// Server is a fake Whoop API serving Fixture.
+type Server struct {
+	*httptest.Server
+}
+
+// NewServer starts a fake Whoop API that accepts token as its bearer token.
+func NewServer(token string) *Server {
+	return &Server{Server: httptest.NewServer(Handler(token))}
+}
+
+// Handler returns the fake API's handler, for serving it outside tests.
+// Pages hold at most the requested limit, so small limits exercise pagination.
+func Handler(token string) http.Handler {
+	var collections map[string][]map[string]interface{}
+	if err := json.Unmarshal(Fixture, &collections); err != nil {
+		panic("whooptest: invalid fixture: " + err.Error())
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			http.Error(w, `{"message":"Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+
+		name, ok := collectionPaths[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		query := r.URL.Query()
+		start, _ := time.Parse(time.RFC3339, query.Get("start"))
+		end, errEnd := time.Parse(time.RFC3339, query.Get("end"))
+
+		// Records are filtered on start; recoveries, which have none, on created_at
+		var matched []map[string]interface{}
+		for _, record := range collections[name] {
+			at, _ := record["start"].(string)
+			if at == "" {
+				at, _ = record["created_at"].(string)
+			}
+			t, err := time.Parse(time.RFC3339, at)
+			if err != nil || t.Before(start) || (errEnd == nil && t.After(end)) {
+				continue
+			}
+			matched = append(matched, record)
+		}
+
+		limit, err := strconv.Atoi(query.Get("limit"))
+		if err != nil || limit <= 0 || limit > 25 {
+			limit = 10
+		}
+		offset, _ := strconv.Atoi(query.Get("nextToken"))
+
+		page := map[string]interface{}{"records": []interface{}{}}
+		if offset < len(matched) {
+			last := min(offset+limit, len(matched))
+			page["records"] = matched[offset:last]
+			if last < len(matched) {
+				page["next_token"] = strconv.Itoa(last)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	})
+}
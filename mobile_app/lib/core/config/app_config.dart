@@ -11,6 +11,7 @@ class AppConfig {
 
   // Storage Keys
   static const String tokenKey = 'auth_token';
+  static const String refreshTokenKey = 'refresh_token';
   static const String userIdKey = 'user_id';
   static const String lastCheckinKey = 'last_checkin_date';
 
@@ -2,6 +2,7 @@ import 'package:dio/dio.dart';
 import 'package:flutter_riverpod/flutter_riverpod.dart';
 
 import '../../features/auth/providers/auth_provider.dart';
+import 'api_client.dart';
 
 class ApiInterceptor extends Interceptor {
   final Ref ref;
@@ -26,10 +27,32 @@ class ApiInterceptor extends Interceptor {
   }
 
   @override
-  void onError(DioException err, ErrorInterceptorHandler handler) {
-    if (err.response?.statusCode == 401) {
+  Future<void> onError(
+      DioException err, ErrorInterceptorHandler handler) async {
+    final options = err.requestOptions;
+    if (err.response?.statusCode != 401 || options.path.contains('/auth/')) {
+      handler.next(err);
+      return;
+    }
+    if (options.extra['retried'] == true) {
       ref.read(authProvider.notifier).signOut();
+      handler.next(err);
+      return;
+    }
+
+    // Access tokens are short-lived: renew once and retry the request.
+    final token = await ref.read(authProvider.notifier).refreshSession();
+    if (token == null) {
+      handler.next(err);
+      return;
+    }
+
+    options.headers['Authorization'] = 'Bearer $token';
+    options.extra['retried'] = true;
+    try {
+      handler.resolve(await ref.read(dioProvider).fetch(options));
+    } on DioException catch (e) {
+      handler.next(e);
     }
-    handler.next(err);
   }
 }
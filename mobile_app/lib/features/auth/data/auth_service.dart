@@ -1,3 +1,5 @@
+import 'package:dio/dio.dart';
+import 'package:flutter/foundation.dart';
 import 'package:flutter_riverpod/flutter_riverpod.dart';
 import 'package:flutter_secure_storage/flutter_secure_storage.dart';
 import 'package:google_sign_in/google_sign_in.dart';
@@ -7,12 +9,14 @@ import '../../../core/network/api_client.dart';
 
 class AuthResponse {
   final String token;
+  final String? refreshToken;
   final String userId;
   final String email;
   final String displayName;
 
   AuthResponse({
     required this.token,
+    this.refreshToken,
     required this.userId,
     required this.email,
     required this.displayName,
@@ -21,6 +25,7 @@ class AuthResponse {
   factory AuthResponse.fromJson(Map<String, dynamic> json) {
     return AuthResponse(
       token: json['token'] as String,
+      refreshToken: json['refresh_token'] as String?,
       userId: json['user_id'] as String,
       email: json['email'] as String,
       displayName: (json['display_name'] as String?) ?? '',
@@ -33,6 +38,10 @@ class AuthService {
   final FlutterSecureStorage _secureStorage;
   final GoogleSignIn _googleSignIn;
 
+  // The in-flight refresh, shared so concurrent 401s refresh only once:
+  // each refresh token is single use, and reusing one ends the session.
+  Future<String?>? _refreshing;
+
   AuthService(this._apiClient, this._secureStorage, this._googleSignIn);
 
   Future<AuthResponse> signInWithGoogle() async {
@@ -49,13 +58,15 @@ class AuthService {
 
     final response = await _apiClient.post<Map<String, dynamic>>(
       '/api/v1/auth/google',
-      data: {'id_token': idToken},
+      data: {'id_token': idToken, 'device_name': _deviceName()},
     );
 
     final authResponse = AuthResponse.fromJson(response.data!);
 
     await Future.wait([
       _secureStorage.write(key: AppConfig.tokenKey, value: authResponse.token),
+      _secureStorage.write(
+          key: AppConfig.refreshTokenKey, value: authResponse.refreshToken),
       _secureStorage.write(key: AppConfig.userIdKey, value: authResponse.userId),
       _secureStorage.write(key: 'email', value: authResponse.email),
       _secureStorage.write(key: 'display_name', value: authResponse.displayName),
@@ -64,13 +75,61 @@ class AuthService {
     return authResponse;
   }
 
+  /// Exchanges the stored refresh token for a new access token. Returns
+  /// null if the session has ended and the user must sign in again.
+  Future<String?> refreshAccessToken() {
+    return _refreshing ??= _refresh().whenComplete(() => _refreshing = null);
+  }
+
+  Future<String?> _refresh() async {
+    final refreshToken =
+        await _secureStorage.read(key: AppConfig.refreshTokenKey);
+    if (refreshToken == null) return null;
+
+    try {
+      final response = await _apiClient.post<Map<String, dynamic>>(
+        '/api/v1/auth/refresh',
+        data: {'refresh_token': refreshToken},
+      );
+      final token = response.data!['token'] as String;
+      await Future.wait([
+        _secureStorage.write(key: AppConfig.tokenKey, value: token),
+        _secureStorage.write(
+          key: AppConfig.refreshTokenKey,
+          value: response.data!['refresh_token'] as String,
+        ),
+      ]);
+      return token;
+    } on DioException {
+      return null;
+    }
+  }
+
   Future<void> signOut() async {
+    final refreshToken =
+        await _secureStorage.read(key: AppConfig.refreshTokenKey);
+    if (refreshToken != null) {
+      try {
+        await _apiClient.post<void>(
+          '/api/v1/auth/logout',
+          data: {'refresh_token': refreshToken},
+        );
+      } on DioException {
+        // Signed out locally regardless; the session expires on its own.
+      }
+    }
+
     await Future.wait([
       _googleSignIn.signOut(),
       _secureStorage.deleteAll(),
     ]);
   }
 
+  String _deviceName() {
+    if (kIsWeb) return 'Web';
+    return defaultTargetPlatform.name;
+  }
+
   Future<AuthResponse?> getStoredCredentials() async {
     final token = await _secureStorage.read(key: AppConfig.tokenKey);
     if (token == null) return null;
@@ -44,6 +44,26 @@ class AuthNotifier extends StateNotifier<AuthState> {
     }
   }
 
+  /// Renews the access token after it expires. Signs out and returns null
+  /// if the session has ended.
+  Future<String?> refreshSession() async {
+    final current = state;
+    final token = await _authService.refreshAccessToken();
+    if (token == null) {
+      await signOut();
+      return null;
+    }
+    if (current is AuthAuthenticated) {
+      state = AuthAuthenticated(
+        token: token,
+        userId: current.userId,
+        email: current.email,
+        displayName: current.displayName,
+      );
+    }
+    return token;
+  }
+
   Future<void> signOut() async {
     await _authService.signOut();
     state = AuthUnauthenticated();
@@ -1,5 +1,5 @@
 -- Seed data for development/testing
--- Run this after init.sql
+-- Run this after the migrations (server migrate up)
 
 -- Sample events for the test user
 DO $$
@@ -116,7 +116,7 @@ wait_for_database() {
             if docker exec health-assistant-db psql -U healthuser -d health_assistant -tAc "SELECT EXISTS (SELECT FROM information_schema.tables WHERE table_name = 'sync_audit');" | grep -q 't'; then
                 print_success "Database schema initialized (sync_audit table exists)"
             else
-                print_warning "sync_audit table not found. Run migration: docker exec health-assistant-db psql -U healthuser -d health_assistant -f /docker-entrypoint-initdb.d/migrations/002_sync_audit.sql"
+                print_warning "sync_audit table not found. Start the backend or run: (cd backend && go run ./cmd/server migrate up)"
             fi
 
             return 0
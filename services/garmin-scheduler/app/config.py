@@ -15,6 +15,13 @@ class Settings(BaseSettings):
 
     # Ingestion service
     INGESTION_SERVICE_URL: str = "http://ingestion-service:8083"
+    # Per-user ingest token (create with: server create-ingest-token -user ID -name NAME).
+    # Data is stored for the token's user, whatever DEFAULT_USER_ID says.
+    INGEST_TOKEN: str = ""
+    # HMAC key requests are signed with; must be in the backend's INGEST_SIGNING_KEYS
+    # and bound to the users synced in INGEST_SIGNING_KEY_USERS
+    INGEST_KEY_ID: str = ""
+    INGEST_SIGNING_KEY: str = ""
 
     # Scheduler configuration
     SYNC_CRON_HOUR: str = "*"  # Every hour by default
@@ -1,13 +1,44 @@
 """Garmin API client wrapper for fetching health data."""
 
 import logging
-from datetime import date, datetime
+import re
+from datetime import date, datetime, timezone
 from typing import Optional, Dict, Any
 
 from garminconnect import Garmin, GarminConnectAuthenticationError, GarminConnectConnectionError
 
 logger = logging.getLogger(__name__)
 
+# Garmin encodes sleep stages in sleepLevels as numeric activity levels
+SLEEP_STAGE_BY_ACTIVITY_LEVEL = {0: "deep", 1: "light", 2: "rem", 3: "awake"}
+
+
+def _gmt_millis_to_iso(timestamp_ms: int) -> str:
+    """Convert a Garmin GMT epoch-milliseconds timestamp to RFC3339."""
+    dt = datetime.fromtimestamp(timestamp_ms / 1000, tz=timezone.utc)
+    return dt.strftime("%Y-%m-%dT%H:%M:%SZ")
+
+
+def _transform_sleep_levels(levels: list) -> list[Dict[str, Any]]:
+    """Convert Garmin sleepLevels entries to the ingestion hypnogram format."""
+    transformed = []
+    for level in levels:
+        stage = SLEEP_STAGE_BY_ACTIVITY_LEVEL.get(int(level.get("activityLevel", -1)))
+        start, end = level.get("startGMT"), level.get("endGMT")
+        if stage is None or not start or not end:
+            continue
+        transformed.append({
+            "stage": stage,
+            "start_gmt": datetime.fromisoformat(start).strftime("%Y-%m-%dT%H:%M:%SZ"),
+            "end_gmt": datetime.fromisoformat(end).strftime("%Y-%m-%dT%H:%M:%SZ"),
+        })
+    return transformed
+
+
+def _rename(data: Dict[str, Any], keys: Dict[str, str]) -> Dict[str, Any]:
+    """Copy the non-null Garmin fields in keys (garmin name -> ingestion name)."""
+    return {ours: data[theirs] for theirs, ours in keys.items() if data.get(theirs) is not None}
+
 
 class GarminClientWrapper:
     """Wrapper around garminconnect library for fetching health data."""
@@ -51,7 +82,8 @@ class GarminClientWrapper:
 
         Returns:
             Dict with keys: sleep_time_seconds, deep_sleep_seconds, light_sleep_seconds,
-            rem_sleep_seconds, awake_seconds, sleep_scores, average_hrv, sleep_end_timestamp_gmt
+            rem_sleep_seconds, awake_seconds, sleep_scores, average_hrv,
+            sleep_start_timestamp_gmt, sleep_end_timestamp_gmt, sleep_levels
         """
         if not self.client:
             raise RuntimeError("Client not connected. Call connect() first.")
@@ -67,7 +99,6 @@ class GarminClientWrapper:
 
             # Extract sleep metrics
             daily_sleep = sleep_data.get("dailySleepDTO", {})
-            sleep_levels = daily_sleep.get("sleepLevels", {})
 
             # Transform to ingestion format
             transformed = {
@@ -85,12 +116,16 @@ class GarminClientWrapper:
             if "averageHRV" in daily_sleep:
                 transformed["average_hrv"] = daily_sleep.get("averageHRV")
 
-            # Add sleep end timestamp
+            # Add sleep start/end timestamps
+            if "sleepStartTimestampGMT" in daily_sleep:
+                transformed["sleep_start_timestamp_gmt"] = _gmt_millis_to_iso(daily_sleep["sleepStartTimestampGMT"])
             if "sleepEndTimestampGMT" in daily_sleep:
-                # Convert Unix timestamp (milliseconds) to ISO format
-                timestamp_ms = daily_sleep["sleepEndTimestampGMT"]
-                dt = datetime.fromtimestamp(timestamp_ms / 1000)
-                transformed["sleep_end_timestamp_gmt"] = dt.isoformat() + "Z"
+                transformed["sleep_end_timestamp_gmt"] = _gmt_millis_to_iso(daily_sleep["sleepEndTimestampGMT"])
+
+            # Add per-epoch stage timeline (hypnogram) if available
+            sleep_levels = _transform_sleep_levels(sleep_data.get("sleepLevels") or [])
+            if sleep_levels:
+                transformed["sleep_levels"] = sleep_levels
 
             logger.info(f"Successfully fetched sleep data for {date_str}: {transformed['sleep_time_seconds']/60:.0f} min")
             return transformed
@@ -157,7 +192,8 @@ class GarminClientWrapper:
         Fetch HRV data for a specific date and transform to ingestion format.
 
         Returns:
-            Dict with keys: last_night_avg, weekly_avg, status
+            Dict with keys: last_night_avg, last_night_5min_high, weekly_avg,
+            status, baseline
         """
         if not self.client:
             raise RuntimeError("Client not connected. Call connect() first.")
@@ -169,14 +205,28 @@ class GarminClientWrapper:
             # Use dedicated HRV method
             hrv_data = self.client.get_hrv_data(date_str)
             if hrv_data:
+                # Garmin nests the nightly figures under hrvSummary
+                summary = hrv_data.get("hrvSummary") or hrv_data
                 transformed = {}
 
-                if "lastNightAvg" in hrv_data:
-                    transformed["last_night_avg"] = hrv_data.get("lastNightAvg")
-                if "weeklyAvg" in hrv_data:
-                    transformed["weekly_avg"] = hrv_data.get("weeklyAvg")
-                if "hrvStatus" in hrv_data:
-                    transformed["status"] = hrv_data.get("hrvStatus")
+                if summary.get("lastNightAvg") is not None:
+                    transformed["last_night_avg"] = summary["lastNightAvg"]
+                if summary.get("lastNight5MinHigh") is not None:
+                    transformed["last_night_5min_high"] = summary["lastNight5MinHigh"]
+                if summary.get("weeklyAvg") is not None:
+                    transformed["weekly_avg"] = summary["weeklyAvg"]
+                if summary.get("status"):
+                    transformed["status"] = summary["status"]
+                baseline = summary.get("baseline")
+                if baseline:
+                    transformed["baseline"] = {
+                        "low_upper": baseline.get("lowUpper"),
+                        "balanced_low": baseline.get("balancedLow"),
+                        "balanced_upper": baseline.get("balancedUpper"),
+                    }
+
+                if "last_night_avg" not in transformed:
+                    transformed = {}
 
                 if transformed:
                     logger.info(f"Successfully fetched HRV data for {date_str}")
@@ -329,3 +379,221 @@ class GarminClientWrapper:
         except Exception as e:
             logger.error(f"Error fetching body battery for {target_date}: {e}")
             return None
+
+    def get_spo2_data(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch pulse ox data for a specific date and transform to ingestion format.
+
+        Returns:
+            Dict with keys: average_spo2, lowest_spo2, average_sleep_spo2
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching SpO2 data for {date_str}")
+
+            spo2_data = self.client.get_spo2_data(date_str) or {}
+            transformed = _rename(spo2_data, {
+                "averageSpO2": "average_spo2",
+                "lowestSpO2": "lowest_spo2",
+                "avgSleepSpO2": "average_sleep_spo2",
+            })
+
+            if "average_spo2" in transformed:
+                logger.info(f"Successfully fetched SpO2 data for {date_str}")
+                return transformed
+
+            logger.info(f"No SpO2 data available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching SpO2 data for {target_date}: {e}")
+            return None
+
+    def get_respiration_data(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch respiration data for a specific date and transform to ingestion format.
+
+        Returns:
+            Dict with keys: average_waking, average_sleep, lowest, highest
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching respiration data for {date_str}")
+
+            respiration_data = self.client.get_respiration_data(date_str) or {}
+            transformed = _rename(respiration_data, {
+                "avgWakingRespirationValue": "average_waking",
+                "avgSleepRespirationValue": "average_sleep",
+                "lowestRespirationValue": "lowest",
+                "highestRespirationValue": "highest",
+            })
+
+            if transformed:
+                logger.info(f"Successfully fetched respiration data for {date_str}")
+                return transformed
+
+            logger.info(f"No respiration data available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching respiration data for {target_date}: {e}")
+            return None
+
+    def get_training_readiness(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch training readiness for a specific date and transform to ingestion format.
+
+        Returns:
+            Dict with keys: score, level, recovery_time_minutes, hrv_weekly_avg
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching training readiness for {date_str}")
+
+            # Garmin returns one entry per recalculation; the first is the latest
+            readiness = self.client.get_training_readiness(date_str)
+            if isinstance(readiness, list):
+                readiness = readiness[0] if readiness else {}
+            transformed = _rename(readiness or {}, {
+                "score": "score",
+                "level": "level",
+                "recoveryTime": "recovery_time_minutes",
+                "hrvWeeklyAverage": "hrv_weekly_avg",
+            })
+
+            if "score" in transformed:
+                logger.info(f"Successfully fetched training readiness for {date_str}")
+                return transformed
+
+            logger.info(f"No training readiness available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching training readiness for {target_date}: {e}")
+            return None
+
+    def get_training_status(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch training status for a specific date and transform to ingestion format.
+
+        Returns:
+            Dict with keys: status, acute_load, chronic_load, acute_chronic_ratio
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching training status for {date_str}")
+
+            training_status = self.client.get_training_status(date_str) or {}
+            latest = (training_status.get("mostRecentTrainingStatus") or {}).get("latestTrainingStatusData") or {}
+
+            # Keyed by device ID; the primary training device reports the status
+            for device_status in latest.values():
+                phrase = device_status.get("trainingStatusFeedbackPhrase")
+                if not phrase:
+                    continue
+
+                # Phrases carry a variant suffix, e.g. PRODUCTIVE_1
+                transformed = {"status": re.sub(r"_\d+$", "", phrase)}
+                transformed.update(_rename(device_status.get("acuteTrainingLoadDTO") or {}, {
+                    "dailyTrainingLoadAcute": "acute_load",
+                    "dailyTrainingLoadChronic": "chronic_load",
+                    "dailyAcuteChronicWorkloadRatio": "acute_chronic_ratio",
+                }))
+
+                logger.info(f"Successfully fetched training status for {date_str}")
+                return transformed
+
+            logger.info(f"No training status available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching training status for {target_date}: {e}")
+            return None
+
+    def get_vo2max(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch VO2max estimates for a specific date and transform to ingestion format.
+
+        Returns:
+            Dict with keys: running, cycling, fitness_age
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching VO2max for {date_str}")
+
+            max_metrics = self.client.get_max_metrics(date_str)
+            if isinstance(max_metrics, list):
+                max_metrics = max_metrics[0] if max_metrics else {}
+            max_metrics = max_metrics or {}
+
+            # "generic" holds the running estimate
+            generic = max_metrics.get("generic") or {}
+            cycling = max_metrics.get("cycling") or {}
+
+            transformed = {}
+            if generic.get("vo2MaxPreciseValue") is not None:
+                transformed["running"] = generic["vo2MaxPreciseValue"]
+            if cycling.get("vo2MaxPreciseValue") is not None:
+                transformed["cycling"] = cycling["vo2MaxPreciseValue"]
+            if generic.get("fitnessAge") is not None:
+                transformed["fitness_age"] = generic["fitnessAge"]
+
+            if "running" in transformed or "cycling" in transformed:
+                logger.info(f"Successfully fetched VO2max for {date_str}")
+                return transformed
+
+            logger.info(f"No VO2max available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching VO2max for {target_date}: {e}")
+            return None
+
+    def get_intensity_minutes(self, target_date: date) -> Optional[Dict[str, Any]]:
+        """
+        Fetch weekly intensity minutes and goal for a specific date and transform
+        to ingestion format.
+
+        Returns:
+            Dict with keys: weekly_moderate, weekly_vigorous, weekly_total, weekly_goal
+        """
+        if not self.client:
+            raise RuntimeError("Client not connected. Call connect() first.")
+
+        try:
+            date_str = target_date.isoformat()
+            logger.info(f"Fetching intensity minutes for {date_str}")
+
+            intensity = self.client.get_intensity_minutes_data(date_str) or {}
+            transformed = _rename(intensity, {
+                "weeklyModerate": "weekly_moderate",
+                "weeklyVigorous": "weekly_vigorous",
+                "weeklyTotal": "weekly_total",
+                "weekGoal": "weekly_goal",
+            })
+
+            if "weekly_goal" in transformed:
+                logger.info(f"Successfully fetched intensity minutes for {date_str}")
+                return transformed
+
+            logger.info(f"No intensity minutes available for {date_str}")
+            return None
+
+        except Exception as e:
+            logger.error(f"Error fetching intensity minutes for {target_date}: {e}")
+            return None
@@ -1,7 +1,11 @@
 """HTTP client for posting data to the Go ingestion service."""
 
+import hashlib
+import hmac
 import logging
-from typing import Dict, Any, Optional
+import secrets
+import time
+from typing import Dict, Any, List, Optional
 from datetime import date, datetime
 
 import httpx
@@ -12,17 +16,61 @@ logger = logging.getLogger(__name__)
 class IngestionClient:
     """Client for sending data to the Go ingestion service."""
 
-    def __init__(self, base_url: str, timeout: int = 30):
+    def __init__(
+        self,
+        base_url: str,
+        ingest_token: str = "",
+        key_id: str = "",
+        signing_key: str = "",
+        timeout: int = 30,
+    ):
         """
         Initialize ingestion client.
 
         Args:
             base_url: Base URL of the ingestion service (e.g., http://ingestion-service:8083)
+            ingest_token: Per-user ingest token, sent as X-Ingest-Token
+            key_id: ID of the HMAC key requests are signed with
+            signing_key: HMAC key; requests are unsigned without one
             timeout: Request timeout in seconds
         """
         self.base_url = base_url.rstrip("/")
         self.timeout = timeout
-        self.client = httpx.AsyncClient(timeout=timeout)
+        self.key_id = key_id
+        self.signing_key = signing_key
+        headers = {"X-Ingest-Token": ingest_token} if ingest_token else {}
+        event_hooks = {"request": [self._sign_request]} if signing_key else {}
+        self.client = httpx.AsyncClient(timeout=timeout, headers=headers, event_hooks=event_hooks)
+
+    @staticmethod
+    def signature(
+        key: str, method: str, request_uri: str, timestamp: str, nonce: str, body: bytes
+    ) -> str:
+        """
+        HMAC-SHA256 request signature, as verified by the Go service's signing package.
+
+        Signs METHOD, request URI, timestamp, nonce and the body's SHA-256, newline-joined.
+        """
+        string_to_sign = "\n".join([
+            method.upper(),
+            request_uri,
+            timestamp,
+            nonce,
+            hashlib.sha256(body).hexdigest(),
+        ])
+        return hmac.new(key.encode(), string_to_sign.encode(), hashlib.sha256).hexdigest()
+
+    async def _sign_request(self, request: httpx.Request):
+        """Add signature headers to an outgoing request."""
+        timestamp = str(int(time.time()))
+        nonce = secrets.token_hex(16)
+        request_uri = request.url.raw_path.decode()
+        request.headers["X-Ingest-Key-Id"] = self.key_id
+        request.headers["X-Ingest-Timestamp"] = timestamp
+        request.headers["X-Ingest-Nonce"] = nonce
+        request.headers["X-Ingest-Signature"] = self.signature(
+            self.signing_key, request.method, request_uri, timestamp, nonce, request.content
+        )
 
     async def close(self):
         """Close the HTTP client."""
@@ -256,6 +304,234 @@ class IngestionClient:
             logger.error(f"Error posting body battery: {e}")
             return None
 
+    async def post_spo2_data(
+        self,
+        user_id: str,
+        target_date: date,
+        spo2_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post pulse ox data to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the pulse ox data
+            spo2_data: Pulse ox metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/spo2"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "spo2_data": spo2_data,
+        }
+
+        try:
+            logger.info(f"Posting SpO2 data for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted SpO2 data: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting SpO2 data: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting SpO2 data: {e}")
+            return None
+
+    async def post_respiration_data(
+        self,
+        user_id: str,
+        target_date: date,
+        respiration_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post respiration data to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the respiration data
+            respiration_data: Respiration metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/respiration"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "respiration_data": respiration_data,
+        }
+
+        try:
+            logger.info(f"Posting respiration data for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted respiration data: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting respiration data: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting respiration data: {e}")
+            return None
+
+    async def post_training_readiness(
+        self,
+        user_id: str,
+        target_date: date,
+        training_readiness_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post training readiness data to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the training readiness data
+            training_readiness_data: Training readiness metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/training-readiness"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "training_readiness_data": training_readiness_data,
+        }
+
+        try:
+            logger.info(f"Posting training readiness for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted training readiness: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting training readiness: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting training readiness: {e}")
+            return None
+
+    async def post_training_status(
+        self,
+        user_id: str,
+        target_date: date,
+        training_status_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post training status data to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the training status data
+            training_status_data: Training status metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/training-status"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "training_status_data": training_status_data,
+        }
+
+        try:
+            logger.info(f"Posting training status for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted training status: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting training status: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting training status: {e}")
+            return None
+
+    async def post_vo2max(
+        self,
+        user_id: str,
+        target_date: date,
+        vo2max_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post VO2max estimates to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the VO2max data
+            vo2max_data: VO2max metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/vo2max"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "vo2max_data": vo2max_data,
+        }
+
+        try:
+            logger.info(f"Posting VO2max for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted VO2max: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting VO2max: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting VO2max: {e}")
+            return None
+
+    async def post_intensity_minutes(
+        self,
+        user_id: str,
+        target_date: date,
+        intensity_minutes_data: Dict[str, Any],
+    ) -> Optional[Dict[str, Any]]:
+        """
+        Post intensity minutes data to ingestion service.
+
+        Args:
+            user_id: User UUID
+            target_date: Date of the intensity minutes data
+            intensity_minutes_data: Intensity minutes metrics dictionary
+
+        Returns:
+            Response dict with status and was_inserted, or None if failed
+        """
+        url = f"{self.base_url}/api/v1/garmin/ingest/intensity-minutes"
+        payload = {
+            "user_id": user_id,
+            "date": target_date.isoformat(),
+            "intensity_minutes_data": intensity_minutes_data,
+        }
+
+        try:
+            logger.info(f"Posting intensity minutes for user {user_id} on {target_date}")
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            result = response.json()
+            logger.info(f"Successfully posted intensity minutes: {result.get('action', 'unknown')}")
+            return result
+        except httpx.HTTPStatusError as e:
+            logger.error(f"HTTP error posting intensity minutes: {e.response.status_code} - {e.response.text}")
+            return None
+        except Exception as e:
+            logger.error(f"Error posting intensity minutes: {e}")
+            return None
+
     async def post_sync_audit(
         self,
         user_id: str,
@@ -323,6 +599,114 @@ class IngestionClient:
             logger.error(f"Failed to post sync audit: {e}")
             return False
 
+    async def start_sync_audit(
+        self, user_id: str, data_type: str, target_date: date
+    ) -> Optional[str]:
+        """
+        Record a sync as running before it starts.
+
+        Args:
+            user_id: User UUID
+            data_type: Type of data being synced
+            target_date: Date of the data
+
+        Returns:
+            The audit ID to finish with finish_sync_audit, or None on error
+        """
+        url = f"{self.base_url}/api/v1/audit/sync/start"
+        payload = {
+            "user_id": user_id,
+            "data_type": data_type,
+            "target_date": target_date.isoformat(),
+        }
+
+        try:
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            return response.json().get("id")
+        except Exception as e:
+            logger.error(f"Failed to start sync audit: {e}")
+            return None
+
+    async def finish_sync_audit(
+        self,
+        audit_id: str,
+        user_id: str,
+        records_fetched: int,
+        records_inserted: int,
+        records_updated: int,
+        earliest_timestamp: Optional[str],
+        latest_timestamp: Optional[str],
+        status: str,
+        error_message: Optional[str] = None,
+    ) -> bool:
+        """
+        Complete or fail a sync started with start_sync_audit. The service
+        records the completion time and duration.
+
+        Args:
+            audit_id: ID returned by start_sync_audit
+            user_id: User UUID the sync was started for
+            records_fetched: Number of records fetched from Garmin
+            records_inserted: Number of new records inserted
+            records_updated: Number of existing records updated
+            earliest_timestamp: Earliest timestamp in the data
+            latest_timestamp: Latest timestamp in the data
+            status: Sync status ('success', 'partial', 'failed')
+            error_message: Error message if failed
+
+        Returns:
+            True if successful, False otherwise
+        """
+        action = "fail" if status == "failed" else "complete"
+        url = f"{self.base_url}/api/v1/audit/sync/{audit_id}/{action}"
+        payload = {
+            "user_id": user_id,
+            "records_fetched": records_fetched,
+            "records_inserted": records_inserted,
+            "records_updated": records_updated,
+        }
+        if status != "failed":
+            payload["status"] = status
+        if earliest_timestamp:
+            payload["earliest_timestamp"] = earliest_timestamp
+        if latest_timestamp:
+            payload["latest_timestamp"] = latest_timestamp
+        if error_message:
+            payload["error_message"] = error_message
+
+        try:
+            response = await self.client.post(url, json=payload)
+            response.raise_for_status()
+            return True
+        except Exception as e:
+            logger.error(f"Failed to finish sync audit {audit_id}: {e}")
+            return False
+
+    async def get_refetch_dates(self, user_id: str) -> Dict[str, List[date]]:
+        """
+        Get the recent dates with gaps in the synced data, to sync again.
+
+        Args:
+            user_id: User UUID (the ingest token's user takes precedence)
+
+        Returns:
+            Dates to re-fetch per data type; empty on error
+        """
+        url = f"{self.base_url}/api/v1/sync/refetch"
+
+        try:
+            response = await self.client.get(url, params={"user_id": user_id})
+            response.raise_for_status()
+            dates = response.json().get("dates") or {}
+            return {
+                data_type: [date.fromisoformat(d) for d in days]
+                for data_type, days in dates.items()
+            }
+        except Exception as e:
+            logger.error(f"Failed to get refetch dates: {e}")
+            return {}
+
     async def check_health(self) -> bool:
         """
         Check if the ingestion service is healthy.
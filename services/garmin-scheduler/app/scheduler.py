@@ -14,6 +14,17 @@ from .ingestion_client import IngestionClient
 
 logger = logging.getLogger(__name__)
 
+# Once-a-day metrics that sync the same way: data_type -> (Garmin client
+# method, ingestion client method, payload keyword)
+DAILY_METRICS = {
+    "spo2": ("get_spo2_data", "post_spo2_data", "spo2_data"),
+    "respiration": ("get_respiration_data", "post_respiration_data", "respiration_data"),
+    "training_readiness": ("get_training_readiness", "post_training_readiness", "training_readiness_data"),
+    "training_status": ("get_training_status", "post_training_status", "training_status_data"),
+    "vo2max": ("get_vo2max", "post_vo2max", "vo2max_data"),
+    "intensity_minutes": ("get_intensity_minutes", "post_intensity_minutes", "intensity_minutes_data"),
+}
+
 
 class GarminSyncScheduler:
     """Scheduler for periodic Garmin data synchronization."""
@@ -32,7 +43,12 @@ class GarminSyncScheduler:
             email=settings.GARMIN_EMAIL,
             password=settings.GARMIN_PASSWORD,
         )
-        self.ingestion_client = IngestionClient(base_url=settings.INGESTION_SERVICE_URL)
+        self.ingestion_client = IngestionClient(
+            base_url=settings.INGESTION_SERVICE_URL,
+            ingest_token=settings.INGEST_TOKEN,
+            key_id=settings.INGEST_KEY_ID,
+            signing_key=settings.INGEST_SIGNING_KEY,
+        )
 
         # Add sync job with cron schedule
         self.scheduler.add_job(
@@ -72,6 +88,7 @@ class GarminSyncScheduler:
         1. Connects to Garmin
         2. Fetches data for yesterday and today
         3. Posts to Go ingestion service
+        4. Re-fetches earlier days the service reports gaps for
         """
         # Prevent concurrent sync runs
         if self._sync_lock.locked():
@@ -97,6 +114,8 @@ class GarminSyncScheduler:
                 for target_date in [yesterday, today]:
                     await self._sync_date(target_date)
 
+                await self._sync_gaps(skip={yesterday, today})
+
                 logger.info("Garmin data sync completed successfully")
 
             except Exception as e:
@@ -154,6 +173,35 @@ class GarminSyncScheduler:
             user_id=user_id,
         )
 
+        # Sync pulse ox, respiration, training and intensity-minute metrics
+        for data_type in DAILY_METRICS:
+            await self._sync_data_type(
+                data_type=data_type,
+                target_date=target_date,
+                user_id=user_id,
+            )
+
+    async def _sync_gaps(self, skip: set):
+        """
+        Sync again the recent days the ingestion service has no data for.
+
+        Args:
+            skip: Dates just synced, which are not retried in the same run
+        """
+        user_id = settings.DEFAULT_USER_ID
+        refetch = await self.ingestion_client.get_refetch_dates(user_id)
+
+        for data_type, dates in sorted(refetch.items()):
+            for target_date in dates:
+                if target_date in skip:
+                    continue
+                logger.info(f"Re-fetching {data_type} for {target_date} to fill a gap")
+                await self._sync_data_type(
+                    data_type=data_type,
+                    target_date=target_date,
+                    user_id=user_id,
+                )
+
     async def _sync_data_type(self, data_type: str, target_date: date, user_id: str):
         """
         Sync a specific data type with full audit tracking.
@@ -172,6 +220,13 @@ class GarminSyncScheduler:
         earliest_timestamp = None
         latest_timestamp = None
 
+        # Record the sync as running; if that fails it is recorded in one go at the end
+        audit_id = await self.ingestion_client.start_sync_audit(
+            user_id=user_id,
+            data_type=data_type,
+            target_date=target_date,
+        )
+
         try:
             # Fetch data from Garmin
             if data_type == "sleep":
@@ -272,6 +327,21 @@ class GarminSyncScheduler:
                     else:
                         records_updated = 1
 
+            elif data_type in DAILY_METRICS:
+                fetch, post, keyword = DAILY_METRICS[data_type]
+                data = getattr(self.garmin_client, fetch)(target_date)
+                if data:
+                    records_fetched = 1
+                    response = await getattr(self.ingestion_client, post)(
+                        user_id=user_id,
+                        target_date=target_date,
+                        **{keyword: data},
+                    )
+                    if response and response.get("was_inserted"):
+                        records_inserted = 1
+                    else:
+                        records_updated = 1
+
             if records_fetched > 0:
                 logger.info(
                     f"{data_type.capitalize()} sync for {target_date}: "
@@ -284,6 +354,20 @@ class GarminSyncScheduler:
             logger.error(f"Error syncing {data_type} data for {target_date}: {e}")
 
         # Record audit
+        if audit_id:
+            await self.ingestion_client.finish_sync_audit(
+                audit_id=audit_id,
+                user_id=user_id,
+                records_fetched=records_fetched,
+                records_inserted=records_inserted,
+                records_updated=records_updated,
+                earliest_timestamp=earliest_timestamp,
+                latest_timestamp=latest_timestamp,
+                status=status,
+                error_message=error_message,
+            )
+            return
+
         sync_completed_at = datetime.utcnow()
         sync_duration_seconds = int((sync_completed_at - sync_started_at).total_seconds())
 
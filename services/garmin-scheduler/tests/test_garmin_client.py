@@ -31,6 +31,12 @@ def test_connect_requires_credentials():
     assert hasattr(client, 'get_activity_data')
     assert hasattr(client, 'get_hrv_data')
     assert hasattr(client, 'get_stress_data')
+    assert hasattr(client, 'get_spo2_data')
+    assert hasattr(client, 'get_respiration_data')
+    assert hasattr(client, 'get_training_readiness')
+    assert hasattr(client, 'get_training_status')
+    assert hasattr(client, 'get_vo2max')
+    assert hasattr(client, 'get_intensity_minutes')
 
 
 def test_data_methods_require_connection():
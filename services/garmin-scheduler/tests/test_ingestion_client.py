@@ -39,5 +39,18 @@ def test_client_methods_exist():
     assert hasattr(client, 'post_activity_data')
     assert hasattr(client, 'post_hrv_data')
     assert hasattr(client, 'post_stress_data')
+    assert hasattr(client, 'post_spo2_data')
+    assert hasattr(client, 'post_respiration_data')
+    assert hasattr(client, 'post_training_readiness')
+    assert hasattr(client, 'post_training_status')
+    assert hasattr(client, 'post_vo2max')
+    assert hasattr(client, 'post_intensity_minutes')
     assert hasattr(client, 'post_sync_audit')
     assert hasattr(client, 'check_health')
+
+
+def test_signature_matches_go_service():
+    """Test the request signature against a vector shared with the Go signing package."""
+    signature = IngestionClient.signature("k", "post", "/p", "1", "n", b"{}")
+
+    assert signature == "dc533d121478677b31b401e711c106dd27430e570fd95d76d2af2cccdb71f11c"